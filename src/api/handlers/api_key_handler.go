@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler manages the API keys of the enterprise
+type APIKeyHandler struct {
+	apiKeyRepo interfaces.APIKeyRepository
+	apiKeys    *auth.APIKeys
+}
+
+func NewAPIKeyHandler(apiKeyRepo interfaces.APIKeyRepository, apiKeys *auth.APIKeys) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyRepo: apiKeyRepo,
+		apiKeys:    apiKeys,
+	}
+}
+
+// CreateAPIKeyRequest represents a new API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required"`
+	// Scopes are read scopes such as "menus:read", "suites:read" or "files:read"
+	Scopes []string `json:"scopes" validate:"required"`
+	// RateLimit is the number of requests allowed per minute, zero for the default
+	RateLimit int        `json:"rate_limit,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAPIKeyResponse shows a new API key, the only time its secret is visible
+type CreateAPIKeyResponse struct {
+	APIKey  *entities.APIKey `json:"api_key"`
+	Key     string           `json:"key"`
+	Message string           `json:"message"`
+}
+
+// CreateAPIKey creates an API key
+// @Summary Create API key
+// @Description Create a long-lived, read-only API key for a device of the caller's enterprise. Devices send it in the X-API-Key header. The key is shown only in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param api_key body CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} CreateAPIKeyResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	req, err := Bind[CreateAPIKeyRequest](c)
+	if err != nil {
+		return err
+	}
+
+	scopes, err := auth.ParseAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid scopes: "+err.Error())
+	}
+	if req.RateLimit < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Rate limit cannot be negative")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return fiber.NewError(fiber.StatusBadRequest, "Expiration must be in the future")
+	}
+
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	secret, prefix, hash, err := h.apiKeys.Generate()
+	if err != nil {
+		return problem.Internal(err, "Failed to generate API key")
+	}
+
+	apiKey := &entities.APIKey{
+		EnterpriseID: enterpriseID,
+		Name:         req.Name,
+		Prefix:       prefix,
+		KeyHash:      hash,
+		Scopes:       scopes,
+		RateLimit:    req.RateLimit,
+		CreatedBy:    &userID,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := h.apiKeyRepo.Create(c.Context(), apiKey); err != nil {
+		return problem.Internal(err, "Failed to create API key")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(CreateAPIKeyResponse{
+		APIKey:  apiKey,
+		Key:     secret,
+		Message: "Store the key somewhere safe, it is not shown again",
+	})
+}
+
+// GetAPIKeys lists the API keys of the enterprise
+// @Summary List API keys
+// @Description List the API keys of the caller's enterprise, revoked and expired ones included, newest first
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entities.APIKey
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.apiKeyRepo.GetAll(c.Context())
+	if err != nil {
+		return problem.Internal(err, "Failed to fetch API keys")
+	}
+
+	return c.JSON(keys)
+}
+
+// GetAPIKeyByID gets an API key by ID
+// @Summary Get API key by ID
+// @Description Get an API key of the caller's enterprise, with when and from where it was last used
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} entities.APIKey
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKeyByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid API key ID")
+	}
+
+	key, err := h.apiKeyRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "API key")
+	}
+
+	return c.JSON(key)
+}
+
+// RevokeAPIKey revokes an API key
+// @Summary Revoke API key
+// @Description Revoke an API key of the caller's enterprise. Devices using it are rejected from their next request on.
+// @Tags api-keys
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid API key ID")
+	}
+
+	if _, err := h.apiKeyRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "API key")
+	}
+	if err := h.apiKeyRepo.Revoke(c.Context(), id); err != nil {
+		return problem.Internal(err, "Failed to revoke API key")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
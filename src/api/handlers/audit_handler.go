@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditLogRepo interfaces.AuditLogRepository
+}
+
+func NewAuditHandler(auditLogRepo interfaces.AuditLogRepository) *AuditHandler {
+	return &AuditHandler{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// GetAuditLogs searches the audit log
+// @Summary Search audit logs
+// @Description Search the audit log by entity, user, action and date range, newest first
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string false "Entity type (table name, e.g. menus)"
+// @Param entity_id query string false "Entity ID"
+// @Param user_id query string false "Acting user ID"
+// @Param enterprise_id query string false "Enterprise ID"
+// @Param action query string false "Action" Enums(create, update, delete, restore, login, logout)
+// @Param from query string false "Start of the date range (RFC3339)"
+// @Param to query string false "End of the date range (RFC3339)"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} entities.AuditLog
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /audit-logs [get]
+func (h *AuditHandler) GetAuditLogs(c *fiber.Ctx) error {
+	filters := interfaces.AuditLogSearchFilters{}
+
+	if entityType := c.Query("entity_type"); entityType != "" {
+		filters.EntityType = &entityType
+	}
+
+	for param, target := range map[string]**uuid.UUID{
+		"entity_id":     &filters.EntityID,
+		"user_id":       &filters.UserID,
+		"enterprise_id": &filters.EnterpriseID,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid "+param)
+		}
+		*target = &id
+	}
+
+	if action := c.Query("action"); action != "" {
+		auditAction := entities.AuditAction(action)
+		filters.Action = &auditAction
+	}
+
+	for param, target := range map[string]**time.Time{
+		"from": &filters.From,
+		"to":   &filters.To,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid "+param+" date, expected RFC3339")
+		}
+		*target = &t
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	logs, err := h.auditLogRepo.Search(c.Context(), filters, limit, offset)
+	if err != nil {
+		return problem.Internal(err, "Failed to search audit logs")
+	}
+
+	return c.JSON(logs)
+}
+
+// GetAuditLogByID gets an audit log entry by ID
+// @Summary Get audit log entry by ID
+// @Description Get a single audit log entry with its before/after values
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Audit log ID"
+// @Success 200 {object} entities.AuditLog
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /audit-logs/{id} [get]
+func (h *AuditHandler) GetAuditLogByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid audit log ID")
+	}
+
+	log, err := h.auditLogRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Audit log entry")
+	}
+
+	return c.JSON(log)
+}
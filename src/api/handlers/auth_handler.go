@@ -1,12 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/url"
+	"strconv"
 	"time"
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/audit"
 	"terra-allwert/infra/auth"
+	"terra-allwert/infra/mail"
 	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,21 +24,50 @@ import (
 
 // AuthHandler handles authentication operations
 type AuthHandler struct {
-	userRepo   interfaces.UserRepository
-	jwtService *auth.JWTService
+	userRepo     interfaces.UserRepository
+	auditLogRepo interfaces.AuditLogRepository
+	sessionRepo  interfaces.SessionRepository
+	jwtService   *auth.JWTService
+	emails       *AuthEmails
+	mfa          *AuthMFA
+	lockout      *auth.LoginLockout
+	// oidc is nil when single sign-on is not configured
+	oidc *AuthOIDC
+}
+
+// AuthEmails holds what the auth handler needs to send account emails
+type AuthEmails struct {
+	Mailer    interfaces.Mailer
+	Templates *mail.Templates
+	// PasswordResets issues the tokens of the links sent to PasswordResetURL
+	PasswordResets   *auth.OneTimeTokens
+	PasswordResetURL string
+	// Verifications issues the tokens of the links sent to VerificationURL, resent at
+	// most once per VerificationResends interval for each address
+	Verifications       *auth.OneTimeTokens
+	VerificationURL     string
+	VerificationResends *auth.Throttle
+	VerificationPolicy  auth.VerificationPolicy
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(userRepo interfaces.UserRepository, jwtService *auth.JWTService) *AuthHandler {
+func NewAuthHandler(userRepo interfaces.UserRepository, auditLogRepo interfaces.AuditLogRepository, sessionRepo interfaces.SessionRepository, jwtService *auth.JWTService, emails *AuthEmails, mfa *AuthMFA, lockout *auth.LoginLockout, oidc *AuthOIDC) *AuthHandler {
 	return &AuthHandler{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+		sessionRepo:  sessionRepo,
+		jwtService:   jwtService,
+		emails:       emails,
+		mfa:          mfa,
+		lockout:      lockout,
+		oidc:         oidc,
 	}
 }
 
 // LoginRequest represents login request payload
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	// Email is only required, accounts are looked up by the address they were given
+	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required,min=6"`
 }
 
@@ -50,134 +88,143 @@ type RefreshTokenRequest struct {
 // AuthResponse represents authentication response
 type AuthResponse struct {
 	User      *UserResponse   `json:"user"`
-	TokenPair *auth.TokenPair `json:"tokens"`
+	TokenPair *auth.TokenPair `json:"tokens,omitempty"`
 	Message   string          `json:"message"`
+	// MFASetupRequired tells the client the tokens only allow setting up two-factor
+	// authentication, which the enterprise requires for the role of the user
+	MFASetupRequired bool `json:"mfa_setup_required,omitempty"`
 }
 
 // UserResponse represents user data in responses (without sensitive info)
 type UserResponse struct {
-	ID           uuid.UUID         `json:"id"`
-	Email        string            `json:"email"`
-	Name         string            `json:"name"`
-	Role         entities.UserRole `json:"role"`
-	EnterpriseID uuid.UUID         `json:"enterprise_id"`
-	CreatedAt    time.Time         `json:"created_at"`
+	ID              uuid.UUID         `json:"id"`
+	Email           string            `json:"email"`
+	Name            string            `json:"name"`
+	Role            entities.UserRole `json:"role"`
+	EnterpriseID    uuid.UUID         `json:"enterprise_id"`
+	EmailVerifiedAt *time.Time        `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
 }
 
 // Login authenticates a user
 // @Summary User login
-// @Description Authenticate user with email and password
+// @Description Authenticate user with email and password. Users with two-factor authentication get an MFAChallengeResponse instead of tokens, to complete at /auth/login/mfa. Failed logins delay the next attempt, increasingly, and too many lock the account or client address for a while.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "Login credentials"
 // @Success 200 {object} AuthResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 202 {object} MFAChallengeResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem "Email not verified"
+// @Failure 429 {object} problem.Problem "Too many failed logins, see Retry-After"
+// @Failure 500 {object} problem.Problem
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available - user repository not initialized",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available - user repository not initialized")
 	}
-	
+
 	if h.jwtService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available - JWT service not initialized",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available - JWT service not initialized")
 	}
 
-	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[LoginRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkLoginAttempts(c, req.Email); err != nil {
+		return err
 	}
 
 	// Find user by email
 	user, err := h.userRepo.GetByEmail(c.Context(), req.Email)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
-		})
+		h.countLoginFailure(c, req.Email, nil)
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid email or password")
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
-		})
+		h.countLoginFailure(c, req.Email, user)
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid email or password")
+	}
+
+	if user.EmailVerifiedAt == nil && h.emails.VerificationPolicy == auth.VerificationLogin {
+		return fiber.NewError(fiber.StatusForbidden, "Email address not verified")
 	}
 
-	// Generate token pair
-	tokenPair, err := h.jwtService.GenerateTokenPair(user)
+	enrollment, err := h.mfaEnrollment(c, user.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate tokens",
-		})
+		return problem.Internal(err, "Failed to check two-factor authentication")
+	}
+	if enrollment.Enabled() {
+		// Failures are cleared once the second factor is verified too
+		return h.challengeMFA(c, user)
+	}
+	mfaSetupRequired, err := h.mfaRequired(c, user)
+	if err != nil {
+		return problem.Internal(err, "Failed to check two-factor authentication")
 	}
+	h.clearLoginFailures(c, user.Email)
 
-	// Prepare response
-	userResponse := &UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		Name:         user.Name,
-		Role:         user.Role,
-		EnterpriseID: user.EnterpriseID,
-		CreatedAt:    user.CreatedAt,
+	// Start a session and generate its token pair
+	tokenPair, err := h.startSession(c, user, mfaSetupRequired)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate tokens")
+	}
+
+	if err := h.recordAuthEvent(c, entities.AuditActionLogin, user.ID, user.EnterpriseID); err != nil {
+		return problem.Internal(err, "Failed to record login")
 	}
 
 	return c.JSON(AuthResponse{
-		User:      userResponse,
-		TokenPair: tokenPair,
-		Message:   "Login successful",
+		User:             newUserResponse(user),
+		TokenPair:        tokenPair,
+		Message:          "Login successful",
+		MFASetupRequired: mfaSetupRequired,
 	})
 }
 
 // Register creates a new user account
 // @Summary User registration
-// @Description Create a new user account
+// @Description Create a new user account and email it a verification link. Visitors may register themselves; registering a manager or admin requires the bearer token of an admin of the same enterprise. No tokens are returned while unverified users cannot log in.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param registration body RegisterRequest true "Registration data"
 // @Success 201 {object} AuthResponse
-// @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem "Enterprise of another tenant"
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil || h.jwtService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
-	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[RegisterRequest](c)
+	if err != nil {
+		return err
 	}
 
 	// Check if user already exists
 	existingUser, err := h.userRepo.GetByEmail(c.Context(), req.Email)
 	if err == nil && existingUser != nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error": "User with this email already exists",
-		})
+		return fiber.NewError(fiber.StatusConflict, "User with this email already exists")
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process password",
-		})
+		return problem.Internal(err, "Failed to process password")
 	}
 
 	// Set default role if not provided
@@ -185,6 +232,21 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	if role == "" {
 		role = entities.UserRoleVisitor
 	}
+	if role == entities.UserRoleSuperAdmin {
+		return fiber.NewError(fiber.StatusForbidden, "Super admins cannot self-register")
+	}
+
+	switch role {
+	case entities.UserRoleVisitor, entities.UserRoleManager, entities.UserRoleAdmin:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid role")
+	}
+
+	// Anyone may register as a visitor, elevated roles are granted by an admin
+	callerRole, _ := c.Locals("user_role").(entities.UserRole)
+	if !auth.CanGrant(callerRole, role) {
+		return fiber.NewError(fiber.StatusForbidden, "Only admins can register users with elevated roles")
+	}
 
 	// Create user
 	user := &entities.User{
@@ -199,97 +261,429 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	if err := h.userRepo.Create(c.Context(), user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create user",
+		return problem.Internal(err, "Failed to create user")
+	}
+
+	// The account exists either way, a lost email can be resent
+	if err := h.sendVerification(c, user); err != nil {
+		slog.WarnContext(c.Context(), "Failed to send verification email", "error", err)
+	}
+
+	if h.emails.VerificationPolicy == auth.VerificationLogin {
+		return c.Status(fiber.StatusCreated).JSON(AuthResponse{
+			User:    newUserResponse(user),
+			Message: "Registration successful, verify your email address to log in",
 		})
 	}
 
-	// Generate token pair
-	tokenPair, err := h.jwtService.GenerateTokenPair(user)
+	mfaSetupRequired, err := h.mfaRequired(c, user)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate tokens",
-		})
+		return problem.Internal(err, "Failed to check two-factor authentication")
 	}
 
-	// Prepare response
-	userResponse := &UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		Name:         user.Name,
-		Role:         user.Role,
-		EnterpriseID: user.EnterpriseID,
-		CreatedAt:    user.CreatedAt,
+	// Start a session and generate its token pair
+	tokenPair, err := h.startSession(c, user, mfaSetupRequired)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate tokens")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(AuthResponse{
-		User:      userResponse,
-		TokenPair: tokenPair,
-		Message:   "Registration successful",
+		User:             newUserResponse(user),
+		TokenPair:        tokenPair,
+		MFASetupRequired: mfaSetupRequired,
+		Message:          "Registration successful",
 	})
 }
 
-// RefreshToken generates new tokens using refresh token
+// RefreshToken rotates the refresh token of a session
 // @Summary Refresh access token
-// @Description Get new access token using refresh token
+// @Description Exchange a refresh token for a new token pair. Refresh tokens are single use: reusing one revokes its session.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param refresh body RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} auth.TokenPair
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.jwtService == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
-	var req RefreshTokenRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[RefreshTokenRequest](c)
+	if err != nil {
+		return err
+	}
+
+	claims, err := h.jwtService.ValidateRefreshToken(req.RefreshToken)
+	if err != nil || claims.SessionID == uuid.Nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+	revoked, err := h.jwtService.IsRefreshTokenRevoked(c.Context(), claims)
+	if err != nil {
+		return problem.Internal(err, "Failed to verify refresh token")
+	}
+	session, err := h.sessionRepo.GetByID(c.Context(), claims.SessionID)
+	if revoked || err != nil || session.UserID != claims.UserID || !session.Active(time.Now()) {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	// A refresh token is used once. Presenting a rotated one means it leaked, so the
+	// whole session goes, including whoever holds the newer tokens.
+	if session.RefreshTokenID != claims.ID {
+		return h.rejectReuse(c, session)
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), claims.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	// Users asked to set up a second factor keep restricted tokens until they do
+	mfaSetupRequired, err := h.mfaSetupPending(c, user)
+	if err != nil {
+		return problem.Internal(err, "Failed to check two-factor authentication")
 	}
 
-	// Refresh tokens
-	tokenPair, err := h.jwtService.RefreshAccessToken(req.RefreshToken, func(userID uuid.UUID) (*entities.User, error) {
-		return h.userRepo.GetByID(c.Context(), userID)
+	tokenPair, err := h.jwtService.GenerateTokenPair(c.Context(), user, session.ID, mfaSetupRequired)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate tokens")
+	}
+
+	actor := audit.ActorFromContext(c.Context())
+	rotated, err := h.sessionRepo.Rotate(c.Context(), session.ID, claims.ID, interfaces.SessionRotation{
+		RefreshTokenID: tokenPair.RefreshTokenID,
+		ExpiresAt:      tokenPair.RefreshTokenExpiresAt,
+		UsedAt:         time.Now(),
+		IPAddress:      actor.IPAddress,
+		UserAgent:      actor.UserAgent,
 	})
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid or expired refresh token",
-		})
+		return problem.Internal(err, "Failed to rotate refresh token")
+	}
+	if !rotated {
+		// Another request used the same token first
+		return h.rejectReuse(c, session)
 	}
 
 	return c.JSON(tokenPair)
 }
 
-// Logout invalidates user tokens (if blacklist is implemented)
+// startSession creates a session for the user and issues its first token pair
+func (h *AuthHandler) startSession(c *fiber.Ctx, user *entities.User, mfaSetupRequired bool) (*auth.TokenPair, error) {
+	sessionID := uuid.New()
+	tokenPair, err := h.jwtService.GenerateTokenPair(c.Context(), user, sessionID, mfaSetupRequired)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := audit.ActorFromContext(c.Context())
+	now := time.Now()
+	session := &entities.UserSession{
+		ID:             sessionID,
+		UserID:         user.ID,
+		RefreshTokenID: tokenPair.RefreshTokenID,
+		UserAgent:      actor.UserAgent,
+		IPAddress:      actor.IPAddress,
+		CreatedAt:      now,
+		LastUsedAt:     now,
+		ExpiresAt:      tokenPair.RefreshTokenExpiresAt,
+	}
+	if err := h.sessionRepo.Create(c.Context(), session); err != nil {
+		return nil, err
+	}
+	return tokenPair, nil
+}
+
+// revokeSession ends a session and every token issued to it
+func (h *AuthHandler) revokeSession(c *fiber.Ctx, session *entities.UserSession) error {
+	if err := h.sessionRepo.Revoke(c.Context(), session.ID); err != nil {
+		return err
+	}
+	return h.jwtService.RevokeSession(c.Context(), session.ID, session.ExpiresAt)
+}
+
+// rejectReuse revokes a session whose refresh token was used twice
+func (h *AuthHandler) rejectReuse(c *fiber.Ctx, session *entities.UserSession) error {
+	if err := h.revokeSession(c, session); err != nil {
+		return problem.Internal(err, "Failed to revoke session")
+	}
+	return fiber.NewError(fiber.StatusUnauthorized, "Refresh token reuse detected, session revoked")
+}
+
+// LogoutRequest represents logout request payload
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Logout revokes the current tokens
 // @Summary User logout
-// @Description Invalidate user tokens
+// @Description Revoke the session of the access token, with every token issued to it, and the given refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param logout body LogoutRequest false "Refresh token to revoke"
 // @Success 200 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	// In a complete implementation, you would:
-	// 1. Extract token from header
-	// 2. Add token to blacklist
-	// 3. Return success
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, _ := middleware.GetEnterpriseFromContext(c)
+
+	var req LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	// RequireAuth already validated the access token
+	accessToken, _ := auth.ExtractTokenFromAuthHeader(c.Get("Authorization"))
+	tokens := []string{accessToken}
+	if req.RefreshToken != "" {
+		// Only the owner may revoke a refresh token
+		claims, err := h.jwtService.ValidateRefreshToken(req.RefreshToken)
+		if err != nil || claims.UserID != userID {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid refresh token")
+		}
+		tokens = append(tokens, req.RefreshToken)
+	}
+
+	for _, token := range tokens {
+		if err := h.jwtService.RevokeToken(c.Context(), token); err != nil {
+			return problem.Internal(err, "Failed to revoke token")
+		}
+	}
+
+	// End the session of the access token, so its other tokens go too
+	if sessionID, ok := c.Locals("session_id").(uuid.UUID); ok && sessionID != uuid.Nil {
+		if session, err := h.sessionRepo.GetByID(c.Context(), sessionID); err == nil && session.UserID == userID {
+			if err := h.revokeSession(c, session); err != nil {
+				return problem.Internal(err, "Failed to revoke session")
+			}
+		}
+	}
+
+	if err := h.recordAuthEvent(c, entities.AuditActionLogout, userID, enterpriseID); err != nil {
+		return problem.Internal(err, "Failed to record logout")
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Logout successful",
 	})
 }
 
+// LogoutAll revokes every token of the user
+// @Summary Log out everywhere
+// @Description Revoke every access and refresh token issued to the authenticated user, on all devices
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, _ := middleware.GetEnterpriseFromContext(c)
+
+	if err := h.jwtService.RevokeAllTokens(c.Context(), userID); err != nil {
+		return problem.Internal(err, "Failed to revoke tokens")
+	}
+	if err := h.sessionRepo.RevokeAllForUser(c.Context(), userID); err != nil {
+		return problem.Internal(err, "Failed to revoke sessions")
+	}
+
+	if err := h.recordAuthEvent(c, entities.AuditActionLogout, userID, enterpriseID); err != nil {
+		return problem.Internal(err, "Failed to record logout")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out on all devices",
+	})
+}
+
+// SessionResponse represents a session of the user
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// GetSessions lists the sessions of the user
+// @Summary List sessions
+// @Description List the active sessions of the authenticated user, one per login, most recently used first
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} SessionResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	currentID, _ := c.Locals("session_id").(uuid.UUID)
+
+	sessions, err := h.sessionRepo.GetActiveByUserID(c.Context(), userID)
+	if err != nil {
+		return problem.Internal(err, "Failed to fetch sessions")
+	}
+
+	responses := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	return c.JSON(responses)
+}
+
+// RevokeSession revokes a session of the user
+// @Summary Revoke session
+// @Description Revoke a session of the authenticated user and every token issued to it
+// @Tags auth
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid session ID")
+	}
+
+	session, err := h.sessionRepo.GetByID(c.Context(), id)
+	if err != nil || session.UserID != userID || !session.Active(time.Now()) {
+		return lookupError(err, "Session")
+	}
+
+	if err := h.revokeSession(c, session); err != nil {
+		return problem.Internal(err, "Failed to revoke session")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// recordAuthEvent writes a login or logout entry to the audit log
+func (h *AuthHandler) recordAuthEvent(c *fiber.Ctx, action entities.AuditAction, userID, enterpriseID uuid.UUID) error {
+	if h.auditLogRepo == nil {
+		return nil
+	}
+
+	entry := audit.NewEntry(c.Context(), action, "users", userID, nil, nil)
+	entry.UserID = &userID
+	if enterpriseID != uuid.Nil {
+		entry.EnterpriseID = &enterpriseID
+	}
+	return h.auditLogRepo.Create(c.Context(), entry)
+}
+
+// checkLoginAttempts rejects logins to the account, or from the client address, that
+// are locked out or backing off
+func (h *AuthHandler) checkLoginAttempts(c *fiber.Ctx, email string) error {
+	wait, err := h.lockout.Check(c.Context(), email, c.IP())
+	if err != nil {
+		return problem.Internal(err, "Failed to check login attempts")
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	setRetryAfter(c, wait)
+	return fiber.NewError(fiber.StatusTooManyRequests, "Too many failed login attempts, try again later")
+}
+
+// countLoginFailure records a failed login, auditing the lockouts it starts. user is nil
+// when no account has the email.
+func (h *AuthHandler) countLoginFailure(c *fiber.Ctx, email string, user *entities.User) {
+	failure, err := h.lockout.Fail(c.Context(), email, c.IP())
+	if err != nil {
+		slog.WarnContext(c.Context(), "Failed to record failed login", "error", err)
+		return
+	}
+	if failure.Wait > 0 {
+		setRetryAfter(c, failure.Wait)
+	}
+
+	for scope, locked := range map[string]bool{"account": failure.AccountLocked, "ip": failure.IPLocked} {
+		if !locked {
+			continue
+		}
+		if err := h.recordLockout(c, scope, user, failure); err != nil {
+			slog.WarnContext(c.Context(), "Failed to audit login lockout", "error", err)
+		}
+	}
+}
+
+// recordLockout writes a lockout to the audit log, against the account when it exists
+func (h *AuthHandler) recordLockout(c *fiber.Ctx, scope string, user *entities.User, failure auth.LoginFailure) error {
+	if h.auditLogRepo == nil {
+		return nil
+	}
+
+	var entityID uuid.UUID
+	if user != nil {
+		entityID = user.ID
+	}
+	entry := audit.NewEntry(c.Context(), entities.AuditActionLockout, "users", entityID, nil, entities.JSONValues{
+		"scope":        scope,
+		"attempts":     failure.Attempts,
+		"locked_until": time.Now().Add(failure.Wait),
+	})
+	if user != nil {
+		entry.UserID = &user.ID
+		entry.EnterpriseID = &user.EnterpriseID
+	}
+	return h.auditLogRepo.Create(c.Context(), entry)
+}
+
+// setRetryAfter tells the client how many whole seconds to wait before retrying
+func setRetryAfter(c *fiber.Ctx, wait time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// clearLoginFailures forgets the failed logins of an account once its owner logged in
+func (h *AuthHandler) clearLoginFailures(c *fiber.Ctx, email string) {
+	if err := h.lockout.Reset(c.Context(), email); err != nil {
+		slog.WarnContext(c.Context(), "Failed to clear failed logins", "error", err)
+	}
+}
+
 // GetProfile returns current user profile
 // @Summary Get user profile
 // @Description Get authenticated user's profile information
@@ -297,15 +691,13 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} UserResponse
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/profile [get]
 func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
 	userID, err := middleware.GetUserFromContext(c)
@@ -315,42 +707,29 @@ func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 
 	user, err := h.userRepo.GetByID(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get user profile",
-		})
-	}
-
-	userResponse := &UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		Name:         user.Name,
-		Role:         user.Role,
-		EnterpriseID: user.EnterpriseID,
-		CreatedAt:    user.CreatedAt,
+		return problem.Internal(err, "Failed to get user profile")
 	}
 
-	return c.JSON(userResponse)
+	return c.JSON(newUserResponse(user))
 }
 
 // UpdateProfile updates user profile
 // @Summary Update user profile
-// @Description Update authenticated user's profile information
+// @Description Update authenticated user's profile information. A new email address has to be verified again.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param profile body UpdateProfileRequest true "Profile data"
 // @Success 200 {object} UserResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/profile [put]
 func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
 	userID, err := middleware.GetUserFromContext(c)
@@ -358,47 +737,41 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		return err
 	}
 
-	var req UpdateProfileRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateProfileRequest](c)
+	if err != nil {
+		return err
 	}
 
 	user, err := h.userRepo.GetByID(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get user",
-		})
+		return problem.Internal(err, "Failed to get user")
 	}
 
 	// Update fields if provided
 	if req.Name != "" {
 		user.Name = req.Name
 	}
-	if req.Email != "" {
+	// A new address has to be verified again
+	emailChanged := req.Email != "" && req.Email != user.Email
+	if emailChanged {
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
 	}
 
 	now := time.Now()
 	user.UpdatedAt = &now
 
 	if err := h.userRepo.Update(c.Context(), user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update profile",
-		})
+		return problem.Internal(err, "Failed to update profile")
 	}
 
-	userResponse := &UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		Name:         user.Name,
-		Role:         user.Role,
-		EnterpriseID: user.EnterpriseID,
-		CreatedAt:    user.CreatedAt,
+	if emailChanged {
+		if err := h.sendVerification(c, user); err != nil {
+			slog.WarnContext(c.Context(), "Failed to send verification email", "error", err)
+		}
 	}
 
-	return c.JSON(userResponse)
+	return c.JSON(newUserResponse(user))
 }
 
 // UpdateProfileRequest represents profile update request
@@ -415,7 +788,7 @@ type ChangePasswordRequest struct {
 
 // ForgotPasswordRequest represents password reset request
 type ForgotPasswordRequest struct {
-	Email string `json:"email" validate:"required,email"`
+	Email string `json:"email" validate:"required"`
 }
 
 // ResetPasswordRequest represents password reset with token request
@@ -433,16 +806,14 @@ type ResetPasswordRequest struct {
 // @Security BearerAuth
 // @Param password body ChangePasswordRequest true "Password data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/change-password [post]
 func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
 	userID, err := middleware.GetUserFromContext(c)
@@ -450,33 +821,25 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		return err
 	}
 
-	var req ChangePasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[ChangePasswordRequest](c)
+	if err != nil {
+		return err
 	}
 
 	user, err := h.userRepo.GetByID(c.Context(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get user",
-		})
+		return problem.Internal(err, "Failed to get user")
 	}
 
 	// Verify current password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Current password is incorrect",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Current password is incorrect")
 	}
 
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process new password",
-		})
+		return problem.Internal(err, "Failed to process new password")
 	}
 
 	// Update password
@@ -485,9 +848,7 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 	user.UpdatedAt = &now
 
 	if err := h.userRepo.Update(c.Context(), user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update password",
-		})
+		return problem.Internal(err, "Failed to update password")
 	}
 
 	return c.JSON(fiber.Map{
@@ -497,29 +858,25 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 
 // ForgotPassword initiates password reset process
 // @Summary Initiate password reset
-// @Description Send password reset instructions to user's email
+// @Description Email a password reset link, valid for 30 minutes, to the user. The response is the same whether or not the email exists.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param forgot body ForgotPasswordRequest true "Email for password reset"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/forgot-password [post]
 func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 	// Check if dependencies are properly initialized
 	if h.userRepo == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error": "Authentication service not available",
-		})
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication service not available")
 	}
 
-	var req ForgotPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[ForgotPasswordRequest](c)
+	if err != nil {
+		return err
 	}
 
 	// Check if user exists
@@ -531,101 +888,222 @@ func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate password reset token (simplified - in production use secure token generation)
-	resetToken := uuid.New().String()
+	// Failures are only logged, the response must not reveal whether the email exists
+	if user.IsActive {
+		if err := h.sendPasswordReset(c, user); err != nil {
+			slog.WarnContext(c.Context(), "Failed to send password reset email", "error", err)
+		}
+	}
 
-	// TODO: Store reset token with expiration in database or cache (Redis)
-	// TODO: Send email with reset link containing the token
-	// For now, we'll just return success
+	return c.JSON(fiber.Map{
+		"message": "If the email exists in our system, password reset instructions have been sent",
+	})
+}
+
+// sendPasswordReset emails the user a link to reset their password. The email goes out
+// in the background, so the response time does not reveal whether the email exists.
+func (h *AuthHandler) sendPasswordReset(c *fiber.Ctx, user *entities.User) error {
+	resetURL, err := tokenLink(c, h.emails.PasswordResets, user.ID, h.emails.PasswordResetURL)
+	if err != nil {
+		return err
+	}
 
-	// In production, you would:
-	// 1. Generate a secure reset token
-	// 2. Store token with expiration (15-30 minutes)
-	// 3. Send email with reset link
-	// 4. Return success without revealing user existence
+	message, err := h.emails.Templates.Render("password_reset", user.Email, map[string]interface{}{
+		"Name":             user.Name,
+		"ResetURL":         resetURL,
+		"ExpiresInMinutes": int(h.emails.PasswordResets.TTL().Minutes()),
+	})
+	if err != nil {
+		return err
+	}
 
-	_ = user       // Prevent unused variable warning
-	_ = resetToken // Prevent unused variable warning
+	h.sendInBackground(message)
+	return nil
+}
 
-	return c.JSON(fiber.Map{
-		"message": "If the email exists in our system, password reset instructions have been sent",
+// sendVerification emails the user a link to verify their email address, in the
+// background like sendPasswordReset
+func (h *AuthHandler) sendVerification(c *fiber.Ctx, user *entities.User) error {
+	verifyURL, err := tokenLink(c, h.emails.Verifications, user.ID, h.emails.VerificationURL)
+	if err != nil {
+		return err
+	}
+
+	message, err := h.emails.Templates.Render("email_verification", user.Email, map[string]interface{}{
+		"Name":           user.Name,
+		"VerifyURL":      verifyURL,
+		"ExpiresInHours": int(h.emails.Verifications.TTL().Hours()),
 	})
+	if err != nil {
+		return err
+	}
+
+	h.sendInBackground(message)
+	return nil
+}
+
+// sendInBackground sends message without holding up the response, logging failures
+func (h *AuthHandler) sendInBackground(message interfaces.MailMessage) {
+	go func() {
+		if err := h.emails.Mailer.Send(context.Background(), message); err != nil {
+			slog.Warn("Failed to send email", "subject", message.Subject, "error", err)
+		}
+	}()
+}
+
+// tokenLink issues a token for the user and appends it to baseURL as ?token=
+func tokenLink(c *fiber.Ctx, tokens *auth.OneTimeTokens, userID uuid.UUID, baseURL string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := tokens.Issue(c.Context(), userID)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
 }
 
 // ResetPassword resets password using reset token
 // @Summary Reset password with token
-// @Description Reset user password using the reset token from email
+// @Description Reset user password using the single use reset token from email, logging the user out of every session
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param reset body ResetPasswordRequest true "Reset token and new password"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /auth/reset-password [post]
 func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
-	var req ResetPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[ResetPasswordRequest](c)
+	if err != nil {
+		return err
 	}
 
-	// TODO: In production implementation:
-	// 1. Validate reset token from database/cache
-	// 2. Check token expiration
-	// 3. Get user associated with token
-	// 4. Update user password
-	// 5. Invalidate the reset token
-	// 6. Optionally invalidate all existing tokens for security
+	// The token is spent even if the reset fails below
+	userID, err := h.emails.PasswordResets.Consume(c.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidOneTimeToken) {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired reset token")
+		}
+		return problem.Internal(err, "Failed to verify reset token")
+	}
+
+	// Hash new password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return problem.Internal(err, "Failed to process new password")
+	}
+
+	if err := h.userRepo.UpdatePassword(c.Context(), userID, string(hashedPassword)); err != nil {
+		return problem.Internal(err, "Failed to update password")
+	}
 
-	// For now, return error indicating implementation needed
-	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		"error": "Password reset functionality is not fully implemented yet. Please contact administrator.",
+	// Whoever knew the old password is logged out everywhere
+	if err := h.jwtService.RevokeAllTokens(c.Context(), userID); err != nil {
+		return problem.Internal(err, "Failed to revoke tokens")
+	}
+	if err := h.sessionRepo.RevokeAllForUser(c.Context(), userID); err != nil {
+		return problem.Internal(err, "Failed to revoke sessions")
+	}
+
+	// The owner of the address proved who they are, lift a lockout others caused
+	if user, err := h.userRepo.GetByID(c.Context(), userID); err == nil {
+		h.clearLoginFailures(c, user.Email)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password reset successfully",
 	})
+}
 
-	// Example production code would be:
-	/*
-		// Validate token and get associated user
-		userID, err := validateResetToken(req.Token)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired reset token",
-			})
-		}
+// VerifyEmailRequest represents an email verification
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
 
-		user, err := h.userRepo.GetByID(c.Context(), userID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to get user",
-			})
-		}
+// ResendVerificationRequest represents a request for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required"`
+}
 
-		// Hash new password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to process new password",
-			})
+// VerifyEmail verifies the email address of a user
+// @Summary Verify email address
+// @Description Verify the email address of a user with the single use token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verification body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	req, err := Bind[VerifyEmailRequest](c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := h.emails.Verifications.Consume(c.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidOneTimeToken) {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired verification token")
 		}
+		return problem.Internal(err, "Failed to verify token")
+	}
 
-		// Update password
-		user.PasswordHash = string(hashedPassword)
-		now := time.Now()
-		user.UpdatedAt = &now
+	if err := h.userRepo.MarkEmailVerified(c.Context(), userID); err != nil {
+		return problem.Internal(err, "Failed to verify email address")
+	}
 
-		if err := h.userRepo.Update(c.Context(), user); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to update password",
-			})
-		}
+	return c.JSON(fiber.Map{
+		"message": "Email address verified successfully",
+	})
+}
 
-		// Invalidate reset token
-		invalidateResetToken(req.Token)
+// ResendVerification sends a new verification email
+// @Summary Resend verification email
+// @Description Email a new verification link, invalidating the previous one. Each address may ask for one email per interval. The response is the same whether or not the email exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resend body ResendVerificationRequest true "Email to verify"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	req, err := Bind[ResendVerificationRequest](c)
+	if err != nil {
+		return err
+	}
 
-		return c.JSON(fiber.Map{
-			"message": "Password reset successfully",
-		})
-	*/
+	// Throttled by address, known or not, so the limit does not reveal whether it exists
+	allowed, wait, err := h.emails.VerificationResends.Allow(c.Context(), req.Email)
+	if err != nil {
+		return problem.Internal(err, "Failed to send verification email")
+	}
+	if !allowed {
+		setRetryAfter(c, wait)
+		return fiber.NewError(fiber.StatusTooManyRequests, "Verification email sent recently, try again later")
+	}
+
+	user, err := h.userRepo.GetByEmail(c.Context(), req.Email)
+	if err == nil && user.IsActive && user.EmailVerifiedAt == nil {
+		if err := h.sendVerification(c, user); err != nil {
+			slog.WarnContext(c.Context(), "Failed to send verification email", "error", err)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the email exists and is not verified yet, a verification link has been sent",
+	})
 }
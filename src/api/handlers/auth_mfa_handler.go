@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// AuthMFA holds what the auth handler needs for two-factor authentication
+type AuthMFA struct {
+	Service     *auth.MFAService
+	Enrollments interfaces.MFARepository
+	// Enterprises decide which roles must use a second factor
+	Enterprises interfaces.EnterpriseRepository
+}
+
+// MFAChallengeResponse is the login response of users with two-factor authentication
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Message     string    `json:"message"`
+}
+
+// LoginMFARequest completes a login with a TOTP code or a recovery code
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code,omitempty" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// MFACodeRequest confirms an MFA change with a TOTP code or, where accepted, a recovery
+// code
+type MFACodeRequest struct {
+	Code         string `json:"code,omitempty" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// MFAStatusResponse describes the two-factor authentication of the user
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TOTPSetupResponse holds what an authenticator app needs to enroll
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	// QRCode is a PNG data URI encoding OTPAuthURI
+	QRCode string `json:"qr_code"`
+}
+
+// RecoveryCodesResponse shows new recovery codes, the only time they are visible
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
+}
+
+// mfaEnrollment gets the TOTP enrollment of the user, nil when there is none
+func (h *AuthHandler) mfaEnrollment(c *fiber.Ctx, userID uuid.UUID) (*entities.UserMFA, error) {
+	enrollment, err := h.mfa.Enrollments.GetByUserID(c.Context(), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return enrollment, err
+}
+
+// mfaRequired reports whether the enterprise of the user requires a second factor for
+// their role
+func (h *AuthHandler) mfaRequired(c *fiber.Ctx, user *entities.User) (bool, error) {
+	enterprise, err := h.mfa.Enterprises.GetByID(c.Context(), user.EnterpriseID)
+	if err != nil {
+		return false, err
+	}
+	return enterprise.MFARequiredRoles.Contains(user.Role), nil
+}
+
+// mfaSetupPending reports whether the user must set up a second factor before using
+// the API
+func (h *AuthHandler) mfaSetupPending(c *fiber.Ctx, user *entities.User) (bool, error) {
+	enrollment, err := h.mfaEnrollment(c, user.ID)
+	if err != nil || enrollment.Enabled() {
+		return false, err
+	}
+	return h.mfaRequired(c, user)
+}
+
+// challengeMFA answers a correct password of a user with two-factor authentication
+func (h *AuthHandler) challengeMFA(c *fiber.Ctx, user *entities.User) error {
+	token, expiresAt, err := h.mfa.Service.IssueChallenge(c.Context(), user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to start two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   expiresAt,
+		Message:     "Enter the code from your authenticator app",
+	})
+}
+
+// verifySecondFactor checks a TOTP code, or a recovery code when allowed, of an enabled
+// enrollment
+func (h *AuthHandler) verifySecondFactor(c *fiber.Ctx, enrollment *entities.UserMFA, code, recoveryCode string, allowRecovery bool) (bool, error) {
+	if code != "" {
+		return h.mfa.Service.VerifyCode(c.Context(), enrollment.UserID, enrollment.Secret, code)
+	}
+	if recoveryCode != "" && allowRecovery {
+		return h.mfa.Enrollments.UseRecoveryCode(c.Context(), enrollment.UserID, auth.HashRecoveryCode(recoveryCode))
+	}
+	return false, nil
+}
+
+// issueRecoveryCodes replaces the recovery codes of the user, returning the new ones
+func (h *AuthHandler) issueRecoveryCodes(c *fiber.Ctx, userID uuid.UUID) ([]string, error) {
+	codes, hashes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.mfa.Enrollments.ReplaceRecoveryCodes(c.Context(), userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// LoginMFA completes a login with the second factor
+// @Summary Complete login with second factor
+// @Description Exchange the MFA token returned by /auth/login and a TOTP code, or an unused recovery code, for tokens. A challenge allows five wrong codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param login body LoginMFARequest true "MFA token and code"
+// @Success 200 {object} AuthResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem "Too many failed logins, see Retry-After"
+// @Failure 500 {object} problem.Problem
+// @Router /auth/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *fiber.Ctx) error {
+	req, err := Bind[LoginMFARequest](c)
+	if err != nil {
+		return err
+	}
+
+	invalidChallenge := func() error {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired MFA token")
+	}
+
+	userID, err := h.mfa.Service.AttemptChallenge(c.Context(), req.MFAToken)
+	if errors.Is(err, auth.ErrInvalidChallenge) {
+		return invalidChallenge()
+	}
+	if err != nil {
+		return problem.Internal(err, "Failed to verify MFA token")
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), userID)
+	if err != nil {
+		return invalidChallenge()
+	}
+	// Wrong codes count against the account like wrong passwords
+	if err := h.checkLoginAttempts(c, user.Email); err != nil {
+		return err
+	}
+	enrollment, err := h.mfaEnrollment(c, userID)
+	if err != nil {
+		return problem.Internal(err, "Failed to check two-factor authentication")
+	}
+	if !enrollment.Enabled() {
+		// Disabled since the challenge was issued, the user has to log in again
+		return invalidChallenge()
+	}
+
+	valid, err := h.verifySecondFactor(c, enrollment, req.Code, req.RecoveryCode, true)
+	if err != nil {
+		return problem.Internal(err, "Failed to verify code")
+	}
+	if !valid {
+		if err := h.mfa.Service.FailChallenge(c.Context(), req.MFAToken); err != nil && !errors.Is(err, auth.ErrInvalidChallenge) {
+			return problem.Internal(err, "Failed to verify code")
+		}
+		h.countLoginFailure(c, user.Email, user)
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code")
+	}
+
+	if err := h.mfa.Service.CompleteChallenge(c.Context(), req.MFAToken); err != nil {
+		if errors.Is(err, auth.ErrInvalidChallenge) {
+			return invalidChallenge()
+		}
+		return problem.Internal(err, "Failed to verify MFA token")
+	}
+
+	h.clearLoginFailures(c, user.Email)
+
+	tokenPair, err := h.startSession(c, user, false)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate tokens")
+	}
+
+	if err := h.recordAuthEvent(c, entities.AuditActionLogin, user.ID, user.EnterpriseID); err != nil {
+		return problem.Internal(err, "Failed to record login")
+	}
+
+	return c.JSON(AuthResponse{
+		User:      newUserResponse(user),
+		TokenPair: tokenPair,
+		Message:   "Login successful",
+	})
+}
+
+// GetMFAStatus returns the two-factor authentication status of the user
+// @Summary Get two-factor authentication status
+// @Description Whether the user enabled two-factor authentication, whether their enterprise requires it and how many recovery codes are left
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MFAStatusResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/mfa [get]
+func (h *AuthHandler) GetMFAStatus(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.mfaEnrollment(c, user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to get two-factor authentication status")
+	}
+	required, err := h.mfaRequired(c, user)
+	if err != nil {
+		return problem.Internal(err, "Failed to get two-factor authentication status")
+	}
+
+	status := MFAStatusResponse{Required: required}
+	if enrollment.Enabled() {
+		status.Enabled = true
+		status.EnabledAt = enrollment.EnabledAt
+		if status.RecoveryCodesRemaining, err = h.mfa.Enrollments.CountRecoveryCodes(c.Context(), user.ID); err != nil {
+			return problem.Internal(err, "Failed to get two-factor authentication status")
+		}
+	}
+
+	return c.JSON(status)
+}
+
+// SetupTOTP starts enrolling an authenticator app
+// @Summary Set up TOTP
+// @Description Generate a TOTP secret for the user, with its otpauth URI and a QR code of it. The secret stays pending until confirmed at /auth/mfa/totp/enable; setting up again replaces a pending secret.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TOTPSetupResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/mfa/totp/setup [post]
+func (h *AuthHandler) SetupTOTP(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.mfaEnrollment(c, user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to set up two-factor authentication")
+	}
+	if enrollment.Enabled() {
+		return fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+	if enrollment == nil {
+		enrollment = &entities.UserMFA{UserID: user.ID}
+	}
+
+	secret, err := h.mfa.Service.NewSecret()
+	if err != nil {
+		return problem.Internal(err, "Failed to set up two-factor authentication")
+	}
+	uri := h.mfa.Service.URI(user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, -6)
+	if err != nil {
+		return problem.Internal(err, "Failed to set up two-factor authentication")
+	}
+
+	enrollment.Secret = secret
+	if err := h.mfa.Enrollments.Save(c.Context(), enrollment); err != nil {
+		return problem.Internal(err, "Failed to set up two-factor authentication")
+	}
+
+	return c.JSON(TOTPSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// EnableTOTP confirms the pending TOTP secret
+// @Summary Enable TOTP
+// @Description Enable two-factor authentication with a first code from the authenticator app. The response holds the recovery codes, shown only this once. Tokens restricted to the setup stay restricted; refresh them or log in again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body MFACodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/mfa/totp/enable [post]
+func (h *AuthHandler) EnableTOTP(c *fiber.Ctx) error {
+	req, err := Bind[MFACodeRequest](c)
+	if err != nil {
+		return err
+	}
+	if req.Code == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.mfaEnrollment(c, user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to enable two-factor authentication")
+	}
+	if enrollment == nil {
+		return fiber.NewError(fiber.StatusNotFound, "No pending two-factor authentication setup")
+	}
+	if enrollment.Enabled() {
+		return fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	valid, err := h.mfa.Service.VerifyCode(c.Context(), user.ID, enrollment.Secret, req.Code)
+	if err != nil {
+		return problem.Internal(err, "Failed to verify code")
+	}
+	if !valid {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code")
+	}
+
+	codes, err := h.issueRecoveryCodes(c, user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate recovery codes")
+	}
+	now := time.Now()
+	enrollment.EnabledAt = &now
+	if err := h.mfa.Enrollments.Save(c.Context(), enrollment); err != nil {
+		return problem.Internal(err, "Failed to enable two-factor authentication")
+	}
+
+	return c.JSON(RecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "Two-factor authentication enabled. Store the recovery codes somewhere safe, they are not shown again",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code of the user, confirmed with a TOTP code. The new codes are shown only this once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body MFACodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	req, err := Bind[MFACodeRequest](c)
+	if err != nil {
+		return err
+	}
+	if req.Code == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	enrollment, err := h.enabledEnrollment(c)
+	if err != nil {
+		return err
+	}
+
+	valid, err := h.verifySecondFactor(c, enrollment, req.Code, "", false)
+	if err != nil {
+		return problem.Internal(err, "Failed to verify code")
+	}
+	if !valid {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code")
+	}
+
+	codes, err := h.issueRecoveryCodes(c, enrollment.UserID)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate recovery codes")
+	}
+
+	return c.JSON(RecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "Recovery codes replaced. Store them somewhere safe, they are not shown again",
+	})
+}
+
+// DisableMFA turns two-factor authentication off
+// @Summary Disable two-factor authentication
+// @Description Remove the authenticator and recovery codes of the user, confirmed with a TOTP code or a recovery code. Not allowed when the enterprise requires a second factor for the role of the user.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *fiber.Ctx) error {
+	req, err := Bind[MFACodeRequest](c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	required, err := h.mfaRequired(c, user)
+	if err != nil {
+		return problem.Internal(err, "Failed to disable two-factor authentication")
+	}
+	if required {
+		return fiber.NewError(fiber.StatusForbidden, "Two-factor authentication is required for your role")
+	}
+
+	enrollment, err := h.enabledEnrollment(c)
+	if err != nil {
+		return err
+	}
+
+	valid, err := h.verifySecondFactor(c, enrollment, req.Code, req.RecoveryCode, true)
+	if err != nil {
+		return problem.Internal(err, "Failed to verify code")
+	}
+	if !valid {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code")
+	}
+
+	if err := h.mfa.Enrollments.Delete(c.Context(), user.ID); err != nil {
+		return problem.Internal(err, "Failed to disable two-factor authentication")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// currentUser loads the authenticated user
+func (h *AuthHandler) currentUser(c *fiber.Ctx) (*entities.User, error) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	user, err := h.userRepo.GetByID(c.Context(), userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "User not found")
+	}
+	return user, nil
+}
+
+// enabledEnrollment gets the enabled enrollment of the authenticated user, failing with
+// not found when two-factor authentication is not enabled
+func (h *AuthHandler) enabledEnrollment(c *fiber.Ctx) (*entities.UserMFA, error) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := h.mfaEnrollment(c, userID)
+	if err != nil {
+		return nil, problem.Internal(err, "Failed to get two-factor authentication status")
+	}
+	if !enrollment.Enabled() {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Two-factor authentication is not enabled")
+	}
+	return enrollment, nil
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errNoAccount is returned for identities matching no user when provisioning is off
+var errNoAccount = errors.New("no account matches the identity")
+
+// AuthOIDC holds what the auth handler needs for single sign-on with OpenID Connect
+type AuthOIDC struct {
+	Provider   *auth.OIDCProvider
+	Identities interfaces.UserIdentityRepository
+	// ProvisionEnterpriseID receives users logging in without an account, with
+	// ProvisionRole. uuid.Nil turns provisioning off, only existing users can log in.
+	ProvisionEnterpriseID uuid.UUID
+	ProvisionRole         entities.UserRole
+}
+
+// OIDCCallbackRequest carries what the identity provider sent to the redirect URL
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" query:"code" validate:"required"`
+	State string `json:"state" query:"state" validate:"required"`
+	// Error and ErrorDescription report a login the provider refused
+	Error            string `json:"error,omitempty" query:"error"`
+	ErrorDescription string `json:"error_description,omitempty" query:"error_description"`
+}
+
+// OIDCLogin starts a single sign-on
+// @Summary Single sign-on
+// @Description Redirect to the OpenID Connect provider to log in with a corporate identity. The provider sends the user back to the configured redirect URL with a code and state, to exchange at /auth/oidc/callback.
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Failure 502 {object} problem.Problem "Identity provider unavailable"
+// @Router /auth/oidc/login [get]
+func (h *AuthHandler) OIDCLogin(c *fiber.Ctx) error {
+	authorizationURL, _, err := h.oidc.Provider.AuthorizationURL(c.Context())
+	if err != nil {
+		slog.WarnContext(c.Context(), "Failed to start OIDC login", "error", err)
+		return fiber.NewError(fiber.StatusBadGateway, "Identity provider unavailable")
+	}
+	return c.Redirect(authorizationURL, fiber.StatusFound)
+}
+
+// OIDCCallback completes a single sign-on
+// @Summary Complete single sign-on
+// @Description Exchange the code and state the identity provider sent for tokens, as query parameters when the provider redirects here or as a JSON body when a frontend receives them. The identity is matched to the user it logged in before, then to a user with its verified email address. Without a match a user is created in the configured enterprise, when provisioning is enabled. Users with two-factor authentication get an MFAChallengeResponse instead of tokens.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string false "Login state"
+// @Param callback body OIDCCallbackRequest false "Code and state"
+// @Success 200 {object} AuthResponse
+// @Success 202 {object} MFAChallengeResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem "No account matches the identity"
+// @Failure 500 {object} problem.Problem
+// @Router /auth/oidc/callback [get]
+// @Router /auth/oidc/callback [post]
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	var req OIDCCallbackRequest
+	parse := c.QueryParser
+	if c.Method() == fiber.MethodPost {
+		parse = c.BodyParser
+	}
+	if err := parse(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request")
+	}
+	if req.Error != "" {
+		return problem.New(fiber.StatusUnauthorized, "Login at the identity provider failed").
+			With("details", strings.TrimSpace(req.Error+" "+req.ErrorDescription))
+	}
+	if req.Code == "" || req.State == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Code and state are required")
+	}
+
+	identity, err := h.oidc.Provider.Exchange(c.Context(), req.State, req.Code)
+	if errors.Is(err, auth.ErrInvalidOIDCState) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid or expired login state")
+	}
+	if err != nil {
+		slog.WarnContext(c.Context(), "OIDC login failed", "error", err)
+		return fiber.NewError(fiber.StatusUnauthorized, "Failed to verify identity")
+	}
+
+	user, err := h.oidcUser(c, identity)
+	if errors.Is(err, errNoAccount) {
+		return fiber.NewError(fiber.StatusForbidden, "No account matches this identity")
+	}
+	if err != nil {
+		return problem.Internal(err, "Failed to find account")
+	}
+
+	if user.EmailVerifiedAt == nil && h.emails.VerificationPolicy == auth.VerificationLogin {
+		return fiber.NewError(fiber.StatusForbidden, "Email address not verified")
+	}
+
+	enrollment, err := h.mfaEnrollment(c, user.ID)
+	if err != nil {
+		return problem.Internal(err, "Failed to check two-factor authentication")
+	}
+	if enrollment.Enabled() {
+		return h.challengeMFA(c, user)
+	}
+	mfaSetupRequired, err := h.mfaRequired(c, user)
+	if err != nil {
+		return problem.Internal(err, "Failed to check two-factor authentication")
+	}
+
+	tokenPair, err := h.startSession(c, user, mfaSetupRequired)
+	if err != nil {
+		return problem.Internal(err, "Failed to generate tokens")
+	}
+
+	if err := h.recordAuthEvent(c, entities.AuditActionLogin, user.ID, user.EnterpriseID); err != nil {
+		return problem.Internal(err, "Failed to record login")
+	}
+
+	return c.JSON(AuthResponse{
+		User:             newUserResponse(user),
+		TokenPair:        tokenPair,
+		Message:          "Login successful",
+		MFASetupRequired: mfaSetupRequired,
+	})
+}
+
+// oidcUser finds the user an identity belongs to, linking it to the user with its email
+// address on first login, or provisioning a user for it
+func (h *AuthHandler) oidcUser(c *fiber.Ctx, identity *auth.OIDCIdentity) (*entities.User, error) {
+	now := time.Now()
+	linked, err := h.oidc.Identities.GetByIssuerSubject(c.Context(), identity.Issuer, identity.Subject)
+	if err == nil {
+		if err := h.oidc.Identities.TouchLastLogin(c.Context(), linked.ID, identity.Email, now); err != nil {
+			slog.WarnContext(c.Context(), "Failed to record OIDC login", "error", err)
+		}
+		return h.userRepo.GetByID(c.Context(), linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Only an address the provider verified may claim an account
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errNoAccount
+	}
+
+	user, err := h.userRepo.GetByEmail(c.Context(), identity.Email)
+	switch {
+	case err == nil:
+		if user.EmailVerifiedAt == nil {
+			if err := h.userRepo.MarkEmailVerified(c.Context(), user.ID); err != nil {
+				return nil, err
+			}
+			user.EmailVerifiedAt = &now
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if user, err = h.provisionOIDCUser(c, identity, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	link := &entities.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+	if err := h.oidc.Identities.Create(c.Context(), link); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionOIDCUser creates a user for an identity matching no account. The user has no
+// password, they log in through the identity provider.
+func (h *AuthHandler) provisionOIDCUser(c *fiber.Ctx, identity *auth.OIDCIdentity, now time.Time) (*entities.User, error) {
+	if h.oidc.ProvisionEnterpriseID == uuid.Nil {
+		return nil, errNoAccount
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	user := &entities.User{
+		ID:              uuid.New(),
+		EnterpriseID:    h.oidc.ProvisionEnterpriseID,
+		Email:           identity.Email,
+		Name:            name,
+		Role:            h.oidc.ProvisionRole,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+	}
+	if err := h.userRepo.Create(c.Context(), user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
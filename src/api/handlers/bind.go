@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Bind parses the body of the request into a T and validates it against its validate
+// tags. Invalid fields fail with a ValidationError, answered with the details of each
+// field.
+func Bind[T any](c *fiber.Ctx) (*T, error) {
+	req := new(T)
+	if err := c.BodyParser(req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, &interfaces.ValidationError{Message: "Validation failed", Err: err}
+	}
+	return req, nil
+}
+
+// UpdatePositionRequest moves an item within its list, such as a menu among its siblings
+type UpdatePositionRequest struct {
+	Position int `json:"position" validate:"min=0"`
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
+	"terra-allwert/infra/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// bind sends body to a handler binding a CreateEnterpriseRequest, returning the request
+// Bind returned, the response and the error of Bind
+func bind(t *testing.T, body string) (*CreateEnterpriseRequest, *http.Response, error) {
+	t.Helper()
+
+	var (
+		req     *CreateEnterpriseRequest
+		bindErr error
+	)
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		req, bindErr = Bind[CreateEnterpriseRequest](c)
+		if bindErr != nil {
+			return bindErr
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	httpReq.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	resp, err := app.Test(httpReq, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return req, resp, bindErr
+}
+
+func TestBindParsesAndValidates(t *testing.T) {
+	req, resp, err := bind(t, `{
+		"id": "`+uuid.NewString()+`",
+		"title": "Jardim Allwert",
+		"slug": "jardim-allwert",
+		"address_city": "Pelotas",
+		"address_state": "rs"
+	}`)
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("got status %d, want 204", resp.StatusCode)
+	}
+
+	// Members that are no field of the request, such as the ID, cannot reach the entity
+	enterprise := req.toEntity()
+	if enterprise.ID != uuid.Nil || enterprise.Title != "Jardim Allwert" || enterprise.AddressState != "RS" {
+		t.Fatalf("mapped %+v", enterprise)
+	}
+	if enterprise.Status != entities.EnterpriseStatusActive {
+		t.Fatalf("mapped status %s, want active", enterprise.Status)
+	}
+}
+
+func TestBindRejectsInvalidBody(t *testing.T) {
+	_, resp, err := bind(t, `{"title":`)
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+		t.Fatalf("Bind returned %v, want a 400", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestBindReportsEveryInvalidField(t *testing.T) {
+	_, resp, err := bind(t, `{
+		"title": "No",
+		"slug": "Not A Slug",
+		"address_state": "XX",
+		"latitude": 91
+	}`)
+	var validationErr *interfaces.ValidationError
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &validationErr) || !errors.As(err, &fieldErrs) {
+		t.Fatalf("Bind returned %v, want a ValidationError of the fields", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", resp.StatusCode)
+	}
+
+	var body struct {
+		Errors []validation.ValidationError `json:"errors"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("invalid problem %s: %v", data, err)
+	}
+	tags := make(map[string]string)
+	for _, fieldErr := range body.Errors {
+		tags[fieldErr.Field] = fieldErr.Tag
+	}
+	want := map[string]string{
+		"title":         "min",
+		"slug":          "slug",
+		"address_city":  "required",
+		"address_state": "state_code",
+		"latitude":      "latitude",
+	}
+	for field, tag := range want {
+		if tags[field] != tag {
+			t.Errorf("field %s failed %q, want %q: %s", field, tags[field], tag, data)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -28,6 +30,148 @@ func NewCarouselHandler(
 	}
 }
 
+// UpdateMenuCarouselRequest holds the settings of a menu carousel clients may set. The
+// whole carousel is replaced: the promotional video is cleared when left out, the other
+// settings are kept.
+type UpdateMenuCarouselRequest struct {
+	PromotionalVideoID *uuid.UUID `json:"promotional_video_id,omitempty"`
+	Autoplay           *bool      `json:"autoplay,omitempty"`
+	AutoplayInterval   *int       `json:"autoplay_interval,omitempty" validate:"omitempty,min=1"`
+	ShowIndicators     *bool      `json:"show_indicators,omitempty"`
+	ShowControls       *bool      `json:"show_controls,omitempty"`
+	TransitionType     *string    `json:"transition_type,omitempty" validate:"omitempty,max=50"`
+}
+
+// CreateMenuCarouselRequest holds the settings of the carousel of a menu
+type CreateMenuCarouselRequest struct {
+	MenuID uuid.UUID `json:"menu_id" validate:"required"`
+	UpdateMenuCarouselRequest
+}
+
+// UpdateCarouselItemRequest holds the fields of a carousel item clients may set. The
+// whole item is replaced: optional fields left out are cleared, except whether the item
+// is active which is kept.
+type UpdateCarouselItemRequest struct {
+	ItemType         entities.CarouselItemType `json:"item_type" validate:"required,oneof=image video map html"`
+	BackgroundFileID *uuid.UUID                `json:"background_file_id,omitempty"`
+	Position         int                       `json:"position" validate:"min=0"`
+	Title            *string                   `json:"title,omitempty" validate:"omitempty,max=255"`
+	Subtitle         *string                   `json:"subtitle,omitempty" validate:"omitempty,max=500"`
+	CtaText          *string                   `json:"cta_text,omitempty" validate:"omitempty,max=100"`
+	CtaURL           *string                   `json:"cta_url,omitempty" validate:"omitempty,url,max=500"`
+	MapType          *entities.MapType         `json:"map_type,omitempty" validate:"omitempty,oneof=standard satellite terrain hybrid"`
+	MapLatitude      *float64                  `json:"map_latitude,omitempty" validate:"omitempty,latitude"`
+	MapLongitude     *float64                  `json:"map_longitude,omitempty" validate:"omitempty,longitude"`
+	MapZoom          *int                      `json:"map_zoom,omitempty" validate:"omitempty,min=0,max=22"`
+	IsActive         *bool                     `json:"is_active,omitempty"`
+	ValidFrom        *time.Time                `json:"valid_from,omitempty"`
+	ValidUntil       *time.Time                `json:"valid_until,omitempty"`
+}
+
+// CreateCarouselItemRequest holds the fields of a new carousel item
+type CreateCarouselItemRequest struct {
+	MenuCarouselID uuid.UUID `json:"menu_carousel_id" validate:"required"`
+	UpdateCarouselItemRequest
+}
+
+// UpdateTextOverlayRequest holds the fields of a text overlay clients may set. The whole
+// overlay is replaced: optional fields left out are cleared, except the text color and
+// size which are kept.
+type UpdateTextOverlayRequest struct {
+	Title           *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description     *string `json:"description,omitempty"`
+	TextColor       *string `json:"text_color,omitempty" validate:"omitempty,hexcolor,max=7"`
+	TextSize        *string `json:"text_size,omitempty" validate:"omitempty,max=20"`
+	BackgroundColor *string `json:"background_color,omitempty" validate:"omitempty,hexcolor"`
+	PositionX       float64 `json:"position_x" validate:"min=0,max=100"`
+	PositionY       float64 `json:"position_y" validate:"min=0,max=100"`
+	AnimationType   *string `json:"animation_type,omitempty" validate:"omitempty,max=50"`
+}
+
+// CreateTextOverlayRequest holds the fields of a new text overlay
+type CreateTextOverlayRequest struct {
+	CarouselItemID uuid.UUID `json:"carousel_item_id" validate:"required"`
+	UpdateTextOverlayRequest
+}
+
+func (r *CreateMenuCarouselRequest) toEntity() *entities.MenuCarousel {
+	carousel := &entities.MenuCarousel{
+		MenuID:           r.MenuID,
+		Autoplay:         true,
+		AutoplayInterval: 5000,
+		ShowIndicators:   true,
+		ShowControls:     true,
+		TransitionType:   "slide",
+	}
+	r.apply(carousel)
+	return carousel
+}
+
+func (r *UpdateMenuCarouselRequest) apply(carousel *entities.MenuCarousel) {
+	carousel.PromotionalVideoID = r.PromotionalVideoID
+	if r.Autoplay != nil {
+		carousel.Autoplay = *r.Autoplay
+	}
+	if r.AutoplayInterval != nil {
+		carousel.AutoplayInterval = *r.AutoplayInterval
+	}
+	if r.ShowIndicators != nil {
+		carousel.ShowIndicators = *r.ShowIndicators
+	}
+	if r.ShowControls != nil {
+		carousel.ShowControls = *r.ShowControls
+	}
+	if r.TransitionType != nil {
+		carousel.TransitionType = *r.TransitionType
+	}
+}
+
+func (r *CreateCarouselItemRequest) toEntity() *entities.CarouselItem {
+	item := &entities.CarouselItem{MenuCarouselID: r.MenuCarouselID, IsActive: true}
+	r.apply(item)
+	return item
+}
+
+func (r *UpdateCarouselItemRequest) apply(item *entities.CarouselItem) {
+	item.ItemType = r.ItemType
+	item.BackgroundFileID = r.BackgroundFileID
+	item.Position = r.Position
+	item.Title = r.Title
+	item.Subtitle = r.Subtitle
+	item.CtaText = r.CtaText
+	item.CtaURL = r.CtaURL
+	item.MapType = r.MapType
+	item.MapLatitude = r.MapLatitude
+	item.MapLongitude = r.MapLongitude
+	item.MapZoom = r.MapZoom
+	if r.IsActive != nil {
+		item.IsActive = *r.IsActive
+	}
+	item.ValidFrom = r.ValidFrom
+	item.ValidUntil = r.ValidUntil
+}
+
+func (r *CreateTextOverlayRequest) toEntity() *entities.CarouselTextOverlay {
+	overlay := &entities.CarouselTextOverlay{CarouselItemID: r.CarouselItemID, TextColor: "#FFFFFF", TextSize: "medium"}
+	r.apply(overlay)
+	return overlay
+}
+
+func (r *UpdateTextOverlayRequest) apply(overlay *entities.CarouselTextOverlay) {
+	overlay.Title = r.Title
+	overlay.Description = r.Description
+	if r.TextColor != nil {
+		overlay.TextColor = *r.TextColor
+	}
+	if r.TextSize != nil {
+		overlay.TextSize = *r.TextSize
+	}
+	overlay.BackgroundColor = r.BackgroundColor
+	overlay.PositionX = r.PositionX
+	overlay.PositionY = r.PositionY
+	overlay.AnimationType = r.AnimationType
+}
+
 // ============== MENU CAROUSEL ENDPOINTS ==============
 
 // CreateMenuCarousel creates a new menu carousel
@@ -37,25 +181,22 @@ func NewCarouselHandler(
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param carousel body entities.MenuCarousel true "Menu Carousel data"
+// @Param carousel body CreateMenuCarouselRequest true "Menu Carousel data"
 // @Success 201 {object} entities.MenuCarousel
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-carousels [post]
 func (h *CarouselHandler) CreateMenuCarousel(c *fiber.Ctx) error {
-	var carousel entities.MenuCarousel
-
-	if err := c.BodyParser(&carousel); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateMenuCarouselRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.carouselRepo.Create(c.Context(), &carousel); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create menu carousel",
-		})
+	carousel := req.toEntity()
+	if err := h.carouselRepo.Create(c.Context(), carousel); err != nil {
+		return problem.Internal(err, "Failed to create menu carousel")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(carousel)
@@ -69,24 +210,20 @@ func (h *CarouselHandler) CreateMenuCarousel(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu Carousel ID"
 // @Success 200 {object} entities.MenuCarousel
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /menu-carousels/{id} [get]
 func (h *CarouselHandler) GetMenuCarouselByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu carousel ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu carousel ID")
 	}
 
 	carousel, err := h.carouselRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Menu carousel not found",
-		})
+		return lookupError(err, "Menu carousel")
 	}
 
 	return c.JSON(carousel)
@@ -100,24 +237,20 @@ func (h *CarouselHandler) GetMenuCarouselByID(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param menuId path string true "Menu ID"
 // @Success 200 {object} entities.MenuCarousel
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /menus/{menuId}/carousel [get]
 func (h *CarouselHandler) GetMenuCarouselByMenuID(c *fiber.Ctx) error {
 	menuIDParam := c.Params("menuId")
 	menuID, err := uuid.Parse(menuIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
 	carousel, err := h.carouselRepo.GetByMenuID(c.Context(), menuID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Menu carousel not found",
-		})
+		return lookupError(err, "Menu carousel")
 	}
 
 	return c.JSON(carousel)
@@ -131,33 +264,33 @@ func (h *CarouselHandler) GetMenuCarouselByMenuID(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Menu Carousel ID"
-// @Param carousel body entities.MenuCarousel true "Menu Carousel data"
+// @Param carousel body UpdateMenuCarouselRequest true "Menu Carousel data"
 // @Success 200 {object} entities.MenuCarousel
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-carousels/{id} [put]
 func (h *CarouselHandler) UpdateMenuCarousel(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu carousel ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu carousel ID")
+	}
+
+	req, err := Bind[UpdateMenuCarouselRequest](c)
+	if err != nil {
+		return err
 	}
 
-	var carousel entities.MenuCarousel
-	if err := c.BodyParser(&carousel); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	carousel, err := h.carouselRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Menu carousel")
 	}
 
-	carousel.ID = id
-	if err := h.carouselRepo.Update(c.Context(), &carousel); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update menu carousel",
-		})
+	req.apply(carousel)
+	if err := h.carouselRepo.Update(c.Context(), carousel); err != nil {
+		return problem.Internal(err, "Failed to update menu carousel")
 	}
 
 	return c.JSON(carousel)
@@ -170,23 +303,24 @@ func (h *CarouselHandler) UpdateMenuCarousel(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu Carousel ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-carousels/{id} [delete]
 func (h *CarouselHandler) DeleteMenuCarousel(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu carousel ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu carousel ID")
+	}
+
+	if _, err := h.carouselRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Menu carousel")
 	}
 
 	if err := h.carouselRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete menu carousel",
-		})
+		return problem.Internal(err, "Failed to delete menu carousel")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -201,25 +335,22 @@ func (h *CarouselHandler) DeleteMenuCarousel(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param item body entities.CarouselItem true "Carousel Item data"
+// @Param item body CreateCarouselItemRequest true "Carousel Item data"
 // @Success 201 {object} entities.CarouselItem
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /carousel-items [post]
 func (h *CarouselHandler) CreateCarouselItem(c *fiber.Ctx) error {
-	var item entities.CarouselItem
-
-	if err := c.BodyParser(&item); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateCarouselItemRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.carouselItemRepo.Create(c.Context(), &item); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create carousel item",
-		})
+	item := req.toEntity()
+	if err := h.carouselItemRepo.Create(c.Context(), item); err != nil {
+		return problem.Internal(err, "Failed to create carousel item")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(item)
@@ -236,17 +367,15 @@ func (h *CarouselHandler) CreateCarouselItem(c *fiber.Ctx) error {
 // @Param offset query int false "Offset" default(0)
 // @Param active_only query boolean false "Only active items"
 // @Success 200 {array} entities.CarouselItem
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-carousels/{carouselId}/items [get]
 func (h *CarouselHandler) GetCarouselItemsByCarousel(c *fiber.Ctx) error {
 	carouselIDParam := c.Params("carouselId")
 	carouselID, err := uuid.Parse(carouselIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid carousel ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -261,9 +390,7 @@ func (h *CarouselHandler) GetCarouselItemsByCarousel(c *fiber.Ctx) error {
 	}
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch carousel items",
-		})
+		return problem.Internal(err, "Failed to fetch carousel items")
 	}
 
 	return c.JSON(items)
@@ -277,33 +404,74 @@ func (h *CarouselHandler) GetCarouselItemsByCarousel(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Carousel Item ID"
-// @Param item body entities.CarouselItem true "Carousel Item data"
+// @Param item body UpdateCarouselItemRequest true "Carousel Item data"
 // @Success 200 {object} entities.CarouselItem
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /carousel-items/{id} [put]
 func (h *CarouselHandler) UpdateCarouselItem(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid carousel item ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel item ID")
+	}
+
+	req, err := Bind[UpdateCarouselItemRequest](c)
+	if err != nil {
+		return err
 	}
 
-	var item entities.CarouselItem
-	if err := c.BodyParser(&item); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	item, err := h.carouselItemRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Carousel item")
 	}
 
-	item.ID = id
-	if err := h.carouselItemRepo.Update(c.Context(), &item); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update carousel item",
-		})
+	req.apply(item)
+	if err := h.carouselItemRepo.Update(c.Context(), item); err != nil {
+		return problem.Internal(err, "Failed to update carousel item")
+	}
+
+	return c.JSON(item)
+}
+
+// PatchCarouselItem partially updates an existing carousel item
+// @Summary Patch carousel item
+// @Description Apply a JSON merge patch (RFC 7396) to an existing carousel item. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags carousel-items
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Carousel Item ID"
+// @Param item body UpdateCarouselItemRequest true "Carousel Item fields to change"
+// @Success 200 {object} entities.CarouselItem
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /carousel-items/{id} [patch]
+func (h *CarouselHandler) PatchCarouselItem(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel item ID")
+	}
+
+	item, err := h.carouselItemRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Carousel item")
+	}
+
+	fields, err := mergePatch(c, item, (*UpdateCarouselItemRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.carouselItemRepo.UpdateFields(c.Context(), item, fields); err != nil {
+			return problem.Internal(err, "Failed to update carousel item")
+		}
 	}
 
 	return c.JSON(item)
@@ -317,35 +485,31 @@ func (h *CarouselHandler) UpdateCarouselItem(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Carousel Item ID"
-// @Param position body object{position=int} true "Position data"
+// @Param position body UpdatePositionRequest true "Position data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /carousel-items/{id}/position [patch]
 func (h *CarouselHandler) UpdateCarouselItemPosition(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid carousel item ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel item ID")
 	}
 
-	var body struct {
-		Position int `json:"position"`
+	req, err := Bind[UpdatePositionRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if _, err := h.carouselItemRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Carousel item")
 	}
 
-	if err := h.carouselItemRepo.UpdatePosition(c.Context(), id, body.Position); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update carousel item position",
-		})
+	if err := h.carouselItemRepo.UpdatePosition(c.Context(), id, req.Position); err != nil {
+		return problem.Internal(err, "Failed to update carousel item position")
 	}
 
 	return c.JSON(fiber.Map{
@@ -360,23 +524,24 @@ func (h *CarouselHandler) UpdateCarouselItemPosition(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Carousel Item ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /carousel-items/{id} [delete]
 func (h *CarouselHandler) DeleteCarouselItem(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid carousel item ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel item ID")
+	}
+
+	if _, err := h.carouselItemRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Carousel item")
 	}
 
 	if err := h.carouselItemRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete carousel item",
-		})
+		return problem.Internal(err, "Failed to delete carousel item")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -391,25 +556,22 @@ func (h *CarouselHandler) DeleteCarouselItem(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param overlay body entities.CarouselTextOverlay true "Text Overlay data"
+// @Param overlay body CreateTextOverlayRequest true "Text Overlay data"
 // @Success 201 {object} entities.CarouselTextOverlay
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /text-overlays [post]
 func (h *CarouselHandler) CreateTextOverlay(c *fiber.Ctx) error {
-	var overlay entities.CarouselTextOverlay
-
-	if err := c.BodyParser(&overlay); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateTextOverlayRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.textOverlayRepo.Create(c.Context(), &overlay); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create text overlay",
-		})
+	overlay := req.toEntity()
+	if err := h.textOverlayRepo.Create(c.Context(), overlay); err != nil {
+		return problem.Internal(err, "Failed to create text overlay")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(overlay)
@@ -425,17 +587,15 @@ func (h *CarouselHandler) CreateTextOverlay(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.CarouselTextOverlay
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /carousel-items/{itemId}/text-overlays [get]
 func (h *CarouselHandler) GetTextOverlaysByItem(c *fiber.Ctx) error {
 	itemIDParam := c.Params("itemId")
 	itemID, err := uuid.Parse(itemIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid carousel item ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid carousel item ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -443,9 +603,7 @@ func (h *CarouselHandler) GetTextOverlaysByItem(c *fiber.Ctx) error {
 
 	overlays, err := h.textOverlayRepo.GetByCarouselItemID(c.Context(), itemID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch text overlays",
-		})
+		return problem.Internal(err, "Failed to fetch text overlays")
 	}
 
 	return c.JSON(overlays)
@@ -459,33 +617,74 @@ func (h *CarouselHandler) GetTextOverlaysByItem(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Text Overlay ID"
-// @Param overlay body entities.CarouselTextOverlay true "Text Overlay data"
+// @Param overlay body UpdateTextOverlayRequest true "Text Overlay data"
 // @Success 200 {object} entities.CarouselTextOverlay
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /text-overlays/{id} [put]
 func (h *CarouselHandler) UpdateTextOverlay(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid text overlay ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid text overlay ID")
+	}
+
+	req, err := Bind[UpdateTextOverlayRequest](c)
+	if err != nil {
+		return err
+	}
+
+	overlay, err := h.textOverlayRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Text overlay")
+	}
+
+	req.apply(overlay)
+	if err := h.textOverlayRepo.Update(c.Context(), overlay); err != nil {
+		return problem.Internal(err, "Failed to update text overlay")
+	}
+
+	return c.JSON(overlay)
+}
+
+// PatchTextOverlay partially updates an existing text overlay
+// @Summary Patch text overlay
+// @Description Apply a JSON merge patch (RFC 7396) to an existing text overlay. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags text-overlays
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Text Overlay ID"
+// @Param overlay body UpdateTextOverlayRequest true "Text Overlay fields to change"
+// @Success 200 {object} entities.CarouselTextOverlay
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /text-overlays/{id} [patch]
+func (h *CarouselHandler) PatchTextOverlay(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid text overlay ID")
 	}
 
-	var overlay entities.CarouselTextOverlay
-	if err := c.BodyParser(&overlay); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	overlay, err := h.textOverlayRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Text overlay")
 	}
 
-	overlay.ID = id
-	if err := h.textOverlayRepo.Update(c.Context(), &overlay); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update text overlay",
-		})
+	fields, err := mergePatch(c, overlay, (*UpdateTextOverlayRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.textOverlayRepo.UpdateFields(c.Context(), overlay, fields); err != nil {
+			return problem.Internal(err, "Failed to update text overlay")
+		}
 	}
 
 	return c.JSON(overlay)
@@ -498,23 +697,24 @@ func (h *CarouselHandler) UpdateTextOverlay(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Text Overlay ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /text-overlays/{id} [delete]
 func (h *CarouselHandler) DeleteTextOverlay(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid text overlay ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid text overlay ID")
+	}
+
+	if _, err := h.textOverlayRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Text overlay")
 	}
 
 	if err := h.textOverlayRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete text overlay",
-		})
+		return problem.Internal(err, "Failed to delete text overlay")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
+	"terra-allwert/infra/tenant"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,32 +23,87 @@ func NewEnterpriseHandler(enterpriseRepo interfaces.EnterpriseRepository) *Enter
 	}
 }
 
+// UpdateEnterpriseRequest holds the fields of an enterprise clients may set. The whole
+// enterprise is replaced: optional fields left out are cleared, except the status which
+// is kept.
+type UpdateEnterpriseRequest struct {
+	Title               string                    `json:"title" validate:"required,min=3,max=255"`
+	Description         *string                   `json:"description,omitempty"`
+	LogoFileID          *uuid.UUID                `json:"logo_file_id,omitempty"`
+	Slug                string                    `json:"slug" validate:"required,slug"`
+	AddressStreet       *string                   `json:"address_street,omitempty" validate:"omitempty,max=255"`
+	AddressNumber       *string                   `json:"address_number,omitempty" validate:"omitempty,max=20"`
+	AddressComplement   *string                   `json:"address_complement,omitempty" validate:"omitempty,max=100"`
+	AddressNeighborhood *string                   `json:"address_neighborhood,omitempty" validate:"omitempty,max=100"`
+	AddressCity         string                    `json:"address_city" validate:"required,max=100"`
+	AddressState        string                    `json:"address_state" validate:"required,state_code"`
+	AddressZipCode      *string                   `json:"address_zip_code,omitempty" validate:"omitempty,max=10"`
+	Latitude            *float64                  `json:"latitude,omitempty" validate:"omitempty,latitude"`
+	Longitude           *float64                  `json:"longitude,omitempty" validate:"omitempty,longitude"`
+	Status              entities.EnterpriseStatus `json:"status,omitempty" validate:"omitempty,oneof=active inactive construction completed"`
+	// MFARequiredRoles lists the roles that must log in with a second factor
+	MFARequiredRoles entities.UserRoles `json:"mfa_required_roles,omitempty" validate:"dive,oneof=visitor manager admin"`
+}
+
+// CreateEnterpriseRequest holds the fields of a new enterprise
+type CreateEnterpriseRequest struct {
+	UpdateEnterpriseRequest
+}
+
+func (r *CreateEnterpriseRequest) toEntity() *entities.Enterprise {
+	enterprise := &entities.Enterprise{Status: entities.EnterpriseStatusActive}
+	r.apply(enterprise)
+	return enterprise
+}
+
+func (r *UpdateEnterpriseRequest) apply(enterprise *entities.Enterprise) {
+	enterprise.Title = r.Title
+	enterprise.Description = r.Description
+	enterprise.LogoFileID = r.LogoFileID
+	enterprise.Slug = r.Slug
+	enterprise.AddressStreet = r.AddressStreet
+	enterprise.AddressNumber = r.AddressNumber
+	enterprise.AddressComplement = r.AddressComplement
+	enterprise.AddressNeighborhood = r.AddressNeighborhood
+	enterprise.AddressCity = r.AddressCity
+	enterprise.AddressState = strings.ToUpper(r.AddressState)
+	enterprise.AddressZipCode = r.AddressZipCode
+	enterprise.Latitude = r.Latitude
+	enterprise.Longitude = r.Longitude
+	if r.Status != "" {
+		enterprise.Status = r.Status
+	}
+	enterprise.MFARequiredRoles = r.MFARequiredRoles
+}
+
 // CreateEnterprise creates a new enterprise
 // @Summary Create a new enterprise
-// @Description Create a new enterprise with the provided data
+// @Description Create a new enterprise with the provided data. Enterprises are created by super admins acting across enterprises (X-Tenant-Scope: all).
 // @Tags enterprises
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param enterprise body entities.Enterprise true "Enterprise data"
+// @Param enterprise body CreateEnterpriseRequest true "Enterprise data"
 // @Success 201 {object} entities.Enterprise
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises [post]
 func (h *EnterpriseHandler) CreateEnterprise(c *fiber.Ctx) error {
-	var enterprise entities.Enterprise
+	req, err := Bind[CreateEnterpriseRequest](c)
+	if err != nil {
+		return err
+	}
 
-	if err := c.BodyParser(&enterprise); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	// A new enterprise lies outside any tenant
+	if _, scoped := tenant.FromContext(c.Context()); scoped {
+		return fiber.NewError(fiber.StatusForbidden, "Only super admins can create enterprises")
 	}
 
-	if err := h.enterpriseRepo.Create(c.Context(), &enterprise); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create enterprise",
-		})
+	enterprise := req.toEntity()
+	if err := h.enterpriseRepo.Create(c.Context(), enterprise); err != nil {
+		return problem.Internal(err, "Failed to create enterprise")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(enterprise)
@@ -59,25 +117,21 @@ func (h *EnterpriseHandler) CreateEnterprise(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Enterprise ID"
 // @Success 200 {object} entities.Enterprise
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/{id} [get]
 func (h *EnterpriseHandler) GetEnterpriseByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid enterprise ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
 	}
 
 	enterprise, err := h.enterpriseRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Enterprise not found",
-		})
+		return lookupError(err, "Enterprise")
 	}
 
 	return c.JSON(enterprise)
@@ -91,18 +145,16 @@ func (h *EnterpriseHandler) GetEnterpriseByID(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param slug path string true "Enterprise slug"
 // @Success 200 {object} entities.Enterprise
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/slug/{slug} [get]
 func (h *EnterpriseHandler) GetEnterpriseBySlug(c *fiber.Ctx) error {
 	slug := c.Params("slug")
 
 	enterprise, err := h.enterpriseRepo.GetBySlug(c.Context(), slug)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Enterprise not found",
-		})
+		return lookupError(err, "Enterprise")
 	}
 
 	return c.JSON(enterprise)
@@ -117,8 +169,8 @@ func (h *EnterpriseHandler) GetEnterpriseBySlug(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Enterprise
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises [get]
 func (h *EnterpriseHandler) GetEnterprises(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -126,9 +178,7 @@ func (h *EnterpriseHandler) GetEnterprises(c *fiber.Ctx) error {
 
 	enterprises, err := h.enterpriseRepo.GetAll(c.Context(), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch enterprises",
-		})
+		return problem.Internal(err, "Failed to fetch enterprises")
 	}
 
 	return c.JSON(enterprises)
@@ -142,34 +192,74 @@ func (h *EnterpriseHandler) GetEnterprises(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Enterprise ID"
-// @Param enterprise body entities.Enterprise true "Enterprise data"
+// @Param enterprise body UpdateEnterpriseRequest true "Enterprise data"
 // @Success 200 {object} entities.Enterprise
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/{id} [put]
 func (h *EnterpriseHandler) UpdateEnterprise(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid enterprise ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
 	}
 
-	var enterprise entities.Enterprise
-	if err := c.BodyParser(&enterprise); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateEnterpriseRequest](c)
+	if err != nil {
+		return err
+	}
+
+	enterprise, err := h.enterpriseRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Enterprise")
 	}
 
-	enterprise.ID = id
-	if err := h.enterpriseRepo.Update(c.Context(), &enterprise); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update enterprise",
-		})
+	req.apply(enterprise)
+	if err := h.enterpriseRepo.Update(c.Context(), enterprise); err != nil {
+		return problem.Internal(err, "Failed to update enterprise")
+	}
+
+	return c.JSON(enterprise)
+}
+
+// PatchEnterprise partially updates an existing enterprise
+// @Summary Patch enterprise
+// @Description Apply a JSON merge patch (RFC 7396) to an existing enterprise. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags enterprises
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Enterprise ID"
+// @Param enterprise body UpdateEnterpriseRequest true "Enterprise fields to change"
+// @Success 200 {object} entities.Enterprise
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /enterprises/{id} [patch]
+func (h *EnterpriseHandler) PatchEnterprise(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
+	}
+
+	enterprise, err := h.enterpriseRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Enterprise")
+	}
+
+	fields, err := mergePatch(c, enterprise, (*UpdateEnterpriseRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.enterpriseRepo.UpdateFields(c.Context(), enterprise, fields); err != nil {
+			return problem.Internal(err, "Failed to update enterprise")
+		}
 	}
 
 	return c.JSON(enterprise)
@@ -182,24 +272,25 @@ func (h *EnterpriseHandler) UpdateEnterprise(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Enterprise ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "Blocked by dependent records"
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/{id} [delete]
 func (h *EnterpriseHandler) DeleteEnterprise(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid enterprise ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
+	}
+
+	if _, err := h.enterpriseRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Enterprise")
 	}
 
 	if err := h.enterpriseRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete enterprise",
-		})
+		return problem.Internal(err, "Failed to delete enterprise")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -215,16 +306,14 @@ func (h *EnterpriseHandler) DeleteEnterprise(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Enterprise
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/search [get]
 func (h *EnterpriseHandler) SearchEnterprises(c *fiber.Ctx) error {
 	query := c.Query("q")
 	if query == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Search query is required",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Search query is required")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -232,9 +321,7 @@ func (h *EnterpriseHandler) SearchEnterprises(c *fiber.Ctx) error {
 
 	enterprises, err := h.enterpriseRepo.Search(c.Context(), query, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search enterprises",
-		})
+		return problem.Internal(err, "Failed to search enterprises")
 	}
 
 	return c.JSON(enterprises)
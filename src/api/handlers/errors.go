@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+
+	"terra-allwert/domain/interfaces"
+
+	"gorm.io/gorm"
+)
+
+// Handlers return their errors to the error handler of the app, problem.ErrorHandler,
+// which renders them as problem details: fiber errors with their status, domain errors
+// with the status they map to and server errors wrapped with problem.Internal with
+// their detail.
+
+// lookupError reports a failed lookup of entity as not found when no record matched,
+// and as a server error otherwise
+func lookupError(err error, entity string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &interfaces.NotFoundError{Entity: entity}
+	}
+	return err
+}
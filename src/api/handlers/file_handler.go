@@ -11,7 +11,9 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/metrics"
 	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
 	"terra-allwert/infra/storage"
 	"terra-allwert/infra/websocket"
 
@@ -27,6 +29,7 @@ type FileHandler struct {
 	progressHub        *websocket.ProgressHub
 	rateLimiter        *middleware.UploadRateLimiter
 	circuitBreaker     *middleware.CircuitBreaker
+	uploadMetrics      *metrics.Metrics
 }
 
 func NewFileHandler(
@@ -37,6 +40,7 @@ func NewFileHandler(
 	progressHub *websocket.ProgressHub,
 	rateLimiter *middleware.UploadRateLimiter,
 	circuitBreaker *middleware.CircuitBreaker,
+	uploadMetrics *metrics.Metrics,
 ) *FileHandler {
 	return &FileHandler{
 		fileRepo:           fileRepo,
@@ -46,9 +50,59 @@ func NewFileHandler(
 		progressHub:        progressHub,
 		rateLimiter:        rateLimiter,
 		circuitBreaker:     circuitBreaker,
+		uploadMetrics:      uploadMetrics,
 	}
 }
 
+// UpdateFileRequest holds the descriptive fields of a file record clients may change.
+// The whole description is replaced: optional fields left out are cleared. The stored
+// object, its type, size and hash are set once, on create.
+type UpdateFileRequest struct {
+	OriginalName    string                `json:"original_name" validate:"required,max=255"`
+	CdnURL          *string               `json:"cdn_url,omitempty" validate:"omitempty,url,max=500"`
+	Width           *int                  `json:"width,omitempty" validate:"omitempty,min=1"`
+	Height          *int                  `json:"height,omitempty" validate:"omitempty,min=1"`
+	DurationSeconds *int                  `json:"duration_seconds,omitempty" validate:"omitempty,min=0"`
+	Metadata        entities.FileMetadata `json:"metadata,omitempty"`
+}
+
+// CreateFileRequest holds the fields of a new file record, describing an object already
+// in storage
+type CreateFileRequest struct {
+	FileType      entities.FileType `json:"file_type" validate:"required,oneof=image video document"`
+	MimeType      string            `json:"mime_type" validate:"required,max=100"`
+	Extension     string            `json:"extension" validate:"required,max=10"`
+	StoragePath   string            `json:"storage_path" validate:"required,max=500"`
+	FileSizeBytes int64             `json:"file_size_bytes" validate:"min=1"`
+	FileHash      *string           `json:"file_hash,omitempty" validate:"omitempty,max=64"`
+	UpdateFileRequest
+}
+
+// toEntity maps the request to a new file of the enterprise, uploaded by uploader
+func (r *CreateFileRequest) toEntity(enterpriseID uuid.UUID, uploader *uuid.UUID) *entities.File {
+	file := &entities.File{
+		EnterpriseID:  enterpriseID,
+		FileType:      r.FileType,
+		MimeType:      r.MimeType,
+		Extension:     r.Extension,
+		StoragePath:   r.StoragePath,
+		FileSizeBytes: r.FileSizeBytes,
+		FileHash:      r.FileHash,
+		UploadedBy:    uploader,
+	}
+	r.apply(file)
+	return file
+}
+
+func (r *UpdateFileRequest) apply(file *entities.File) {
+	file.OriginalName = r.OriginalName
+	file.CdnURL = r.CdnURL
+	file.Width = r.Width
+	file.Height = r.Height
+	file.DurationSeconds = r.DurationSeconds
+	file.Metadata = r.Metadata
+}
+
 type PresignedUploadRequest struct {
 	FileName    string `json:"file_name" validate:"required"`
 	ContentType string `json:"content_type" validate:"required"`
@@ -98,17 +152,19 @@ type CompleteMultipartRequest struct {
 // @Security BearerAuth
 // @Param request body PresignedUploadRequest true "Upload request data"
 // @Success 200 {object} PresignedUploadResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/presigned-upload [post]
 func (h *FileHandler) RequestPresignedUploadURL(c *fiber.Ctx) error {
-	var req PresignedUploadRequest
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[PresignedUploadRequest](c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
 	}
 
 	// Generate unique file ID and storage path
@@ -125,28 +181,28 @@ func (h *FileHandler) RequestPresignedUploadURL(c *fiber.Ctx) error {
 		req.ContentType,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate presigned URL",
-		})
+		return problem.Internal(err, "Failed to generate presigned URL")
 	}
 
 	// Pre-register file in database
 	file := &entities.File{
 		ID:            fileID,
+		EnterpriseID:  enterpriseID,
 		FileType:      determineFileType(req.ContentType),
 		MimeType:      req.ContentType,
 		Extension:     strings.TrimPrefix(ext, "."),
 		OriginalName:  req.FileName,
 		StoragePath:   storagePath,
 		FileSizeBytes: req.FileSize,
+		UploadedBy:    uploaderOf(c),
 	}
 
 	if err := h.fileRepo.Create(c.Context(), file); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to register file",
-		})
+		return problem.Internal(err, "Failed to register file")
 	}
 
+	h.uploadMetrics.ObserveUpload(string(middleware.GetFileSizeCategory(req.FileSize)), "presigned", req.FileSize)
+
 	response := PresignedUploadResponse{
 		UploadURL:   presignedURL.String(),
 		FileID:      fileID.String(),
@@ -166,17 +222,19 @@ func (h *FileHandler) RequestPresignedUploadURL(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param request body MultipartUploadRequest true "Multipart upload request"
 // @Success 200 {object} MultipartUploadResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/multipart-upload [post]
 func (h *FileHandler) RequestMultipartUpload(c *fiber.Ctx) error {
-	var req MultipartUploadRequest
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[MultipartUploadRequest](c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
 	}
 
 	// Set default part size to 5MB if not specified
@@ -195,9 +253,7 @@ func (h *FileHandler) RequestMultipartUpload(c *fiber.Ctx) error {
 		req.ContentType,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to initiate multipart upload",
-		})
+		return problem.Internal(err, "Failed to initiate multipart upload")
 	}
 
 	// Calculate number of parts
@@ -218,9 +274,7 @@ func (h *FileHandler) RequestMultipartUpload(c *fiber.Ctx) error {
 		if err != nil {
 			// Abort multipart upload on error
 			h.storageService.AbortMultipartUpload(c.Context(), storagePath, uploadID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to generate part URLs",
-			})
+			return problem.Internal(err, "Failed to generate part URLs")
 		}
 
 		partURLs[i-1] = PartURL{
@@ -233,22 +287,24 @@ func (h *FileHandler) RequestMultipartUpload(c *fiber.Ctx) error {
 	// Pre-register file in database
 	file := &entities.File{
 		ID:            fileID,
+		EnterpriseID:  enterpriseID,
 		FileType:      determineFileType(req.ContentType),
 		MimeType:      req.ContentType,
 		Extension:     strings.TrimPrefix(ext, "."),
 		OriginalName:  req.FileName,
 		StoragePath:   storagePath,
 		FileSizeBytes: req.FileSize,
+		UploadedBy:    uploaderOf(c),
 	}
 
 	if err := h.fileRepo.Create(c.Context(), file); err != nil {
 		// Abort multipart upload on error
 		h.storageService.AbortMultipartUpload(c.Context(), storagePath, uploadID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to register file",
-		})
+		return problem.Internal(err, "Failed to register file")
 	}
 
+	h.uploadMetrics.ObserveUpload(string(middleware.GetFileSizeCategory(req.FileSize)), "multipart", req.FileSize)
+
 	response := MultipartUploadResponse{
 		UploadID:    uploadID,
 		FileID:      fileID.String(),
@@ -269,33 +325,27 @@ func (h *FileHandler) RequestMultipartUpload(c *fiber.Ctx) error {
 // @Param fileId path string true "File ID"
 // @Param request body CompleteMultipartRequest true "Complete request"
 // @Success 200 {object} entities.File
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{fileId}/complete-multipart [post]
 func (h *FileHandler) CompleteMultipartUpload(c *fiber.Ctx) error {
 	fileIDParam := c.Params("fileId")
 	fileID, err := uuid.Parse(fileIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	var req CompleteMultipartRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CompleteMultipartRequest](c)
+	if err != nil {
+		return err
 	}
 
 	// Get file from database
 	file, err := h.fileRepo.GetByID(c.Context(), fileID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File not found",
-		})
+		return lookupError(err, "File")
 	}
 
 	// Complete multipart upload
@@ -306,9 +356,7 @@ func (h *FileHandler) CompleteMultipartUpload(c *fiber.Ctx) error {
 		req.Parts,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to complete multipart upload",
-		})
+		return problem.Internal(err, "Failed to complete multipart upload")
 	}
 
 	// Get file info from storage to update metadata
@@ -338,25 +386,27 @@ func (h *FileHandler) CompleteMultipartUpload(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param file body entities.File true "File data"
+// @Param file body CreateFileRequest true "File data"
 // @Success 201 {object} entities.File
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files [post]
 func (h *FileHandler) CreateFile(c *fiber.Ctx) error {
-	var file entities.File
+	req, err := Bind[CreateFileRequest](c)
+	if err != nil {
+		return err
+	}
 
-	if err := c.BodyParser(&file); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.fileRepo.Create(c.Context(), &file); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create file",
-		})
+	file := req.toEntity(enterpriseID, uploaderOf(c))
+	if err := h.fileRepo.Create(c.Context(), file); err != nil {
+		return problem.Internal(err, "Failed to create file")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(file)
@@ -370,24 +420,20 @@ func (h *FileHandler) CreateFile(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "File ID"
 // @Success 200 {object} entities.File
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /files/{id} [get]
 func (h *FileHandler) GetFileByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
 	file, err := h.fileRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File not found",
-		})
+		return lookupError(err, "File")
 	}
 
 	return c.JSON(file)
@@ -405,8 +451,8 @@ func (h *FileHandler) GetFileByID(c *fiber.Ctx) error {
 // @Param mime_type query string false "MIME type filter"
 // @Param uploader query string false "Uploader ID filter"
 // @Success 200 {array} entities.File
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files [get]
 func (h *FileHandler) GetFiles(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -453,9 +499,7 @@ func (h *FileHandler) GetFiles(c *fiber.Ctx) error {
 	}
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch files",
-		})
+		return problem.Internal(err, "Failed to fetch files")
 	}
 
 	return c.JSON(files)
@@ -469,86 +513,106 @@ func (h *FileHandler) GetFiles(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "File ID"
-// @Param file body entities.File true "File data"
+// @Param file body UpdateFileRequest true "File data"
 // @Success 200 {object} entities.File
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{id} [put]
 func (h *FileHandler) UpdateFile(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	var file entities.File
-	if err := c.BodyParser(&file); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateFileRequest](c)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.fileRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "File")
 	}
 
-	file.ID = id
-	if err := h.fileRepo.Update(c.Context(), &file); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update file",
-		})
+	req.apply(file)
+	if err := h.fileRepo.Update(c.Context(), file); err != nil {
+		return problem.Internal(err, "Failed to update file")
 	}
 
 	return c.JSON(file)
 }
 
-// DeleteFile deletes a file
-// @Summary Delete file
-// @Description Delete a file by ID (also removes from storage)
+// PatchFile partially updates an existing file
+// @Summary Patch file
+// @Description Apply a JSON merge patch (RFC 7396) to an existing file. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
 // @Tags files
+// @Accept application/merge-patch+json
+// @Produce json
 // @Security BearerAuth
 // @Param id path string true "File ID"
-// @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /files/{id} [delete]
-func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
+// @Param file body UpdateFileRequest true "File fields to change"
+// @Success 200 {object} entities.File
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /files/{id} [patch]
+func (h *FileHandler) PatchFile(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	// Get file to get storage path
 	file, err := h.fileRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File not found",
-		})
+		return lookupError(err, "File")
+	}
+
+	fields, err := mergePatch(c, file, (*UpdateFileRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.fileRepo.UpdateFields(c.Context(), file, fields); err != nil {
+			return problem.Internal(err, "Failed to update file")
+		}
 	}
 
-	// Delete from storage
-	if err := h.storageService.DeleteFile(c.Context(), file.StoragePath); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Warning: Failed to delete file from storage: %v\n", err)
+	return c.JSON(file)
+}
+
+// DeleteFile moves a file to the trash
+// @Summary Delete file
+// @Description Move a file to the trash by ID (storage objects are removed when the trash is purged)
+// @Tags files
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "Blocked by dependent records"
+// @Failure 500 {object} problem.Problem
+// @Router /files/{id} [delete]
+func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	// Delete file variants from storage and database
-	variants, _ := h.fileVariantRepo.GetByOriginalFileID(c.Context(), id, 1000, 0)
-	for _, variant := range variants {
-		h.storageService.DeleteFile(c.Context(), variant.StoragePath)
+	if _, err := h.fileRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "File")
 	}
-	h.fileVariantRepo.DeleteByOriginalFile(c.Context(), id)
 
-	// Delete from database
+	// Storage objects stay until the file is purged from the trash, so it can be restored
 	if err := h.fileRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete file",
-		})
+		return problem.Internal(err, "Failed to delete file")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -563,25 +627,21 @@ func (h *FileHandler) DeleteFile(c *fiber.Ctx) error {
 // @Param id path string true "File ID"
 // @Param expires_in query int false "Expiration in seconds" default(3600)
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{id}/download-url [get]
 func (h *FileHandler) GetPresignedDownloadURL(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
 	file, err := h.fileRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File not found",
-		})
+		return lookupError(err, "File")
 	}
 
 	// Parse expiration (default 1 hour)
@@ -594,9 +654,7 @@ func (h *FileHandler) GetPresignedDownloadURL(c *fiber.Ctx) error {
 		expiration,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate download URL",
-		})
+		return problem.Internal(err, "Failed to generate download URL")
 	}
 
 	return c.JSON(fiber.Map{
@@ -629,3 +687,12 @@ func generateFileHash(content []byte) string {
 	hash := sha256.Sum256(content)
 	return hex.EncodeToString(hash[:])
 }
+
+// uploaderOf returns the authenticated user, recorded as the uploader of new files
+func uploaderOf(c *fiber.Ctx) *uuid.UUID {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -36,6 +38,38 @@ func NewFileVariantHandler(
 	}
 }
 
+// UpdateFileVariantRequest holds the fields of a file variant clients may set. The whole
+// variant is replaced: the CDN URL is cleared when left out.
+type UpdateFileVariantRequest struct {
+	VariantName   string  `json:"variant_name" validate:"required,max=50"`
+	StoragePath   string  `json:"storage_path" validate:"required,max=500"`
+	CdnURL        *string `json:"cdn_url,omitempty" validate:"omitempty,url,max=500"`
+	Width         int     `json:"width" validate:"required,min=1"`
+	Height        int     `json:"height" validate:"required,min=1"`
+	FileSizeBytes int64   `json:"file_size_bytes" validate:"min=1"`
+}
+
+// CreateFileVariantRequest holds the fields of a new variant of a file
+type CreateFileVariantRequest struct {
+	OriginalFileID uuid.UUID `json:"original_file_id" validate:"required"`
+	UpdateFileVariantRequest
+}
+
+func (r *CreateFileVariantRequest) toEntity() *entities.FileVariant {
+	variant := &entities.FileVariant{OriginalFileID: r.OriginalFileID}
+	r.apply(variant)
+	return variant
+}
+
+func (r *UpdateFileVariantRequest) apply(variant *entities.FileVariant) {
+	variant.VariantName = r.VariantName
+	variant.StoragePath = r.StoragePath
+	variant.CdnURL = r.CdnURL
+	variant.Width = r.Width
+	variant.Height = r.Height
+	variant.FileSizeBytes = r.FileSizeBytes
+}
+
 // CreateFileVariant creates a new file variant
 // @Summary Create a new file variant
 // @Description Create a new file variant (thumbnail, resized version, etc.)
@@ -43,25 +77,22 @@ func NewFileVariantHandler(
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param variant body entities.FileVariant true "File variant data"
+// @Param variant body CreateFileVariantRequest true "File variant data"
 // @Success 201 {object} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants [post]
 func (h *FileVariantHandler) CreateFileVariant(c *fiber.Ctx) error {
-	var variant entities.FileVariant
-
-	if err := c.BodyParser(&variant); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateFileVariantRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.fileVariantRepo.Create(c.Context(), &variant); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create file variant",
-		})
+	variant := req.toEntity()
+	if err := h.fileVariantRepo.Create(c.Context(), variant); err != nil {
+		return problem.Internal(err, "Failed to create file variant")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(variant)
@@ -77,33 +108,27 @@ func (h *FileVariantHandler) CreateFileVariant(c *fiber.Ctx) error {
 // @Param fileId path string true "Original File ID"
 // @Param request body CreateVariantRequest true "Variant creation request"
 // @Success 201 {object} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{fileId}/variants [post]
 func (h *FileVariantHandler) CreateVariantForFile(c *fiber.Ctx) error {
 	fileIDParam := c.Params("fileId")
 	fileID, err := uuid.Parse(fileIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
-	var req CreateVariantRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateVariantRequest](c)
+	if err != nil {
+		return err
 	}
 
 	// Check if original file exists
 	originalFile, err := h.fileRepo.GetByID(c.Context(), fileID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Original file not found",
-		})
+		return lookupError(err, "Original file")
 	}
 
 	// Generate storage path for variant
@@ -121,9 +146,7 @@ func (h *FileVariantHandler) CreateVariantForFile(c *fiber.Ctx) error {
 	}
 
 	if err := h.fileVariantRepo.Create(c.Context(), variant); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create file variant",
-		})
+		return problem.Internal(err, "Failed to create file variant")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(variant)
@@ -137,24 +160,20 @@ func (h *FileVariantHandler) CreateVariantForFile(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "File Variant ID"
 // @Success 200 {object} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /file-variants/{id} [get]
 func (h *FileVariantHandler) GetFileVariantByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file variant ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file variant ID")
 	}
 
 	variant, err := h.fileVariantRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File variant not found",
-		})
+		return lookupError(err, "File variant")
 	}
 
 	return c.JSON(variant)
@@ -170,17 +189,15 @@ func (h *FileVariantHandler) GetFileVariantByID(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{fileId}/variants [get]
 func (h *FileVariantHandler) GetFileVariantsByOriginalFile(c *fiber.Ctx) error {
 	fileIDParam := c.Params("fileId")
 	fileID, err := uuid.Parse(fileIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -188,9 +205,7 @@ func (h *FileVariantHandler) GetFileVariantsByOriginalFile(c *fiber.Ctx) error {
 
 	variants, err := h.fileVariantRepo.GetByOriginalFileID(c.Context(), fileID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch file variants",
-		})
+		return problem.Internal(err, "Failed to fetch file variants")
 	}
 
 	return c.JSON(variants)
@@ -205,31 +220,25 @@ func (h *FileVariantHandler) GetFileVariantsByOriginalFile(c *fiber.Ctx) error {
 // @Param fileId path string true "Original File ID"
 // @Param variantName path string true "Variant Name"
 // @Success 200 {object} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /files/{fileId}/variants/{variantName} [get]
 func (h *FileVariantHandler) GetFileVariantByName(c *fiber.Ctx) error {
 	fileIDParam := c.Params("fileId")
 	fileID, err := uuid.Parse(fileIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
 	variantName := c.Params("variantName")
 	if variantName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Variant name is required",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Variant name is required")
 	}
 
 	variant, err := h.fileVariantRepo.GetByVariantName(c.Context(), fileID, variantName)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File variant not found",
-		})
+		return lookupError(err, "File variant")
 	}
 
 	return c.JSON(variant)
@@ -246,8 +255,8 @@ func (h *FileVariantHandler) GetFileVariantByName(c *fiber.Ctx) error {
 // @Param width query int false "Filter by width"
 // @Param height query int false "Filter by height"
 // @Success 200 {array} entities.FileVariant
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants [get]
 func (h *FileVariantHandler) GetAllFileVariants(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -265,23 +274,17 @@ func (h *FileVariantHandler) GetAllFileVariants(c *fiber.Ctx) error {
 			if err1 == nil && err2 == nil {
 				variants, err = h.fileVariantRepo.GetByDimensions(c.Context(), width, height, limit, offset)
 			} else {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": "Invalid width or height parameters",
-				})
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid width or height parameters")
 			}
 		} else {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Height parameter is required when width is specified",
-			})
+			return fiber.NewError(fiber.StatusBadRequest, "Height parameter is required when width is specified")
 		}
 	} else {
 		variants, err = h.fileVariantRepo.GetAll(c.Context(), limit, offset)
 	}
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch file variants",
-		})
+		return problem.Internal(err, "Failed to fetch file variants")
 	}
 
 	return c.JSON(variants)
@@ -295,34 +298,33 @@ func (h *FileVariantHandler) GetAllFileVariants(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "File Variant ID"
-// @Param variant body entities.FileVariant true "File variant data"
+// @Param variant body UpdateFileVariantRequest true "File variant data"
 // @Success 200 {object} entities.FileVariant
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants/{id} [put]
 func (h *FileVariantHandler) UpdateFileVariant(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file variant ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file variant ID")
 	}
 
-	var variant entities.FileVariant
-	if err := c.BodyParser(&variant); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateFileVariantRequest](c)
+	if err != nil {
+		return err
 	}
 
-	variant.ID = id
-	if err := h.fileVariantRepo.Update(c.Context(), &variant); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update file variant",
-		})
+	variant, err := h.fileVariantRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "File variant")
+	}
+
+	req.apply(variant)
+	if err := h.fileVariantRepo.Update(c.Context(), variant); err != nil {
+		return problem.Internal(err, "Failed to update file variant")
 	}
 
 	return c.JSON(variant)
@@ -335,39 +337,37 @@ func (h *FileVariantHandler) UpdateFileVariant(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "File Variant ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants/{id} [delete]
 func (h *FileVariantHandler) DeleteFileVariant(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file variant ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file variant ID")
 	}
 
 	// Get variant to get storage path
 	variant, err := h.fileVariantRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File variant not found",
-		})
+		return lookupError(err, "File variant")
 	}
 
 	// Delete from storage
 	if err := h.storageService.DeleteFile(c.Context(), variant.StoragePath); err != nil {
 		// Log error but don't fail the request
-		fmt.Printf("Warning: Failed to delete file variant from storage: %v\n", err)
+		slog.WarnContext(c.Context(), "Failed to delete file variant from storage", "path", variant.StoragePath, "error", err)
 	}
 
 	// Delete from database
+	if _, err := h.fileVariantRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "File variant")
+	}
+
 	if err := h.fileVariantRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete file variant",
-		})
+		return problem.Internal(err, "Failed to delete file variant")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -380,40 +380,34 @@ func (h *FileVariantHandler) DeleteFileVariant(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param fileId path string true "Original File ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/{fileId}/variants [delete]
 func (h *FileVariantHandler) DeleteAllVariantsForFile(c *fiber.Ctx) error {
 	fileIDParam := c.Params("fileId")
 	fileID, err := uuid.Parse(fileIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file ID")
 	}
 
 	// Get all variants for the file
 	variants, err := h.fileVariantRepo.GetByOriginalFileID(c.Context(), fileID, 1000, 0)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch file variants",
-		})
+		return problem.Internal(err, "Failed to fetch file variants")
 	}
 
 	// Delete each variant from storage
 	for _, variant := range variants {
 		if err := h.storageService.DeleteFile(c.Context(), variant.StoragePath); err != nil {
 			// Log error but continue
-			fmt.Printf("Warning: Failed to delete file variant from storage: %v\n", err)
+			slog.WarnContext(c.Context(), "Failed to delete file variant from storage", "path", variant.StoragePath, "error", err)
 		}
 	}
 
 	// Delete all variants from database
 	if err := h.fileVariantRepo.DeleteByOriginalFile(c.Context(), fileID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete file variants",
-		})
+		return problem.Internal(err, "Failed to delete file variants")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -429,25 +423,21 @@ func (h *FileVariantHandler) DeleteAllVariantsForFile(c *fiber.Ctx) error {
 // @Param id path string true "File Variant ID"
 // @Param expires_in query int false "Expiration in seconds" default(3600)
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants/{id}/upload-url [get]
 func (h *FileVariantHandler) GetPresignedVariantUploadURL(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file variant ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file variant ID")
 	}
 
 	variant, err := h.fileVariantRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File variant not found",
-		})
+		return lookupError(err, "File variant")
 	}
 
 	// Parse expiration (default 1 hour)
@@ -464,9 +454,7 @@ func (h *FileVariantHandler) GetPresignedVariantUploadURL(c *fiber.Ctx) error {
 		contentType,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate upload URL",
-		})
+		return problem.Internal(err, "Failed to generate upload URL")
 	}
 
 	return c.JSON(fiber.Map{
@@ -484,25 +472,21 @@ func (h *FileVariantHandler) GetPresignedVariantUploadURL(c *fiber.Ctx) error {
 // @Param id path string true "File Variant ID"
 // @Param expires_in query int false "Expiration in seconds" default(3600)
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /file-variants/{id}/download-url [get]
 func (h *FileVariantHandler) GetPresignedVariantDownloadURL(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid file variant ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid file variant ID")
 	}
 
 	variant, err := h.fileVariantRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File variant not found",
-		})
+		return lookupError(err, "File variant")
 	}
 
 	// Parse expiration (default 1 hour)
@@ -515,9 +499,7 @@ func (h *FileVariantHandler) GetPresignedVariantDownloadURL(c *fiber.Ctx) error
 		expiration,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate download URL",
-		})
+		return problem.Internal(err, "Failed to generate download URL")
 	}
 
 	return c.JSON(fiber.Map{
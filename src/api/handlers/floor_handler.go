@@ -5,6 +5,7 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,6 +21,34 @@ func NewFloorHandler(floorRepo interfaces.FloorRepository) *FloorHandler {
 	}
 }
 
+// UpdateFloorRequest holds the fields of a floor clients may set. The whole floor is
+// replaced: optional fields left out are cleared.
+type UpdateFloorRequest struct {
+	FloorNumber     int        `json:"floor_number"`
+	FloorName       *string    `json:"floor_name,omitempty" validate:"omitempty,max=100"`
+	BannerFileID    *uuid.UUID `json:"banner_file_id,omitempty"`
+	FloorPlanFileID *uuid.UUID `json:"floor_plan_file_id,omitempty"`
+}
+
+// CreateFloorRequest holds the fields of a new floor
+type CreateFloorRequest struct {
+	TowerID uuid.UUID `json:"tower_id" validate:"required"`
+	UpdateFloorRequest
+}
+
+func (r *CreateFloorRequest) toEntity() *entities.Floor {
+	floor := &entities.Floor{TowerID: r.TowerID}
+	r.apply(floor)
+	return floor
+}
+
+func (r *UpdateFloorRequest) apply(floor *entities.Floor) {
+	floor.FloorNumber = r.FloorNumber
+	floor.FloorName = r.FloorName
+	floor.BannerFileID = r.BannerFileID
+	floor.FloorPlanFileID = r.FloorPlanFileID
+}
+
 // CreateFloor creates a new floor
 // @Summary Create a new floor
 // @Description Create a new floor with the provided data
@@ -27,25 +56,22 @@ func NewFloorHandler(floorRepo interfaces.FloorRepository) *FloorHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param floor body entities.Floor true "Floor data"
+// @Param floor body CreateFloorRequest true "Floor data"
 // @Success 201 {object} entities.Floor
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors [post]
 func (h *FloorHandler) CreateFloor(c *fiber.Ctx) error {
-	var floor entities.Floor
-
-	if err := c.BodyParser(&floor); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateFloorRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.floorRepo.Create(c.Context(), &floor); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create floor",
-		})
+	floor := req.toEntity()
+	if err := h.floorRepo.Create(c.Context(), floor); err != nil {
+		return problem.Internal(err, "Failed to create floor")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(floor)
@@ -59,25 +85,21 @@ func (h *FloorHandler) CreateFloor(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Floor ID"
 // @Success 200 {object} entities.Floor
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors/{id} [get]
 func (h *FloorHandler) GetFloorByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid floor ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor ID")
 	}
 
 	floor, err := h.floorRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Floor not found",
-		})
+		return lookupError(err, "Floor")
 	}
 
 	return c.JSON(floor)
@@ -92,8 +114,8 @@ func (h *FloorHandler) GetFloorByID(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Floor
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors [get]
 func (h *FloorHandler) GetFloors(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -101,9 +123,7 @@ func (h *FloorHandler) GetFloors(c *fiber.Ctx) error {
 
 	floors, err := h.floorRepo.GetAll(c.Context(), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch floors",
-		})
+		return problem.Internal(err, "Failed to fetch floors")
 	}
 
 	return c.JSON(floors)
@@ -119,17 +139,15 @@ func (h *FloorHandler) GetFloors(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Floor
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{towerId}/floors [get]
 func (h *FloorHandler) GetFloorsByTower(c *fiber.Ctx) error {
 	towerIDParam := c.Params("towerId")
 	towerID, err := uuid.Parse(towerIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -137,9 +155,7 @@ func (h *FloorHandler) GetFloorsByTower(c *fiber.Ctx) error {
 
 	floors, err := h.floorRepo.GetByTowerID(c.Context(), towerID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch floors",
-		})
+		return problem.Internal(err, "Failed to fetch floors")
 	}
 
 	return c.JSON(floors)
@@ -154,33 +170,27 @@ func (h *FloorHandler) GetFloorsByTower(c *fiber.Ctx) error {
 // @Param towerId path string true "Tower ID"
 // @Param floorNumber path int true "Floor Number"
 // @Success 200 {object} entities.Floor
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{towerId}/floors/{floorNumber} [get]
 func (h *FloorHandler) GetFloorByNumber(c *fiber.Ctx) error {
 	towerIDParam := c.Params("towerId")
 	towerID, err := uuid.Parse(towerIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
 	}
 
 	floorNumberParam := c.Params("floorNumber")
 	floorNumber, err := strconv.Atoi(floorNumberParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid floor number",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor number")
 	}
 
 	floor, err := h.floorRepo.GetByFloorNumber(c.Context(), towerID, floorNumber)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Floor not found",
-		})
+		return lookupError(err, "Floor")
 	}
 
 	return c.JSON(floor)
@@ -194,34 +204,74 @@ func (h *FloorHandler) GetFloorByNumber(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Floor ID"
-// @Param floor body entities.Floor true "Floor data"
+// @Param floor body UpdateFloorRequest true "Floor data"
 // @Success 200 {object} entities.Floor
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors/{id} [put]
 func (h *FloorHandler) UpdateFloor(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid floor ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor ID")
+	}
+
+	req, err := Bind[UpdateFloorRequest](c)
+	if err != nil {
+		return err
+	}
+
+	floor, err := h.floorRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Floor")
+	}
+
+	req.apply(floor)
+	if err := h.floorRepo.Update(c.Context(), floor); err != nil {
+		return problem.Internal(err, "Failed to update floor")
+	}
+
+	return c.JSON(floor)
+}
+
+// PatchFloor partially updates an existing floor
+// @Summary Patch floor
+// @Description Apply a JSON merge patch (RFC 7396) to an existing floor. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags floors
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Floor ID"
+// @Param floor body UpdateFloorRequest true "Floor fields to change"
+// @Success 200 {object} entities.Floor
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /floors/{id} [patch]
+func (h *FloorHandler) PatchFloor(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor ID")
 	}
 
-	var floor entities.Floor
-	if err := c.BodyParser(&floor); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	floor, err := h.floorRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Floor")
 	}
 
-	floor.ID = id
-	if err := h.floorRepo.Update(c.Context(), &floor); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update floor",
-		})
+	fields, err := mergePatch(c, floor, (*UpdateFloorRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.floorRepo.UpdateFields(c.Context(), floor, fields); err != nil {
+			return problem.Internal(err, "Failed to update floor")
+		}
 	}
 
 	return c.JSON(floor)
@@ -234,24 +284,24 @@ func (h *FloorHandler) UpdateFloor(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Floor ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors/{id} [delete]
 func (h *FloorHandler) DeleteFloor(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid floor ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor ID")
+	}
+
+	if _, err := h.floorRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Floor")
 	}
 
 	if err := h.floorRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete floor",
-		})
+		return problem.Internal(err, "Failed to delete floor")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"terra-allwert/infra/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthHandler serves the probes of orchestrators and load balancers and the health
+// report of the API
+type HealthHandler struct {
+	checker *health.Checker
+	version string
+}
+
+func NewHealthHandler(checker *health.Checker, version string) *HealthHandler {
+	return &HealthHandler{checker: checker, version: version}
+}
+
+// LivenessResponse is the answer of the liveness probe
+type LivenessResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// HealthResponse represents the health check response
+type HealthResponse struct {
+	Status   string                   `json:"status" example:"ok"`
+	Message  string                   `json:"message" example:"API is running"`
+	Version  string                   `json:"version" example:"1.0.0"`
+	Phase    string                   `json:"phase" example:"ready"`
+	Services map[string]health.Result `json:"services"`
+}
+
+// Live reports the process is up and serving requests
+// @Summary Liveness probe
+// @Description Succeeds while the process serves requests, without checking its dependencies. Restart the instance when it fails.
+// @Tags health
+// @Produce json
+// @Success 200 {object} LivenessResponse
+// @Router /livez [get]
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(LivenessResponse{Status: health.StatusOK})
+}
+
+// Ready reports whether the instance can take traffic
+// @Summary Readiness probe
+// @Description Succeeds once the API started and while Postgres, Redis, MinIO and the WebSocket hub pass their checks. Fails during startup and graceful shutdown. Results are cached for a few seconds.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	report := h.checker.Run(c.Context())
+	if report.Status != health.StatusOK {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.JSON(report)
+}
+
+// Health reports the status of the API and its dependencies
+// @Summary Health Check
+// @Description Verifica se a API está funcionando e o estado do Postgres, Redis, MinIO e do hub WebSocket. Responde 200 mesmo com dependências indisponíveis, use /readyz para balanceamento.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Router /health [get]
+func (h *HealthHandler) Health(c *fiber.Ctx) error {
+	report := h.checker.Run(c.Context())
+	status := health.StatusOK
+	for _, result := range report.Checks {
+		if result.Status != health.StatusOK {
+			status = "degraded"
+		}
+	}
+	return c.JSON(HealthResponse{
+		Status:   status,
+		Message:  "API is running",
+		Version:  h.version,
+		Phase:    report.Phase,
+		Services: report.Checks,
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"terra-allwert/infra/auth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{jwtService: jwtService}
+}
+
+// GetJWKS returns the JSON Web Key Set
+// @Summary JSON Web Key Set
+// @Description Public keys access tokens are signed with, matched by the kid header of a token. Includes keys kept for verification during a rotation. Empty while tokens are signed with a shared secret.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.jwtService.JWKS())
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
+	"terra-allwert/infra/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MemoryStorageHandler serves the presigned URLs issued by the in-memory storage service
+type MemoryStorageHandler struct {
+	storage *storage.MemoryStorageService
+}
+
+func NewMemoryStorageHandler(storage *storage.MemoryStorageService) *MemoryStorageHandler {
+	return &MemoryStorageHandler{storage: storage}
+}
+
+// PutObject stores an object or a multipart part sent to a presigned upload URL
+func (h *MemoryStorageHandler) PutObject(c *fiber.Ctx) error {
+	objectKey := c.Params("*")
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query string")
+	}
+	if err := h.storage.VerifyPresignedURL(fiber.MethodPut, objectKey, query); err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	body := c.Body()
+	if uploadID := query.Get("uploadId"); uploadID != "" {
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid part number")
+		}
+		etag, err := h.storage.UploadPart(c.Context(), objectKey, uploadID, partNumber, body)
+		if err != nil {
+			if errors.Is(err, storage.ErrUploadNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, err.Error())
+			}
+			return problem.Internal(err, "Failed to store part")
+		}
+		c.Set("ETag", `"`+etag+`"`)
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	contentType := c.Get(fiber.HeaderContentType, query.Get("Content-Type"))
+	if err := h.storage.UploadFile(c.Context(), objectKey, bytes.NewReader(body), int64(len(body)), contentType); err != nil {
+		return problem.Internal(err, "Failed to store object")
+	}
+
+	info, err := h.storage.GetFileInfo(c.Context(), objectKey)
+	if err == nil {
+		c.Set("ETag", `"`+info.ETag+`"`)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GetObject streams an object requested through a presigned download URL
+func (h *MemoryStorageHandler) GetObject(c *fiber.Ctx) error {
+	objectKey := c.Params("*")
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query string")
+	}
+	if err := h.storage.VerifyPresignedURL(fiber.MethodGet, objectKey, query); err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	info, err := h.storage.GetFileInfo(c.Context(), objectKey)
+	if err != nil {
+		return objectLookupError(err)
+	}
+	reader, err := h.storage.DownloadFile(c.Context(), objectKey)
+	if err != nil {
+		return objectLookupError(err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return problem.Internal(err, "Failed to read object")
+	}
+	if info.ContentType != "" {
+		c.Set(fiber.HeaderContentType, info.ContentType)
+	}
+	c.Set("ETag", `"`+info.ETag+`"`)
+	return c.Send(data)
+}
+
+// objectLookupError reports a failed lookup of an object as not found when it does not
+// exist, and as a server error otherwise
+func objectLookupError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return &interfaces.NotFoundError{Entity: "Object"}
+	}
+	return err
+}
@@ -5,6 +5,7 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,6 +21,51 @@ func NewMenuHandler(menuRepo interfaces.MenuRepository) *MenuHandler {
 	}
 }
 
+// UpdateMenuRequest holds the fields of a menu clients may set. The whole menu is
+// replaced: optional fields left out are cleared, except the menu type and visibility
+// which are kept.
+type UpdateMenuRequest struct {
+	ParentMenuID  *uuid.UUID          `json:"parent_menu_id,omitempty"`
+	Title         string              `json:"title" validate:"required,min=1,max=255"`
+	Slug          string              `json:"slug" validate:"required,slug"`
+	ScreenType    entities.ScreenType `json:"screen_type" validate:"required,oneof=pins floor_plan carousel list map"`
+	MenuType      entities.MenuType   `json:"menu_type,omitempty" validate:"omitempty,oneof=standard submenu"`
+	Position      int                 `json:"position" validate:"min=0"`
+	Icon          *string             `json:"icon,omitempty" validate:"omitempty,max=50"`
+	IsVisible     *bool               `json:"is_visible,omitempty"`
+	PathHierarchy *string             `json:"path_hierarchy,omitempty" validate:"omitempty,max=500"`
+	DepthLevel    int                 `json:"depth_level" validate:"min=0"`
+}
+
+// CreateMenuRequest holds the fields of a new menu
+type CreateMenuRequest struct {
+	EnterpriseID uuid.UUID `json:"enterprise_id" validate:"required"`
+	UpdateMenuRequest
+}
+
+func (r *CreateMenuRequest) toEntity() *entities.Menu {
+	menu := &entities.Menu{EnterpriseID: r.EnterpriseID, MenuType: entities.MenuTypeStandard, IsVisible: true}
+	r.apply(menu)
+	return menu
+}
+
+func (r *UpdateMenuRequest) apply(menu *entities.Menu) {
+	menu.ParentMenuID = r.ParentMenuID
+	menu.Title = r.Title
+	menu.Slug = r.Slug
+	menu.ScreenType = r.ScreenType
+	if r.MenuType != "" {
+		menu.MenuType = r.MenuType
+	}
+	menu.Position = r.Position
+	menu.Icon = r.Icon
+	if r.IsVisible != nil {
+		menu.IsVisible = *r.IsVisible
+	}
+	menu.PathHierarchy = r.PathHierarchy
+	menu.DepthLevel = r.DepthLevel
+}
+
 // CreateMenu creates a new menu
 // @Summary Create a new menu
 // @Description Create a new menu with the provided data
@@ -27,25 +73,22 @@ func NewMenuHandler(menuRepo interfaces.MenuRepository) *MenuHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param menu body entities.Menu true "Menu data"
+// @Param menu body CreateMenuRequest true "Menu data"
 // @Success 201 {object} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus [post]
 func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
-	var menu entities.Menu
-
-	if err := c.BodyParser(&menu); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateMenuRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.menuRepo.Create(c.Context(), &menu); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create menu",
-		})
+	menu := req.toEntity()
+	if err := h.menuRepo.Create(c.Context(), menu); err != nil {
+		return problem.Internal(err, "Failed to create menu")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(menu)
@@ -59,25 +102,21 @@ func (h *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu ID"
 // @Success 200 {object} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus/{id} [get]
 func (h *MenuHandler) GetMenuByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
 	menu, err := h.menuRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Menu not found",
-		})
+		return lookupError(err, "Menu")
 	}
 
 	return c.JSON(menu)
@@ -92,8 +131,8 @@ func (h *MenuHandler) GetMenuByID(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Menu
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus [get]
 func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -101,9 +140,7 @@ func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 
 	menus, err := h.menuRepo.GetAll(c.Context(), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch menus",
-		})
+		return problem.Internal(err, "Failed to fetch menus")
 	}
 
 	return c.JSON(menus)
@@ -119,17 +156,15 @@ func (h *MenuHandler) GetMenus(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/{enterpriseId}/menus [get]
 func (h *MenuHandler) GetMenusByEnterprise(c *fiber.Ctx) error {
 	enterpriseIDParam := c.Params("enterpriseId")
 	enterpriseID, err := uuid.Parse(enterpriseIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid enterprise ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -137,9 +172,7 @@ func (h *MenuHandler) GetMenusByEnterprise(c *fiber.Ctx) error {
 
 	menus, err := h.menuRepo.GetByEnterpriseID(c.Context(), enterpriseID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch menus",
-		})
+		return problem.Internal(err, "Failed to fetch menus")
 	}
 
 	return c.JSON(menus)
@@ -153,24 +186,20 @@ func (h *MenuHandler) GetMenusByEnterprise(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param enterpriseId path string true "Enterprise ID"
 // @Success 200 {array} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /enterprises/{enterpriseId}/menus/hierarchy [get]
 func (h *MenuHandler) GetMenuHierarchy(c *fiber.Ctx) error {
 	enterpriseIDParam := c.Params("enterpriseId")
 	enterpriseID, err := uuid.Parse(enterpriseIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid enterprise ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid enterprise ID")
 	}
 
 	menus, err := h.menuRepo.GetMenuHierarchy(c.Context(), enterpriseID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch menu hierarchy",
-		})
+		return problem.Internal(err, "Failed to fetch menu hierarchy")
 	}
 
 	return c.JSON(menus)
@@ -186,17 +215,15 @@ func (h *MenuHandler) GetMenuHierarchy(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus/{parentId}/children [get]
 func (h *MenuHandler) GetChildMenus(c *fiber.Ctx) error {
 	parentIDParam := c.Params("parentId")
 	parentID, err := uuid.Parse(parentIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid parent menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid parent menu ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -204,9 +231,7 @@ func (h *MenuHandler) GetChildMenus(c *fiber.Ctx) error {
 
 	menus, err := h.menuRepo.GetChildren(c.Context(), parentID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch child menus",
-		})
+		return problem.Internal(err, "Failed to fetch child menus")
 	}
 
 	return c.JSON(menus)
@@ -220,34 +245,74 @@ func (h *MenuHandler) GetChildMenus(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Menu ID"
-// @Param menu body entities.Menu true "Menu data"
+// @Param menu body UpdateMenuRequest true "Menu data"
 // @Success 200 {object} entities.Menu
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus/{id} [put]
 func (h *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
-	var menu entities.Menu
-	if err := c.BodyParser(&menu); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateMenuRequest](c)
+	if err != nil {
+		return err
+	}
+
+	menu, err := h.menuRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Menu")
 	}
 
-	menu.ID = id
-	if err := h.menuRepo.Update(c.Context(), &menu); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update menu",
-		})
+	req.apply(menu)
+	if err := h.menuRepo.Update(c.Context(), menu); err != nil {
+		return problem.Internal(err, "Failed to update menu")
+	}
+
+	return c.JSON(menu)
+}
+
+// PatchMenu partially updates an existing menu
+// @Summary Patch menu
+// @Description Apply a JSON merge patch (RFC 7396) to an existing menu. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags menus
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Menu ID"
+// @Param menu body UpdateMenuRequest true "Menu fields to change"
+// @Success 200 {object} entities.Menu
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /menus/{id} [patch]
+func (h *MenuHandler) PatchMenu(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
+	}
+
+	menu, err := h.menuRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Menu")
+	}
+
+	fields, err := mergePatch(c, menu, (*UpdateMenuRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.menuRepo.UpdateFields(c.Context(), menu, fields); err != nil {
+			return problem.Internal(err, "Failed to update menu")
+		}
 	}
 
 	return c.JSON(menu)
@@ -261,35 +326,31 @@ func (h *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Menu ID"
-// @Param position body object{position=int} true "Position data"
+// @Param position body UpdatePositionRequest true "Position data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menus/{id}/position [patch]
 func (h *MenuHandler) UpdateMenuPosition(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
-	var body struct {
-		Position int `json:"position"`
+	req, err := Bind[UpdatePositionRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if _, err := h.menuRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Menu")
 	}
 
-	if err := h.menuRepo.UpdatePosition(c.Context(), id, body.Position); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update menu position",
-		})
+	if err := h.menuRepo.UpdatePosition(c.Context(), id, req.Position); err != nil {
+		return problem.Internal(err, "Failed to update menu position")
 	}
 
 	return c.JSON(fiber.Map{
@@ -304,24 +365,25 @@ func (h *MenuHandler) UpdateMenuPosition(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "Blocked by dependent records"
+// @Failure 500 {object} problem.Problem
 // @Router /menus/{id} [delete]
 func (h *MenuHandler) DeleteMenu(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
+	}
+
+	if _, err := h.menuRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Menu")
 	}
 
 	if err := h.menuRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete menu",
-		})
+		return problem.Internal(err, "Failed to delete menu")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
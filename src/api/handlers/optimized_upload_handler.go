@@ -9,7 +9,9 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/metrics"
 	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
 	"terra-allwert/infra/storage"
 	"terra-allwert/infra/websocket"
 
@@ -25,6 +27,15 @@ type OptimizedUploadHandler struct {
 	progressHub        *websocket.ProgressHub
 	rateLimiter        *middleware.UploadRateLimiter
 	circuitBreaker     *middleware.CircuitBreaker
+	thresholds         UploadThresholds
+	uploadMetrics      *metrics.Metrics
+}
+
+// UploadThresholds are the largest files uploaded directly through the API and to a
+// presigned URL, larger files are uploaded in parts
+type UploadThresholds struct {
+	DirectMaxSize    int64
+	PresignedMaxSize int64
 }
 
 func NewOptimizedUploadHandler(
@@ -34,6 +45,8 @@ func NewOptimizedUploadHandler(
 	progressHub *websocket.ProgressHub,
 	rateLimiter *middleware.UploadRateLimiter,
 	circuitBreaker *middleware.CircuitBreaker,
+	thresholds UploadThresholds,
+	uploadMetrics *metrics.Metrics,
 ) *OptimizedUploadHandler {
 	return &OptimizedUploadHandler{
 		fileRepo:           fileRepo,
@@ -42,6 +55,8 @@ func NewOptimizedUploadHandler(
 		progressHub:        progressHub,
 		rateLimiter:        rateLimiter,
 		circuitBreaker:     circuitBreaker,
+		thresholds:         thresholds,
+		uploadMetrics:      uploadMetrics,
 	}
 }
 
@@ -79,38 +94,37 @@ type PartUploadInfo struct {
 // @Security BearerAuth
 // @Param upload body OptimizedUploadRequest true "Upload request"
 // @Success 200 {object} OptimizedUploadResponse
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 429 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/optimized-upload [post]
 func (h *OptimizedUploadHandler) InitiateOptimizedUpload(c *fiber.Ctx) error {
-	var req OptimizedUploadRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[OptimizedUploadRequest](c)
+	if err != nil {
+		return err
+	}
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
 	}
 
 	ctx := c.Context()
 
 	// Check circuit breaker
 	if !h.circuitBreaker.IsAllowed() {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"error":       "Service temporarily unavailable",
-			"retry_after": "30",
-		})
+		return problem.New(fiber.StatusServiceUnavailable, "Service temporarily unavailable").
+			With("retry_after", "30")
 	}
 
 	// Rate limiting check
 	if !h.rateLimiter.Allow(req.UserID, req.FileSize) {
 		category := middleware.GetFileSizeCategory(req.FileSize)
-		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-			"error":         "Upload rate limit exceeded",
-			"file_size":     req.FileSize,
-			"size_category": string(category),
-			"retry_after":   "60",
-		})
+		return problem.New(fiber.StatusTooManyRequests, "Upload rate limit exceeded").
+			With("file_size", req.FileSize).
+			With("size_category", string(category)).
+			With("retry_after", "60")
 	}
 
 	// Generate file ID
@@ -120,7 +134,6 @@ func (h *OptimizedUploadHandler) InitiateOptimizedUpload(c *fiber.Ctx) error {
 	uploadMethod := h.chooseUploadMethod(req.FileSize)
 
 	var response OptimizedUploadResponse
-	var err error
 
 	switch uploadMethod {
 	case "direct":
@@ -130,19 +143,16 @@ func (h *OptimizedUploadHandler) InitiateOptimizedUpload(c *fiber.Ctx) error {
 	case "multipart":
 		response, err = h.handleMultipartUpload(ctx, fileID, req)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid upload method determined",
-		})
+		return fiber.NewError(fiber.StatusInternalServerError, "Invalid upload method determined")
 	}
 
 	if err != nil {
 		h.circuitBreaker.RecordFailure()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to initiate upload: %v", err),
-		})
+		return problem.Internal(err, "Failed to initiate upload")
 	}
 
 	h.circuitBreaker.RecordSuccess()
+	h.uploadMetrics.ObserveUpload(string(middleware.GetFileSizeCategory(req.FileSize)), uploadMethod, req.FileSize)
 
 	// Determine file type and extension
 	extension := strings.ToLower(filepath.Ext(req.FileName))
@@ -159,20 +169,20 @@ func (h *OptimizedUploadHandler) InitiateOptimizedUpload(c *fiber.Ctx) error {
 	now := time.Now()
 	file := &entities.File{
 		ID:            fileID,
+		EnterpriseID:  enterpriseID,
 		OriginalName:  req.FileName,
 		MimeType:      req.ContentType,
 		Extension:     extension,
 		FileType:      fileType,
 		FileSizeBytes: req.FileSize,
 		StoragePath:   "uploading", // Will be updated when upload completes
+		UploadedBy:    uploaderOf(c),
 		CreatedAt:     now,
 		UpdatedAt:     &now,
 	}
 
 	if err := h.fileRepo.Create(ctx, file); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create file record",
-		})
+		return problem.Internal(err, "Failed to create file record")
 	}
 
 	// Send initial progress update
@@ -195,30 +205,30 @@ func (h *OptimizedUploadHandler) InitiateOptimizedUpload(c *fiber.Ctx) error {
 // chooseUploadMethod selects the optimal upload method based on file size
 func (h *OptimizedUploadHandler) chooseUploadMethod(fileSize int64) string {
 	switch {
-	case fileSize < 16*1024*1024: // < 16MB - direct upload through API
+	case fileSize < h.thresholds.DirectMaxSize: // direct upload through API
 		return "direct"
-	case fileSize < 100*1024*1024: // 16MB - 100MB - presigned URL
+	case fileSize < h.thresholds.PresignedMaxSize: // presigned URL
 		return "presigned"
-	default: // > 100MB - multipart upload
+	default: // multipart upload
 		return "multipart"
 	}
 }
 
 // handleDirectUpload handles small files directly through the API
-func (h *OptimizedUploadHandler) handleDirectUpload(ctx context.Context, fileID uuid.UUID, req OptimizedUploadRequest) (OptimizedUploadResponse, error) {
+func (h *OptimizedUploadHandler) handleDirectUpload(ctx context.Context, fileID uuid.UUID, req *OptimizedUploadRequest) (OptimizedUploadResponse, error) {
 	return OptimizedUploadResponse{
 		FileID:    fileID.String(),
 		Method:    "direct",
 		ExpiresAt: time.Now().Add(15 * time.Minute).Format(time.RFC3339),
 		Metadata: map[string]interface{}{
 			"upload_endpoint": "/api/v1/files/direct-upload",
-			"max_size":        16 * 1024 * 1024,
+			"max_size":        h.thresholds.DirectMaxSize,
 		},
 	}, nil
 }
 
 // handlePresignedUpload generates presigned URLs for medium-sized files
-func (h *OptimizedUploadHandler) handlePresignedUpload(ctx context.Context, fileID uuid.UUID, req OptimizedUploadRequest) (OptimizedUploadResponse, error) {
+func (h *OptimizedUploadHandler) handlePresignedUpload(ctx context.Context, fileID uuid.UUID, req *OptimizedUploadRequest) (OptimizedUploadResponse, error) {
 	storageKey := fmt.Sprintf("files/%s/%s", fileID.String(), req.FileName)
 	expiration := 60 * time.Minute
 
@@ -234,13 +244,13 @@ func (h *OptimizedUploadHandler) handlePresignedUpload(ctx context.Context, file
 		ExpiresAt: time.Now().Add(expiration).Format(time.RFC3339),
 		Metadata: map[string]interface{}{
 			"storage_key": storageKey,
-			"max_size":    100 * 1024 * 1024,
+			"max_size":    h.thresholds.PresignedMaxSize,
 		},
 	}, nil
 }
 
 // handleMultipartUpload initiates multipart upload for large files
-func (h *OptimizedUploadHandler) handleMultipartUpload(ctx context.Context, fileID uuid.UUID, req OptimizedUploadRequest) (OptimizedUploadResponse, error) {
+func (h *OptimizedUploadHandler) handleMultipartUpload(ctx context.Context, fileID uuid.UUID, req *OptimizedUploadRequest) (OptimizedUploadResponse, error) {
 	storageKey := fmt.Sprintf("files/%s/%s", fileID.String(), req.FileName)
 
 	uploadID, err := h.storageService.InitiateMultipartUpload(ctx, storageKey, req.ContentType)
@@ -331,19 +341,17 @@ func (h *OptimizedUploadHandler) calculateOptimalPartSize(fileSize int64) int64
 // @Param uploadId path string true "Upload ID"
 // @Param completion body CompleteMultipartRequest true "Completion data"
 // @Success 200 {object} entities.File
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/optimized-upload/{uploadId}/complete [post]
 func (h *OptimizedUploadHandler) CompleteOptimizedUpload(c *fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
 
-	var req CompleteMultipartRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CompleteMultipartRequest](c)
+	if err != nil {
+		return err
 	}
 
 	ctx := c.Context()
@@ -351,25 +359,19 @@ func (h *OptimizedUploadHandler) CompleteOptimizedUpload(c *fiber.Ctx) error {
 	// Get upload state
 	uploadState, err := h.uploadStateManager.GetUploadState(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Upload not found",
-		})
+		return lookupError(err, "Upload")
 	}
 
 	// Complete the multipart upload
 	if err := h.storageService.CompleteMultipartUpload(ctx, uploadState.ObjectKey, uploadID, req.Parts); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to complete upload",
-		})
+		return problem.Internal(err, "Failed to complete upload")
 	}
 
 	// Update file status
 	fileID, _ := uuid.Parse(uploadState.ObjectKey[6:42]) // Extract UUID from "files/{uuid}/filename"
 	file, err := h.fileRepo.GetByID(ctx, fileID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "File record not found",
-		})
+		return lookupError(err, "File record")
 	}
 
 	file.StoragePath = uploadState.ObjectKey
@@ -377,9 +379,7 @@ func (h *OptimizedUploadHandler) CompleteOptimizedUpload(c *fiber.Ctx) error {
 	file.UpdatedAt = &now
 
 	if err := h.fileRepo.Update(ctx, file); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update file record",
-		})
+		return problem.Internal(err, "Failed to update file record")
 	}
 
 	// Update upload state
@@ -415,9 +415,9 @@ func (h *OptimizedUploadHandler) CompleteOptimizedUpload(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param uploadId path string true "Upload ID"
 // @Success 200 {object} storage.UploadState
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/upload-progress/{uploadId} [get]
 func (h *OptimizedUploadHandler) GetUploadProgress(c *fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
@@ -425,9 +425,7 @@ func (h *OptimizedUploadHandler) GetUploadProgress(c *fiber.Ctx) error {
 	ctx := c.Context()
 	uploadState, err := h.uploadStateManager.GetUploadState(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Upload not found",
-		})
+		return lookupError(err, "Upload")
 	}
 
 	// Calculate progress percentage
@@ -453,9 +451,9 @@ func (h *OptimizedUploadHandler) GetUploadProgress(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param uploadId path string true "Upload ID"
 // @Success 204 "No Content"
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /files/optimized-upload/{uploadId}/abort [delete]
 func (h *OptimizedUploadHandler) AbortOptimizedUpload(c *fiber.Ctx) error {
 	uploadID := c.Params("uploadId")
@@ -463,16 +461,12 @@ func (h *OptimizedUploadHandler) AbortOptimizedUpload(c *fiber.Ctx) error {
 	ctx := c.Context()
 	uploadState, err := h.uploadStateManager.GetUploadState(ctx, uploadID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Upload not found",
-		})
+		return lookupError(err, "Upload")
 	}
 
 	// Abort the multipart upload in storage
 	if err := h.storageService.AbortMultipartUpload(ctx, uploadState.ObjectKey, uploadID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to abort upload",
-		})
+		return problem.Internal(err, "Failed to abort upload")
 	}
 
 	// Update upload state
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/mergepatch"
+	"terra-allwert/infra/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// mergePatch applies the JSON merge patch (RFC 7396) in the body of the request to the
+// fields of entity clients may set, as held by its update request U, and validates the
+// result like a full update before apply copies it to entity. It returns the fields that
+// changed, named like their JSON members and columns, none when the patch changed
+// nothing.
+func mergePatch[U, E any](c *fiber.Ctx, entity *E, apply func(*U, *E)) ([]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if mediaType != mergepatch.ContentType && mediaType != fiber.MIMEApplicationJSON {
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType, "Send a JSON merge patch as "+mergepatch.ContentType)
+	}
+
+	// The patch applies to the fields as they currently are
+	current, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var req U
+	if err := json.Unmarshal(current, &req); err != nil {
+		return nil, err
+	}
+	before, err := json.Marshal(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergepatch.Apply(before, c.Body())
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid merge patch")
+	}
+	var patched U
+	if err := json.Unmarshal(merged, &patched); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validation.ValidateStruct(&patched); err != nil {
+		return nil, &interfaces.ValidationError{Message: "Validation failed", Err: err}
+	}
+
+	after, err := json.Marshal(&patched)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := mergepatch.Changed(before, after)
+	if err != nil {
+		return nil, err
+	}
+	apply(&patched, entity)
+	return fields, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"terra-allwert/domain/entities"
+
+	"gorm.io/gorm/schema"
+)
+
+// TestMergePatchFieldsAreColumns checks that mergePatch may pass the JSON members of the
+// update requests to UpdateFields as they are: each is named like the column of the
+// entity it sets.
+func TestMergePatchFieldsAreColumns(t *testing.T) {
+	tests := []struct {
+		request any
+		entity  any
+	}{
+		{UpdateEnterpriseRequest{}, entities.Enterprise{}},
+		{UpdateMenuRequest{}, entities.Menu{}},
+		{UpdateTowerRequest{}, entities.Tower{}},
+		{UpdateFloorRequest{}, entities.Floor{}},
+		{UpdateSuiteRequest{}, entities.Suite{}},
+		{UpdateCarouselItemRequest{}, entities.CarouselItem{}},
+		{UpdateTextOverlayRequest{}, entities.CarouselTextOverlay{}},
+		{UpdatePinMarkerRequest{}, entities.PinMarker{}},
+		{UpdateFileRequest{}, entities.File{}},
+	}
+	for _, tt := range tests {
+		request := reflect.TypeOf(tt.request)
+		t.Run(request.Name(), func(t *testing.T) {
+			entity, err := schema.Parse(tt.entity, &sync.Map{}, schema.NamingStrategy{})
+			if err != nil {
+				t.Fatalf("failed to parse the schema of %T: %v", tt.entity, err)
+			}
+			for i := 0; i < request.NumField(); i++ {
+				name, _, _ := strings.Cut(request.Field(i).Tag.Get("json"), ",")
+				if name == "" || name == "-" {
+					t.Fatalf("%s.%s has no JSON name", request.Name(), request.Field(i).Name)
+				}
+				if field := entity.LookUpField(name); field == nil || field.DBName != name {
+					t.Errorf("%s member %q is no column of %s", request.Name(), name, entity.Table)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -28,6 +29,125 @@ func NewPinsHandler(
 	}
 }
 
+// UpdateMenuPinsRequest holds the settings of a pins menu clients may set. The whole
+// settings are replaced: files left out are cleared, the other settings are kept.
+type UpdateMenuPinsRequest struct {
+	BackgroundFileID   *uuid.UUID `json:"background_file_id,omitempty"`
+	PromotionalVideoID *uuid.UUID `json:"promotional_video_id,omitempty"`
+	EnableZoom         *bool      `json:"enable_zoom,omitempty"`
+	EnablePan          *bool      `json:"enable_pan,omitempty"`
+	MinZoom            *float64   `json:"min_zoom,omitempty" validate:"omitempty,gt=0,lt=10"`
+	MaxZoom            *float64   `json:"max_zoom,omitempty" validate:"omitempty,gt=0,lt=10"`
+}
+
+// CreateMenuPinsRequest holds the settings of the pins of a menu
+type CreateMenuPinsRequest struct {
+	MenuID uuid.UUID `json:"menu_id" validate:"required"`
+	UpdateMenuPinsRequest
+}
+
+// UpdatePinMarkerRequest holds the fields of a pin marker clients may set. The whole
+// marker is replaced: optional fields left out are cleared, except the icon, action and
+// visibility which are kept.
+type UpdatePinMarkerRequest struct {
+	Title       string              `json:"title" validate:"required,min=1,max=255"`
+	Description *string             `json:"description,omitempty"`
+	PositionX   float64             `json:"position_x" validate:"min=0,max=100"`
+	PositionY   float64             `json:"position_y" validate:"min=0,max=100"`
+	IconType    *string             `json:"icon_type,omitempty" validate:"omitempty,max=50"`
+	IconColor   *string             `json:"icon_color,omitempty" validate:"omitempty,hexcolor,max=7"`
+	ActionType  *entities.PinAction `json:"action_type,omitempty" validate:"omitempty,oneof=info carousel link modal"`
+	ActionData  entities.ActionData `json:"action_data,omitempty"`
+	IsVisible   *bool               `json:"is_visible,omitempty"`
+}
+
+// CreatePinMarkerRequest holds the fields of a new pin marker
+type CreatePinMarkerRequest struct {
+	MenuPinID uuid.UUID `json:"menu_pin_id" validate:"required"`
+	UpdatePinMarkerRequest
+}
+
+// UpdatePinMarkerImageRequest holds the fields of a pin marker image clients may set.
+// The whole image is replaced: the caption is cleared when left out.
+type UpdatePinMarkerImageRequest struct {
+	FileID   uuid.UUID `json:"file_id" validate:"required"`
+	Position int       `json:"position" validate:"min=0"`
+	Caption  *string   `json:"caption,omitempty" validate:"omitempty,max=500"`
+}
+
+// CreatePinMarkerImageRequest holds the fields of a new pin marker image
+type CreatePinMarkerImageRequest struct {
+	PinMarkerID uuid.UUID `json:"pin_marker_id" validate:"required"`
+	UpdatePinMarkerImageRequest
+}
+
+func (r *CreateMenuPinsRequest) toEntity() *entities.MenuPins {
+	pins := &entities.MenuPins{MenuID: r.MenuID, EnableZoom: true, EnablePan: true, MinZoom: 0.5, MaxZoom: 3.0}
+	r.apply(pins)
+	return pins
+}
+
+func (r *UpdateMenuPinsRequest) apply(pins *entities.MenuPins) {
+	pins.BackgroundFileID = r.BackgroundFileID
+	pins.PromotionalVideoID = r.PromotionalVideoID
+	if r.EnableZoom != nil {
+		pins.EnableZoom = *r.EnableZoom
+	}
+	if r.EnablePan != nil {
+		pins.EnablePan = *r.EnablePan
+	}
+	if r.MinZoom != nil {
+		pins.MinZoom = *r.MinZoom
+	}
+	if r.MaxZoom != nil {
+		pins.MaxZoom = *r.MaxZoom
+	}
+}
+
+func (r *CreatePinMarkerRequest) toEntity() *entities.PinMarker {
+	marker := &entities.PinMarker{
+		MenuPinID:  r.MenuPinID,
+		IconType:   "default",
+		IconColor:  "#FF0000",
+		ActionType: entities.PinActionInfo,
+		IsVisible:  true,
+	}
+	r.apply(marker)
+	return marker
+}
+
+func (r *UpdatePinMarkerRequest) apply(marker *entities.PinMarker) {
+	marker.Title = r.Title
+	marker.Description = r.Description
+	marker.PositionX = r.PositionX
+	marker.PositionY = r.PositionY
+	if r.IconType != nil {
+		marker.IconType = *r.IconType
+	}
+	if r.IconColor != nil {
+		marker.IconColor = *r.IconColor
+	}
+	if r.ActionType != nil {
+		marker.ActionType = *r.ActionType
+	}
+	marker.ActionData = r.ActionData
+	if r.IsVisible != nil {
+		marker.IsVisible = *r.IsVisible
+	}
+}
+
+func (r *CreatePinMarkerImageRequest) toEntity() *entities.PinMarkerImage {
+	image := &entities.PinMarkerImage{PinMarkerID: r.PinMarkerID}
+	r.apply(image)
+	return image
+}
+
+func (r *UpdatePinMarkerImageRequest) apply(image *entities.PinMarkerImage) {
+	image.FileID = r.FileID
+	image.Position = r.Position
+	image.Caption = r.Caption
+}
+
 // ============== MENU PINS ENDPOINTS ==============
 
 // CreateMenuPins creates a new menu pins
@@ -37,25 +157,22 @@ func NewPinsHandler(
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param pins body entities.MenuPins true "Menu Pins data"
+// @Param pins body CreateMenuPinsRequest true "Menu Pins data"
 // @Success 201 {object} entities.MenuPins
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-pins [post]
 func (h *PinsHandler) CreateMenuPins(c *fiber.Ctx) error {
-	var pins entities.MenuPins
-
-	if err := c.BodyParser(&pins); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateMenuPinsRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.pinsRepo.Create(c.Context(), &pins); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create menu pins",
-		})
+	pins := req.toEntity()
+	if err := h.pinsRepo.Create(c.Context(), pins); err != nil {
+		return problem.Internal(err, "Failed to create menu pins")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(pins)
@@ -69,24 +186,20 @@ func (h *PinsHandler) CreateMenuPins(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu Pins ID"
 // @Success 200 {object} entities.MenuPins
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /menu-pins/{id} [get]
 func (h *PinsHandler) GetMenuPinsByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu pins ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu pins ID")
 	}
 
 	pins, err := h.pinsRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Menu pins not found",
-		})
+		return lookupError(err, "Menu pins")
 	}
 
 	return c.JSON(pins)
@@ -100,24 +213,20 @@ func (h *PinsHandler) GetMenuPinsByID(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param menuId path string true "Menu ID"
 // @Success 200 {object} entities.MenuPins
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Router /menus/{menuId}/pins [get]
 func (h *PinsHandler) GetMenuPinsByMenuID(c *fiber.Ctx) error {
 	menuIDParam := c.Params("menuId")
 	menuID, err := uuid.Parse(menuIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu ID")
 	}
 
 	pins, err := h.pinsRepo.GetByMenuID(c.Context(), menuID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Menu pins not found",
-		})
+		return lookupError(err, "Menu pins")
 	}
 
 	return c.JSON(pins)
@@ -131,33 +240,33 @@ func (h *PinsHandler) GetMenuPinsByMenuID(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Menu Pins ID"
-// @Param pins body entities.MenuPins true "Menu Pins data"
+// @Param pins body UpdateMenuPinsRequest true "Menu Pins data"
 // @Success 200 {object} entities.MenuPins
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-pins/{id} [put]
 func (h *PinsHandler) UpdateMenuPins(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu pins ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu pins ID")
 	}
 
-	var pins entities.MenuPins
-	if err := c.BodyParser(&pins); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateMenuPinsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	pins, err := h.pinsRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Menu pins")
 	}
 
-	pins.ID = id
-	if err := h.pinsRepo.Update(c.Context(), &pins); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update menu pins",
-		})
+	req.apply(pins)
+	if err := h.pinsRepo.Update(c.Context(), pins); err != nil {
+		return problem.Internal(err, "Failed to update menu pins")
 	}
 
 	return c.JSON(pins)
@@ -170,23 +279,24 @@ func (h *PinsHandler) UpdateMenuPins(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Menu Pins ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-pins/{id} [delete]
 func (h *PinsHandler) DeleteMenuPins(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu pins ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu pins ID")
+	}
+
+	if _, err := h.pinsRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Menu pins")
 	}
 
 	if err := h.pinsRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete menu pins",
-		})
+		return problem.Internal(err, "Failed to delete menu pins")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -201,25 +311,22 @@ func (h *PinsHandler) DeleteMenuPins(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param marker body entities.PinMarker true "Pin Marker data"
+// @Param marker body CreatePinMarkerRequest true "Pin Marker data"
 // @Success 201 {object} entities.PinMarker
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-markers [post]
 func (h *PinsHandler) CreatePinMarker(c *fiber.Ctx) error {
-	var marker entities.PinMarker
-
-	if err := c.BodyParser(&marker); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreatePinMarkerRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.markerRepo.Create(c.Context(), &marker); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create pin marker",
-		})
+	marker := req.toEntity()
+	if err := h.markerRepo.Create(c.Context(), marker); err != nil {
+		return problem.Internal(err, "Failed to create pin marker")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(marker)
@@ -236,17 +343,15 @@ func (h *PinsHandler) CreatePinMarker(c *fiber.Ctx) error {
 // @Param offset query int false "Offset" default(0)
 // @Param visible_only query boolean false "Only visible markers"
 // @Success 200 {array} entities.PinMarker
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-pins/{menuPinId}/markers [get]
 func (h *PinsHandler) GetPinMarkersByMenuPin(c *fiber.Ctx) error {
 	menuPinIDParam := c.Params("menuPinId")
 	menuPinID, err := uuid.Parse(menuPinIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu pins ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu pins ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -261,9 +366,7 @@ func (h *PinsHandler) GetPinMarkersByMenuPin(c *fiber.Ctx) error {
 	}
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch pin markers",
-		})
+		return problem.Internal(err, "Failed to fetch pin markers")
 	}
 
 	return c.JSON(markers)
@@ -283,45 +386,35 @@ func (h *PinsHandler) GetPinMarkersByMenuPin(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.PinMarker
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-pins/{menuPinId}/markers/search [get]
 func (h *PinsHandler) GetPinMarkersByPosition(c *fiber.Ctx) error {
 	menuPinIDParam := c.Params("menuPinId")
 	menuPinID, err := uuid.Parse(menuPinIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu pins ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu pins ID")
 	}
 
 	minX, err := strconv.ParseFloat(c.Query("min_x"), 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid min_x parameter",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid min_x parameter")
 	}
 
 	maxX, err := strconv.ParseFloat(c.Query("max_x"), 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid max_x parameter",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid max_x parameter")
 	}
 
 	minY, err := strconv.ParseFloat(c.Query("min_y"), 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid min_y parameter",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid min_y parameter")
 	}
 
 	maxY, err := strconv.ParseFloat(c.Query("max_y"), 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid max_y parameter",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid max_y parameter")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -329,9 +422,7 @@ func (h *PinsHandler) GetPinMarkersByPosition(c *fiber.Ctx) error {
 
 	markers, err := h.markerRepo.GetByPosition(c.Context(), menuPinID, minX, maxX, minY, maxY, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch pin markers",
-		})
+		return problem.Internal(err, "Failed to fetch pin markers")
 	}
 
 	return c.JSON(markers)
@@ -345,33 +436,74 @@ func (h *PinsHandler) GetPinMarkersByPosition(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Pin Marker ID"
-// @Param marker body entities.PinMarker true "Pin Marker data"
+// @Param marker body UpdatePinMarkerRequest true "Pin Marker data"
 // @Success 200 {object} entities.PinMarker
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-markers/{id} [put]
 func (h *PinsHandler) UpdatePinMarker(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker ID")
+	}
+
+	req, err := Bind[UpdatePinMarkerRequest](c)
+	if err != nil {
+		return err
 	}
 
-	var marker entities.PinMarker
-	if err := c.BodyParser(&marker); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	marker, err := h.markerRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Pin marker")
 	}
 
-	marker.ID = id
-	if err := h.markerRepo.Update(c.Context(), &marker); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update pin marker",
-		})
+	req.apply(marker)
+	if err := h.markerRepo.Update(c.Context(), marker); err != nil {
+		return problem.Internal(err, "Failed to update pin marker")
+	}
+
+	return c.JSON(marker)
+}
+
+// PatchPinMarker partially updates an existing pin marker
+// @Summary Patch pin marker
+// @Description Apply a JSON merge patch (RFC 7396) to an existing pin marker. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags pin-markers
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Pin Marker ID"
+// @Param marker body UpdatePinMarkerRequest true "Pin Marker fields to change"
+// @Success 200 {object} entities.PinMarker
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /pin-markers/{id} [patch]
+func (h *PinsHandler) PatchPinMarker(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker ID")
+	}
+
+	marker, err := h.markerRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Pin marker")
+	}
+
+	fields, err := mergePatch(c, marker, (*UpdatePinMarkerRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.markerRepo.UpdateFields(c.Context(), marker, fields); err != nil {
+			return problem.Internal(err, "Failed to update pin marker")
+		}
 	}
 
 	return c.JSON(marker)
@@ -384,23 +516,24 @@ func (h *PinsHandler) UpdatePinMarker(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Pin Marker ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-markers/{id} [delete]
 func (h *PinsHandler) DeletePinMarker(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker ID")
+	}
+
+	if _, err := h.markerRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Pin marker")
 	}
 
 	if err := h.markerRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete pin marker",
-		})
+		return problem.Internal(err, "Failed to delete pin marker")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -415,25 +548,22 @@ func (h *PinsHandler) DeletePinMarker(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param image body entities.PinMarkerImage true "Pin Marker Image data"
+// @Param image body CreatePinMarkerImageRequest true "Pin Marker Image data"
 // @Success 201 {object} entities.PinMarkerImage
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-marker-images [post]
 func (h *PinsHandler) CreatePinMarkerImage(c *fiber.Ctx) error {
-	var image entities.PinMarkerImage
-
-	if err := c.BodyParser(&image); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreatePinMarkerImageRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.markerImageRepo.Create(c.Context(), &image); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create pin marker image",
-		})
+	image := req.toEntity()
+	if err := h.markerImageRepo.Create(c.Context(), image); err != nil {
+		return problem.Internal(err, "Failed to create pin marker image")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(image)
@@ -449,17 +579,15 @@ func (h *PinsHandler) CreatePinMarkerImage(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.PinMarkerImage
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-markers/{markerId}/images [get]
 func (h *PinsHandler) GetPinMarkerImagesByMarker(c *fiber.Ctx) error {
 	markerIDParam := c.Params("markerId")
 	markerID, err := uuid.Parse(markerIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -467,9 +595,7 @@ func (h *PinsHandler) GetPinMarkerImagesByMarker(c *fiber.Ctx) error {
 
 	images, err := h.markerImageRepo.GetByPinMarkerID(c.Context(), markerID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch pin marker images",
-		})
+		return problem.Internal(err, "Failed to fetch pin marker images")
 	}
 
 	return c.JSON(images)
@@ -483,33 +609,33 @@ func (h *PinsHandler) GetPinMarkerImagesByMarker(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Pin Marker Image ID"
-// @Param image body entities.PinMarkerImage true "Pin Marker Image data"
+// @Param image body UpdatePinMarkerImageRequest true "Pin Marker Image data"
 // @Success 200 {object} entities.PinMarkerImage
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-marker-images/{id} [put]
 func (h *PinsHandler) UpdatePinMarkerImage(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker image ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker image ID")
 	}
 
-	var image entities.PinMarkerImage
-	if err := c.BodyParser(&image); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdatePinMarkerImageRequest](c)
+	if err != nil {
+		return err
 	}
 
-	image.ID = id
-	if err := h.markerImageRepo.Update(c.Context(), &image); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update pin marker image",
-		})
+	image, err := h.markerImageRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Pin marker image")
+	}
+
+	req.apply(image)
+	if err := h.markerImageRepo.Update(c.Context(), image); err != nil {
+		return problem.Internal(err, "Failed to update pin marker image")
 	}
 
 	return c.JSON(image)
@@ -523,35 +649,31 @@ func (h *PinsHandler) UpdatePinMarkerImage(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Pin Marker Image ID"
-// @Param position body object{position=int} true "Position data"
+// @Param position body UpdatePositionRequest true "Position data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-marker-images/{id}/position [patch]
 func (h *PinsHandler) UpdatePinMarkerImagePosition(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker image ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker image ID")
 	}
 
-	var body struct {
-		Position int `json:"position"`
+	req, err := Bind[UpdatePositionRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if _, err := h.markerImageRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Pin marker image")
 	}
 
-	if err := h.markerImageRepo.UpdatePosition(c.Context(), id, body.Position); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update pin marker image position",
-		})
+	if err := h.markerImageRepo.UpdatePosition(c.Context(), id, req.Position); err != nil {
+		return problem.Internal(err, "Failed to update pin marker image position")
 	}
 
 	return c.JSON(fiber.Map{
@@ -566,23 +688,24 @@ func (h *PinsHandler) UpdatePinMarkerImagePosition(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Pin Marker Image ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /pin-marker-images/{id} [delete]
 func (h *PinsHandler) DeletePinMarkerImage(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pin marker image ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid pin marker image ID")
+	}
+
+	if _, err := h.markerImageRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Pin marker image")
 	}
 
 	if err := h.markerImageRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete pin marker image",
-		})
+		return problem.Internal(err, "Failed to delete pin marker image")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
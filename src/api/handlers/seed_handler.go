@@ -3,6 +3,7 @@ package handlers
 import (
 	"terra-allwert/infra/config"
 	"terra-allwert/infra/database/seeds"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/driver/postgres"
@@ -25,11 +26,6 @@ type SeedResponse struct {
 	Message string `json:"message" example:"Seeds executed successfully"`
 }
 
-type ErrorResponse struct {
-	Error   string `json:"error" example:"Internal server error"`
-	Message string `json:"message" example:"Detailed error message"`
-}
-
 // RunSeeds godoc
 // @Summary Run Database Seeds
 // @Description Execute database seeds to populate initial data (enterprises and users)
@@ -38,8 +34,8 @@ type ErrorResponse struct {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} SeedResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /seeds/run [post]
 func (h *SeedHandler) RunSeeds(c *fiber.Ctx) error {
 	// Build database connection string
@@ -56,19 +52,13 @@ func (h *SeedHandler) RunSeeds(c *fiber.Ctx) error {
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Database connection failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "Database connection failed")
 	}
 
 	// Create seeder and run seeds
 	seeder := seeds.NewSeeder(db)
 	if err := seeder.SeedAll(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Seeding failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "Seeding failed")
 	}
 
 	return c.JSON(SeedResponse{
@@ -85,8 +75,8 @@ func (h *SeedHandler) RunSeeds(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} SeedResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /seeds/enterprises [post]
 func (h *SeedHandler) RunEnterpriseSeeds(c *fiber.Ctx) error {
 	// Build database connection string
@@ -103,19 +93,13 @@ func (h *SeedHandler) RunEnterpriseSeeds(c *fiber.Ctx) error {
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Database connection failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "Database connection failed")
 	}
 
 	// Create seeder and run enterprise seeds only
 	seeder := seeds.NewSeeder(db)
 	if err := seeder.SeedEnterprises(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Enterprise seeding failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "Enterprise seeding failed")
 	}
 
 	return c.JSON(SeedResponse{
@@ -132,8 +116,8 @@ func (h *SeedHandler) RunEnterpriseSeeds(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} SeedResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /seeds/users [post]
 func (h *SeedHandler) RunUserSeeds(c *fiber.Ctx) error {
 	// Build database connection string
@@ -150,19 +134,13 @@ func (h *SeedHandler) RunUserSeeds(c *fiber.Ctx) error {
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "Database connection failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "Database connection failed")
 	}
 
 	// Create seeder and run user seeds only
 	seeder := seeds.NewSeeder(db)
 	if err := seeder.SeedUsers(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error:   "User seeding failed",
-			Message: err.Error(),
-		})
+		return problem.Internal(err, "User seeding failed")
 	}
 
 	return c.JSON(SeedResponse{
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,6 +22,65 @@ func NewSuiteHandler(suiteRepo interfaces.SuiteRepository) *SuiteHandler {
 	}
 }
 
+// UpdateSuiteRequest holds the fields of a suite clients may set. The whole suite is
+// replaced: optional fields left out are cleared, except the status which is kept.
+type UpdateSuiteRequest struct {
+	UnitNumber      string                `json:"unit_number" validate:"required,max=20"`
+	Title           string                `json:"title" validate:"required,min=1,max=255"`
+	Description     *string               `json:"description,omitempty"`
+	PositionX       *float64              `json:"position_x,omitempty"`
+	PositionY       *float64              `json:"position_y,omitempty"`
+	AreaSqm         float64               `json:"area_sqm" validate:"required,min=1"`
+	Bedrooms        int                   `json:"bedrooms" validate:"min=0"`
+	SuitesCount     int                   `json:"suites_count" validate:"min=0"`
+	Bathrooms       int                   `json:"bathrooms" validate:"min=0"`
+	ParkingSpaces   *int                  `json:"parking_spaces,omitempty" validate:"omitempty,min=0"`
+	SunPosition     *entities.SunPosition `json:"sun_position,omitempty" validate:"omitempty,sun_position"`
+	Status          entities.SuiteStatus  `json:"status,omitempty" validate:"omitempty,suite_status"`
+	FloorPlanFileID *uuid.UUID            `json:"floor_plan_file_id,omitempty"`
+	Price           *float64              `json:"price,omitempty" validate:"omitempty,min=0"`
+}
+
+// CreateSuiteRequest holds the fields of a new suite
+type CreateSuiteRequest struct {
+	FloorID uuid.UUID `json:"floor_id" validate:"required"`
+	UpdateSuiteRequest
+}
+
+// UpdateSuiteStatusRequest changes the availability of a suite
+type UpdateSuiteStatusRequest struct {
+	Status entities.SuiteStatus `json:"status" validate:"required,suite_status"`
+}
+
+func (r *CreateSuiteRequest) toEntity() *entities.Suite {
+	suite := &entities.Suite{FloorID: r.FloorID, Status: entities.SuiteStatusAvailable}
+	r.apply(suite)
+	return suite
+}
+
+func (r *UpdateSuiteRequest) apply(suite *entities.Suite) {
+	suite.UnitNumber = r.UnitNumber
+	suite.Title = r.Title
+	suite.Description = r.Description
+	suite.PositionX = r.PositionX
+	suite.PositionY = r.PositionY
+	suite.AreaSqm = r.AreaSqm
+	suite.Bedrooms = r.Bedrooms
+	suite.SuitesCount = r.SuitesCount
+	suite.Bathrooms = r.Bathrooms
+	suite.ParkingSpaces = r.ParkingSpaces
+	suite.SunPosition = nil
+	if r.SunPosition != nil {
+		position := entities.SunPosition(strings.ToUpper(string(*r.SunPosition)))
+		suite.SunPosition = &position
+	}
+	if r.Status != "" {
+		suite.Status = entities.SuiteStatus(strings.ToLower(string(r.Status)))
+	}
+	suite.FloorPlanFileID = r.FloorPlanFileID
+	suite.Price = r.Price
+}
+
 // CreateSuite creates a new suite
 // @Summary Create a new suite
 // @Description Create a new suite with the provided data
@@ -27,25 +88,22 @@ func NewSuiteHandler(suiteRepo interfaces.SuiteRepository) *SuiteHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param suite body entities.Suite true "Suite data"
+// @Param suite body CreateSuiteRequest true "Suite data"
 // @Success 201 {object} entities.Suite
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites [post]
 func (h *SuiteHandler) CreateSuite(c *fiber.Ctx) error {
-	var suite entities.Suite
-
-	if err := c.BodyParser(&suite); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateSuiteRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.suiteRepo.Create(c.Context(), &suite); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create suite",
-		})
+	suite := req.toEntity()
+	if err := h.suiteRepo.Create(c.Context(), suite); err != nil {
+		return problem.Internal(err, "Failed to create suite")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(suite)
@@ -59,25 +117,21 @@ func (h *SuiteHandler) CreateSuite(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Suite ID"
 // @Success 200 {object} entities.Suite
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites/{id} [get]
 func (h *SuiteHandler) GetSuiteByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid suite ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suite ID")
 	}
 
 	suite, err := h.suiteRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Suite not found",
-		})
+		return lookupError(err, "Suite")
 	}
 
 	return c.JSON(suite)
@@ -92,8 +146,8 @@ func (h *SuiteHandler) GetSuiteByID(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Suite
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites [get]
 func (h *SuiteHandler) GetSuites(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -101,9 +155,7 @@ func (h *SuiteHandler) GetSuites(c *fiber.Ctx) error {
 
 	suites, err := h.suiteRepo.GetAll(c.Context(), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch suites",
-		})
+		return problem.Internal(err, "Failed to fetch suites")
 	}
 
 	return c.JSON(suites)
@@ -119,17 +171,15 @@ func (h *SuiteHandler) GetSuites(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Suite
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /floors/{floorId}/suites [get]
 func (h *SuiteHandler) GetSuitesByFloor(c *fiber.Ctx) error {
 	floorIDParam := c.Params("floorId")
 	floorID, err := uuid.Parse(floorIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid floor ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid floor ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -137,9 +187,7 @@ func (h *SuiteHandler) GetSuitesByFloor(c *fiber.Ctx) error {
 
 	suites, err := h.suiteRepo.GetByFloorID(c.Context(), floorID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch suites",
-		})
+		return problem.Internal(err, "Failed to fetch suites")
 	}
 
 	return c.JSON(suites)
@@ -161,8 +209,8 @@ func (h *SuiteHandler) GetSuitesByFloor(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Suite
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites/search [get]
 func (h *SuiteHandler) SearchSuites(c *fiber.Ctx) error {
 	filters := interfaces.SuiteSearchFilters{}
@@ -213,9 +261,7 @@ func (h *SuiteHandler) SearchSuites(c *fiber.Ctx) error {
 
 	suites, err := h.suiteRepo.Search(c.Context(), filters, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search suites",
-		})
+		return problem.Internal(err, "Failed to search suites")
 	}
 
 	return c.JSON(suites)
@@ -229,34 +275,74 @@ func (h *SuiteHandler) SearchSuites(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Suite ID"
-// @Param suite body entities.Suite true "Suite data"
+// @Param suite body UpdateSuiteRequest true "Suite data"
 // @Success 200 {object} entities.Suite
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites/{id} [put]
 func (h *SuiteHandler) UpdateSuite(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid suite ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suite ID")
+	}
+
+	req, err := Bind[UpdateSuiteRequest](c)
+	if err != nil {
+		return err
+	}
+
+	suite, err := h.suiteRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Suite")
+	}
+
+	req.apply(suite)
+	if err := h.suiteRepo.Update(c.Context(), suite); err != nil {
+		return problem.Internal(err, "Failed to update suite")
+	}
+
+	return c.JSON(suite)
+}
+
+// PatchSuite partially updates an existing suite
+// @Summary Patch suite
+// @Description Apply a JSON merge patch (RFC 7396) to an existing suite. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags suites
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Suite ID"
+// @Param suite body UpdateSuiteRequest true "Suite fields to change"
+// @Success 200 {object} entities.Suite
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /suites/{id} [patch]
+func (h *SuiteHandler) PatchSuite(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suite ID")
 	}
 
-	var suite entities.Suite
-	if err := c.BodyParser(&suite); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	suite, err := h.suiteRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Suite")
 	}
 
-	suite.ID = id
-	if err := h.suiteRepo.Update(c.Context(), &suite); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update suite",
-		})
+	fields, err := mergePatch(c, suite, (*UpdateSuiteRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.suiteRepo.UpdateFields(c.Context(), suite, fields); err != nil {
+			return problem.Internal(err, "Failed to update suite")
+		}
 	}
 
 	return c.JSON(suite)
@@ -270,36 +356,31 @@ func (h *SuiteHandler) UpdateSuite(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Suite ID"
-// @Param status body object{status=string} true "Status data"
+// @Param status body UpdateSuiteStatusRequest true "Status data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites/{id}/status [patch]
 func (h *SuiteHandler) UpdateSuiteStatus(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid suite ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suite ID")
 	}
 
-	var body struct {
-		Status entities.SuiteStatus `json:"status"`
+	req, err := Bind[UpdateSuiteStatusRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if _, err := h.suiteRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Suite")
 	}
 
-	if err := h.suiteRepo.UpdateStatus(c.Context(), id, body.Status); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update suite status",
-		})
+	if err := h.suiteRepo.UpdateStatus(c.Context(), id, entities.SuiteStatus(strings.ToLower(string(req.Status)))); err != nil {
+		return problem.Internal(err, "Failed to update suite status")
 	}
 
 	return c.JSON(fiber.Map{
@@ -314,24 +395,24 @@ func (h *SuiteHandler) UpdateSuiteStatus(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Suite ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /suites/{id} [delete]
 func (h *SuiteHandler) DeleteSuite(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid suite ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid suite ID")
+	}
+
+	if _, err := h.suiteRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Suite")
 	}
 
 	if err := h.suiteRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete suite",
-		})
+		return problem.Internal(err, "Failed to delete suite")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -5,6 +5,7 @@ import (
 
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/problem"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -20,6 +21,38 @@ func NewTowerHandler(towerRepo interfaces.TowerRepository) *TowerHandler {
 	}
 }
 
+// UpdateTowerRequest holds the fields of a tower clients may set. The whole tower is
+// replaced: optional fields left out are cleared.
+type UpdateTowerRequest struct {
+	Title         string  `json:"title" validate:"required,min=1,max=255"`
+	Description   *string `json:"description,omitempty"`
+	BuildingCode  *string `json:"building_code,omitempty" validate:"omitempty,max=50"`
+	TotalFloors   *int    `json:"total_floors,omitempty" validate:"omitempty,min=1"`
+	UnitsPerFloor *int    `json:"units_per_floor,omitempty" validate:"omitempty,min=1"`
+	Position      int     `json:"position" validate:"min=0"`
+}
+
+// CreateTowerRequest holds the fields of a new tower
+type CreateTowerRequest struct {
+	MenuFloorPlanID uuid.UUID `json:"menu_floor_plan_id" validate:"required"`
+	UpdateTowerRequest
+}
+
+func (r *CreateTowerRequest) toEntity() *entities.Tower {
+	tower := &entities.Tower{MenuFloorPlanID: r.MenuFloorPlanID}
+	r.apply(tower)
+	return tower
+}
+
+func (r *UpdateTowerRequest) apply(tower *entities.Tower) {
+	tower.Title = r.Title
+	tower.Description = r.Description
+	tower.BuildingCode = r.BuildingCode
+	tower.TotalFloors = r.TotalFloors
+	tower.UnitsPerFloor = r.UnitsPerFloor
+	tower.Position = r.Position
+}
+
 // CreateTower creates a new tower
 // @Summary Create a new tower
 // @Description Create a new tower with the provided data
@@ -27,25 +60,22 @@ func NewTowerHandler(towerRepo interfaces.TowerRepository) *TowerHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param tower body entities.Tower true "Tower data"
+// @Param tower body CreateTowerRequest true "Tower data"
 // @Success 201 {object} entities.Tower
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers [post]
 func (h *TowerHandler) CreateTower(c *fiber.Ctx) error {
-	var tower entities.Tower
-
-	if err := c.BodyParser(&tower); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[CreateTowerRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := h.towerRepo.Create(c.Context(), &tower); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create tower",
-		})
+	tower := req.toEntity()
+	if err := h.towerRepo.Create(c.Context(), tower); err != nil {
+		return problem.Internal(err, "Failed to create tower")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(tower)
@@ -59,25 +89,21 @@ func (h *TowerHandler) CreateTower(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Tower ID"
 // @Success 200 {object} entities.Tower
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{id} [get]
 func (h *TowerHandler) GetTowerByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
 	}
 
 	tower, err := h.towerRepo.GetByID(c.Context(), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Tower not found",
-		})
+		return lookupError(err, "Tower")
 	}
 
 	return c.JSON(tower)
@@ -92,8 +118,8 @@ func (h *TowerHandler) GetTowerByID(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Tower
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers [get]
 func (h *TowerHandler) GetTowers(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -101,9 +127,7 @@ func (h *TowerHandler) GetTowers(c *fiber.Ctx) error {
 
 	towers, err := h.towerRepo.GetAll(c.Context(), limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch towers",
-		})
+		return problem.Internal(err, "Failed to fetch towers")
 	}
 
 	return c.JSON(towers)
@@ -119,17 +143,15 @@ func (h *TowerHandler) GetTowers(c *fiber.Ctx) error {
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} entities.Tower
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /menu-floor-plans/{menuFloorPlanId}/towers [get]
 func (h *TowerHandler) GetTowersByMenuFloorPlan(c *fiber.Ctx) error {
 	menuFloorPlanIDParam := c.Params("menuFloorPlanId")
 	menuFloorPlanID, err := uuid.Parse(menuFloorPlanIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid menu floor plan ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid menu floor plan ID")
 	}
 
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -137,9 +159,7 @@ func (h *TowerHandler) GetTowersByMenuFloorPlan(c *fiber.Ctx) error {
 
 	towers, err := h.towerRepo.GetByMenuFloorPlanID(c.Context(), menuFloorPlanID, limit, offset)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch towers",
-		})
+		return problem.Internal(err, "Failed to fetch towers")
 	}
 
 	return c.JSON(towers)
@@ -153,34 +173,74 @@ func (h *TowerHandler) GetTowersByMenuFloorPlan(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Tower ID"
-// @Param tower body entities.Tower true "Tower data"
+// @Param tower body UpdateTowerRequest true "Tower data"
 // @Success 200 {object} entities.Tower
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{id} [put]
 func (h *TowerHandler) UpdateTower(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
 	}
 
-	var tower entities.Tower
-	if err := c.BodyParser(&tower); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	req, err := Bind[UpdateTowerRequest](c)
+	if err != nil {
+		return err
+	}
+
+	tower, err := h.towerRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Tower")
 	}
 
-	tower.ID = id
-	if err := h.towerRepo.Update(c.Context(), &tower); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update tower",
-		})
+	req.apply(tower)
+	if err := h.towerRepo.Update(c.Context(), tower); err != nil {
+		return problem.Internal(err, "Failed to update tower")
+	}
+
+	return c.JSON(tower)
+}
+
+// PatchTower partially updates an existing tower
+// @Summary Patch tower
+// @Description Apply a JSON merge patch (RFC 7396) to an existing tower. Members set to null are cleared, omitted members are left untouched and only the changed columns are written.
+// @Tags towers
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tower ID"
+// @Param tower body UpdateTowerRequest true "Tower fields to change"
+// @Success 200 {object} entities.Tower
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 415 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /towers/{id} [patch]
+func (h *TowerHandler) PatchTower(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
+	}
+
+	tower, err := h.towerRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "Tower")
+	}
+
+	fields, err := mergePatch(c, tower, (*UpdateTowerRequest).apply)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if err := h.towerRepo.UpdateFields(c.Context(), tower, fields); err != nil {
+			return problem.Internal(err, "Failed to update tower")
+		}
 	}
 
 	return c.JSON(tower)
@@ -194,35 +254,31 @@ func (h *TowerHandler) UpdateTower(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Tower ID"
-// @Param position body object{position=int} true "Position data"
+// @Param position body UpdatePositionRequest true "Position data"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{id}/position [patch]
 func (h *TowerHandler) UpdateTowerPosition(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
 	}
 
-	var body struct {
-		Position int `json:"position"`
+	req, err := Bind[UpdatePositionRequest](c)
+	if err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if _, err := h.towerRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Tower")
 	}
 
-	if err := h.towerRepo.UpdatePosition(c.Context(), id, body.Position); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update tower position",
-		})
+	if err := h.towerRepo.UpdatePosition(c.Context(), id, req.Position); err != nil {
+		return problem.Internal(err, "Failed to update tower position")
 	}
 
 	return c.JSON(fiber.Map{
@@ -237,24 +293,24 @@ func (h *TowerHandler) UpdateTowerPosition(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path string true "Tower ID"
 // @Success 204 "No Content"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /towers/{id} [delete]
 func (h *TowerHandler) DeleteTower(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid tower ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tower ID")
+	}
+
+	if _, err := h.towerRepo.GetByID(c.Context(), id); err != nil {
+		return lookupError(err, "Tower")
 	}
 
 	if err := h.towerRepo.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete tower",
-		})
+		return problem.Internal(err, "Failed to delete tower")
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
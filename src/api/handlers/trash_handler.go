@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TrashHandler struct {
+	trashRepo      interfaces.TrashRepository
+	storageService interfaces.StorageService
+	retention      time.Duration
+}
+
+func NewTrashHandler(trashRepo interfaces.TrashRepository, storageService interfaces.StorageService, retentionDays int) *TrashHandler {
+	return &TrashHandler{
+		trashRepo:      trashRepo,
+		storageService: storageService,
+		retention:      time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// GetTrash lists the deleted items of the caller's enterprise
+// @Summary List trash
+// @Description List the soft deleted items of the enterprise, most recently deleted first. Children deleted together with their parent are listed through the parent.
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string false "Entity type (table name, e.g. menus)"
+// @Param limit query int false "Limit, at most 100" default(50) minimum(1) maximum(100)
+// @Param offset query int false "Offset" default(0) minimum(0)
+// @Success 200 {array} interfaces.TrashItem
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *fiber.Ctx) error {
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	limit, offset, err := pagination(c, 50)
+	if err != nil {
+		return err
+	}
+
+	filters := interfaces.TrashListFilters{EntityType: c.Query("entity_type")}
+	allowed, err := canManageUsers(c)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		if filters.EntityType == usersEntityType {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+		filters.ExcludedTypes = []string{usersEntityType}
+	}
+
+	items, err := h.trashRepo.List(c.Context(), enterpriseID, filters, limit, offset)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrUnknownEntityType) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid entity_type")
+		}
+		return problem.Internal(err, "Failed to list trash")
+	}
+
+	return c.JSON(items)
+}
+
+// RestoreItem restores a deleted item
+// @Summary Restore item from trash
+// @Description Undelete an item of the enterprise together with the children deleted with it
+// @Tags trash
+// @Security BearerAuth
+// @Param entity_type path string true "Entity type (table name, e.g. towers)"
+// @Param id path string true "Item ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "Parent is still deleted"
+// @Failure 500 {object} problem.Problem
+// @Router /trash/{entity_type}/{id}/restore [post]
+func (h *TrashHandler) RestoreItem(c *fiber.Ctx) error {
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid item ID")
+	}
+
+	if c.Params("entity_type") == usersEntityType {
+		allowed, err := canManageUsers(c)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+	}
+
+	if err := h.trashRepo.Restore(c.Context(), enterpriseID, c.Params("entity_type"), id); err != nil {
+		switch {
+		case errors.Is(err, interfaces.ErrUnknownEntityType):
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid entity_type")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Item not found in trash")
+		case errors.Is(err, interfaces.ErrParentDeleted):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return problem.Internal(err, "Failed to restore item")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PurgeTrash permanently removes expired trash
+// @Summary Purge trash
+// @Description Permanently delete the enterprise items deleted longer ago than the retention window (TRASH_RETENTION_DAYS), removing the storage objects of purged files
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} interfaces.PurgeResult
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /trash/purge [post]
+func (h *TrashHandler) PurgeTrash(c *fiber.Ctx) error {
+	enterpriseID, err := middleware.GetEnterpriseFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.trashRepo.Purge(c.Context(), enterpriseID, time.Now().Add(-h.retention))
+	if err != nil {
+		return problem.Internal(err, "Failed to purge trash")
+	}
+
+	// Storage objects go only once their rows are gone for good
+	for _, path := range result.StoragePaths {
+		if err := h.storageService.DeleteFile(c.Context(), path); err != nil {
+			// Log error but don't fail the request
+			slog.WarnContext(c.Context(), "Failed to delete file from storage", "path", path, "error", err)
+		}
+	}
+
+	return c.JSON(result)
+}
+
+// usersEntityType is the trash entity type of deleted users, which only the roles
+// managing users may see and restore
+const usersEntityType = "users"
+
+// canManageUsers reports whether the caller may update users
+func canManageUsers(c *fiber.Ctx) (bool, error) {
+	role, err := middleware.GetUserRoleFromContext(c)
+	if err != nil {
+		return false, err
+	}
+	return auth.Can(role, auth.ResourceUsers, auth.ActionUpdate), nil
+}
+
+// maxPageSize caps the limit clients may ask of the trash
+const maxPageSize = 100
+
+// pagination parses the limit and offset query parameters, limit defaulting to
+// defaultLimit and capped at maxPageSize. Values that are not non-negative integers, or a
+// zero limit, fail with a 400.
+func pagination(c *fiber.Ctx, defaultLimit int) (limit, offset int, err error) {
+	limit, err = strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid limit, expected a positive integer")
+	}
+	offset, err = strconv.Atoi(c.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid offset, expected a non-negative integer")
+	}
+	return min(limit, maxPageSize), offset, nil
+}
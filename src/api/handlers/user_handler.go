@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/audit"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UserHandler manages the users of the enterprise
+type UserHandler struct {
+	userRepo     interfaces.UserRepository
+	auditLogRepo interfaces.AuditLogRepository
+	lockout      *auth.LoginLockout
+}
+
+func NewUserHandler(userRepo interfaces.UserRepository, auditLogRepo interfaces.AuditLogRepository, lockout *auth.LoginLockout) *UserHandler {
+	return &UserHandler{
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+		lockout:      lockout,
+	}
+}
+
+// UpdateUserRoleRequest represents a role change
+type UpdateUserRoleRequest struct {
+	Role entities.UserRole `json:"role" validate:"required,oneof=visitor manager admin"`
+}
+
+func newUserResponse(user *entities.User) *UserResponse {
+	return &UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            user.Role,
+		EnterpriseID:    user.EnterpriseID,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+// GetUsers lists the users of the enterprise
+// @Summary List users
+// @Description List the users of the caller's enterprise
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} UserResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /users [get]
+func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	users, err := h.userRepo.GetAll(c.Context(), limit, offset)
+	if err != nil {
+		return problem.Internal(err, "Failed to fetch users")
+	}
+
+	responses := make([]*UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, newUserResponse(user))
+	}
+
+	return c.JSON(responses)
+}
+
+// GetUserByID gets a user by ID
+// @Summary Get user by ID
+// @Description Get a user of the caller's enterprise
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "User")
+	}
+
+	return c.JSON(newUserResponse(user))
+}
+
+// UpdateUserRole changes the role of a user
+// @Summary Update user role
+// @Description Grant a user of the caller's enterprise the visitor, manager or admin role. Users cannot change their own role, and super admins are left alone.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role body UpdateUserRoleRequest true "New role"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /users/{id}/role [patch]
+func (h *UserHandler) UpdateUserRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	req, err := Bind[UpdateUserRoleRequest](c)
+	if err != nil {
+		return err
+	}
+
+	callerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	callerRole, err := middleware.GetUserRoleFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	switch req.Role {
+	case entities.UserRoleVisitor, entities.UserRoleManager, entities.UserRoleAdmin:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid role")
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "User")
+	}
+
+	// Changing your own role could leave the enterprise without an admin
+	if user.ID == callerID {
+		return fiber.NewError(fiber.StatusForbidden, "Users cannot change their own role")
+	}
+	if user.Role == entities.UserRoleSuperAdmin || !auth.CanGrant(callerRole, req.Role) {
+		return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+	}
+
+	user.Role = req.Role
+	if err := h.userRepo.Update(c.Context(), user); err != nil {
+		return problem.Internal(err, "Failed to update user")
+	}
+
+	return c.JSON(newUserResponse(user))
+}
+
+// DeleteUser deletes a user
+// @Summary Delete user
+// @Description Delete a user of the caller's enterprise. Users cannot delete themselves.
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem "Blocked by dependent records"
+// @Failure 500 {object} problem.Problem
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	callerID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "User")
+	}
+
+	if user.ID == callerID {
+		return fiber.NewError(fiber.StatusForbidden, "Users cannot delete themselves")
+	}
+	if user.Role == entities.UserRoleSuperAdmin {
+		if role, _ := middleware.GetUserRoleFromContext(c); role != entities.UserRoleSuperAdmin {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+	}
+
+	if err := h.userRepo.Delete(c.Context(), id); err != nil {
+		return problem.Internal(err, "Failed to delete user")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnlockUser lifts the login lockout of a user
+// @Summary Unlock user
+// @Description Clear the failed logins of a user of the caller's enterprise, ending a lockout or backoff. Lockouts of client addresses are not affected.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return lookupError(err, "User")
+	}
+
+	lockedUntil, err := h.lockout.AccountLockedUntil(c.Context(), user.Email)
+	if err != nil {
+		return problem.Internal(err, "Failed to unlock user")
+	}
+	if err := h.lockout.Reset(c.Context(), user.Email); err != nil {
+		return problem.Internal(err, "Failed to unlock user")
+	}
+
+	wasLocked := lockedUntil.After(time.Now())
+	if wasLocked && h.auditLogRepo != nil {
+		entry := audit.NewEntry(c.Context(), entities.AuditActionUnlock, "users", user.ID, nil, nil)
+		if err := h.auditLogRepo.Create(c.Context(), entry); err != nil {
+			return problem.Internal(err, "Failed to record unlock")
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "User unlocked",
+		"was_locked": wasLocked,
+	})
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+)
+
+func SetupAPIKeyRoutes(app *fiber.App, handler *handlers.APIKeyHandler, authMiddleware *middleware.AuthMiddleware) {
+	api := app.Group("/api/v1")
+	apiKeys := api.Group("/api-keys", authMiddleware.RequireAuth())
+
+	// API key management routes (admin only)
+	apiKeys.Post("/", authMiddleware.Authorize(auth.ResourceAPIKeys, auth.ActionCreate), handler.CreateAPIKey)
+	apiKeys.Get("/", authMiddleware.Authorize(auth.ResourceAPIKeys, auth.ActionRead), handler.GetAPIKeys)
+	apiKeys.Get("/:id", authMiddleware.Authorize(auth.ResourceAPIKeys, auth.ActionRead), handler.GetAPIKeyByID)
+	apiKeys.Delete("/:id", authMiddleware.Authorize(auth.ResourceAPIKeys, auth.ActionDelete), handler.RevokeAPIKey)
+}
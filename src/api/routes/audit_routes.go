@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+)
+
+func SetupAuditRoutes(app *fiber.App, handler *handlers.AuditHandler, authMiddleware *middleware.AuthMiddleware) {
+	api := app.Group("/api/v1")
+	auditLogs := api.Group("/audit-logs", authMiddleware.RequireAuth())
+
+	// Audit log routes (admin only)
+	auditLogs.Get("/", authMiddleware.Authorize(auth.ResourceAuditLogs, auth.ActionRead), handler.GetAuditLogs)
+	auditLogs.Get("/:id", authMiddleware.Authorize(auth.ResourceAuditLogs, auth.ActionRead), handler.GetAuditLogByID)
+}
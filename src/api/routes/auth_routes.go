@@ -12,26 +12,50 @@ import (
 func SetupAuthRoutes(
 	router fiber.Router,
 	userRepo interfaces.UserRepository,
+	auditLogRepo interfaces.AuditLogRepository,
+	sessionRepo interfaces.SessionRepository,
 	jwtService *auth.JWTService,
+	emails *handlers.AuthEmails,
+	mfa *handlers.AuthMFA,
+	lockout *auth.LoginLockout,
+	oidc *handlers.AuthOIDC,
 	authMiddleware *middleware.AuthMiddleware,
 ) {
 	// Create auth handler
-	authHandler := handlers.NewAuthHandler(userRepo, jwtService)
+	authHandler := handlers.NewAuthHandler(userRepo, auditLogRepo, sessionRepo, jwtService, emails, mfa, lockout, oidc)
 
 	// Use the existing /api/v1 group passed from main.go
 	auth := router.Group("/auth")
 
 	// Public auth routes
 	auth.Post("/login", authHandler.Login)
-	auth.Post("/register", authHandler.Register)
+	auth.Post("/login/mfa", authHandler.LoginMFA)
+	auth.Post("/register", authMiddleware.OptionalAuth(), authHandler.Register)
 	auth.Post("/refresh", authHandler.RefreshToken)
 	auth.Post("/forgot-password", authHandler.ForgotPassword)
 	auth.Post("/reset-password", authHandler.ResetPassword)
+	auth.Post("/verify-email", authHandler.VerifyEmail)
+	auth.Post("/resend-verification", authHandler.ResendVerification)
+
+	// Single sign-on, when an OpenID Connect provider is configured
+	if oidc != nil {
+		auth.Get("/oidc/login", authHandler.OIDCLogin)
+		auth.Get("/oidc/callback", authHandler.OIDCCallback)
+		auth.Post("/oidc/callback", authHandler.OIDCCallback)
+	}
 
 	// Protected auth routes
 	authProtected := auth.Use(authMiddleware.RequireAuth())
 	authProtected.Post("/logout", authHandler.Logout)
+	authProtected.Post("/logout-all", authHandler.LogoutAll)
 	authProtected.Get("/profile", authHandler.GetProfile)
 	authProtected.Put("/profile", authHandler.UpdateProfile)
 	authProtected.Post("/change-password", authHandler.ChangePassword)
+	authProtected.Get("/sessions", authHandler.GetSessions)
+	authProtected.Delete("/sessions/:id", authHandler.RevokeSession)
+	authProtected.Get("/mfa", authHandler.GetMFAStatus)
+	authProtected.Post("/mfa/totp/setup", authHandler.SetupTOTP)
+	authProtected.Post("/mfa/totp/enable", authHandler.EnableTOTP)
+	authProtected.Post("/mfa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	authProtected.Post("/mfa/disable", authHandler.DisableMFA)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -11,27 +12,29 @@ func SetupCarouselRoutes(app *fiber.App, handler *handlers.CarouselHandler, auth
 
 	// Menu Carousel routes (all protected)
 	menuCarousels := api.Group("/menu-carousels", authMiddleware.RequireAuth())
-	menuCarousels.Post("/", handler.CreateMenuCarousel)
-	menuCarousels.Get("/:id", handler.GetMenuCarouselByID)
-	menuCarousels.Put("/:id", handler.UpdateMenuCarousel)
-	menuCarousels.Delete("/:id", handler.DeleteMenuCarousel)
-	menuCarousels.Get("/:carouselId/items", handler.GetCarouselItemsByCarousel)
+	menuCarousels.Post("/", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionCreate), handler.CreateMenuCarousel)
+	menuCarousels.Get("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionRead), handler.GetMenuCarouselByID)
+	menuCarousels.Put("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.UpdateMenuCarousel)
+	menuCarousels.Delete("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionDelete), handler.DeleteMenuCarousel)
+	menuCarousels.Get("/:carouselId/items", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionRead), handler.GetCarouselItemsByCarousel)
 
 	// Carousel Item routes (all protected)
 	carouselItems := api.Group("/carousel-items", authMiddleware.RequireAuth())
-	carouselItems.Post("/", handler.CreateCarouselItem)
-	carouselItems.Put("/:id", handler.UpdateCarouselItem)
-	carouselItems.Patch("/:id/position", handler.UpdateCarouselItemPosition)
-	carouselItems.Delete("/:id", handler.DeleteCarouselItem)
-	carouselItems.Get("/:itemId/text-overlays", handler.GetTextOverlaysByItem)
+	carouselItems.Post("/", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionCreate), handler.CreateCarouselItem)
+	carouselItems.Put("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.UpdateCarouselItem)
+	carouselItems.Patch("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.PatchCarouselItem)
+	carouselItems.Patch("/:id/position", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.UpdateCarouselItemPosition)
+	carouselItems.Delete("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionDelete), handler.DeleteCarouselItem)
+	carouselItems.Get("/:itemId/text-overlays", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionRead), handler.GetTextOverlaysByItem)
 
 	// Text Overlay routes (all protected)
 	textOverlays := api.Group("/text-overlays", authMiddleware.RequireAuth())
-	textOverlays.Post("/", handler.CreateTextOverlay)
-	textOverlays.Put("/:id", handler.UpdateTextOverlay)
-	textOverlays.Delete("/:id", handler.DeleteTextOverlay)
+	textOverlays.Post("/", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionCreate), handler.CreateTextOverlay)
+	textOverlays.Put("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.UpdateTextOverlay)
+	textOverlays.Patch("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionUpdate), handler.PatchTextOverlay)
+	textOverlays.Delete("/:id", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionDelete), handler.DeleteTextOverlay)
 
 	// Menu-specific carousel routes (all protected)
 	menus := api.Group("/menus", authMiddleware.RequireAuth())
-	menus.Get("/:menuId/carousel", handler.GetMenuCarouselByMenuID)
+	menus.Get("/:menuId/carousel", authMiddleware.Authorize(auth.ResourceCarousels, auth.ActionRead), handler.GetMenuCarouselByMenuID)
 }
\ No newline at end of file
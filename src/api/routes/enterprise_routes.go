@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -10,12 +11,13 @@ func SetupEnterpriseRoutes(app *fiber.App, handler *handlers.EnterpriseHandler,
 	api := app.Group("/api/v1")
 	enterprises := api.Group("/enterprises", authMiddleware.RequireAuth())
 
-	// Enterprise routes (all protected)
-	enterprises.Post("/", handler.CreateEnterprise)
-	enterprises.Get("/", handler.GetEnterprises)
-	enterprises.Get("/search", handler.SearchEnterprises)
-	enterprises.Get("/slug/:slug", handler.GetEnterpriseBySlug)
-	enterprises.Get("/:id", handler.GetEnterpriseByID)
-	enterprises.Put("/:id", handler.UpdateEnterprise)
-	enterprises.Delete("/:id", handler.DeleteEnterprise)
+	// Enterprise routes (all protected, creating and deleting enterprises is for super admins)
+	enterprises.Post("/", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionCreate), handler.CreateEnterprise)
+	enterprises.Get("/", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionRead), handler.GetEnterprises)
+	enterprises.Get("/search", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionRead), handler.SearchEnterprises)
+	enterprises.Get("/slug/:slug", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionRead), handler.GetEnterpriseBySlug)
+	enterprises.Get("/:id", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionRead), handler.GetEnterpriseByID)
+	enterprises.Put("/:id", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionUpdate), handler.UpdateEnterprise)
+	enterprises.Patch("/:id", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionUpdate), handler.PatchEnterprise)
+	enterprises.Delete("/:id", authMiddleware.Authorize(auth.ResourceEnterprises, auth.ActionDelete), handler.DeleteEnterprise)
 }
\ No newline at end of file
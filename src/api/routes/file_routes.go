@@ -3,45 +3,50 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
-func SetupFileRoutes(app *fiber.App, fileHandler *handlers.FileHandler, variantHandler *handlers.FileVariantHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupFileRoutes(app *fiber.App, fileHandler *handlers.FileHandler, variantHandler *handlers.FileVariantHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.UploadRateLimiter) {
 	api := app.Group("/api/v1")
 
+	// Upload rate limiting runs after authentication so the limiter can key on the user
+	uploadRateLimit := middleware.UploadRateLimitMiddleware(rateLimiter)
+
 	// ============== FILE ROUTES ==============
 	
 	// File CRUD routes (all protected)
 	files := api.Group("/files", authMiddleware.RequireAuth())
-	files.Post("/", fileHandler.CreateFile)
-	files.Get("/", fileHandler.GetFiles)
-	files.Get("/:id", fileHandler.GetFileByID)
-	files.Put("/:id", fileHandler.UpdateFile)
-	files.Delete("/:id", fileHandler.DeleteFile)
+	files.Post("/", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), fileHandler.CreateFile)
+	files.Get("/", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), fileHandler.GetFiles)
+	files.Get("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), fileHandler.GetFileByID)
+	files.Put("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionUpdate), fileHandler.UpdateFile)
+	files.Patch("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionUpdate), fileHandler.PatchFile)
+	files.Delete("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionDelete), fileHandler.DeleteFile)
 
 	// Presigned URL routes for files
-	files.Post("/presigned-upload", fileHandler.RequestPresignedUploadURL)
-	files.Post("/multipart-upload", fileHandler.RequestMultipartUpload)
-	files.Post("/:fileId/complete-multipart", fileHandler.CompleteMultipartUpload)
-	files.Get("/:id/download-url", fileHandler.GetPresignedDownloadURL)
+	files.Post("/presigned-upload", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), uploadRateLimit, fileHandler.RequestPresignedUploadURL)
+	files.Post("/multipart-upload", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), uploadRateLimit, fileHandler.RequestMultipartUpload)
+	files.Post("/:fileId/complete-multipart", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), fileHandler.CompleteMultipartUpload)
+	files.Get("/:id/download-url", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), fileHandler.GetPresignedDownloadURL)
 
 	// File variant routes nested under files
-	files.Post("/:fileId/variants", variantHandler.CreateVariantForFile)
-	files.Get("/:fileId/variants", variantHandler.GetFileVariantsByOriginalFile)
-	files.Get("/:fileId/variants/:variantName", variantHandler.GetFileVariantByName)
-	files.Delete("/:fileId/variants", variantHandler.DeleteAllVariantsForFile)
+	files.Post("/:fileId/variants", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), variantHandler.CreateVariantForFile)
+	files.Get("/:fileId/variants", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), variantHandler.GetFileVariantsByOriginalFile)
+	files.Get("/:fileId/variants/:variantName", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), variantHandler.GetFileVariantByName)
+	files.Delete("/:fileId/variants", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionDelete), variantHandler.DeleteAllVariantsForFile)
 
 	// ============== FILE VARIANT ROUTES ==============
 	
 	// Standalone file variant routes (all protected)
 	fileVariants := api.Group("/file-variants", authMiddleware.RequireAuth())
-	fileVariants.Post("/", variantHandler.CreateFileVariant)
-	fileVariants.Get("/", variantHandler.GetAllFileVariants)
-	fileVariants.Get("/:id", variantHandler.GetFileVariantByID)
-	fileVariants.Put("/:id", variantHandler.UpdateFileVariant)
-	fileVariants.Delete("/:id", variantHandler.DeleteFileVariant)
+	fileVariants.Post("/", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), variantHandler.CreateFileVariant)
+	fileVariants.Get("/", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), variantHandler.GetAllFileVariants)
+	fileVariants.Get("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), variantHandler.GetFileVariantByID)
+	fileVariants.Put("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionUpdate), variantHandler.UpdateFileVariant)
+	fileVariants.Delete("/:id", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionDelete), variantHandler.DeleteFileVariant)
 
 	// Presigned URL routes for file variants
-	fileVariants.Get("/:id/upload-url", variantHandler.GetPresignedVariantUploadURL)
-	fileVariants.Get("/:id/download-url", variantHandler.GetPresignedVariantDownloadURL)
+	fileVariants.Get("/:id/upload-url", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionUpdate), variantHandler.GetPresignedVariantUploadURL)
+	fileVariants.Get("/:id/download-url", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), variantHandler.GetPresignedVariantDownloadURL)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -11,26 +12,28 @@ func SetupFloorPlanRoutes(app *fiber.App, towerHandler *handlers.TowerHandler, f
 
 	// Tower routes (all protected)
 	towers := api.Group("/towers", authMiddleware.RequireAuth())
-	towers.Post("/", towerHandler.CreateTower)
-	towers.Get("/", towerHandler.GetTowers)
-	towers.Get("/:id", towerHandler.GetTowerByID)
-	towers.Put("/:id", towerHandler.UpdateTower)
-	towers.Patch("/:id/position", towerHandler.UpdateTowerPosition)
-	towers.Delete("/:id", towerHandler.DeleteTower)
+	towers.Post("/", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionCreate), towerHandler.CreateTower)
+	towers.Get("/", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), towerHandler.GetTowers)
+	towers.Get("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), towerHandler.GetTowerByID)
+	towers.Put("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionUpdate), towerHandler.UpdateTower)
+	towers.Patch("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionUpdate), towerHandler.PatchTower)
+	towers.Patch("/:id/position", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionUpdate), towerHandler.UpdateTowerPosition)
+	towers.Delete("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionDelete), towerHandler.DeleteTower)
 
 	// Floor routes (all protected)
 	floors := api.Group("/floors", authMiddleware.RequireAuth())
-	floors.Post("/", floorHandler.CreateFloor)
-	floors.Get("/", floorHandler.GetFloors)
-	floors.Get("/:id", floorHandler.GetFloorByID)
-	floors.Put("/:id", floorHandler.UpdateFloor)
-	floors.Delete("/:id", floorHandler.DeleteFloor)
+	floors.Post("/", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionCreate), floorHandler.CreateFloor)
+	floors.Get("/", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), floorHandler.GetFloors)
+	floors.Get("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), floorHandler.GetFloorByID)
+	floors.Put("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionUpdate), floorHandler.UpdateFloor)
+	floors.Patch("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionUpdate), floorHandler.PatchFloor)
+	floors.Delete("/:id", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionDelete), floorHandler.DeleteFloor)
 
 	// Menu Floor Plan specific routes (all protected)
 	menuFloorPlans := api.Group("/menu-floor-plans", authMiddleware.RequireAuth())
-	menuFloorPlans.Get("/:menuFloorPlanId/towers", towerHandler.GetTowersByMenuFloorPlan)
+	menuFloorPlans.Get("/:menuFloorPlanId/towers", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), towerHandler.GetTowersByMenuFloorPlan)
 
 	// Tower-specific floor routes
-	towers.Get("/:towerId/floors", floorHandler.GetFloorsByTower)
-	towers.Get("/:towerId/floors/:floorNumber", floorHandler.GetFloorByNumber)
+	towers.Get("/:towerId/floors", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), floorHandler.GetFloorsByTower)
+	towers.Get("/:towerId/floors/:floorNumber", authMiddleware.Authorize(auth.ResourceFloorPlans, auth.ActionRead), floorHandler.GetFloorByNumber)
 }
\ No newline at end of file
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+)
+
+// SetupHealthRoutes exposes the liveness and readiness probes at the root, and the
+// health report under the API
+func SetupHealthRoutes(app *fiber.App, healthHandler *handlers.HealthHandler) {
+	app.Get("/livez", healthHandler.Live)
+	app.Get("/readyz", healthHandler.Ready)
+	app.Get("/api/v1/health", healthHandler.Health)
+}
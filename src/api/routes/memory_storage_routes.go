@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+)
+
+// SetupMemoryStorageRoutes exposes the presigned URLs of the in-memory storage driver
+func SetupMemoryStorageRoutes(app *fiber.App, handler *handlers.MemoryStorageHandler) {
+	objects := app.Group("/storage")
+	objects.Put("/:bucket/*", handler.PutObject)
+	objects.Get("/:bucket/*", handler.GetObject)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -11,16 +12,17 @@ func SetupMenuRoutes(app *fiber.App, handler *handlers.MenuHandler, authMiddlewa
 	
 	// Menu routes (all protected)
 	menus := api.Group("/menus", authMiddleware.RequireAuth())
-	menus.Post("/", handler.CreateMenu)
-	menus.Get("/", handler.GetMenus)
-	menus.Get("/:id", handler.GetMenuByID)
-	menus.Put("/:id", handler.UpdateMenu)
-	menus.Patch("/:id/position", handler.UpdateMenuPosition)
-	menus.Delete("/:id", handler.DeleteMenu)
-	menus.Get("/:parentId/children", handler.GetChildMenus)
+	menus.Post("/", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionCreate), handler.CreateMenu)
+	menus.Get("/", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionRead), handler.GetMenus)
+	menus.Get("/:id", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionRead), handler.GetMenuByID)
+	menus.Put("/:id", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionUpdate), handler.UpdateMenu)
+	menus.Patch("/:id", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionUpdate), handler.PatchMenu)
+	menus.Patch("/:id/position", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionUpdate), handler.UpdateMenuPosition)
+	menus.Delete("/:id", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionDelete), handler.DeleteMenu)
+	menus.Get("/:parentId/children", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionRead), handler.GetChildMenus)
 
 	// Enterprise-specific menu routes (all protected)
 	enterprises := api.Group("/enterprises", authMiddleware.RequireAuth())
-	enterprises.Get("/:enterpriseId/menus", handler.GetMenusByEnterprise)
-	enterprises.Get("/:enterpriseId/menus/hierarchy", handler.GetMenuHierarchy)
+	enterprises.Get("/:enterpriseId/menus", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionRead), handler.GetMenusByEnterprise)
+	enterprises.Get("/:enterpriseId/menus/hierarchy", authMiddleware.Authorize(auth.ResourceMenus, auth.ActionRead), handler.GetMenuHierarchy)
 }
\ No newline at end of file
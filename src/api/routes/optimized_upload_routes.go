@@ -2,9 +2,10 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/redis/go-redis/v9"
 	"terra-allwert/api/handlers"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/metrics"
 	"terra-allwert/infra/middleware"
 	"terra-allwert/infra/storage"
 	"terra-allwert/infra/websocket"
@@ -15,15 +16,14 @@ func SetupOptimizedUploadRoutes(
 	app *fiber.App,
 	fileRepo interfaces.FileRepository,
 	storageService interfaces.StorageService,
-	redisClient *redis.Client,
+	uploadStateManager *storage.UploadStateManager,
 	progressHub *websocket.ProgressHub,
 	rateLimiter *middleware.UploadRateLimiter,
 	circuitBreaker *middleware.CircuitBreaker,
+	thresholds handlers.UploadThresholds,
+	uploadMetrics *metrics.Metrics,
 	authMiddleware *middleware.AuthMiddleware,
 ) {
-	// Initialize upload state manager
-	uploadStateManager := storage.NewUploadStateManager(redisClient)
-
 	// Initialize optimized upload handler
 	optimizedHandler := handlers.NewOptimizedUploadHandler(
 		fileRepo,
@@ -32,14 +32,16 @@ func SetupOptimizedUploadRoutes(
 		progressHub,
 		rateLimiter,
 		circuitBreaker,
+		thresholds,
+		uploadMetrics,
 	)
 
 	api := app.Group("/api/v1")
 	files := api.Group("/files", authMiddleware.RequireAuth())
 
 	// Optimized upload endpoints
-	files.Post("/optimized-upload", optimizedHandler.InitiateOptimizedUpload)
-	files.Post("/optimized-upload/:uploadId/complete", optimizedHandler.CompleteOptimizedUpload)
-	files.Get("/upload-progress/:uploadId", optimizedHandler.GetUploadProgress)
-	files.Delete("/optimized-upload/:uploadId/abort", optimizedHandler.AbortOptimizedUpload)
+	files.Post("/optimized-upload", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), optimizedHandler.InitiateOptimizedUpload)
+	files.Post("/optimized-upload/:uploadId/complete", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), optimizedHandler.CompleteOptimizedUpload)
+	files.Get("/upload-progress/:uploadId", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionRead), optimizedHandler.GetUploadProgress)
+	files.Delete("/optimized-upload/:uploadId/abort", authMiddleware.Authorize(auth.ResourceFiles, auth.ActionCreate), optimizedHandler.AbortOptimizedUpload)
 }
\ No newline at end of file
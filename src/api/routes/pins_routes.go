@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -11,28 +12,29 @@ func SetupPinsRoutes(app *fiber.App, handler *handlers.PinsHandler, authMiddlewa
 
 	// Menu Pins routes (all protected)
 	menuPins := api.Group("/menu-pins", authMiddleware.RequireAuth())
-	menuPins.Post("/", handler.CreateMenuPins)
-	menuPins.Get("/:id", handler.GetMenuPinsByID)
-	menuPins.Put("/:id", handler.UpdateMenuPins)
-	menuPins.Delete("/:id", handler.DeleteMenuPins)
-	menuPins.Get("/:menuPinId/markers", handler.GetPinMarkersByMenuPin)
-	menuPins.Get("/:menuPinId/markers/search", handler.GetPinMarkersByPosition)
+	menuPins.Post("/", authMiddleware.Authorize(auth.ResourcePins, auth.ActionCreate), handler.CreateMenuPins)
+	menuPins.Get("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionRead), handler.GetMenuPinsByID)
+	menuPins.Put("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionUpdate), handler.UpdateMenuPins)
+	menuPins.Delete("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionDelete), handler.DeleteMenuPins)
+	menuPins.Get("/:menuPinId/markers", authMiddleware.Authorize(auth.ResourcePins, auth.ActionRead), handler.GetPinMarkersByMenuPin)
+	menuPins.Get("/:menuPinId/markers/search", authMiddleware.Authorize(auth.ResourcePins, auth.ActionRead), handler.GetPinMarkersByPosition)
 
 	// Pin Marker routes (all protected)
 	pinMarkers := api.Group("/pin-markers", authMiddleware.RequireAuth())
-	pinMarkers.Post("/", handler.CreatePinMarker)
-	pinMarkers.Put("/:id", handler.UpdatePinMarker)
-	pinMarkers.Delete("/:id", handler.DeletePinMarker)
-	pinMarkers.Get("/:markerId/images", handler.GetPinMarkerImagesByMarker)
+	pinMarkers.Post("/", authMiddleware.Authorize(auth.ResourcePins, auth.ActionCreate), handler.CreatePinMarker)
+	pinMarkers.Put("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionUpdate), handler.UpdatePinMarker)
+	pinMarkers.Patch("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionUpdate), handler.PatchPinMarker)
+	pinMarkers.Delete("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionDelete), handler.DeletePinMarker)
+	pinMarkers.Get("/:markerId/images", authMiddleware.Authorize(auth.ResourcePins, auth.ActionRead), handler.GetPinMarkerImagesByMarker)
 
 	// Pin Marker Image routes (all protected)
 	pinMarkerImages := api.Group("/pin-marker-images", authMiddleware.RequireAuth())
-	pinMarkerImages.Post("/", handler.CreatePinMarkerImage)
-	pinMarkerImages.Put("/:id", handler.UpdatePinMarkerImage)
-	pinMarkerImages.Patch("/:id/position", handler.UpdatePinMarkerImagePosition)
-	pinMarkerImages.Delete("/:id", handler.DeletePinMarkerImage)
+	pinMarkerImages.Post("/", authMiddleware.Authorize(auth.ResourcePins, auth.ActionCreate), handler.CreatePinMarkerImage)
+	pinMarkerImages.Put("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionUpdate), handler.UpdatePinMarkerImage)
+	pinMarkerImages.Patch("/:id/position", authMiddleware.Authorize(auth.ResourcePins, auth.ActionUpdate), handler.UpdatePinMarkerImagePosition)
+	pinMarkerImages.Delete("/:id", authMiddleware.Authorize(auth.ResourcePins, auth.ActionDelete), handler.DeletePinMarkerImage)
 
 	// Menu-specific pins routes (all protected)
 	menus := api.Group("/menus", authMiddleware.RequireAuth())
-	menus.Get("/:menuId/pins", handler.GetMenuPinsByMenuID)
+	menus.Get("/:menuId/pins", authMiddleware.Authorize(auth.ResourcePins, auth.ActionRead), handler.GetMenuPinsByMenuID)
 }
\ No newline at end of file
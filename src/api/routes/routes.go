@@ -7,7 +7,7 @@ import (
 )
 
 // SetupAllRoutes configures all API routes
-func SetupAllRoutes(app *fiber.App, handlers *Handlers, authMiddleware *middleware.AuthMiddleware) {
+func SetupAllRoutes(app *fiber.App, handlers *Handlers, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.UploadRateLimiter) {
 	// Setup individual route groups
 	SetupEnterpriseRoutes(app, handlers.EnterpriseHandler, authMiddleware)
 	SetupMenuRoutes(app, handlers.MenuHandler, authMiddleware)
@@ -15,7 +15,11 @@ func SetupAllRoutes(app *fiber.App, handlers *Handlers, authMiddleware *middlewa
 	SetupSuiteRoutes(app, handlers.SuiteHandler, authMiddleware)
 	SetupCarouselRoutes(app, handlers.CarouselHandler, authMiddleware)
 	SetupPinsRoutes(app, handlers.PinsHandler, authMiddleware)
-	SetupFileRoutes(app, handlers.FileHandler, handlers.FileVariantHandler, authMiddleware)
+	SetupFileRoutes(app, handlers.FileHandler, handlers.FileVariantHandler, authMiddleware, rateLimiter)
+	SetupAuditRoutes(app, handlers.AuditHandler, authMiddleware)
+	SetupTrashRoutes(app, handlers.TrashHandler, authMiddleware)
+	SetupUserRoutes(app, handlers.UserHandler, authMiddleware)
+	SetupAPIKeyRoutes(app, handlers.APIKeyHandler, authMiddleware)
 }
 
 // Handlers holds all handler instances
@@ -29,4 +33,8 @@ type Handlers struct {
 	PinsHandler         *handlers.PinsHandler
 	FileHandler         *handlers.FileHandler
 	FileVariantHandler  *handlers.FileVariantHandler
+	AuditHandler        *handlers.AuditHandler
+	TrashHandler        *handlers.TrashHandler
+	UserHandler         *handlers.UserHandler
+	APIKeyHandler       *handlers.APIKeyHandler
 }
\ No newline at end of file
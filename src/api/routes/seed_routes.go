@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
 	"terra-allwert/infra/config"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -13,8 +14,8 @@ func SetupSeedRoutes(app *fiber.App, cfg *config.Config, authMiddleware *middlew
 	api := app.Group("/api/v1")
 	seeds := api.Group("/seeds", authMiddleware.RequireAuth())
 
-	// Seed endpoints
-	seeds.Post("/run", seedHandler.RunSeeds)
-	seeds.Post("/enterprises", seedHandler.RunEnterpriseSeeds)
-	seeds.Post("/users", seedHandler.RunUserSeeds)
+	// Seed endpoints (super admins only)
+	seeds.Post("/run", authMiddleware.Authorize(auth.ResourceSeeds, auth.ActionCreate), seedHandler.RunSeeds)
+	seeds.Post("/enterprises", authMiddleware.Authorize(auth.ResourceSeeds, auth.ActionCreate), seedHandler.RunEnterpriseSeeds)
+	seeds.Post("/users", authMiddleware.Authorize(auth.ResourceSeeds, auth.ActionCreate), seedHandler.RunUserSeeds)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
 	"terra-allwert/infra/middleware"
 )
 
@@ -11,15 +12,16 @@ func SetupSuiteRoutes(app *fiber.App, handler *handlers.SuiteHandler, authMiddle
 	
 	// Suite routes (all protected)
 	suites := api.Group("/suites", authMiddleware.RequireAuth())
-	suites.Post("/", handler.CreateSuite)
-	suites.Get("/", handler.GetSuites)
-	suites.Get("/search", handler.SearchSuites)
-	suites.Get("/:id", handler.GetSuiteByID)
-	suites.Put("/:id", handler.UpdateSuite)
-	suites.Patch("/:id/status", handler.UpdateSuiteStatus)
-	suites.Delete("/:id", handler.DeleteSuite)
+	suites.Post("/", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionCreate), handler.CreateSuite)
+	suites.Get("/", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionRead), handler.GetSuites)
+	suites.Get("/search", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionRead), handler.SearchSuites)
+	suites.Get("/:id", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionRead), handler.GetSuiteByID)
+	suites.Put("/:id", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionUpdate), handler.UpdateSuite)
+	suites.Patch("/:id", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionUpdate), handler.PatchSuite)
+	suites.Patch("/:id/status", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionUpdate), handler.UpdateSuiteStatus)
+	suites.Delete("/:id", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionDelete), handler.DeleteSuite)
 
 	// Floor-based suite routes (all protected)
 	floors := api.Group("/floors", authMiddleware.RequireAuth())
-	floors.Get("/:floorId/suites", handler.GetSuitesByFloor)
+	floors.Get("/:floorId/suites", authMiddleware.Authorize(auth.ResourceSuites, auth.ActionRead), handler.GetSuitesByFloor)
 }
\ No newline at end of file
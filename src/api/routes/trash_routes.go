@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+)
+
+func SetupTrashRoutes(app *fiber.App, handler *handlers.TrashHandler, authMiddleware *middleware.AuthMiddleware) {
+	api := app.Group("/api/v1")
+	trash := api.Group("/trash", authMiddleware.RequireAuth())
+
+	// Trash routes (admins and managers, purge is admin only)
+	trash.Get("/", authMiddleware.Authorize(auth.ResourceTrash, auth.ActionRead), handler.GetTrash)
+	trash.Post("/purge", authMiddleware.Authorize(auth.ResourceTrash, auth.ActionDelete), handler.PurgeTrash)
+	trash.Post("/:entity_type/:id/restore", authMiddleware.Authorize(auth.ResourceTrash, auth.ActionUpdate), handler.RestoreItem)
+}
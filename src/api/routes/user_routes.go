@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/middleware"
+)
+
+func SetupUserRoutes(app *fiber.App, handler *handlers.UserHandler, authMiddleware *middleware.AuthMiddleware) {
+	api := app.Group("/api/v1")
+	users := api.Group("/users", authMiddleware.RequireAuth())
+
+	// User management routes (admin only)
+	users.Get("/", authMiddleware.Authorize(auth.ResourceUsers, auth.ActionRead), handler.GetUsers)
+	users.Get("/:id", authMiddleware.Authorize(auth.ResourceUsers, auth.ActionRead), handler.GetUserByID)
+	users.Patch("/:id/role", authMiddleware.Authorize(auth.ResourceUsers, auth.ActionUpdate), handler.UpdateUserRole)
+	users.Post("/:id/unlock", authMiddleware.Authorize(auth.ResourceUsers, auth.ActionUpdate), handler.UnlockUser)
+	users.Delete("/:id", authMiddleware.Authorize(auth.ResourceUsers, auth.ActionDelete), handler.DeleteUser)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"terra-allwert/api/handlers"
+)
+
+// SetupWellKnownRoutes exposes the public discovery documents of the API
+func SetupWellKnownRoutes(app *fiber.App, jwksHandler *handlers.JWKSHandler) {
+	wellKnown := app.Group("/.well-known")
+	wellKnown.Get("/jwks.json", jwksHandler.GetJWKS)
+}
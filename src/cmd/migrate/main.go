@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"terra-allwert/infra/config"
+	"terra-allwert/infra/database"
+	"terra-allwert/infra/database/migrations"
+)
+
+const usage = `Usage: migrate <command> [args]
+
+Commands:
+  up              Apply all pending migrations
+  down [N]        Roll back the last N applied migrations (default 1)
+  status          Show applied, pending and drifted migrations
+  create NAME     Create a new empty up/down migration pair
+`
+
+func main() {
+	dir := flag.String("dir", migrations.SourceDir, "migrations directory used by create")
+	configFile := flag.String("config", "", "YAML configuration file, overridden by the environment")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log.Println("🗄️  Terra Allwert Database Migrations")
+	log.Println("====================================")
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("create requires a migration name")
+		}
+		paths, err := migrations.Create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("❌ Failed to create migration: %v", err)
+		}
+		for _, path := range paths {
+			log.Printf("📝 Created %s", path)
+		}
+		return
+	}
+
+	cfg, err := config.LoadFile(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Printf("⬆️  Applied %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("❌ Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("✅ Database is already up to date")
+			return
+		}
+		log.Printf("✅ Applied %d migration(s)", len(applied))
+
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				log.Fatalf("invalid number of migrations %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, n)
+		for _, migration := range rolledBack {
+			log.Printf("⬇️  Rolled back %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("❌ Rollback failed: %v", err)
+		}
+		log.Printf("✅ Rolled back %d migration(s)", len(rolledBack))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Missing:
+				state = "drifted (file missing)"
+			case status.Drifted:
+				state = "drifted (checksum mismatch)"
+			case status.Applied:
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -15,7 +15,10 @@ func main() {
 	log.Println("================================")
 
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Build database connection string
 	dsn := buildDSN(cfg)
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyScopes lists what an API key may do, as "resource:action" strings
+type APIKeyScopes []string
+
+func (s *APIKeyScopes) Scan(value interface{}) error {
+	*s = nil
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return nil
+}
+
+func (s APIKeyScopes) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+// APIKey lets a device such as a showroom kiosk read the content of one enterprise
+// without a user. Only the hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID           uuid.UUID    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	EnterpriseID uuid.UUID    `json:"enterprise_id" gorm:"type:uuid;not null;index"`
+	Name         string       `json:"name" gorm:"not null;size:255" validate:"required"`
+	Prefix       string       `json:"prefix" gorm:"not null;size:16"`
+	KeyHash      string       `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Scopes       APIKeyScopes `json:"scopes" gorm:"type:jsonb;not null;default:'[]'"`
+	// RateLimit is the number of requests allowed per minute, zero for the default
+	RateLimit  int        `json:"rate_limit" gorm:"not null;default:0"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP *string    `json:"last_used_ip,omitempty" gorm:"type:inet"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+func (k *APIKey) BeforeCreate(db *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+func (k *APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether the key is neither revoked nor expired
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -19,6 +19,8 @@ const (
 	AuditActionRestore AuditAction = "restore"
 	AuditActionLogin   AuditAction = "login"
 	AuditActionLogout  AuditAction = "logout"
+	AuditActionLockout AuditAction = "lockout"
+	AuditActionUnlock  AuditAction = "unlock"
 )
 
 func (aa *AuditAction) Scan(value interface{}) error {
@@ -66,7 +68,7 @@ type AuditLog struct {
 	Action       AuditAction  `json:"action" gorm:"type:varchar(20);not null"`
 	OldValues    JSONValues   `json:"old_values,omitempty" gorm:"type:jsonb"`
 	NewValues    JSONValues   `json:"new_values,omitempty" gorm:"type:jsonb"`
-	IPAddress    *net.IP      `json:"ip_address,omitempty" gorm:"type:inet"`
+	IPAddress    *string      `json:"ip_address,omitempty" gorm:"type:inet"`
 	UserAgent    *string      `json:"user_agent,omitempty" gorm:"type:text"`
 	CreatedAt    time.Time    `json:"created_at" gorm:"not null"`
 }
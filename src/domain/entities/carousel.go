@@ -49,9 +49,9 @@ func (mt MapType) Value() (driver.Value, error) {
 type MenuCarousel struct {
 	ID                   uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuID               uuid.UUID  `json:"menu_id" gorm:"type:uuid;unique;not null"`
-	Menu                 Menu       `json:"menu,omitempty" gorm:"foreignKey:MenuID"`
+	Menu                 Menu       `json:"menu,omitempty" gorm:"foreignKey:MenuID;constraint:OnDelete:CASCADE"`
 	PromotionalVideoID   *uuid.UUID `json:"promotional_video_id,omitempty" gorm:"type:uuid"`
-	PromotionalVideo     *File      `json:"promotional_video,omitempty" gorm:"foreignKey:PromotionalVideoID"`
+	PromotionalVideo     *File      `json:"promotional_video,omitempty" gorm:"foreignKey:PromotionalVideoID;constraint:OnDelete:SET NULL"`
 	Autoplay             bool       `json:"autoplay" gorm:"default:true"`
 	AutoplayInterval     int        `json:"autoplay_interval" gorm:"default:5000"`
 	ShowIndicators       bool       `json:"show_indicators" gorm:"default:true"`
@@ -59,6 +59,7 @@ type MenuCarousel struct {
 	TransitionType       string     `json:"transition_type" gorm:"size:50;default:slide"`
 	CreatedAt            time.Time  `json:"created_at" gorm:"not null"`
 	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+	DeletedAt            gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
 	CarouselItems []CarouselItem `json:"carousel_items,omitempty" gorm:"foreignKey:MenuCarouselID"`
@@ -78,10 +79,10 @@ func (mc *MenuCarousel) TableName() string {
 type CarouselItem struct {
 	ID               uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuCarouselID   uuid.UUID        `json:"menu_carousel_id" gorm:"type:uuid;not null"`
-	MenuCarousel     MenuCarousel     `json:"menu_carousel,omitempty" gorm:"foreignKey:MenuCarouselID"`
+	MenuCarousel     MenuCarousel     `json:"menu_carousel,omitempty" gorm:"foreignKey:MenuCarouselID;constraint:OnDelete:CASCADE"`
 	ItemType         CarouselItemType `json:"item_type" gorm:"type:varchar(20);not null"`
 	BackgroundFileID *uuid.UUID       `json:"background_file_id,omitempty" gorm:"type:uuid"`
-	BackgroundFile   *File            `json:"background_file,omitempty" gorm:"foreignKey:BackgroundFileID"`
+	BackgroundFile   *File            `json:"background_file,omitempty" gorm:"foreignKey:BackgroundFileID;constraint:OnDelete:SET NULL"`
 	Position         int              `json:"position" gorm:"not null;default:0"`
 	Title            *string          `json:"title,omitempty" gorm:"size:255"`
 	Subtitle         *string          `json:"subtitle,omitempty" gorm:"size:500"`
@@ -116,7 +117,7 @@ func (ci *CarouselItem) TableName() string {
 type CarouselTextOverlay struct {
 	ID              uuid.UUID    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	CarouselItemID  uuid.UUID    `json:"carousel_item_id" gorm:"type:uuid;not null"`
-	CarouselItem    CarouselItem `json:"carousel_item,omitempty" gorm:"foreignKey:CarouselItemID"`
+	CarouselItem    CarouselItem `json:"carousel_item,omitempty" gorm:"foreignKey:CarouselItemID;constraint:OnDelete:CASCADE"`
 	Title           *string      `json:"title,omitempty" gorm:"size:255"`
 	Description     *string      `json:"description,omitempty" gorm:"type:text"`
 	TextColor       string       `json:"text_color" gorm:"size:7;default:#FFFFFF"`
@@ -43,6 +43,8 @@ type Enterprise struct {
 	Latitude             *float64         `json:"latitude,omitempty" gorm:"type:decimal(10,8)"`
 	Longitude            *float64         `json:"longitude,omitempty" gorm:"type:decimal(11,8)"`
 	Status               EnterpriseStatus `json:"status" gorm:"type:varchar(20);not null;default:active"`
+	// MFARequiredRoles lists the roles that must log in with a second factor
+	MFARequiredRoles     UserRoles        `json:"mfa_required_roles" gorm:"type:jsonb;not null;default:'[]'"`
 	CreatedAt            time.Time        `json:"created_at" gorm:"not null"`
 	UpdatedAt            *time.Time       `json:"updated_at,omitempty"`
 	DeletedAt            gorm.DeletedAt   `json:"deleted_at,omitempty" gorm:"index"`
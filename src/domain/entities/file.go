@@ -53,6 +53,7 @@ func (fm FileMetadata) Value() (driver.Value, error) {
 
 type File struct {
 	ID             uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	EnterpriseID   uuid.UUID     `json:"enterprise_id" gorm:"type:uuid;not null;index"`
 	FileType       FileType      `json:"file_type" gorm:"type:varchar(20);not null"`
 	MimeType       string        `json:"mime_type" gorm:"not null;size:100" validate:"required"`
 	Extension      string        `json:"extension" gorm:"not null;size:10" validate:"required"`
@@ -89,7 +90,7 @@ func (f *File) TableName() string {
 type FileVariant struct {
 	ID             uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	OriginalFileID uuid.UUID `json:"original_file_id" gorm:"type:uuid;not null"`
-	OriginalFile   File      `json:"original_file,omitempty" gorm:"foreignKey:OriginalFileID"`
+	OriginalFile   File      `json:"original_file,omitempty" gorm:"foreignKey:OriginalFileID;constraint:OnDelete:CASCADE"`
 	VariantName    string    `json:"variant_name" gorm:"not null;size:50" validate:"required"`
 	StoragePath    string    `json:"storage_path" gorm:"not null;size:500" validate:"required"`
 	CdnURL         *string   `json:"cdn_url,omitempty" gorm:"size:500"`
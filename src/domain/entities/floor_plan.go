@@ -53,12 +53,13 @@ func (ss SuiteStatus) Value() (driver.Value, error) {
 type MenuFloorPlan struct {
 	ID                     uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuID                 uuid.UUID `json:"menu_id" gorm:"type:uuid;unique;not null"`
-	Menu                   Menu      `json:"menu,omitempty" gorm:"foreignKey:MenuID"`
+	Menu                   Menu      `json:"menu,omitempty" gorm:"foreignKey:MenuID;constraint:OnDelete:CASCADE"`
 	DefaultView            *string   `json:"default_view,omitempty" gorm:"size:50"`
 	EnableUnitFilters      bool      `json:"enable_unit_filters" gorm:"default:true"`
 	EnableUnitComparison   bool      `json:"enable_unit_comparison" gorm:"default:true"`
 	CreatedAt              time.Time `json:"created_at" gorm:"not null"`
 	UpdatedAt              *time.Time `json:"updated_at,omitempty"`
+	DeletedAt              gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
 	Towers []Tower `json:"towers,omitempty" gorm:"foreignKey:MenuFloorPlanID"`
@@ -78,7 +79,7 @@ func (mfp *MenuFloorPlan) TableName() string {
 type Tower struct {
 	ID                uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuFloorPlanID   uuid.UUID      `json:"menu_floor_plan_id" gorm:"type:uuid;not null"`
-	MenuFloorPlan     MenuFloorPlan  `json:"menu_floor_plan,omitempty" gorm:"foreignKey:MenuFloorPlanID"`
+	MenuFloorPlan     MenuFloorPlan  `json:"menu_floor_plan,omitempty" gorm:"foreignKey:MenuFloorPlanID;constraint:OnDelete:CASCADE"`
 	Title             string         `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
 	Description       *string        `json:"description,omitempty" gorm:"type:text"`
 	BuildingCode      *string        `json:"building_code,omitempty" gorm:"size:50"`
@@ -107,13 +108,13 @@ func (t *Tower) TableName() string {
 type Floor struct {
 	ID               uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	TowerID          uuid.UUID      `json:"tower_id" gorm:"type:uuid;not null"`
-	Tower            Tower          `json:"tower,omitempty" gorm:"foreignKey:TowerID"`
+	Tower            Tower          `json:"tower,omitempty" gorm:"foreignKey:TowerID;constraint:OnDelete:CASCADE"`
 	FloorNumber      int            `json:"floor_number" gorm:"not null"`
 	FloorName        *string        `json:"floor_name,omitempty" gorm:"size:100"`
 	BannerFileID     *uuid.UUID     `json:"banner_file_id,omitempty" gorm:"type:uuid"`
-	BannerFile       *File          `json:"banner_file,omitempty" gorm:"foreignKey:BannerFileID"`
+	BannerFile       *File          `json:"banner_file,omitempty" gorm:"foreignKey:BannerFileID;constraint:OnDelete:SET NULL"`
 	FloorPlanFileID  *uuid.UUID     `json:"floor_plan_file_id,omitempty" gorm:"type:uuid"`
-	FloorPlanFile    *File          `json:"floor_plan_file,omitempty" gorm:"foreignKey:FloorPlanFileID"`
+	FloorPlanFile    *File          `json:"floor_plan_file,omitempty" gorm:"foreignKey:FloorPlanFileID;constraint:OnDelete:SET NULL"`
 	CreatedAt        time.Time      `json:"created_at" gorm:"not null"`
 	UpdatedAt        *time.Time     `json:"updated_at,omitempty"`
 	DeletedAt        gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -136,7 +137,7 @@ func (f *Floor) TableName() string {
 type Suite struct {
 	ID               uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	FloorID          uuid.UUID      `json:"floor_id" gorm:"type:uuid;not null"`
-	Floor            Floor          `json:"floor,omitempty" gorm:"foreignKey:FloorID"`
+	Floor            Floor          `json:"floor,omitempty" gorm:"foreignKey:FloorID;constraint:OnDelete:CASCADE"`
 	UnitNumber       string         `json:"unit_number" gorm:"not null;size:20" validate:"required"`
 	Title            string         `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
 	Description      *string        `json:"description,omitempty" gorm:"type:text"`
@@ -150,7 +151,7 @@ type Suite struct {
 	SunPosition      *SunPosition   `json:"sun_position,omitempty" gorm:"type:varchar(2)"`
 	Status           SuiteStatus    `json:"status" gorm:"type:varchar(20);not null;default:available"`
 	FloorPlanFileID  *uuid.UUID     `json:"floor_plan_file_id,omitempty" gorm:"type:uuid"`
-	FloorPlanFile    *File          `json:"floor_plan_file,omitempty" gorm:"foreignKey:FloorPlanFileID"`
+	FloorPlanFile    *File          `json:"floor_plan_file,omitempty" gorm:"foreignKey:FloorPlanFileID;constraint:OnDelete:SET NULL"`
 	Price            *float64       `json:"price,omitempty" gorm:"type:decimal(15,2)"`
 	CreatedAt        time.Time      `json:"created_at" gorm:"not null"`
 	UpdatedAt        *time.Time     `json:"updated_at,omitempty"`
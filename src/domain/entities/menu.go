@@ -46,9 +46,9 @@ func (mt MenuType) Value() (driver.Value, error) {
 type Menu struct {
 	ID             uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	EnterpriseID   uuid.UUID      `json:"enterprise_id" gorm:"type:uuid;not null"`
-	Enterprise     Enterprise     `json:"enterprise,omitempty" gorm:"foreignKey:EnterpriseID"`
+	Enterprise     Enterprise     `json:"enterprise,omitempty" gorm:"foreignKey:EnterpriseID;constraint:OnDelete:RESTRICT"`
 	ParentMenuID   *uuid.UUID     `json:"parent_menu_id,omitempty" gorm:"type:uuid"`
-	ParentMenu     *Menu          `json:"parent_menu,omitempty" gorm:"foreignKey:ParentMenuID"`
+	ParentMenu     *Menu          `json:"parent_menu,omitempty" gorm:"foreignKey:ParentMenuID;constraint:OnDelete:RESTRICT"`
 	Title          string         `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
 	Slug           string         `json:"slug" gorm:"not null;size:255" validate:"required,slug"`
 	ScreenType     ScreenType     `json:"screen_type" gorm:"type:varchar(20);not null"`
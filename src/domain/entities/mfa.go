@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMFA is the TOTP enrollment of a user. A new secret stays pending until the user
+// confirms it with a first valid code, which sets EnabledAt.
+type UserMFA struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Secret    string     `json:"-" gorm:"not null;size:64"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func (m *UserMFA) BeforeCreate(db *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+func (m *UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// Enabled reports whether logins of the user require a second factor
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFARecoveryCode is a single use code letting a user who lost their authenticator log
+// in. Only its hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;size:64"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+}
+
+func (c *MFARecoveryCode) BeforeCreate(db *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (c *MFARecoveryCode) TableName() string {
+	return "user_mfa_recovery_codes"
+}
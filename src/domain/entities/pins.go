@@ -55,17 +55,18 @@ func (ad ActionData) Value() (driver.Value, error) {
 type MenuPins struct {
 	ID                  uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuID              uuid.UUID  `json:"menu_id" gorm:"type:uuid;unique;not null"`
-	Menu                Menu       `json:"menu,omitempty" gorm:"foreignKey:MenuID"`
+	Menu                Menu       `json:"menu,omitempty" gorm:"foreignKey:MenuID;constraint:OnDelete:CASCADE"`
 	BackgroundFileID    *uuid.UUID `json:"background_file_id,omitempty" gorm:"type:uuid"`
-	BackgroundFile      *File      `json:"background_file,omitempty" gorm:"foreignKey:BackgroundFileID"`
+	BackgroundFile      *File      `json:"background_file,omitempty" gorm:"foreignKey:BackgroundFileID;constraint:OnDelete:SET NULL"`
 	PromotionalVideoID  *uuid.UUID `json:"promotional_video_id,omitempty" gorm:"type:uuid"`
-	PromotionalVideo    *File      `json:"promotional_video,omitempty" gorm:"foreignKey:PromotionalVideoID"`
+	PromotionalVideo    *File      `json:"promotional_video,omitempty" gorm:"foreignKey:PromotionalVideoID;constraint:OnDelete:SET NULL"`
 	EnableZoom          bool       `json:"enable_zoom" gorm:"default:true"`
 	EnablePan           bool       `json:"enable_pan" gorm:"default:true"`
 	MinZoom             float64    `json:"min_zoom" gorm:"type:decimal(3,2);default:0.5"`
 	MaxZoom             float64    `json:"max_zoom" gorm:"type:decimal(3,2);default:3.0"`
 	CreatedAt           time.Time  `json:"created_at" gorm:"not null"`
 	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+	DeletedAt           gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
 	PinMarkers []PinMarker `json:"pin_markers,omitempty" gorm:"foreignKey:MenuPinID"`
@@ -85,7 +86,7 @@ func (mp *MenuPins) TableName() string {
 type PinMarker struct {
 	ID           uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	MenuPinID    uuid.UUID      `json:"menu_pin_id" gorm:"type:uuid;not null"`
-	MenuPin      MenuPins       `json:"menu_pin,omitempty" gorm:"foreignKey:MenuPinID"`
+	MenuPin      MenuPins       `json:"menu_pin,omitempty" gorm:"foreignKey:MenuPinID;constraint:OnDelete:CASCADE"`
 	Title        string         `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
 	Description  *string        `json:"description,omitempty" gorm:"type:text"`
 	PositionX    float64        `json:"position_x" gorm:"type:decimal(5,2);not null"`
@@ -117,9 +118,9 @@ func (pm *PinMarker) TableName() string {
 type PinMarkerImage struct {
 	ID           uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	PinMarkerID  uuid.UUID `json:"pin_marker_id" gorm:"type:uuid;not null"`
-	PinMarker    PinMarker `json:"pin_marker,omitempty" gorm:"foreignKey:PinMarkerID"`
+	PinMarker    PinMarker `json:"pin_marker,omitempty" gorm:"foreignKey:PinMarkerID;constraint:OnDelete:CASCADE"`
 	FileID       uuid.UUID `json:"file_id" gorm:"type:uuid;not null"`
-	File         File      `json:"file,omitempty" gorm:"foreignKey:FileID"`
+	File         File      `json:"file,omitempty" gorm:"foreignKey:FileID;constraint:OnDelete:RESTRICT"`
 	Position     int       `json:"position" gorm:"not null;default:0"`
 	Caption      *string   `json:"caption,omitempty" gorm:"size:500"`
 	CreatedAt    time.Time `json:"created_at" gorm:"not null"`
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserSession is a refresh token family: the chain of refresh tokens rotated from one
+// login. Only the latest token of the chain may be used; presenting an older one means
+// it leaked, and revokes the whole family.
+type UserSession struct {
+	ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	RefreshTokenID string     `json:"-" gorm:"not null;size:64"`
+	UserAgent      *string    `json:"user_agent,omitempty" gorm:"type:text"`
+	IPAddress      *string    `json:"ip_address,omitempty" gorm:"type:inet"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null"`
+	LastUsedAt     time.Time  `json:"last_used_at" gorm:"not null"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (s *UserSession) BeforeCreate(db *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+func (s *UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// Active reports whether the session can still be refreshed
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
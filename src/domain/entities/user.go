@@ -2,6 +2,7 @@ package entities
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,8 @@ const (
 	UserRoleVisitor UserRole = "visitor"
 	UserRoleManager UserRole = "manager"
 	UserRoleAdmin   UserRole = "admin"
+	// UserRoleSuperAdmin administers the platform and may opt out of tenant scoping
+	UserRoleSuperAdmin UserRole = "super_admin"
 )
 
 func (ur *UserRole) Scan(value interface{}) error {
@@ -25,10 +28,42 @@ func (ur UserRole) Value() (driver.Value, error) {
 	return string(ur), nil
 }
 
+// UserRoles is a set of roles stored as a JSON array
+type UserRoles []UserRole
+
+func (ur *UserRoles) Scan(value interface{}) error {
+	*ur = nil
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, ur)
+	case string:
+		return json.Unmarshal([]byte(v), ur)
+	}
+	return nil
+}
+
+func (ur UserRoles) Value() (driver.Value, error) {
+	if ur == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(ur)
+	return string(data), err
+}
+
+// Contains reports whether role is in the set
+func (ur UserRoles) Contains(role UserRole) bool {
+	for _, r := range ur {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 type User struct {
 	ID               uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	EnterpriseID     uuid.UUID  `json:"enterprise_id" gorm:"type:uuid;not null"`
-	Enterprise       Enterprise `json:"enterprise,omitempty" gorm:"foreignKey:EnterpriseID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Enterprise       Enterprise `json:"enterprise,omitempty" gorm:"foreignKey:EnterpriseID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 	Name             string     `json:"name" gorm:"not null;size:255" validate:"required,min=2,max=255"`
 	Email            string     `json:"email" gorm:"unique;not null;size:255" validate:"required,email"`
 	PasswordHash     string     `json:"-" gorm:"not null;size:255"`
@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to their account at an OpenID Connect provider, the subject
+// the provider knows them by
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Issuer      string     `json:"issuer" gorm:"not null;size:255;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject     string     `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_user_identities_issuer_subject"`
+	Email       string     `json:"email" gorm:"size:255"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
+}
+
+func (i *UserIdentity) BeforeCreate(db *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+func (i *UserIdentity) TableName() string {
+	return "user_identities"
+}
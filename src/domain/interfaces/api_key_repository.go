@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/domain/entities"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error)
+	// GetByHash gets a key, revoked and expired ones included, by the hash of its secret
+	GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	// GetAll lists the keys of the caller's enterprise, newest first
+	GetAll(ctx context.Context) ([]*entities.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// TouchLastUsed records when and from where the key was last used
+	TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, ip string) error
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/domain/entities"
+)
+
+type AuditLogSearchFilters struct {
+	EntityType   *string
+	EntityID     *uuid.UUID
+	UserID       *uuid.UUID
+	EnterpriseID *uuid.UUID
+	Action       *entities.AuditAction
+	From         *time.Time
+	To           *time.Time
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *entities.AuditLog) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error)
+	Search(ctx context.Context, filters AuditLogSearchFilters, limit, offset int) ([]*entities.AuditLog, error)
+}
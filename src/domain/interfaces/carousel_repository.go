@@ -22,6 +22,7 @@ type CarouselItemRepository interface {
 	GetByMenuCarouselID(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselItem, error)
 	Update(ctx context.Context, item *entities.CarouselItem) error
+	UpdateFields(ctx context.Context, item *entities.CarouselItem, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdatePosition(ctx context.Context, itemID uuid.UUID, position int) error
 	GetActiveItems(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error)
@@ -34,5 +35,6 @@ type CarouselTextOverlayRepository interface {
 	GetByCarouselItemID(ctx context.Context, carouselItemID uuid.UUID, limit, offset int) ([]*entities.CarouselTextOverlay, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselTextOverlay, error)
 	Update(ctx context.Context, overlay *entities.CarouselTextOverlay) error
+	UpdateFields(ctx context.Context, overlay *entities.CarouselTextOverlay, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
\ No newline at end of file
@@ -13,6 +13,7 @@ type EnterpriseRepository interface {
 	GetBySlug(ctx context.Context, slug string) (*entities.Enterprise, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.Enterprise, error)
 	Update(ctx context.Context, enterprise *entities.Enterprise) error
+	UpdateFields(ctx context.Context, enterprise *entities.Enterprise, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByCity(ctx context.Context, city string, limit, offset int) ([]*entities.Enterprise, error)
 	GetByStatus(ctx context.Context, status entities.EnterpriseStatus, limit, offset int) ([]*entities.Enterprise, error)
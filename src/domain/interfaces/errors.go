@@ -0,0 +1,114 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownEntityType is returned for trash operations on a type without soft delete
+	ErrUnknownEntityType = errors.New("unknown entity type")
+
+	// ErrParentDeleted is returned when restoring a row whose parent is still in the trash
+	ErrParentDeleted = errors.New("parent is deleted, restore it first")
+
+	// ErrOtherTenant is returned when a write references a row outside the caller's
+	// enterprise. It reads as not found so other tenants' IDs are not disclosed.
+	ErrOtherTenant = errors.New("record not found")
+)
+
+// Blocker describes live rows that still depend on an entity being deleted
+type Blocker struct {
+	Entity string `json:"entity"`
+	Count  int64  `json:"count"`
+}
+
+// RestrictError is returned by repository deletes when a RESTRICT rule applies
+type RestrictError struct {
+	Entity   string
+	ID       uuid.UUID
+	Blockers []Blocker
+}
+
+func (e *RestrictError) Error() string {
+	parts := make([]string, 0, len(e.Blockers))
+	for _, blocker := range e.Blockers {
+		parts = append(parts, fmt.Sprintf("%d %s", blocker.Count, blocker.Entity))
+	}
+	return fmt.Sprintf("cannot delete %s %s: still referenced by %s", e.Entity, e.ID, strings.Join(parts, ", "))
+}
+
+// NewRestrictError builds a RestrictError from blocker counts, returning nil when none is positive
+func NewRestrictError(entity string, id uuid.UUID, blockers ...Blocker) error {
+	var found []Blocker
+	for _, blocker := range blockers {
+		if blocker.Count > 0 {
+			found = append(found, blocker)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return &RestrictError{Entity: entity, ID: id, Blockers: found}
+}
+
+// NotFoundError is returned when the record looked up does not exist
+type NotFoundError struct {
+	Entity string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
+
+// ConflictError is returned when a write clashes with an existing record, such as a
+// duplicate value of a unique column
+type ConflictError struct {
+	Entity string
+	Field  string
+	Err    error
+}
+
+// NewConflictError reports a duplicate value of field of entity, wrapping the error of
+// the store
+func NewConflictError(entity, field string, err error) *ConflictError {
+	return &ConflictError{Entity: entity, Field: field, Err: err}
+}
+
+func (e *ConflictError) Error() string {
+	if e.Field == "" {
+		return e.Entity + " already exists"
+	}
+	return fmt.Sprintf("%s with this %s already exists", e.Entity, e.Field)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned when input is invalid. Err holds the failed field
+// validations when set.
+type ValidationError struct {
+	Message string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ForbiddenError is returned when the caller may not perform an operation
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
@@ -28,6 +28,7 @@ type FileRepository interface {
 	GetByHash(ctx context.Context, hash string) (*entities.File, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.File, error)
 	Update(ctx context.Context, file *entities.File) error
+	UpdateFields(ctx context.Context, file *entities.File, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByUploader(ctx context.Context, uploaderID uuid.UUID, limit, offset int) ([]*entities.File, error)
 	GetByType(ctx context.Context, fileType entities.FileType, limit, offset int) ([]*entities.File, error)
@@ -0,0 +1,15 @@
+package interfaces
+
+import "context"
+
+// MailMessage is an email with a plain text body and an optional HTML alternative
+type MailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message MailMessage) error
+}
@@ -14,6 +14,7 @@ type MenuRepository interface {
 	GetBySlug(ctx context.Context, enterpriseID uuid.UUID, slug string) (*entities.Menu, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.Menu, error)
 	Update(ctx context.Context, menu *entities.Menu) error
+	UpdateFields(ctx context.Context, menu *entities.Menu, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetChildren(ctx context.Context, parentID uuid.UUID, limit, offset int) ([]*entities.Menu, error)
 	GetRootMenus(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.Menu, error)
@@ -28,6 +29,7 @@ type TowerRepository interface {
 	GetByMenuFloorPlanID(ctx context.Context, menuFloorPlanID uuid.UUID, limit, offset int) ([]*entities.Tower, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.Tower, error)
 	Update(ctx context.Context, tower *entities.Tower) error
+	UpdateFields(ctx context.Context, tower *entities.Tower, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdatePosition(ctx context.Context, towerID uuid.UUID, position int) error
 }
@@ -38,6 +40,7 @@ type FloorRepository interface {
 	GetByTowerID(ctx context.Context, towerID uuid.UUID, limit, offset int) ([]*entities.Floor, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.Floor, error)
 	Update(ctx context.Context, floor *entities.Floor) error
+	UpdateFields(ctx context.Context, floor *entities.Floor, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByFloorNumber(ctx context.Context, towerID uuid.UUID, floorNumber int) (*entities.Floor, error)
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"terra-allwert/domain/entities"
+)
+
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.UserMFA, error)
+	// Save creates or updates the enrollment of mfa.UserID
+	Save(ctx context.Context, mfa *entities.UserMFA) error
+	// Delete removes the enrollment of the user together with its recovery codes
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// ReplaceRecoveryCodes swaps every recovery code of the user for the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	// UseRecoveryCode marks an unused code of the user as used, reporting whether it
+	// found one. Of two concurrent uses of the same code only one succeeds.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	// CountRecoveryCodes counts the unused recovery codes of the user
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -22,6 +22,7 @@ type PinMarkerRepository interface {
 	GetByMenuPinID(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.PinMarker, error)
 	Update(ctx context.Context, marker *entities.PinMarker) error
+	UpdateFields(ctx context.Context, marker *entities.PinMarker, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetVisibleMarkers(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error)
 	GetByActionType(ctx context.Context, menuPinID uuid.UUID, actionType entities.PinAction, limit, offset int) ([]*entities.PinMarker, error)
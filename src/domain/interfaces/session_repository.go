@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/domain/entities"
+)
+
+// SessionRotation is the new state of a session after its refresh token was used
+type SessionRotation struct {
+	RefreshTokenID string
+	ExpiresAt      time.Time
+	UsedAt         time.Time
+	IPAddress      *string
+	UserAgent      *string
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.UserSession) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserSession, error)
+	// GetActiveByUserID lists the sessions of the user that are neither revoked nor expired
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.UserSession, error)
+	// Rotate replaces the refresh token of an active session, only if its current token is
+	// still refreshTokenID. It reports false when another refresh won the race.
+	Rotate(ctx context.Context, id uuid.UUID, refreshTokenID string, rotation SessionRotation) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+}
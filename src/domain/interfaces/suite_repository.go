@@ -31,6 +31,7 @@ type SuiteRepository interface {
 	GetByFloorID(ctx context.Context, floorID uuid.UUID, limit, offset int) ([]*entities.Suite, error)
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.Suite, error)
 	Update(ctx context.Context, suite *entities.Suite) error
+	UpdateFields(ctx context.Context, suite *entities.Suite, fields []string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateStatus(ctx context.Context, suiteID uuid.UUID, status entities.SuiteStatus) error
 	GetByStatus(ctx context.Context, status entities.SuiteStatus, limit, offset int) ([]*entities.Suite, error)
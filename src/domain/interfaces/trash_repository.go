@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashItem is a soft deleted row that can be restored or purged. Rows deleted together
+// with their parent are not listed, they come back when the parent is restored.
+type TrashItem struct {
+	EntityType string    `json:"entity_type"`
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+// PurgeResult reports the rows removed by a purge. StoragePaths lists the storage objects
+// of the purged files and their variants, which the caller removes from storage.
+type PurgeResult struct {
+	Purged       map[string]int64 `json:"purged"`
+	StoragePaths []string         `json:"-"`
+}
+
+// TrashListFilters narrows a trash listing. An empty EntityType lists every entity type
+// but the ExcludedTypes.
+type TrashListFilters struct {
+	EntityType    string
+	ExcludedTypes []string
+}
+
+type TrashRepository interface {
+	List(ctx context.Context, enterpriseID uuid.UUID, filters TrashListFilters, limit, offset int) ([]*TrashItem, error)
+	Restore(ctx context.Context, enterpriseID uuid.UUID, entityType string, id uuid.UUID) error
+	Purge(ctx context.Context, enterpriseID uuid.UUID, deletedBefore time.Time) (*PurgeResult, error)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/domain/entities"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entities.UserIdentity) error
+	// GetByIssuerSubject gets the identity a provider knows by subject
+	GetByIssuerSubject(ctx context.Context, issuer, subject string) (*entities.UserIdentity, error)
+	// TouchLastLogin records a login with the identity and the email the provider sent
+	TouchLastLogin(ctx context.Context, id uuid.UUID, email string, loginAt time.Time) error
+}
@@ -16,6 +16,7 @@ type UserRepository interface {
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
 	GetByRole(ctx context.Context, role entities.UserRole, limit, offset int) ([]*entities.User, error)
 	GetActiveUsers(ctx context.Context, limit, offset int) ([]*entities.User, error)
 	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"terra-allwert/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+// Context keys read by ActorFromContext. The user and enterprise keys are the ones set by
+// AuthMiddleware; the request keys are set by AuthMiddleware.LogUserActivity. Handlers pass
+// the fiber request context to repositories, so these Locals are visible there.
+const (
+	UserKey       = "user_uuid"
+	EnterpriseKey = "enterprise_uuid"
+	IPKey         = "audit_ip"
+	UserAgentKey  = "audit_user_agent"
+)
+
+const redacted = "[REDACTED]"
+
+// ignoredTables are never audited. Sessions change on every token refresh; logins and
+// logouts are audited as their own events. MFA rows only hold secrets.
+var ignoredTables = map[string]bool{
+	"audit_logs":              true,
+	"schema_migrations":       true,
+	"user_sessions":           true,
+	"user_mfa":                true,
+	"user_mfa_recovery_codes": true,
+}
+
+// sensitiveColumns are recorded as changed without their values
+var sensitiveColumns = map[string]bool{
+	"password_hash": true,
+	"key_hash":      true,
+}
+
+// noisyColumns are bookkeeping columns left out of update diffs; logins are audited as
+// their own events, API key uses are not audited
+var noisyColumns = map[string]bool{
+	"updated_at":    true,
+	"last_login_at": true,
+	"last_used_at":  true,
+	"last_used_ip":  true,
+}
+
+var schemaCache sync.Map
+
+// Actor identifies who performed a mutation
+type Actor struct {
+	UserID       *uuid.UUID
+	EnterpriseID *uuid.UUID
+	IPAddress    *string
+	UserAgent    *string
+}
+
+// ActorFromContext extracts the acting user and request metadata from the context
+func ActorFromContext(ctx context.Context) Actor {
+	var actor Actor
+	if ctx == nil {
+		return actor
+	}
+	if id, ok := ctx.Value(UserKey).(uuid.UUID); ok {
+		actor.UserID = &id
+	}
+	if id, ok := ctx.Value(EnterpriseKey).(uuid.UUID); ok {
+		actor.EnterpriseID = &id
+	}
+	if ip, ok := ctx.Value(IPKey).(string); ok && ip != "" {
+		actor.IPAddress = &ip
+	}
+	if userAgent, ok := ctx.Value(UserAgentKey).(string); ok && userAgent != "" {
+		actor.UserAgent = &userAgent
+	}
+	return actor
+}
+
+// Ignored reports whether mutations of the table are not audited
+func Ignored(table string) bool {
+	return ignoredTables[table]
+}
+
+// NewEntry builds an audit log entry attributed to the actor found in ctx
+func NewEntry(ctx context.Context, action entities.AuditAction, entityType string, entityID uuid.UUID, oldValues, newValues entities.JSONValues) *entities.AuditLog {
+	actor := ActorFromContext(ctx)
+	entry := &entities.AuditLog{
+		ID:           uuid.New(),
+		UserID:       actor.UserID,
+		EnterpriseID: actor.EnterpriseID,
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Action:       action,
+		OldValues:    redact(oldValues),
+		NewValues:    redact(newValues),
+		IPAddress:    actor.IPAddress,
+		UserAgent:    actor.UserAgent,
+	}
+
+	// Rows that carry their own enterprise are attributed to it when there is no actor
+	if entry.EnterpriseID == nil {
+		for _, values := range []entities.JSONValues{newValues, oldValues} {
+			if id, err := uuid.Parse(stringValue(values["enterprise_id"])); err == nil {
+				entry.EnterpriseID = &id
+				break
+			}
+		}
+	}
+	return entry
+}
+
+// Snapshot captures the column values of a model, keyed by column name
+func Snapshot(model interface{}) (entities.JSONValues, error) {
+	s, err := schema.Parse(model, &schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	return snapshotOf(context.Background(), s, reflect.ValueOf(model)), nil
+}
+
+// TableName returns the table a model is stored in
+func TableName(model interface{}) string {
+	s, err := schema.Parse(model, &schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return ""
+	}
+	return s.Table
+}
+
+// Diff keeps only the columns whose value changed between two snapshots
+func Diff(oldValues, newValues entities.JSONValues) (entities.JSONValues, entities.JSONValues) {
+	oldChanged := make(entities.JSONValues)
+	newChanged := make(entities.JSONValues)
+	for column, newValue := range newValues {
+		if noisyColumns[column] {
+			continue
+		}
+		if oldValue, ok := oldValues[column]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			oldChanged[column] = oldValues[column]
+			newChanged[column] = newValue
+		}
+	}
+	return oldChanged, newChanged
+}
+
+// UpdateAction tells a restore (deleted_at cleared) apart from a plain update
+func UpdateAction(oldValues, newValues entities.JSONValues) entities.AuditAction {
+	if oldValues["deleted_at"] != nil && newValues["deleted_at"] == nil {
+		return entities.AuditActionRestore
+	}
+	return entities.AuditActionUpdate
+}
+
+func snapshotOf(ctx context.Context, s *schema.Schema, value reflect.Value) entities.JSONValues {
+	value = reflect.Indirect(value)
+	raw := make(map[string]interface{}, len(s.DBNames))
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		fieldValue, _ := field.ValueOf(ctx, value)
+		raw[field.DBName] = fieldValue
+	}
+
+	// Round-trip through JSON so snapshots compare and store the way they are persisted
+	values := make(entities.JSONValues, len(raw))
+	if data, err := json.Marshal(raw); err == nil {
+		json.Unmarshal(data, &values)
+	}
+	return values
+}
+
+func redact(values entities.JSONValues) entities.JSONValues {
+	if values == nil {
+		return nil
+	}
+	for column := range values {
+		if sensitiveColumns[column] {
+			values[column] = redacted
+		}
+	}
+	return values
+}
+
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"reflect"
+
+	"terra-allwert/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const beforeKey = "audit:before"
+
+// RegisterCallbacks hooks audit logging into every create, update and delete issued
+// through db. Entries are written in the same transaction as the mutation, so a failed
+// audit write rolls the mutation back.
+func RegisterCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", loadBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", loadBefore); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+func afterCreate(db *gorm.DB) {
+	if !audited(db) {
+		return
+	}
+	ctx := db.Statement.Context
+
+	var entries []*entities.AuditLog
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		if id, ok := primaryKey(db, row); ok {
+			values := snapshotOf(ctx, db.Statement.Schema, row)
+			entries = append(entries, NewEntry(ctx, entities.AuditActionCreate, db.Statement.Table, id, nil, values))
+		}
+	})
+	write(db, entries)
+}
+
+func afterUpdate(db *gorm.DB) {
+	before, ok := beforeRows(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+	ctx := db.Statement.Context
+
+	ids := make([]uuid.UUID, 0, len(before))
+	for id := range before {
+		ids = append(ids, id)
+	}
+	after, err := load(db, true, clause.Where{Exprs: []clause.Expression{
+		clause.IN{Column: clause.PrimaryColumn, Values: toValues(ids)},
+	}})
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	var entries []*entities.AuditLog
+	for id, snapshot := range after {
+		oldValues, newValues := Diff(before[id], snapshot)
+		if len(newValues) == 0 {
+			continue
+		}
+		action := UpdateAction(before[id], snapshot)
+		entries = append(entries, NewEntry(ctx, action, db.Statement.Table, id, oldValues, newValues))
+	}
+	write(db, entries)
+}
+
+func afterDelete(db *gorm.DB) {
+	before, ok := beforeRows(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+	ctx := db.Statement.Context
+
+	var entries []*entities.AuditLog
+	for id, oldValues := range before {
+		entries = append(entries, NewEntry(ctx, entities.AuditActionDelete, db.Statement.Table, id, oldValues, nil))
+	}
+	write(db, entries)
+}
+
+// loadBefore snapshots the rows an update or delete is about to touch
+func loadBefore(db *gorm.DB) {
+	if !audited(db) {
+		return
+	}
+	stmt := db.Statement
+
+	var conditions []clause.Expression
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where.Exprs...)
+	}
+	for _, value := range []reflect.Value{stmt.ReflectValue, reflect.ValueOf(stmt.Model)} {
+		if !value.IsValid() {
+			continue
+		}
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, value, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			conditions = append(conditions, clause.IN{Column: column, Values: values})
+		}
+	}
+
+	// Without conditions GORM refuses the statement unless global updates are allowed
+	if len(conditions) == 0 && !stmt.AllowGlobalUpdate {
+		return
+	}
+
+	before, err := load(db, stmt.Unscoped, clause.Where{Exprs: conditions})
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(beforeKey, before)
+}
+
+// load reads the matching rows of the statement's model as snapshots keyed by ID
+func load(db *gorm.DB, unscoped bool, where clause.Where) (map[uuid.UUID]entities.JSONValues, error) {
+	stmt := db.Statement
+	rows := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(reflect.New(stmt.Schema.ModelType).Interface()).Table(stmt.Table)
+	if unscoped {
+		tx = tx.Unscoped()
+	}
+	tx.Statement.AddClause(where)
+	if err := tx.Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[uuid.UUID]entities.JSONValues, rows.Elem().Len())
+	eachRow(rows.Elem(), func(row reflect.Value) {
+		if id, ok := primaryKey(db, row); ok {
+			snapshots[id] = snapshotOf(stmt.Context, stmt.Schema, row)
+		}
+	})
+	return snapshots, nil
+}
+
+func beforeRows(db *gorm.DB) (map[uuid.UUID]entities.JSONValues, bool) {
+	if db.Error != nil {
+		return nil, false
+	}
+	value, ok := db.InstanceGet(beforeKey)
+	if !ok {
+		return nil, false
+	}
+	before, ok := value.(map[uuid.UUID]entities.JSONValues)
+	return before, ok && len(before) > 0
+}
+
+// audited reports whether the statement targets an audited model keyed by a UUID
+func audited(db *gorm.DB) bool {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || Ignored(stmt.Table) {
+		return false
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	return pk != nil && pk.FieldType == reflect.TypeOf(uuid.UUID{})
+}
+
+func primaryKey(db *gorm.DB, row reflect.Value) (uuid.UUID, bool) {
+	value, zero := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, reflect.Indirect(row))
+	if zero {
+		return uuid.Nil, false
+	}
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
+
+func eachRow(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			fn(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+func toValues(ids []uuid.UUID) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
+
+// write stores the entries through the statement's connection, inside its transaction
+func write(db *gorm.DB, entries []*entities.AuditLog) {
+	if len(entries) == 0 {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	if err := tx.Omit(clause.Associations).Create(&entries).Error; err != nil {
+		db.AddError(err)
+	}
+}
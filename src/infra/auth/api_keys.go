@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/cache"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to spot
+const APIKeyPrefix = "tak_"
+
+// ErrInvalidAPIKey is returned for unknown, revoked and expired API keys
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// apiKeyResources are the resources API keys may be granted. Keys only ever read.
+var apiKeyResources = map[Resource]bool{
+	ResourceEnterprises: true,
+	ResourceMenus:       true,
+	ResourceFloorPlans:  true,
+	ResourceSuites:      true,
+	ResourceCarousels:   true,
+	ResourcePins:        true,
+	ResourceFiles:       true,
+}
+
+// Scope names the permission to perform action on resource, such as "menus:read"
+func Scope(resource Resource, action Action) string {
+	return string(resource) + ":" + string(action)
+}
+
+// ParseAPIKeyScopes validates the scopes requested for an API key, dropping duplicates
+func ParseAPIKeyScopes(scopes []string) (entities.APIKeyScopes, error) {
+	parsed := make(entities.APIKeyScopes, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		resource, action, _ := strings.Cut(scope, ":")
+		if !apiKeyResources[Resource(resource)] || Action(action) != ActionRead {
+			return nil, fmt.Errorf("invalid scope %q", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			parsed = append(parsed, scope)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return parsed, nil
+}
+
+// APIKeyAllows reports whether an API key with the given scopes may perform the action
+// on the resource
+func APIKeyAllows(scopes entities.APIKeyScopes, resource Resource, action Action) bool {
+	want := Scope(resource, action)
+	for _, scope := range scopes {
+		if scope == want {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeys authenticates and rate limits API keys
+type APIKeys struct {
+	repo             interfaces.APIKeyRepository
+	store            cache.Store
+	defaultRateLimit int
+	// lastUsed writes the last use of a key at most once a minute
+	lastUsed *Throttle
+}
+
+// NewAPIKeys creates the API key authenticator. Keys without a rate limit of their own
+// get defaultRateLimit requests per minute.
+func NewAPIKeys(repo interfaces.APIKeyRepository, store cache.Store, defaultRateLimit int) *APIKeys {
+	return &APIKeys{
+		repo:             repo,
+		store:            store,
+		defaultRateLimit: defaultRateLimit,
+		lastUsed:         NewThrottle(store, "api_key_last_used", time.Minute),
+	}
+}
+
+// Generate creates a new key, returning it for the client together with the prefix and
+// hash to store
+func (k *APIKeys) Generate() (key, prefix, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, key[:len(APIKeyPrefix)+8], hashToken(key), nil
+}
+
+// Authenticate gets the active key matching the secret sent by a client, recording its
+// use from ip
+func (k *APIKeys) Authenticate(ctx context.Context, secret, ip string) (*entities.APIKey, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := k.repo.GetByHash(ctx, hashToken(secret))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Last use is bookkeeping, a failure to record it does not fail the request
+	if touch, _, err := k.lastUsed.Allow(ctx, key.ID.String()); err == nil && touch {
+		if err := k.repo.TouchLastUsed(ctx, key.ID, now, ip); err != nil {
+			slog.WarnContext(ctx, "Failed to record API key use", "error", err)
+		}
+	}
+	return key, nil
+}
+
+// Allow counts a request of the key against its per minute rate limit. When the limit
+// is reached it reports false and how long until the next minute starts.
+func (k *APIKeys) Allow(ctx context.Context, key *entities.APIKey) (bool, time.Duration, error) {
+	limit := key.RateLimit
+	if limit <= 0 {
+		limit = k.defaultRateLimit
+	}
+	if limit <= 0 {
+		return true, 0, nil
+	}
+
+	now := time.Now()
+	window := now.Truncate(time.Minute)
+	counterKey := "auth:api_key:rate:" + key.ID.String() + ":" + strconv.FormatInt(window.Unix(), 10)
+	count, err := k.store.Incr(ctx, counterKey, time.Minute)
+	if err != nil {
+		return false, 0, err
+	}
+	if count > int64(limit) {
+		return false, window.Add(time.Minute).Sub(now), nil
+	}
+	return true, 0, nil
+}
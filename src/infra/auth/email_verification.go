@@ -0,0 +1,24 @@
+package auth
+
+import "fmt"
+
+// VerificationPolicy decides what users may do before verifying their email address
+type VerificationPolicy string
+
+const (
+	// VerificationOptional lets unverified users do everything their role allows
+	VerificationOptional VerificationPolicy = "optional"
+	// VerificationLogin keeps unverified users from logging in
+	VerificationLogin VerificationPolicy = "login"
+	// VerificationWrite lets unverified users log in and read, but not write
+	VerificationWrite VerificationPolicy = "write"
+)
+
+// ParseVerificationPolicy parses the EMAIL_VERIFICATION_POLICY setting
+func ParseVerificationPolicy(value string) (VerificationPolicy, error) {
+	switch policy := VerificationPolicy(value); policy {
+	case VerificationOptional, VerificationLogin, VerificationWrite:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown email verification policy %q", value)
+}
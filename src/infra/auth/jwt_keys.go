@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing tokens
+const minRSAKeyBits = 2048
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// JWTKey is a key tokens are signed or verified with
+type JWTKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	// signKey is nil for keys that only verify tokens
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// JWTKeySet holds the key new tokens are signed with and every key tokens are still
+// accepted from.
+//
+// Asymmetric keys are loaded from a directory of PEM files named after their key ID,
+// "<kid>.pem". A private key (PKCS#8 RSA or Ed25519, or PKCS#1 RSA) can sign, a public
+// key (PKIX) only verifies. Every key is published in the JWKS, so other services can
+// verify tokens without holding a signing secret.
+//
+// Rotating the signing key without logging anybody out:
+//  1. Add the new private key to the directory and restart. It is published but does
+//     not sign yet, giving verifiers caching the JWKS time to pick it up.
+//  2. Point the signing key ID to the new key and restart. New tokens carry its kid,
+//     tokens signed with the old key stay valid.
+//  3. Once the refresh token lifetime has passed, remove the old key, or replace it with
+//     its public key until then to keep it off the signing path.
+type JWTKeySet struct {
+	signing *JWTKey
+	keys    map[string]*JWTKey
+}
+
+// NewHMACKeySet creates a key set signing and verifying HS256 tokens with a shared
+// secret. Tokens carry no kid and nothing is published in the JWKS.
+func NewHMACKeySet(secret string) *JWTKeySet {
+	key := &JWTKey{
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	return &JWTKeySet{signing: key, keys: map[string]*JWTKey{"": key}}
+}
+
+// LoadJWTKeySet loads the asymmetric keys in dir. Tokens are signed with the private key
+// signingKeyID, which may be empty when the directory holds a single private key.
+func LoadJWTKeySet(dir, signingKeyID string) (*JWTKeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JWT keys: %w", err)
+	}
+	sort.Strings(paths)
+
+	set := &JWTKeySet{keys: make(map[string]*JWTKey, len(paths))}
+	var private []string
+	for _, path := range paths {
+		key, err := loadJWTKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate JWT key ID %q", key.ID)
+		}
+		set.keys[key.ID] = key
+		if key.signKey != nil {
+			private = append(private, key.ID)
+		}
+	}
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("no JWT keys found in %s", dir)
+	}
+
+	if signingKeyID == "" {
+		if len(private) != 1 {
+			return nil, fmt.Errorf("%d private JWT keys found in %s, the signing key ID must name one", len(private), dir)
+		}
+		signingKeyID = private[0]
+	}
+	signing, ok := set.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing JWT key %q not found in %s", signingKeyID, dir)
+	}
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("signing JWT key %q is a public key", signingKeyID)
+	}
+	set.signing = signing
+	return set, nil
+}
+
+// SigningKeyID returns the kid of the key new tokens are signed with, empty for a shared
+// secret
+func (s *JWTKeySet) SigningKeyID() string {
+	return s.signing.ID
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (s *JWTKeySet) Algorithm() string {
+	return s.signing.Method.Alg()
+}
+
+// KeyIDs returns the kids tokens are accepted from, sorted
+func (s *JWTKeySet) KeyIDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sign creates a token with claims signed by the signing key
+func (s *JWTKeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.ID != "" {
+		token.Header["kid"] = s.signing.ID
+	}
+	return token.SignedString(s.signing.signKey)
+}
+
+// keyFunc finds the key a token was signed with by its kid, rejecting tokens whose
+// algorithm does not match the key
+func (s *JWTKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys, and EC keys of identity providers with Y
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys tokens are accepted from. A shared secret is never
+// published, the set is empty then.
+func (s *JWTKeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range s.KeyIDs() {
+		key := s.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// loadJWTKey reads a PEM key file, its name without the extension being the kid
+func loadJWTKey(path string) (*JWTKey, error) {
+	id := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+	if !keyIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid JWT key ID %q, use letters, digits, dots, dashes and underscores", id)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %s is not PEM encoded", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("JWT key %s has unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key %s: %w", path, err)
+	}
+
+	key, err := newJWTKey(id, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func newJWTKey(id string, parsed interface{}) (*JWTKey, error) {
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys need at least %d bits", minRSAKeyBits)
+		}
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys need at least %d bits", minRSAKeyBits)
+		}
+		return &JWTKey{ID: id, Method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case ed25519.PrivateKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &JWTKey{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: k}, nil
+	default:
+		return nil, errors.New("unsupported key type, use RSA or Ed25519")
+	}
+}
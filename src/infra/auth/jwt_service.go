@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,9 +12,10 @@ import (
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	secretKey             string
+	keys                  *JWTKeySet
 	accessTokenDuration   time.Duration
 	refreshTokenDuration  time.Duration
+	blacklist             TokenBlacklist
 }
 
 // TokenPair represents access and refresh tokens
@@ -23,6 +25,7 @@ type TokenPair struct {
 	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
 	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 	TokenType             string    `json:"token_type"`
+	RefreshTokenID        string    `json:"-"`
 }
 
 // Claims represents JWT token claims
@@ -32,6 +35,11 @@ type Claims struct {
 	Role         entities.UserRole  `json:"role"`
 	EnterpriseID uuid.UUID          `json:"enterprise_id"`
 	TokenType    string             `json:"token_type"` // "access" or "refresh"
+	Generation   int64              `json:"gen"`        // Token generation of the user, see TokenBlacklist
+	SessionID    uuid.UUID          `json:"sid"`        // Session the token was issued to
+	// MFASetupRequired restricts the token to setting up two-factor authentication,
+	// required for the role of the user by their enterprise
+	MFASetupRequired bool `json:"mfa_setup,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -40,31 +48,49 @@ type RefreshTokenClaims struct {
 	UserID    uuid.UUID `json:"user_id"`
 	TokenID   string    `json:"token_id"` // Unique token identifier
 	TokenType string    `json:"token_type"`
+	Generation int64    `json:"gen"`
+	SessionID  uuid.UUID `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// NewJWTService creates a new JWT service
-func NewJWTService(secretKey string, accessTokenHours, refreshTokenHours int) *JWTService {
+// NewJWTService creates a new JWT service signing and verifying tokens with keys
+func NewJWTService(keys *JWTKeySet, accessTokenTTL, refreshTokenTTL time.Duration) *JWTService {
 	return &JWTService{
-		secretKey:             secretKey,
-		accessTokenDuration:   time.Duration(accessTokenHours) * time.Hour,
-		refreshTokenDuration:  time.Duration(refreshTokenHours) * time.Hour,
+		keys:                  keys,
+		accessTokenDuration:   accessTokenTTL,
+		refreshTokenDuration:  refreshTokenTTL,
 	}
 }
 
-// GenerateTokenPair creates both access and refresh tokens
-func (j *JWTService) GenerateTokenPair(user *entities.User) (*TokenPair, error) {
+// SetBlacklist enables token revocation. Without a blacklist tokens stay valid until
+// they expire.
+func (j *JWTService) SetBlacklist(blacklist TokenBlacklist) {
+	j.blacklist = blacklist
+}
+
+// GenerateTokenPair creates both access and refresh tokens for a session of the user.
+// With mfaSetupRequired the access token only allows setting up two-factor
+// authentication.
+func (j *JWTService) GenerateTokenPair(ctx context.Context, user *entities.User, sessionID uuid.UUID, mfaSetupRequired bool) (*TokenPair, error) {
 	now := time.Now()
 	tokenID := uuid.New().String()
 
+	var generation int64
+	if j.blacklist != nil {
+		var err error
+		if generation, err = j.blacklist.TokenGeneration(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to get token generation: %w", err)
+		}
+	}
+
 	// Generate access token
-	accessToken, accessExpiresAt, err := j.generateAccessToken(user, now)
+	accessToken, accessExpiresAt, err := j.generateAccessToken(user, sessionID, generation, mfaSetupRequired, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, refreshExpiresAt, err := j.generateRefreshToken(user.ID, tokenID, now)
+	refreshToken, refreshExpiresAt, err := j.generateRefreshToken(user.ID, sessionID, tokenID, generation, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -75,19 +101,24 @@ func (j *JWTService) GenerateTokenPair(user *entities.User) (*TokenPair, error)
 		AccessTokenExpiresAt:  accessExpiresAt,
 		RefreshTokenExpiresAt: refreshExpiresAt,
 		TokenType:             "Bearer",
+		RefreshTokenID:        tokenID,
 	}, nil
 }
 
 // generateAccessToken creates a new access token
-func (j *JWTService) generateAccessToken(user *entities.User, now time.Time) (string, time.Time, error) {
+func (j *JWTService) generateAccessToken(user *entities.User, sessionID uuid.UUID, generation int64, mfaSetupRequired bool, now time.Time) (string, time.Time, error) {
 	expiresAt := now.Add(j.accessTokenDuration)
 
 	claims := &Claims{
-		UserID:    user.ID,
-		Email:     user.Email,
-		Role:      user.Role,
-		TokenType: "access",
+		UserID:           user.ID,
+		Email:            user.Email,
+		Role:             user.Role,
+		TokenType:        "access",
+		Generation:       generation,
+		SessionID:        sessionID,
+		MFASetupRequired: mfaSetupRequired,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -99,8 +130,7 @@ func (j *JWTService) generateAccessToken(user *entities.User, now time.Time) (st
 	// Add enterprise ID
 	claims.EnterpriseID = user.EnterpriseID
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
+	tokenString, err := j.keys.sign(claims)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -109,14 +139,17 @@ func (j *JWTService) generateAccessToken(user *entities.User, now time.Time) (st
 }
 
 // generateRefreshToken creates a new refresh token
-func (j *JWTService) generateRefreshToken(userID uuid.UUID, tokenID string, now time.Time) (string, time.Time, error) {
+func (j *JWTService) generateRefreshToken(userID, sessionID uuid.UUID, tokenID string, generation int64, now time.Time) (string, time.Time, error) {
 	expiresAt := now.Add(j.refreshTokenDuration)
 
 	claims := &RefreshTokenClaims{
-		UserID:    userID,
-		TokenID:   tokenID,
-		TokenType: "refresh",
+		UserID:     userID,
+		TokenID:    tokenID,
+		TokenType:  "refresh",
+		Generation: generation,
+		SessionID:  sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -125,8 +158,7 @@ func (j *JWTService) generateRefreshToken(userID uuid.UUID, tokenID string, now
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
+	tokenString, err := j.keys.sign(claims)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -136,12 +168,7 @@ func (j *JWTService) generateRefreshToken(userID uuid.UUID, tokenID string, now
 
 // ValidateAccessToken validates and parses an access token
 func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keys.keyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -165,12 +192,7 @@ func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
 
 // ValidateRefreshToken validates and parses a refresh token
 func (j *JWTService) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, j.keys.keyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
@@ -192,27 +214,9 @@ func (j *JWTService) ValidateRefreshToken(tokenString string) (*RefreshTokenClai
 	return claims, nil
 }
 
-// RefreshAccessToken generates a new access token using a valid refresh token
-func (j *JWTService) RefreshAccessToken(refreshTokenString string, getUserByID func(uuid.UUID) (*entities.User, error)) (*TokenPair, error) {
-	// Validate refresh token
-	refreshClaims, err := j.ValidateRefreshToken(refreshTokenString)
-	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
-	}
-
-	// Get user data
-	user, err := getUserByID(refreshClaims.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
-
-	// Generate new token pair
-	tokenPair, err := j.GenerateTokenPair(user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
-	}
-
-	return tokenPair, nil
+// JWKS returns the public keys tokens are verified with
+func (j *JWTService) JWKS() JWKS {
+	return j.keys.JWKS()
 }
 
 // ExtractTokenFromAuthHeader extracts token from Authorization header
@@ -229,31 +233,68 @@ func ExtractTokenFromAuthHeader(authHeader string) (string, error) {
 	return authHeader[len(bearerPrefix):], nil
 }
 
-// TokenBlacklist interface for managing revoked tokens
-type TokenBlacklist interface {
-	AddToken(tokenID string, expiresAt time.Time) error
-	IsTokenBlacklisted(tokenID string) (bool, error)
-	CleanupExpiredTokens() error
-}
+// RevokeToken adds an access or refresh token to the blacklist until it expires
+func (j *JWTService) RevokeToken(ctx context.Context, tokenString string) error {
+	if j.blacklist == nil {
+		return nil
+	}
 
-// RevokeToken adds a token to the blacklist
-func (j *JWTService) RevokeToken(tokenString string, blacklist TokenBlacklist) error {
 	// Parse token to get claims without validation (token might be expired)
-	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
-	if err != nil {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
 		return fmt.Errorf("failed to parse token: %w", err)
 	}
-
-	claims, ok := token.Claims.(*Claims)
-	if !ok {
-		return fmt.Errorf("invalid token claims")
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("token has no ID or expiration")
 	}
 
 	// Add to blacklist with expiration time
-	return blacklist.AddToken(claims.ID, claims.ExpiresAt.Time)
+	return j.blacklist.AddToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// RevokeSession revokes every token issued to a session until the session expires
+func (j *JWTService) RevokeSession(ctx context.Context, sessionID uuid.UUID, expiresAt time.Time) error {
+	if j.blacklist == nil {
+		return nil
+	}
+	return j.blacklist.AddToken(ctx, sessionID.String(), expiresAt)
+}
+
+// RevokeAllTokens revokes every token issued to the user so far
+func (j *JWTService) RevokeAllTokens(ctx context.Context, userID uuid.UUID) error {
+	if j.blacklist == nil {
+		return nil
+	}
+	_, err := j.blacklist.IncrementTokenGeneration(ctx, userID)
+	return err
+}
+
+// IsTokenRevoked checks if a validated access token has been revoked, on its own, with
+// its session or by a logout everywhere
+func (j *JWTService) IsTokenRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	return j.isRevoked(ctx, claims.ID, claims.SessionID, claims.UserID, claims.Generation)
 }
 
-// IsTokenRevoked checks if a token is in the blacklist
-func (j *JWTService) IsTokenRevoked(tokenID string, blacklist TokenBlacklist) (bool, error) {
-	return blacklist.IsTokenBlacklisted(tokenID)
-}
\ No newline at end of file
+// IsRefreshTokenRevoked checks if a validated refresh token has been revoked
+func (j *JWTService) IsRefreshTokenRevoked(ctx context.Context, claims *RefreshTokenClaims) (bool, error) {
+	return j.isRevoked(ctx, claims.ID, claims.SessionID, claims.UserID, claims.Generation)
+}
+
+func (j *JWTService) isRevoked(ctx context.Context, tokenID string, sessionID, userID uuid.UUID, generation int64) (bool, error) {
+	if j.blacklist == nil {
+		return false, nil
+	}
+
+	current, err := j.blacklist.TokenGeneration(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if generation < current {
+		return true, nil
+	}
+
+	if revoked, err := j.blacklist.IsTokenBlacklisted(ctx, sessionID.String()); err != nil || revoked {
+		return revoked, err
+	}
+	return j.blacklist.IsTokenBlacklisted(ctx, tokenID)
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"terra-allwert/infra/cache"
+)
+
+// LockoutPolicy sets how failed logins slow down and lock out further attempts
+type LockoutPolicy struct {
+	// MaxAttempts failed logins lock an account, MaxIPAttempts lock a client address.
+	// Zero disables the lockout.
+	MaxAttempts   int
+	MaxIPAttempts int
+	// Backoff is the delay after the first failed login of an account, doubled for every
+	// further failure until the lockout
+	Backoff time.Duration
+	// Duration is both how long a lockout lasts and the window failures are counted in
+	Duration time.Duration
+}
+
+// LoginFailure describes what a failed login led to
+type LoginFailure struct {
+	// Attempts is the number of failures of the account in the current window
+	Attempts int
+	// Wait is how long until the next attempt is allowed
+	Wait time.Duration
+	// AccountLocked and IPLocked report a lockout started by this failure
+	AccountLocked bool
+	IPLocked      bool
+}
+
+// LoginLockout tracks failed logins by account and by client address, delaying and
+// eventually locking out further attempts
+type LoginLockout struct {
+	store  cache.Store
+	policy LockoutPolicy
+}
+
+// NewLoginLockout creates a login lockout enforcing policy
+func NewLoginLockout(store cache.Store, policy LockoutPolicy) *LoginLockout {
+	return &LoginLockout{store: store, policy: policy}
+}
+
+// Check returns how long until a login to the account from ip is allowed, zero when it
+// is allowed now
+func (l *LoginLockout) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	var wait time.Duration
+	for _, subject := range []string{accountSubject(email), ipSubject(ip)} {
+		until, err := l.lockedUntil(ctx, subject)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := time.Until(until); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// AccountLockedUntil returns when the lockout or backoff of the account ends, the zero
+// time when there is none
+func (l *LoginLockout) AccountLockedUntil(ctx context.Context, email string) (time.Time, error) {
+	return l.lockedUntil(ctx, accountSubject(email))
+}
+
+// Fail records a failed login to the account from ip. Unknown accounts are counted like
+// existing ones, so the responses do not reveal which exist.
+func (l *LoginLockout) Fail(ctx context.Context, email, ip string) (LoginFailure, error) {
+	var failure LoginFailure
+
+	attempts, err := l.store.Incr(ctx, failuresKey(accountSubject(email)), l.policy.Duration)
+	if err != nil {
+		return failure, err
+	}
+	failure.Attempts = int(attempts)
+	if l.policy.MaxAttempts > 0 && failure.Attempts >= l.policy.MaxAttempts {
+		failure.AccountLocked = true
+		failure.Wait = l.policy.Duration
+	} else {
+		failure.Wait = l.backoff(failure.Attempts)
+	}
+	if err := l.lock(ctx, accountSubject(email), failure.Wait, failure.AccountLocked); err != nil {
+		return failure, err
+	}
+
+	if ip == "" {
+		return failure, nil
+	}
+	ipAttempts, err := l.store.Incr(ctx, failuresKey(ipSubject(ip)), l.policy.Duration)
+	if err != nil {
+		return failure, err
+	}
+	// Addresses get no backoff, many users may share one behind a NAT
+	if l.policy.MaxIPAttempts > 0 && int(ipAttempts) >= l.policy.MaxIPAttempts {
+		failure.IPLocked = true
+		failure.Wait = l.policy.Duration
+		if err := l.lock(ctx, ipSubject(ip), l.policy.Duration, true); err != nil {
+			return failure, err
+		}
+	}
+	return failure, nil
+}
+
+// Reset clears the failures and lockout of the account, after a successful login or
+// when an admin unlocks it
+func (l *LoginLockout) Reset(ctx context.Context, email string) error {
+	subject := accountSubject(email)
+	return l.store.Delete(ctx, failuresKey(subject), lockKey(subject))
+}
+
+// backoff returns the delay after the given number of failures
+func (l *LoginLockout) backoff(attempts int) time.Duration {
+	if l.policy.Backoff <= 0 {
+		return 0
+	}
+	wait := l.policy.Backoff
+	for i := 1; i < attempts && wait < l.policy.Duration; i++ {
+		wait *= 2
+	}
+	if wait > l.policy.Duration {
+		wait = l.policy.Duration
+	}
+	return wait
+}
+
+// lock blocks logins of subject for wait. A lockout also restarts the failure count, so
+// after it ends the subject gets the full number of attempts again.
+func (l *LoginLockout) lock(ctx context.Context, subject string, wait time.Duration, lockout bool) error {
+	if lockout {
+		if err := l.store.Delete(ctx, failuresKey(subject)); err != nil {
+			return err
+		}
+	}
+	if wait <= 0 {
+		return nil
+	}
+	until := time.Now().Add(wait)
+	return l.store.Set(ctx, lockKey(subject), []byte(strconv.FormatInt(until.UnixMilli(), 10)), wait)
+}
+
+func (l *LoginLockout) lockedUntil(ctx context.Context, subject string) (time.Time, error) {
+	data, err := l.store.Get(ctx, lockKey(subject))
+	if errors.Is(err, cache.ErrNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	until, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(until), nil
+}
+
+// Accounts are keyed by a hash of the email, so the store does not keep emails around
+func accountSubject(email string) string {
+	return "account:" + hashToken(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+func failuresKey(subject string) string {
+	return "auth:login:failures:" + subject
+}
+
+func lockKey(subject string) string {
+	return "auth:login:lock:" + subject
+}
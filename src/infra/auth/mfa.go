@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/infra/cache"
+)
+
+// TOTP parameters, the defaults of every authenticator app (RFC 6238)
+const (
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	// totpSkew accepts codes one period before or after the current one, for clock drift
+	totpSkew = 1
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	maxChallengeAttempts = 5
+)
+
+// ErrInvalidChallenge is returned for unknown, expired, completed and exhausted MFA
+// challenges
+var ErrInvalidChallenge = errors.New("invalid or expired MFA challenge")
+
+// MFAService verifies second factors: TOTP codes, each accepted once, and the login
+// challenges issued between the password and the code
+type MFAService struct {
+	store        cache.Store
+	issuer       string
+	challengeTTL time.Duration
+}
+
+// NewMFAService creates an MFA service naming the accounts after issuer in
+// authenticator apps, with login challenges valid for challengeTTL
+func NewMFAService(store cache.Store, issuer string, challengeTTL time.Duration) *MFAService {
+	return &MFAService{store: store, issuer: issuer, challengeTTL: challengeTTL}
+}
+
+// NewSecret generates a TOTP secret, base32 encoded as authenticator apps expect it
+func (m *MFAService) NewSecret() (string, error) {
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// URI builds the otpauth URI authenticator apps enroll from, usually through a QR code
+func (m *MFAService) URI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", m.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(m.issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// VerifyCode checks a TOTP code of the user. A code is accepted once, so a code seen
+// over the shoulder cannot be replayed.
+func (m *MFAService) VerifyCode(ctx context.Context, userID uuid.UUID, secret, code string) (bool, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return false, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return false, nil
+	}
+
+	counter := time.Now().Unix() / int64(totpPeriod.Seconds())
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := counter + offset
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, uint64(step))), []byte(code)) != 1 {
+			continue
+		}
+		usedKey := "auth:totp:used:" + userID.String() + ":" + strconv.FormatInt(step, 10)
+		return m.store.SetNX(ctx, usedKey, []byte("1"), (2*totpSkew+1)*totpPeriod)
+	}
+	return false, nil
+}
+
+// totpCode computes the code of a time step (RFC 4226 dynamic truncation)
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// NewRecoveryCodes generates a fresh set of recovery codes, returning them for the user
+// and their hashes for storage
+func NewRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		var code strings.Builder
+		for j, b := range raw {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes = append(codes, code.String())
+		hashes = append(hashes, HashRecoveryCode(code.String()))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code as typed by the user, ignoring case, spaces
+// and dashes
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	return hashToken(normalized)
+}
+
+// IssueChallenge starts a login challenge for a user who passed the password check
+func (m *MFAService) IssueChallenge(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := time.Now().Add(m.challengeTTL)
+	value := fmt.Sprintf("%s|%d", userID, expiresAt.Unix())
+	if err := m.store.Set(ctx, challengeKey(token), []byte(value), m.challengeTTL); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// AttemptChallenge counts an attempt to answer a live challenge and returns the user it
+// was issued to. Attempts are counted atomically before the code is checked, so codes
+// guessed in parallel cannot exceed maxChallengeAttempts.
+func (m *MFAService) AttemptChallenge(ctx context.Context, token string) (uuid.UUID, error) {
+	userID, expiresAt, err := m.loadChallenge(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return uuid.Nil, m.endChallenge(ctx, token)
+	}
+	attempts, err := m.store.Incr(ctx, challengeAttemptsKey(token), ttl)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if attempts > maxChallengeAttempts {
+		return uuid.Nil, m.endChallenge(ctx, token)
+	}
+	return userID, nil
+}
+
+// FailChallenge records that the attempt answered a wrong code, ending the challenge once
+// its attempts are used up
+func (m *MFAService) FailChallenge(ctx context.Context, token string) error {
+	data, err := m.store.Get(ctx, challengeAttemptsKey(token))
+	if errors.Is(err, cache.ErrNotFound) {
+		return ErrInvalidChallenge
+	}
+	if err != nil {
+		return err
+	}
+	if attempts, _ := strconv.Atoi(string(data)); attempts >= maxChallengeAttempts {
+		return m.endChallenge(ctx, token)
+	}
+	return nil
+}
+
+// CompleteChallenge ends a challenge answered correctly. Of two concurrent answers only
+// one completes it.
+func (m *MFAService) CompleteChallenge(ctx context.Context, token string) error {
+	_, err := m.store.GetDelete(ctx, challengeKey(token))
+	if errors.Is(err, cache.ErrNotFound) {
+		return ErrInvalidChallenge
+	}
+	if err != nil {
+		return err
+	}
+	return m.store.Delete(ctx, challengeAttemptsKey(token))
+}
+
+// endChallenge removes a challenge and its attempts, returning ErrInvalidChallenge
+func (m *MFAService) endChallenge(ctx context.Context, token string) error {
+	if err := m.store.Delete(ctx, challengeKey(token), challengeAttemptsKey(token)); err != nil {
+		return err
+	}
+	return ErrInvalidChallenge
+}
+
+func (m *MFAService) loadChallenge(ctx context.Context, token string) (uuid.UUID, time.Time, error) {
+	data, err := m.store.Get(ctx, challengeKey(token))
+	if errors.Is(err, cache.ErrNotFound) {
+		return uuid.Nil, time.Time{}, ErrInvalidChallenge
+	}
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	parts := strings.Split(string(data), "|")
+	if len(parts) != 2 {
+		return uuid.Nil, time.Time{}, ErrInvalidChallenge
+	}
+	userID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, time.Time{}, ErrInvalidChallenge
+	}
+	expiresUnix, _ := strconv.ParseInt(parts[1], 10, 64)
+	return userID, time.Unix(expiresUnix, 0), nil
+}
+
+func challengeKey(token string) string {
+	return "auth:mfa_challenge:" + hashToken(token)
+}
+
+func challengeAttemptsKey(token string) string {
+	return "auth:mfa_challenge_attempts:" + hashToken(token)
+}
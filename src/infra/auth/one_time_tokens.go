@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/infra/cache"
+)
+
+// ErrInvalidOneTimeToken is returned for unknown, expired and already used tokens
+var ErrInvalidOneTimeToken = errors.New("invalid or expired token")
+
+// OneTimeTokens issues random single use tokens bound to a user, such as password reset
+// links. Only a hash of each token is stored, so a leaked store does not leak usable
+// tokens, and issuing a new token for a user invalidates the previous one.
+type OneTimeTokens struct {
+	store   cache.Store
+	purpose string
+	ttl     time.Duration
+}
+
+// NewOneTimeTokens creates a token issuer for purpose, with tokens valid for ttl
+func NewOneTimeTokens(store cache.Store, purpose string, ttl time.Duration) *OneTimeTokens {
+	return &OneTimeTokens{store: store, purpose: purpose, ttl: ttl}
+}
+
+// TTL returns how long issued tokens stay valid
+func (t *OneTimeTokens) TTL() time.Duration {
+	return t.ttl
+}
+
+// Issue creates a new token for the user
+func (t *OneTimeTokens) Issue(ctx context.Context, userID uuid.UUID) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	// Drop the token issued before, if any
+	if previous, err := t.store.GetDelete(ctx, t.userKey(userID)); err == nil {
+		if err := t.store.Delete(ctx, t.tokenKey(string(previous))); err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		return "", err
+	}
+
+	hash := hashToken(token)
+	if err := t.store.Set(ctx, t.tokenKey(hash), []byte(userID.String()), t.ttl); err != nil {
+		return "", err
+	}
+	if err := t.store.Set(ctx, t.userKey(userID), []byte(hash), t.ttl); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Consume redeems a token, returning the user it was issued to. A token can be
+// consumed once.
+func (t *OneTimeTokens) Consume(ctx context.Context, token string) (uuid.UUID, error) {
+	data, err := t.store.GetDelete(ctx, t.tokenKey(hashToken(token)))
+	if errors.Is(err, cache.ErrNotFound) {
+		return uuid.Nil, ErrInvalidOneTimeToken
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	userID, err := uuid.Parse(string(data))
+	if err != nil {
+		return uuid.Nil, ErrInvalidOneTimeToken
+	}
+	if err := t.store.Delete(ctx, t.userKey(userID)); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+func (t *OneTimeTokens) tokenKey(hash string) string {
+	return "auth:" + t.purpose + ":token:" + hash
+}
+
+func (t *OneTimeTokens) userKey(userID uuid.UUID) string {
+	return "auth:" + t.purpose + ":user:" + userID.String()
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
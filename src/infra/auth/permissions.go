@@ -0,0 +1,106 @@
+package auth
+
+import "terra-allwert/domain/entities"
+
+// Resource is a group of API routes sharing the same permissions
+type Resource string
+
+const (
+	ResourceEnterprises Resource = "enterprises"
+	ResourceUsers       Resource = "users"
+	ResourceMenus       Resource = "menus"
+	ResourceFloorPlans  Resource = "floor_plans"
+	ResourceSuites      Resource = "suites"
+	ResourceCarousels   Resource = "carousels"
+	ResourcePins        Resource = "pins"
+	ResourceFiles       Resource = "files"
+	ResourceAuditLogs   Resource = "audit_logs"
+	ResourceTrash       Resource = "trash"
+	ResourceSeeds       Resource = "seeds"
+	ResourceAPIKeys     Resource = "api_keys"
+)
+
+// Action is what a route does with its resource
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var (
+	everyone = []entities.UserRole{entities.UserRoleVisitor, entities.UserRoleManager, entities.UserRoleAdmin}
+	editors  = []entities.UserRole{entities.UserRoleManager, entities.UserRoleAdmin}
+	admins   = []entities.UserRole{entities.UserRoleAdmin}
+)
+
+// contentPermissions lets every role read the content of its enterprise and managers edit it
+var contentPermissions = map[Action][]entities.UserRole{
+	ActionRead:   everyone,
+	ActionCreate: editors,
+	ActionUpdate: editors,
+	ActionDelete: editors,
+}
+
+// permissions lists the roles allowed to perform each action. Super admins are allowed
+// everything and are the only ones allowed the actions missing here. Tenant scoping keeps
+// every other role inside its own enterprise.
+var permissions = map[Resource]map[Action][]entities.UserRole{
+	ResourceEnterprises: {
+		ActionRead:   everyone,
+		ActionUpdate: admins,
+	},
+	ResourceUsers: {
+		ActionRead:   admins,
+		ActionCreate: admins,
+		ActionUpdate: admins,
+		ActionDelete: admins,
+	},
+	ResourceMenus:      contentPermissions,
+	ResourceFloorPlans: contentPermissions,
+	ResourceSuites:     contentPermissions,
+	ResourceCarousels:  contentPermissions,
+	ResourcePins:       contentPermissions,
+	ResourceFiles:      contentPermissions,
+	ResourceAuditLogs: {
+		ActionRead: admins,
+	},
+	ResourceAPIKeys: {
+		ActionRead:   admins,
+		ActionCreate: admins,
+		ActionDelete: admins,
+	},
+	// Restoring is an update of the deleted item, purging deletes it for good
+	ResourceTrash: {
+		ActionRead:   editors,
+		ActionUpdate: editors,
+		ActionDelete: admins,
+	},
+}
+
+// Can reports whether the role may perform the action on the resource
+func Can(role entities.UserRole, resource Resource, action Action) bool {
+	if role == entities.UserRoleSuperAdmin {
+		return true
+	}
+	for _, allowed := range permissions[resource][action] {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether the role may create users with, or assign, the granted role.
+// Visitors may register themselves, elevated roles are handed out by admins.
+func CanGrant(role, granted entities.UserRole) bool {
+	switch granted {
+	case entities.UserRoleVisitor:
+		return true
+	case entities.UserRoleManager, entities.UserRoleAdmin:
+		return Can(role, ResourceUsers, ActionCreate)
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"terra-allwert/infra/cache"
+)
+
+// Throttle allows an action once per interval for each key, such as resending an email
+// to the same address
+type Throttle struct {
+	store    cache.Store
+	name     string
+	interval time.Duration
+}
+
+// NewThrottle creates a throttle named name allowing one action per interval
+func NewThrottle(store cache.Store, name string, interval time.Duration) *Throttle {
+	return &Throttle{store: store, name: name, interval: interval}
+}
+
+// Allow reports whether the action may run for key now and, when it may not, how long
+// until it can. An allowed action starts a new interval.
+func (t *Throttle) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if t.interval <= 0 {
+		return true, 0, nil
+	}
+
+	// Keys are hashed so the store does not keep emails around
+	storeKey := "auth:" + t.name + ":throttle:" + hashToken(strings.ToLower(strings.TrimSpace(key)))
+	next := time.Now().Add(t.interval)
+	allowed, err := t.store.SetNX(ctx, storeKey, []byte(strconv.FormatInt(next.Unix(), 10)), t.interval)
+	if err != nil || allowed {
+		return allowed, 0, err
+	}
+
+	data, err := t.store.Get(ctx, storeKey)
+	if errors.Is(err, cache.ErrNotFound) {
+		// Expired in between, the next attempt goes through
+		return false, time.Second, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	until, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return false, t.interval, nil
+	}
+	wait := time.Until(time.Unix(until, 0))
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return false, wait, nil
+}
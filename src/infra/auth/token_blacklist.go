@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"terra-allwert/infra/cache"
+)
+
+const (
+	revokedTokenPrefix    = "auth:revoked:"
+	tokenGenerationPrefix = "auth:generation:"
+)
+
+// TokenBlacklist interface for managing revoked tokens
+type TokenBlacklist interface {
+	// AddToken revokes a single token until it expires
+	AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error)
+	// TokenGeneration returns the generation new tokens of the user are issued with.
+	// Tokens carrying an older generation are revoked.
+	TokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error)
+	// IncrementTokenGeneration revokes every token issued so far to the user
+	IncrementTokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// StoreTokenBlacklist implements TokenBlacklist on a cache.Store: Redis in production,
+// memory with the in-memory driver. Revoked tokens expire from the store together with
+// the tokens themselves.
+type StoreTokenBlacklist struct {
+	store cache.Store
+}
+
+// NewTokenBlacklist creates a token blacklist kept in store
+func NewTokenBlacklist(store cache.Store) *StoreTokenBlacklist {
+	return &StoreTokenBlacklist{store: store}
+}
+
+// AddToken adds a token to the blacklist until expiresAt
+func (b *StoreTokenBlacklist) AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Expired tokens are rejected anyway
+		return nil
+	}
+	return b.store.Set(ctx, revokedTokenPrefix+tokenID, []byte("1"), ttl)
+}
+
+// IsTokenBlacklisted checks if a token is in the blacklist
+func (b *StoreTokenBlacklist) IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error) {
+	_, err := b.store.Get(ctx, revokedTokenPrefix+tokenID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// TokenGeneration gets the current token generation of the user, zero until the user
+// first logs out everywhere
+func (b *StoreTokenBlacklist) TokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error) {
+	data, err := b.store.Get(ctx, tokenGenerationPrefix+userID.String())
+	if errors.Is(err, cache.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// IncrementTokenGeneration bumps the token generation of the user atomically. The counter
+// never expires, or tokens of earlier generations would become valid again after a reset.
+func (b *StoreTokenBlacklist) IncrementTokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return b.store.Incr(ctx, tokenGenerationPrefix+userID.String(), 0)
+}
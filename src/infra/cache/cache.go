@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist or has expired
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store is a minimal key/value store with expiration, backed by Redis or memory
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetDelete gets the value stored under key and removes it atomically
+	GetDelete(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value under key unless the key exists, reporting whether it did
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Incr increments the counter under key, returning its new value. A new counter
+	// expires after ttl; incrementing does not extend it.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Delete(ctx context.Context, keys ...string) error
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
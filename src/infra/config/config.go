@@ -4,48 +4,186 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// DriverMemory selects the in-memory implementations for DB_DRIVER and STORAGE_DRIVER,
+// letting the API run without Postgres, Redis or MinIO
+const DriverMemory = "memory"
+
+// DefaultJWTSecret is the JWT_SECRET used when none is configured, refused in production
+const DefaultJWTSecret = "dev-secret-key"
+
+// Config holds every setting of the API. Each field is read from the environment
+// variable in its env tag, falling back to the optional YAML file and then to the
+// default tag. Fields tagged secret are redacted when the configuration is printed,
+// min sets the smallest value accepted for numbers.
 type Config struct {
-	// Server
-	Port        string
-	Environment string
+	// Server, given ShutdownTimeout to drain requests and stop workers on SIGTERM. It keeps
+	// serving for ShutdownDrainDelay of that time with /readyz failing, about one probe
+	// period of the load balancer, before it stops accepting connections.
+	Port               string        `env:"PORT" default:"3000"`
+	Environment        string        `env:"ENVIRONMENT" default:"development"`
+	ShutdownTimeout    time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+
+	// Logging, records are JSON unless LOG_FORMAT is text
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`
+	LogFormat string `env:"LOG_FORMAT" default:"json"`
+
+	// Tracing, spans are exported over OTLP to OTEL_EXPORTER_OTLP_ENDPOINT, written to
+	// stdout, or not recorded with none
+	OTelTracesExporter  string `env:"OTEL_TRACES_EXPORTER" default:"none"`
+	OTelServiceName     string `env:"OTEL_SERVICE_NAME" default:"terra-allwert-api"`
+	OTelEndpoint        string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://localhost:4318"`
+	OTelEndpointHeaders string `env:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"`
+
+	// Metrics, served to Prometheus at /metrics on their own port, kept off the API
+	PrometheusEnabled bool   `env:"PROMETHEUS_ENABLED" default:"true"`
+	PrometheusPort    string `env:"PROMETHEUS_PORT" default:"9090"`
+
+	// Health checks of /readyz, each failed after HEALTH_CHECK_TIMEOUT, their results
+	// reused for HEALTH_CACHE_TTL
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	HealthCacheTTL     time.Duration `env:"HEALTH_CACHE_TTL" default:"5s"`
+
+	// CORS, credentials cannot be allowed for every origin
+	CORSAllowedOrigins   []string `env:"CORS_ALLOWED_ORIGINS" default:"*"`
+	CORSAllowCredentials bool     `env:"CORS_ALLOW_CREDENTIALS" default:"false"`
 
 	// Database
-	DBDriver   string
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
+	DBDriver          string        `env:"DB_DRIVER" default:"postgres"`
+	DBHost            string        `env:"DB_HOST" default:"localhost"`
+	DBPort            string        `env:"DB_PORT" default:"5432"`
+	DBUser            string        `env:"DB_USER" default:"postgres"`
+	DBPassword        string        `env:"DB_PASSWORD" default:"postgres" secret:"true"`
+	DBName            string        `env:"DB_NAME" default:"terraallwert"`
+	DBSSLMode         string        `env:"DB_SSLMODE" default:"disable"`
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"30" min:"1"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"10" min:"0"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"1h"`
+
+	// Storage
+	StorageDriver string `env:"STORAGE_DRIVER" default:"minio"`
 
 	// MinIO
-	MinIOEndpoint   string
-	MinIOAccessKey  string
-	MinIOSecretKey  string
-	MinIOUseSSL     bool
-	MinIOBucket     string
-
-	// Redis
-	RedisHost     string
-	RedisPort     string
-	RedisPassword string
-	RedisDB       int
+	MinIOEndpoint  string `env:"MINIO_ENDPOINT" default:"localhost:9000"`
+	MinIOAccessKey string `env:"MINIO_ACCESS_KEY" default:"minioadmin"`
+	MinIOSecretKey string `env:"MINIO_SECRET_KEY" default:"minioadmin" secret:"true"`
+	MinIOUseSSL    bool   `env:"MINIO_USE_SSL" default:"false"`
+	MinIOBucket    string `env:"MINIO_BUCKET" default:"terraallwert"`
+
+	// Redis, a pool size of zero keeps the go-redis default
+	RedisHost         string `env:"REDIS_HOST" default:"localhost"`
+	RedisPort         string `env:"REDIS_PORT" default:"6379"`
+	RedisPassword     string `env:"REDIS_PASSWORD" secret:"true"`
+	RedisDB           int    `env:"REDIS_DB" default:"0" min:"0"`
+	RedisPoolSize     int    `env:"REDIS_POOL_SIZE" default:"0" min:"0"`
+	RedisMinIdleConns int    `env:"REDIS_MIN_IDLE_CONNS" default:"0" min:"0"`
+	RedisMaxRetries   int    `env:"REDIS_MAX_RETRIES" default:"3" min:"0"`
 
 	// JWT
-	JWTSecret          string
-	JWTExpirationHours int
+	JWTSecret          string        `env:"JWT_SECRET" default:"dev-secret-key" secret:"true"`
+	JWTAccessTokenTTL  time.Duration `env:"JWT_ACCESS_TOKEN_EXPIRY" default:"24h"`
+	JWTRefreshTokenTTL time.Duration `env:"JWT_REFRESH_TOKEN_EXPIRY" default:"7d"`
+	JWTKeysDir         string        `env:"JWT_KEYS_DIR"`
+	JWTSigningKeyID    string        `env:"JWT_SIGNING_KEY_ID"`
+
+	// Uploads: files up to UploadDirectMaxSize go through the API, up to
+	// UploadPresignedMaxSize to a presigned URL, larger ones in parts. Upload rates are
+	// per user and hour for small (< 1MB), medium (< 100MB) and large files.
+	UploadDirectMaxSize     ByteSize `env:"UPLOAD_DIRECT_MAX_SIZE" default:"16MB" min:"1"`
+	UploadPresignedMaxSize  ByteSize `env:"UPLOAD_PRESIGNED_MAX_SIZE" default:"100MB" min:"1"`
+	UploadRateSmallPerHour  int      `env:"UPLOAD_RATE_SMALL_PER_HOUR" default:"3000" min:"1"`
+	UploadRateMediumPerHour int      `env:"UPLOAD_RATE_MEDIUM_PER_HOUR" default:"300" min:"1"`
+	UploadRateLargePerHour  int      `env:"UPLOAD_RATE_LARGE_PER_HOUR" default:"6" min:"1"`
+	UploadRateBurst         int      `env:"UPLOAD_RATE_BURST" default:"1" min:"1"`
+
+	// Trash
+	TrashRetentionDays int `env:"TRASH_RETENTION_DAYS" default:"30" min:"1"`
+
+	// Mail
+	MailDriver       string `env:"MAIL_DRIVER" default:"stdout"`
+	MailFilePath     string `env:"MAIL_FILE_PATH" default:"mail.log"`
+	SMTPHost         string `env:"SMTP_HOST" default:"localhost"`
+	SMTPPort         string `env:"SMTP_PORT" default:"587"`
+	SMTPUser         string `env:"SMTP_USER"`
+	SMTPPassword     string `env:"SMTP_PASSWORD" secret:"true"`
+	SMTPFrom         string `env:"SMTP_FROM" default:"noreply@terraallwert.com"`
+	PasswordResetURL string `env:"PASSWORD_RESET_URL" default:"http://localhost:3000/reset-password"`
+
+	// Email verification
+	EmailVerificationPolicy        string `env:"EMAIL_VERIFICATION_POLICY" default:"optional"`
+	EmailVerificationURL           string `env:"EMAIL_VERIFICATION_URL" default:"http://localhost:3000/verify-email"`
+	EmailVerificationResendSeconds int    `env:"EMAIL_VERIFICATION_RESEND_SECONDS" default:"60" min:"0"`
+
+	// Login lockout
+	LoginMaxAttempts    int `env:"LOGIN_MAX_ATTEMPTS" default:"5" min:"0"`
+	LoginIPMaxAttempts  int `env:"LOGIN_IP_MAX_ATTEMPTS" default:"20" min:"0"`
+	LoginBackoffSeconds int `env:"LOGIN_BACKOFF_SECONDS" default:"1" min:"0"`
+	LoginLockoutMinutes int `env:"LOGIN_LOCKOUT_MINUTES" default:"15" min:"1"`
+
+	// API keys
+	APIKeyRateLimit int `env:"API_KEY_RATE_LIMIT" default:"120" min:"0"`
+
+	// OpenID Connect single sign-on
+	OIDCIssuerURL             string `env:"OIDC_ISSUER_URL"`
+	OIDCClientID              string `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret          string `env:"OIDC_CLIENT_SECRET" secret:"true"`
+	OIDCRedirectURL           string `env:"OIDC_REDIRECT_URL" default:"http://localhost:3000/api/v1/auth/oidc/callback"`
+	OIDCScopes                string `env:"OIDC_SCOPES" default:"openid email profile"`
+	OIDCProvisionEnterpriseID string `env:"OIDC_PROVISION_ENTERPRISE_ID"`
+	OIDCProvisionRole         string `env:"OIDC_PROVISION_ROLE" default:"visitor"`
+}
+
+// Load reads the configuration from the environment, .env files and the YAML file named
+// by CONFIG_FILE, if any. See LoadFile.
+func Load() (*Config, error) {
+	return LoadFile("")
 }
 
-func Load() *Config {
+// LoadFile reads the configuration from the environment, .env files and the YAML file at
+// path, falling back to CONFIG_FILE when path is empty. The environment wins over .env
+// files, which win over the YAML file. Every malformed or invalid setting is reported
+// in a single ValidationError.
+func LoadFile(path string) (*Config, error) {
+	loadDotEnv()
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	var file map[string]string
+	if path != "" {
+		var err error
+		if file, err = readYAML(path); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{}
+	problems := load(cfg, file, path)
+	// Values that failed to parse are reported once, not again by the validation
+	for _, problem := range cfg.validate() {
+		key, _, _ := strings.Cut(problem, ":")
+		if !slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, key+":") }) {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+func loadDotEnv() {
 	// Try to load .env from project root first
 	projectRoot := getProjectRoot()
 	envPath := filepath.Join(projectRoot, ".env")
-	
+
 	if _, err := os.Stat(envPath); err == nil {
 		if err := godotenv.Load(envPath); err != nil {
 			log.Printf("Warning: Error loading .env from project root: %v", err)
@@ -58,63 +196,6 @@ func Load() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: No .env file found in current directory")
 	}
-
-	return &Config{
-		// Server
-		Port:        getEnv("PORT", "3000"),
-		Environment: getEnv("ENVIRONMENT", "development"),
-
-		// Database
-		DBDriver:   getEnv("DB_DRIVER", "postgres"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "postgres"),
-		DBName:     getEnv("DB_NAME", "terraallwert"),
-		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
-
-		// MinIO
-		MinIOEndpoint:  getEnv("MINIO_ENDPOINT", "localhost:9000"),
-		MinIOAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-		MinIOSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
-		MinIOUseSSL:    getEnvAsBool("MINIO_USE_SSL", false),
-		MinIOBucket:    getEnv("MINIO_BUCKET", "terraallwert"),
-
-		// Redis
-		RedisHost:     getEnv("REDIS_HOST", "localhost"),
-		RedisPort:     getEnv("REDIS_PORT", "6379"),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-		RedisDB:       getEnvAsInt("REDIS_DB", 0),
-
-		// JWT
-		JWTSecret:          getEnv("JWT_SECRET", "dev-secret-key"),
-		JWTExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
-	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
-		}
-	}
-	return defaultValue
-}
-
-func getEnvAsBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolVal, err := strconv.ParseBool(value); err == nil {
-			return boolVal
-		}
-	}
-	return defaultValue
 }
 
 func getProjectRoot() string {
@@ -148,4 +229,4 @@ func getProjectRoot() string {
 
 	// If not found, return current directory
 	return currentDir
-}
\ No newline at end of file
+}
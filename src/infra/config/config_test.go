@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes a YAML configuration file, returning its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+// expectProblems fails the test unless err is a ValidationError with a problem for each
+// of keys, and no other
+func expectProblems(t *testing.T, err error, keys ...string) {
+	t.Helper()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got error %v, want a ValidationError", err)
+	}
+	if len(validationErr.Problems) != len(keys) {
+		t.Fatalf("got problems %q, want one for each of %v", validationErr.Problems, keys)
+	}
+	for i, key := range keys {
+		if !strings.HasPrefix(validationErr.Problems[i], key+": ") {
+			t.Fatalf("got problems %q, want one for each of %v", validationErr.Problems, keys)
+		}
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "")
+	t.Setenv("JWT_SECRET", "")
+
+	cfg, err := LoadFile(writeConfigFile(t, ""))
+	if err != nil {
+		t.Fatalf("defaults do not load: %v", err)
+	}
+	if cfg.JWTAccessTokenTTL != 24*time.Hour || cfg.JWTRefreshTokenTTL != 7*24*time.Hour {
+		t.Fatalf("token lifetimes are %s and %s, want 24h and 7d", cfg.JWTAccessTokenTTL, cfg.JWTRefreshTokenTTL)
+	}
+	if cfg.UploadDirectMaxSize != 16<<20 || len(cfg.CORSAllowedOrigins) != 1 || cfg.CORSAllowedOrigins[0] != "*" {
+		t.Fatalf("loaded direct uploads up to %s from %v", cfg.UploadDirectMaxSize, cfg.CORSAllowedOrigins)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Setenv("PORT", "4000")
+	t.Setenv("JWT_ACCESS_TOKEN_EXPIRY", "")
+
+	cfg, err := LoadFile(writeConfigFile(t, `
+port: 5000
+jwt:
+  access_token_expiry: 15m
+cors_allowed_origins:
+  - https://app.example.com
+  - https://admin.example.com
+upload_direct_max_size: 8MB
+`))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	// The environment takes precedence over the file
+	if cfg.Port != "4000" {
+		t.Fatalf("port is %s, want the 4000 of the environment", cfg.Port)
+	}
+	if cfg.JWTAccessTokenTTL != 15*time.Minute {
+		t.Fatalf("access token lifetime is %s, want 15m", cfg.JWTAccessTokenTTL)
+	}
+	if strings.Join(cfg.CORSAllowedOrigins, ",") != "https://app.example.com,https://admin.example.com" {
+		t.Fatalf("CORS origins are %v", cfg.CORSAllowedOrigins)
+	}
+	if cfg.UploadDirectMaxSize != 8<<20 {
+		t.Fatalf("direct uploads are up to %s, want 8MB", cfg.UploadDirectMaxSize)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("PORT", "http")
+	t.Setenv("DB_DRIVER", "mysql")
+	t.Setenv("REDIS_DB", "-1")
+	t.Setenv("JWT_ACCESS_TOKEN_EXPIRY", "soon")
+	t.Setenv("UPLOAD_DIRECT_MAX_SIZE", "big")
+
+	_, err := LoadFile(writeConfigFile(t, "unknown_setting: 1\n"))
+	expectProblems(t, err,
+		"REDIS_DB",
+		"JWT_ACCESS_TOKEN_EXPIRY",
+		"UPLOAD_DIRECT_MAX_SIZE",
+		"UNKNOWN_SETTING",
+		"PORT",
+		"DB_DRIVER",
+	)
+}
+
+func TestLoadValidatesSettingsTogether(t *testing.T) {
+	t.Setenv("JWT_ACCESS_TOKEN_EXPIRY", "2h")
+	t.Setenv("JWT_REFRESH_TOKEN_EXPIRY", "1h")
+	t.Setenv("UPLOAD_DIRECT_MAX_SIZE", "32MB")
+	t.Setenv("UPLOAD_PRESIGNED_MAX_SIZE", "16MB")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+
+	_, err := LoadFile(writeConfigFile(t, ""))
+	expectProblems(t, err, "CORS_ALLOW_CREDENTIALS", "JWT_REFRESH_TOKEN_EXPIRY", "UPLOAD_PRESIGNED_MAX_SIZE")
+}
+
+func TestLoadRefusesDefaultSecretInProduction(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_KEYS_DIR", "")
+
+	_, err := LoadFile(writeConfigFile(t, ""))
+	expectProblems(t, err, "JWT_SECRET")
+
+	t.Setenv("JWT_SECRET", "a-secret-of-our-own")
+	if _, err := LoadFile(writeConfigFile(t, "")); err != nil {
+		t.Fatalf("production with a secret of its own does not load: %v", err)
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"16KB", 16 << 10},
+		{"16mb", 16 << 20},
+		{"1.5GB", 3 << 29},
+		{" 2 MB ", 2 << 20},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "MB", "-1", "16TB"} {
+		if _, err := ParseByteSize(in); err == nil {
+			t.Errorf("ParseByteSize(%q) succeeded", in)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"15m", 15 * time.Minute},
+		{"24h", 24 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "7", "1.5d", "a week"} {
+		if _, err := parseDuration(in); err == nil {
+			t.Errorf("parseDuration(%q) succeeded", in)
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	t.Setenv("JWT_SECRET", "a-secret-of-our-own")
+	t.Setenv("REDIS_PASSWORD", "")
+
+	cfg, err := LoadFile(writeConfigFile(t, ""))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("failed to print: %v", err)
+	}
+	printed := out.String()
+	if strings.Contains(printed, "a-secret-of-our-own") || !strings.Contains(printed, "jwt_secret: '"+redacted+"'") {
+		t.Fatalf("printed the JWT secret:\n%s", printed)
+	}
+	// Secrets left empty are shown as such
+	if !strings.Contains(printed, "redis_password: \"\"") {
+		t.Fatalf("printed an empty Redis password as set:\n%s", printed)
+	}
+	if !strings.Contains(printed, "jwt_refresh_token_expiry: 168h\n") {
+		t.Fatalf("printed durations as Go does:\n%s", printed)
+	}
+}
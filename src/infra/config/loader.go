@@ -0,0 +1,269 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// redacted replaces set secrets when the configuration is printed
+const redacted = "[redacted]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ValidationError lists every setting that could not be loaded or is invalid
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// ByteSize is a size in bytes, configured as a number with an optional B, KB, MB or GB
+// suffix in powers of 1024
+type ByteSize int64
+
+// ParseByteSize parses sizes such as "512", "16MB" or "1.5GB"
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, use a number of bytes or a KB, MB or GB suffix", s)
+	}
+	return ByteSize(n * float64(multiplier)), nil
+}
+
+func (b ByteSize) String() string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if int64(b) >= unit.size && int64(b)%unit.size == 0 {
+			return fmt.Sprintf("%d%s", int64(b)/unit.size, unit.suffix)
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// parseDuration parses Go durations, with a d suffix for whole days such as "7d"
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, use a value such as 15m, 24h or 7d", s)
+	}
+	return d, nil
+}
+
+// formatDuration formats durations without zero minutes and seconds, "24h" rather than
+// "24h0m0s"
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// readYAML reads a YAML configuration file into settings keyed by environment variable.
+// Keys are matched case-insensitively and nested keys are joined with underscores, so
+// "jwt: {access_token_expiry: 15m}" sets JWT_ACCESS_TOKEN_EXPIRY. Lists are joined with
+// commas.
+func readYAML(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	settings := make(map[string]string)
+	flattenYAML("", document, settings)
+	return settings, nil
+}
+
+func flattenYAML(prefix string, document yaml.MapSlice, settings map[string]string) {
+	for _, item := range document {
+		key := strings.ToUpper(fmt.Sprint(item.Key))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch value := item.Value.(type) {
+		case yaml.MapSlice:
+			flattenYAML(key, value, settings)
+		case []interface{}:
+			values := make([]string, len(value))
+			for i, v := range value {
+				values[i] = fmt.Sprint(v)
+			}
+			settings[key] = strings.Join(values, ",")
+		case nil:
+			settings[key] = ""
+		default:
+			settings[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// load sets every field of cfg from the environment, the file settings or its default,
+// returning a problem for each value that cannot be parsed and each unknown file key
+func load(cfg *Config, file map[string]string, path string) []string {
+	var problems []string
+	known := make(map[string]bool)
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		known[key] = true
+
+		raw, ok := os.LookupEnv(key)
+		if !ok || raw == "" {
+			raw, ok = file[key]
+		}
+		if !ok || raw == "" {
+			raw = field.Tag.Get("default")
+		}
+		if raw == "" {
+			continue
+		}
+		if err := setField(v.Field(i), field, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	var unknown []string
+	for key := range file {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown setting in %s", key, path))
+	}
+	return problems
+}
+
+func setField(value reflect.Value, field reflect.StructField, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case field.Type == durationType:
+		d, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	case field.Type == reflect.TypeOf(ByteSize(0)):
+		size, err := ParseByteSize(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(size))
+		return checkMin(field, int64(size))
+	}
+
+	switch field.Type.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetInt(int64(n))
+		return checkMin(field, int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, use true or false", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type)
+	}
+	return nil
+}
+
+func checkMin(field reflect.StructField, n int64) error {
+	tag, ok := field.Tag.Lookup("min")
+	if !ok {
+		return nil
+	}
+	min, _ := strconv.ParseInt(tag, 10, 64)
+	if n < min {
+		return fmt.Errorf("must be at least %d", min)
+	}
+	return nil
+}
+
+// Print writes the configuration as YAML keyed by environment variable, with the values
+// of set secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	var document yaml.MapSlice
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var value interface{}
+		switch current := v.Field(i).Interface().(type) {
+		case time.Duration:
+			value = formatDuration(current)
+		case ByteSize:
+			value = current.String()
+		default:
+			value = current
+		}
+		if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+			value = redacted
+		}
+		document = append(document, yaml.MapItem{Key: strings.ToLower(field.Tag.Get("env")), Value: value})
+	}
+
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// validate checks the settings against each other and against the values the API
+// supports, returning a problem for each invalid one
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			add(key, "invalid value %q, use one of %s", value, strings.Join(allowed, ", "))
+		}
+	}
+	isURL := func(key, value string) {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			add(key, "invalid URL %q", value)
+		}
+	}
+	isPort := func(key, value string) {
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			add(key, "invalid port %q", value)
+		}
+	}
+
+	// Server
+	isPort("PORT", c.Port)
+	oneOf("ENVIRONMENT", c.Environment, "development", "test", "staging", "production")
+	if c.ShutdownTimeout <= 0 {
+		add("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if c.ShutdownDrainDelay < 0 || c.ShutdownDrainDelay >= c.ShutdownTimeout {
+		add("SHUTDOWN_DRAIN_DELAY", "must not be negative and must be below SHUTDOWN_TIMEOUT")
+	}
+	if c.HealthCheckTimeout <= 0 {
+		add("HEALTH_CHECK_TIMEOUT", "must be positive")
+	}
+	if c.HealthCacheTTL < 0 {
+		add("HEALTH_CACHE_TTL", "must not be negative")
+	}
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		add("CORS_ALLOW_CREDENTIALS", "credentials cannot be allowed with CORS_ALLOWED_ORIGINS *, list the origins")
+	}
+
+	// Observability
+	oneOf("LOG_LEVEL", strings.ToLower(c.LogLevel), "debug", "info", "warn", "error")
+	oneOf("LOG_FORMAT", c.LogFormat, "json", "text")
+	oneOf("OTEL_TRACES_EXPORTER", c.OTelTracesExporter, "none", "otlp", "stdout")
+	if c.OTelTracesExporter == "otlp" {
+		isURL("OTEL_EXPORTER_OTLP_ENDPOINT", c.OTelEndpoint)
+	}
+	if c.PrometheusEnabled {
+		isPort("PROMETHEUS_PORT", c.PrometheusPort)
+		if c.PrometheusPort == c.Port {
+			add("PROMETHEUS_PORT", "must differ from PORT")
+		}
+	}
+
+	// Persistence
+	oneOf("DB_DRIVER", c.DBDriver, "postgres", DriverMemory)
+	oneOf("STORAGE_DRIVER", c.StorageDriver, "minio", DriverMemory)
+	if c.DBDriver != DriverMemory {
+		isPort("DB_PORT", c.DBPort)
+		isPort("REDIS_PORT", c.RedisPort)
+		if c.DBMaxIdleConns > c.DBMaxOpenConns {
+			add("DB_MAX_IDLE_CONNS", "must not exceed DB_MAX_OPEN_CONNS (%d)", c.DBMaxOpenConns)
+		}
+		if c.RedisPoolSize > 0 && c.RedisMinIdleConns > c.RedisPoolSize {
+			add("REDIS_MIN_IDLE_CONNS", "must not exceed REDIS_POOL_SIZE (%d)", c.RedisPoolSize)
+		}
+	}
+	if c.DBConnMaxLifetime < 0 {
+		add("DB_CONN_MAX_LIFETIME", "must not be negative")
+	}
+
+	// Tokens
+	if c.JWTAccessTokenTTL <= 0 {
+		add("JWT_ACCESS_TOKEN_EXPIRY", "must be positive")
+	}
+	if c.JWTRefreshTokenTTL <= c.JWTAccessTokenTTL {
+		add("JWT_REFRESH_TOKEN_EXPIRY", "must be longer than JWT_ACCESS_TOKEN_EXPIRY (%s)", c.JWTAccessTokenTTL)
+	}
+	if c.Environment == "production" && c.JWTKeysDir == "" && c.JWTSecret == DefaultJWTSecret {
+		add("JWT_SECRET", "is the default secret, set JWT_KEYS_DIR or a JWT_SECRET of your own")
+	}
+
+	// Uploads
+	if c.UploadPresignedMaxSize < c.UploadDirectMaxSize {
+		add("UPLOAD_PRESIGNED_MAX_SIZE", "must not be smaller than UPLOAD_DIRECT_MAX_SIZE (%s)", c.UploadDirectMaxSize)
+	}
+
+	// Mail and email verification
+	oneOf("MAIL_DRIVER", c.MailDriver, "stdout", "file", "smtp")
+	if c.MailDriver == "smtp" {
+		isPort("SMTP_PORT", c.SMTPPort)
+	}
+	oneOf("EMAIL_VERIFICATION_POLICY", c.EmailVerificationPolicy, "optional", "login", "write")
+	isURL("PASSWORD_RESET_URL", c.PasswordResetURL)
+	isURL("EMAIL_VERIFICATION_URL", c.EmailVerificationURL)
+
+	// Single sign-on
+	if c.OIDCIssuerURL != "" {
+		isURL("OIDC_ISSUER_URL", c.OIDCIssuerURL)
+		isURL("OIDC_REDIRECT_URL", c.OIDCRedirectURL)
+		if c.OIDCClientID == "" {
+			add("OIDC_CLIENT_ID", "is required with OIDC_ISSUER_URL")
+		}
+		if !slices.Contains(strings.Fields(c.OIDCScopes), "openid") {
+			add("OIDC_SCOPES", "must include openid")
+		}
+		if c.OIDCProvisionEnterpriseID != "" {
+			if _, err := uuid.Parse(c.OIDCProvisionEnterpriseID); err != nil {
+				add("OIDC_PROVISION_ENTERPRISE_ID", "invalid enterprise ID %q", c.OIDCProvisionEnterpriseID)
+			}
+			oneOf("OIDC_PROVISION_ROLE", c.OIDCProvisionRole, "visitor", "manager", "admin")
+		}
+	}
+	return problems
+}
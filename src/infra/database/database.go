@@ -1,17 +1,24 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"terra-allwert/domain/entities"
+	"terra-allwert/infra/audit"
 	"terra-allwert/infra/config"
+	"terra-allwert/infra/database/migrations"
 	"terra-allwert/infra/database/seeds"
+	"terra-allwert/infra/logging"
+	"terra-allwert/infra/tenant"
 
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 // Database holds the database connection
@@ -19,8 +26,62 @@ type Database struct {
 	DB *gorm.DB
 }
 
-// New creates a new database connection
-func New(cfg *config.Config) (*Database, error) {
+// New creates a new database connection, refusing to start when the schema is not
+// at the latest migration. Queries are traced with tracerProvider.
+func New(cfg *config.Config, tracerProvider trace.TracerProvider) (*Database, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &Database{DB: db}
+
+	// Restrict every query to the caller's enterprise
+	if err := tenant.RegisterCallbacks(db); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to register tenant callbacks: %w", err)
+	}
+
+	// Record a span for every query, with its placeholders rather than its values
+	if err := db.Use(gormtracing.NewPlugin(
+		gormtracing.WithTracerProvider(tracerProvider),
+		gormtracing.WithoutQueryVariables(),
+		gormtracing.WithoutMetrics(),
+	)); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to register tracing callbacks: %w", err)
+	}
+
+	// Report duplicate unique values as conflicts
+	if err := registerErrorTranslation(db); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to register error translation callbacks: %w", err)
+	}
+
+	// Record every mutation in audit_logs
+	if err := audit.RegisterCallbacks(db); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to register audit callbacks: %w", err)
+	}
+
+	// Check the schema against the migration set
+	if err := database.verifyMigrations(); err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	// Check if database is empty and run seeds if needed
+	if err := database.checkAndSeed(); err != nil {
+		slog.Warn("Failed to check/seed database", "error", err)
+		// Don't fail startup, just log the warning
+	}
+
+	slog.Info("Database connected and schema is up to date")
+	return database, nil
+}
+
+// Open connects to the database without checking migrations
+func Open(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
 		cfg.DBHost,
@@ -31,10 +92,10 @@ func New(cfg *config.Config) (*Database, error) {
 		cfg.DBSSLMode,
 	)
 
-	// Configure GORM logger
-	gormLogger := logger.Default.LogMode(logger.Info)
+	// Configure GORM logger, queries are logged at debug level outside production
+	gormLogger := logging.NewGormLogger(slog.Default(), logger.Info)
 	if cfg.Environment == "production" {
-		gormLogger = logger.Default.LogMode(logger.Error)
+		gormLogger = logging.NewGormLogger(slog.Default(), logger.Warn)
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
@@ -42,7 +103,6 @@ func New(cfg *config.Config) (*Database, error) {
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
-		DisableForeignKeyConstraintWhenMigrating: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -54,46 +114,24 @@ func New(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(30)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
 
-	database := &Database{DB: db}
+	return db, nil
+}
 
-	// Auto-migrate tables
-	if err := database.migrate(); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+// verifyMigrations fails when migrations are pending or applied migrations have drifted
+func (d *Database) verifyMigrations() error {
+	migrator, err := migrations.NewMigrator(d.DB)
+	if err != nil {
+		return err
 	}
 
-	// Check if database is empty and run seeds if needed
-	if err := database.checkAndSeed(); err != nil {
-		log.Printf("⚠️  Warning: Failed to check/seed database: %v", err)
-		// Don't fail startup, just log the warning
+	if err := migrator.Verify(context.Background()); err != nil {
+		return fmt.Errorf("%w (run `make migrate` or `go run ./cmd/migrate up`)", err)
 	}
-
-	log.Println("✅ Database connected and migrated successfully")
-	return database, nil
-}
-
-// migrate runs auto-migration for all entities
-func (d *Database) migrate() error {
-	return d.DB.AutoMigrate(
-		&entities.User{},
-		&entities.Enterprise{},
-		&entities.File{},
-		&entities.FileVariant{},
-		&entities.Menu{},
-		&entities.MenuCarousel{},
-		&entities.CarouselItem{},
-		&entities.CarouselTextOverlay{},
-		&entities.Tower{},
-		&entities.Floor{},
-		&entities.Suite{},
-		&entities.MenuFloorPlan{},
-		&entities.MenuPins{},
-		&entities.PinMarker{},
-		&entities.PinMarkerImage{},
-	)
+	return nil
 }
 
 // checkAndSeed verifica se o banco está vazio e executa seeds se necessário
@@ -111,16 +149,16 @@ func (d *Database) checkAndSeed() error {
 
 	// Se não há dados essenciais, executar seeding
 	if enterpriseCount == 0 && userCount == 0 {
-		log.Println("🌱 Database appears to be empty, running automatic seeding...")
+		slog.Info("Database appears to be empty, running automatic seeding")
 		
 		seeder := seeds.NewSeeder(d.DB)
 		if err := seeder.SeedAll(); err != nil {
 			return fmt.Errorf("failed to run automatic seeding: %w", err)
 		}
 		
-		log.Println("✅ Automatic database seeding completed successfully!")
+		slog.Info("Automatic database seeding completed")
 	} else {
-		log.Printf("📊 Database contains %d enterprises and %d users - skipping seeding", enterpriseCount, userCount)
+		slog.Info("Database is not empty, skipping seeding", "enterprises", enterpriseCount, "users", userCount)
 	}
 
 	return nil
@@ -135,6 +173,15 @@ func (d *Database) Close() error {
 	return sqlDB.Close()
 }
 
+// Ping checks the database accepts connections
+func (d *Database) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // GetDB returns the GORM database instance
 func (d *Database) GetDB() *gorm.DB {
 	return d.DB
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"terra-allwert/domain/interfaces"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports a duplicate unique value with
+const uniqueViolation = "23505"
+
+// duplicateKeyPattern extracts the columns from the detail of a unique violation, such
+// as "Key (slug)=(terra) already exists."
+var duplicateKeyPattern = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// registerErrorTranslation replaces the unique violations reported by Postgres on
+// writes with a ConflictError naming the entity and the duplicated columns
+func registerErrorTranslation(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("errors:translate_create", translateError); err != nil {
+		return err
+	}
+	return callback.Update().After("gorm:update").Register("errors:translate_update", translateError)
+}
+
+func translateError(db *gorm.DB) {
+	var pgErr *pgconn.PgError
+	if db.Error == nil || !errors.As(db.Error, &pgErr) || pgErr.Code != uniqueViolation {
+		return
+	}
+
+	entity := pgErr.TableName
+	if db.Statement.Schema != nil {
+		entity = words(db.Statement.Schema.Name)
+	}
+	field := ""
+	if match := duplicateKeyPattern.FindStringSubmatch(pgErr.Detail); match != nil {
+		field = match[1]
+	}
+	db.Error = interfaces.NewConflictError(entity, field, db.Error)
+}
+
+// words turns the name of a model, such as MenuCarousel, into lowercase words
+func words(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,185 @@
+package migrations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// migrationFiles is a valid migration set of two versions
+func migrationFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"0002_add_slug.up.sql":             {Data: []byte("ALTER TABLE enterprises ADD COLUMN slug text;")},
+		"0002_add_slug.down.sql":           {Data: []byte("ALTER TABLE enterprises DROP COLUMN slug;")},
+		"0001_create_enterprises.up.sql":   {Data: []byte("CREATE TABLE enterprises (id uuid PRIMARY KEY);")},
+		"0001_create_enterprises.down.sql": {Data: []byte("DROP TABLE enterprises;")},
+	}
+}
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load the embedded migrations: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s follows version %d", migration.Version, migration.Name, i)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(migrationFiles())
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "create_enterprises" || migrations[1].Name != "add_slug" {
+		t.Fatalf("loaded %+v, want both migrations by version", migrations)
+	}
+	if migrations[1].Up != "ALTER TABLE enterprises ADD COLUMN slug text;" || migrations[1].Down != "ALTER TABLE enterprises DROP COLUMN slug;" {
+		t.Fatalf("loaded %q and %q for 0002", migrations[1].Up, migrations[1].Down)
+	}
+	if len(migrations[0].Checksum) != 64 || migrations[0].Checksum == migrations[1].Checksum {
+		t.Fatalf("checksums are %q and %q", migrations[0].Checksum, migrations[1].Checksum)
+	}
+}
+
+func TestLoadRejectsInvalidSets(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(fstest.MapFS)
+		want   string
+	}{
+		{"invalid name", func(files fstest.MapFS) {
+			files["0003-Add Index.up.sql"] = &fstest.MapFile{}
+		}, "invalid migration file name"},
+		{"conflicting names", func(files fstest.MapFS) {
+			files["0002_add_title.down.sql"] = files["0002_add_slug.down.sql"]
+			delete(files, "0002_add_slug.down.sql")
+		}, "conflicting names"},
+		{"no down", func(files fstest.MapFS) {
+			delete(files, "0002_add_slug.down.sql")
+		}, "must have both up and down files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := migrationFiles()
+			tt.change(files)
+			if _, err := load(files); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("load returned %v, want an error about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadChecksumsContent(t *testing.T) {
+	before, err := load(migrationFiles())
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	for _, name := range []string{"0002_add_slug.up.sql", "0002_add_slug.down.sql"} {
+		files := migrationFiles()
+		files[name] = &fstest.MapFile{Data: append(files[name].Data, " -- edited"...)}
+		after, err := load(files)
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		if before[0].Checksum != after[0].Checksum || before[1].Checksum == after[1].Checksum {
+			t.Fatalf("editing %s did not change the checksum of 0002 alone", name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	migrations, err := load(migrationFiles())
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	applied := func(migrations ...Migration) map[int64]SchemaMigration {
+		rows := make(map[int64]SchemaMigration)
+		for _, migration := range migrations {
+			rows[migration.Version] = SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}
+		}
+		return rows
+	}
+	edited := migrations[0]
+	edited.Checksum = "edited"
+	removed := Migration{Version: 3, Name: "add_index", Checksum: "removed"}
+
+	tests := []struct {
+		name    string
+		applied map[int64]SchemaMigration
+		want    error
+		labels  string
+	}{
+		{"up to date", applied(migrations...), nil, ""},
+		{"nothing applied", applied(), ErrPendingMigrations, "0001_create_enterprises, 0002_add_slug"},
+		{"pending", applied(migrations[0]), ErrPendingMigrations, "0002_add_slug"},
+		{"edited after applying", applied(edited, migrations[1]), ErrDriftedMigrations, "0001_create_enterprises"},
+		{"applied migration removed", applied(append(migrations, removed)...), ErrDriftedMigrations, "0003_add_index"},
+		// Drift is reported before pending migrations
+		{"drifted and pending", applied(edited), ErrDriftedMigrations, "0001_create_enterprises"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(statuses(migrations, tt.applied))
+			if !errors.Is(err, tt.want) || (err != nil && !strings.HasSuffix(err.Error(), ": "+tt.labels)) {
+				t.Fatalf("verify returned %v, want %v of %s", err, tt.want, tt.labels)
+			}
+		})
+	}
+}
+
+func TestStatusListsMissingMigrations(t *testing.T) {
+	migrations, err := load(migrationFiles())
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	statuses := statuses(migrations, map[int64]SchemaMigration{
+		1: {Version: 1, Name: "create_enterprises", Checksum: migrations[0].Checksum},
+		7: {Version: 7, Name: "add_index", Checksum: "removed"},
+	})
+	if len(statuses) != 3 {
+		t.Fatalf("got %d statuses, want the 2 known migrations and the missing one", len(statuses))
+	}
+	if !statuses[0].Applied || statuses[0].Drifted || statuses[1].Applied {
+		t.Fatalf("got statuses %+v", statuses[:2])
+	}
+	if missing := statuses[2]; missing.Version != 7 || !missing.Applied || !missing.Drifted || !missing.Missing {
+		t.Fatalf("got status %+v for the applied migration missing from the set", missing)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_create_enterprises.up.sql", "0001_create_enterprises.down.sql", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	paths, err := Create(dir, "Add Slug to Enterprises")
+	if err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "0002_add_slug_to_enterprises.up.sql"),
+		filepath.Join(dir, "0002_add_slug_to_enterprises.down.sql"),
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("created %v, want %v", paths, want)
+	}
+	if _, err := Create(dir, "--"); err == nil {
+		t.Fatal("created a migration without a name")
+	}
+}
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS property_views;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS pin_marker_images;
+DROP TABLE IF EXISTS pin_markers;
+DROP TABLE IF EXISTS menu_pins;
+DROP TABLE IF EXISTS carousel_text_overlays;
+DROP TABLE IF EXISTS carousel_items;
+DROP TABLE IF EXISTS menu_carousels;
+DROP TABLE IF EXISTS suites;
+DROP TABLE IF EXISTS floors;
+DROP TABLE IF EXISTS towers;
+DROP TABLE IF EXISTS menu_floor_plans;
+DROP TABLE IF EXISTS menus;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS enterprises;
+DROP TABLE IF EXISTS file_variants;
+DROP TABLE IF EXISTS files;
@@ -0,0 +1,296 @@
+-- Baseline schema, equivalent to what GORM AutoMigrate produced before
+-- versioned migrations were introduced. Statements are idempotent so that
+-- existing databases can adopt the migration history without changes.
+
+CREATE TABLE IF NOT EXISTS files (
+    id uuid DEFAULT gen_random_uuid(),
+    file_type varchar(20) NOT NULL,
+    mime_type varchar(100) NOT NULL,
+    extension varchar(10) NOT NULL,
+    original_name varchar(255) NOT NULL,
+    storage_path varchar(500) NOT NULL,
+    cdn_url varchar(500),
+    file_size_bytes bigint NOT NULL,
+    file_hash varchar(64),
+    width bigint,
+    height bigint,
+    duration_seconds bigint,
+    metadata jsonb,
+    uploaded_by uuid,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_files_file_hash UNIQUE (file_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_files_deleted_at ON files (deleted_at);
+
+CREATE TABLE IF NOT EXISTS file_variants (
+    id uuid DEFAULT gen_random_uuid(),
+    original_file_id uuid NOT NULL,
+    variant_name varchar(50) NOT NULL,
+    storage_path varchar(500) NOT NULL,
+    cdn_url varchar(500),
+    width bigint NOT NULL,
+    height bigint NOT NULL,
+    file_size_bytes bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS enterprises (
+    id uuid DEFAULT gen_random_uuid(),
+    title varchar(255) NOT NULL,
+    description text,
+    logo_file_id uuid,
+    slug varchar(255) NOT NULL,
+    address_street varchar(255),
+    address_number varchar(20),
+    address_complement varchar(100),
+    address_neighborhood varchar(100),
+    address_city varchar(100) NOT NULL,
+    address_state varchar(2) NOT NULL,
+    address_zip_code varchar(10),
+    latitude decimal(10,8),
+    longitude decimal(11,8),
+    status varchar(20) NOT NULL DEFAULT 'active',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_enterprises_slug UNIQUE (slug)
+);
+CREATE INDEX IF NOT EXISTS idx_enterprises_deleted_at ON enterprises (deleted_at);
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid DEFAULT gen_random_uuid(),
+    enterprise_id uuid NOT NULL,
+    name varchar(255) NOT NULL,
+    email varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    role varchar(20) NOT NULL DEFAULT 'visitor',
+    phone varchar(20),
+    avatar_file_id uuid,
+    is_active boolean NOT NULL DEFAULT true,
+    email_verified_at timestamptz,
+    last_login_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS menus (
+    id uuid DEFAULT gen_random_uuid(),
+    enterprise_id uuid NOT NULL,
+    parent_menu_id uuid,
+    title varchar(255) NOT NULL,
+    slug varchar(255) NOT NULL,
+    screen_type varchar(20) NOT NULL,
+    menu_type varchar(20) NOT NULL DEFAULT 'standard',
+    position bigint NOT NULL DEFAULT 0,
+    icon varchar(50),
+    is_visible boolean NOT NULL DEFAULT true,
+    path_hierarchy varchar(500),
+    depth_level bigint NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menus_deleted_at ON menus (deleted_at);
+
+CREATE TABLE IF NOT EXISTS menu_floor_plans (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_id uuid NOT NULL,
+    default_view varchar(50),
+    enable_unit_filters boolean DEFAULT true,
+    enable_unit_comparison boolean DEFAULT true,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_menu_floor_plans_menu_id UNIQUE (menu_id)
+);
+
+CREATE TABLE IF NOT EXISTS towers (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_floor_plan_id uuid NOT NULL,
+    title varchar(255) NOT NULL,
+    description text,
+    building_code varchar(50),
+    total_floors bigint,
+    units_per_floor bigint,
+    position bigint NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_towers_deleted_at ON towers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS floors (
+    id uuid DEFAULT gen_random_uuid(),
+    tower_id uuid NOT NULL,
+    floor_number bigint NOT NULL,
+    floor_name varchar(100),
+    banner_file_id uuid,
+    floor_plan_file_id uuid,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_floors_deleted_at ON floors (deleted_at);
+
+CREATE TABLE IF NOT EXISTS suites (
+    id uuid DEFAULT gen_random_uuid(),
+    floor_id uuid NOT NULL,
+    unit_number varchar(20) NOT NULL,
+    title varchar(255) NOT NULL,
+    description text,
+    position_x decimal(6,2),
+    position_y decimal(6,2),
+    area_sqm decimal(10,2) NOT NULL,
+    bedrooms bigint NOT NULL DEFAULT 0,
+    suites_count bigint NOT NULL DEFAULT 0,
+    bathrooms bigint NOT NULL DEFAULT 0,
+    parking_spaces bigint DEFAULT 0,
+    sun_position varchar(2),
+    status varchar(20) NOT NULL DEFAULT 'available',
+    floor_plan_file_id uuid,
+    price decimal(15,2),
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_suites_deleted_at ON suites (deleted_at);
+
+CREATE TABLE IF NOT EXISTS menu_carousels (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_id uuid NOT NULL,
+    promotional_video_id uuid,
+    autoplay boolean DEFAULT true,
+    autoplay_interval bigint DEFAULT 5000,
+    show_indicators boolean DEFAULT true,
+    show_controls boolean DEFAULT true,
+    transition_type varchar(50) DEFAULT 'slide',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_menu_carousels_menu_id UNIQUE (menu_id)
+);
+
+CREATE TABLE IF NOT EXISTS carousel_items (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_carousel_id uuid NOT NULL,
+    item_type varchar(20) NOT NULL,
+    background_file_id uuid,
+    position bigint NOT NULL DEFAULT 0,
+    title varchar(255),
+    subtitle varchar(500),
+    cta_text varchar(100),
+    cta_url varchar(500),
+    map_type varchar(20),
+    map_latitude decimal(10,8),
+    map_longitude decimal(11,8),
+    map_zoom bigint DEFAULT 15,
+    is_active boolean DEFAULT true,
+    valid_from timestamptz,
+    valid_until timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_carousel_items_deleted_at ON carousel_items (deleted_at);
+
+CREATE TABLE IF NOT EXISTS carousel_text_overlays (
+    id uuid DEFAULT gen_random_uuid(),
+    carousel_item_id uuid NOT NULL,
+    title varchar(255),
+    description text,
+    text_color varchar(7) DEFAULT '#FFFFFF',
+    text_size varchar(20) DEFAULT 'medium',
+    background_color varchar(9),
+    position_x decimal(5,2) NOT NULL,
+    position_y decimal(5,2) NOT NULL,
+    animation_type varchar(50),
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS menu_pins (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_id uuid NOT NULL,
+    background_file_id uuid,
+    promotional_video_id uuid,
+    enable_zoom boolean DEFAULT true,
+    enable_pan boolean DEFAULT true,
+    min_zoom decimal(3,2) DEFAULT 0.5,
+    max_zoom decimal(3,2) DEFAULT 3,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_menu_pins_menu_id UNIQUE (menu_id)
+);
+
+CREATE TABLE IF NOT EXISTS pin_markers (
+    id uuid DEFAULT gen_random_uuid(),
+    menu_pin_id uuid NOT NULL,
+    title varchar(255) NOT NULL,
+    description text,
+    position_x decimal(5,2) NOT NULL,
+    position_y decimal(5,2) NOT NULL,
+    icon_type varchar(50) DEFAULT 'default',
+    icon_color varchar(7) DEFAULT '#FF0000',
+    action_type varchar(20) DEFAULT 'info',
+    action_data jsonb,
+    is_visible boolean DEFAULT true,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_pin_markers_deleted_at ON pin_markers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS pin_marker_images (
+    id uuid DEFAULT gen_random_uuid(),
+    pin_marker_id uuid NOT NULL,
+    file_id uuid NOT NULL,
+    position bigint NOT NULL DEFAULT 0,
+    caption varchar(500),
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid,
+    enterprise_id uuid,
+    entity_type varchar(100) NOT NULL,
+    entity_id uuid NOT NULL,
+    action varchar(20) NOT NULL,
+    old_values jsonb,
+    new_values jsonb,
+    ip_address inet,
+    user_agent text,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS property_views (
+    id uuid DEFAULT gen_random_uuid(),
+    suite_id uuid NOT NULL,
+    user_id uuid,
+    session_id varchar(100) NOT NULL,
+    ip_address inet,
+    user_agent text,
+    referrer text,
+    view_duration_seconds bigint,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,58 @@
+DROP INDEX IF EXISTS idx_file_variants_original_file_id;
+DROP INDEX IF EXISTS idx_pin_marker_images_file_id;
+DROP INDEX IF EXISTS idx_pin_marker_images_pin_marker_id;
+DROP INDEX IF EXISTS idx_pin_markers_menu_pin_id;
+DROP INDEX IF EXISTS idx_carousel_text_overlays_carousel_item_id;
+DROP INDEX IF EXISTS idx_carousel_items_menu_carousel_id;
+DROP INDEX IF EXISTS idx_suites_floor_id;
+DROP INDEX IF EXISTS idx_floors_tower_id;
+DROP INDEX IF EXISTS idx_towers_menu_floor_plan_id;
+DROP INDEX IF EXISTS idx_menus_parent_menu_id;
+DROP INDEX IF EXISTS idx_menus_enterprise_id;
+DROP INDEX IF EXISTS idx_users_enterprise_id;
+
+ALTER TABLE property_views DROP CONSTRAINT IF EXISTS fk_property_views_user_id;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS fk_audit_logs_enterprise_id;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS fk_audit_logs_user_id;
+ALTER TABLE pin_marker_images DROP CONSTRAINT IF EXISTS fk_pin_marker_images_file_id;
+ALTER TABLE menu_pins DROP CONSTRAINT IF EXISTS fk_menu_pins_promotional_video_id;
+ALTER TABLE menu_pins DROP CONSTRAINT IF EXISTS fk_menu_pins_background_file_id;
+ALTER TABLE carousel_items DROP CONSTRAINT IF EXISTS fk_carousel_items_background_file_id;
+ALTER TABLE menu_carousels DROP CONSTRAINT IF EXISTS fk_menu_carousels_promotional_video_id;
+ALTER TABLE suites DROP CONSTRAINT IF EXISTS fk_suites_floor_plan_file_id;
+ALTER TABLE floors DROP CONSTRAINT IF EXISTS fk_floors_floor_plan_file_id;
+ALTER TABLE floors DROP CONSTRAINT IF EXISTS fk_floors_banner_file_id;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_avatar_file_id;
+ALTER TABLE enterprises DROP CONSTRAINT IF EXISTS fk_enterprises_logo_file_id;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS fk_files_uploaded_by;
+ALTER TABLE property_views DROP CONSTRAINT IF EXISTS fk_property_views_suite_id;
+ALTER TABLE file_variants DROP CONSTRAINT IF EXISTS fk_file_variants_original_file_id;
+ALTER TABLE pin_marker_images DROP CONSTRAINT IF EXISTS fk_pin_marker_images_pin_marker_id;
+ALTER TABLE pin_markers DROP CONSTRAINT IF EXISTS fk_pin_markers_menu_pin_id;
+ALTER TABLE carousel_text_overlays DROP CONSTRAINT IF EXISTS fk_carousel_text_overlays_carousel_item_id;
+ALTER TABLE carousel_items DROP CONSTRAINT IF EXISTS fk_carousel_items_menu_carousel_id;
+ALTER TABLE suites DROP CONSTRAINT IF EXISTS fk_suites_floor_id;
+ALTER TABLE floors DROP CONSTRAINT IF EXISTS fk_floors_tower_id;
+ALTER TABLE towers DROP CONSTRAINT IF EXISTS fk_towers_menu_floor_plan_id;
+ALTER TABLE menu_pins DROP CONSTRAINT IF EXISTS fk_menu_pins_menu_id;
+ALTER TABLE menu_carousels DROP CONSTRAINT IF EXISTS fk_menu_carousels_menu_id;
+ALTER TABLE menu_floor_plans DROP CONSTRAINT IF EXISTS fk_menu_floor_plans_menu_id;
+ALTER TABLE menus DROP CONSTRAINT IF EXISTS fk_menus_parent_menu_id;
+ALTER TABLE menus DROP CONSTRAINT IF EXISTS fk_menus_enterprise_id;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_enterprise_id;
+
+-- Soft deleted configurations cannot be represented without deleted_at
+DELETE FROM menu_pins WHERE deleted_at IS NOT NULL;
+DELETE FROM menu_carousels WHERE deleted_at IS NOT NULL;
+DELETE FROM menu_floor_plans WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS uni_menu_pins_menu_id;
+DROP INDEX IF EXISTS uni_menu_carousels_menu_id;
+DROP INDEX IF EXISTS uni_menu_floor_plans_menu_id;
+ALTER TABLE menu_pins ADD CONSTRAINT uni_menu_pins_menu_id UNIQUE (menu_id);
+ALTER TABLE menu_carousels ADD CONSTRAINT uni_menu_carousels_menu_id UNIQUE (menu_id);
+ALTER TABLE menu_floor_plans ADD CONSTRAINT uni_menu_floor_plans_menu_id UNIQUE (menu_id);
+
+ALTER TABLE menu_pins DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE menu_carousels DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE menu_floor_plans DROP COLUMN IF EXISTS deleted_at;
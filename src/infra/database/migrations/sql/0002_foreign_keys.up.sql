@@ -0,0 +1,118 @@
+-- Soft delete for the per-menu screen configurations, so deleting a menu can
+-- cascade to them the same way it does for towers, floors and suites.
+ALTER TABLE menu_floor_plans ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE menu_carousels ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE menu_pins ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_menu_floor_plans_deleted_at ON menu_floor_plans (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_menu_carousels_deleted_at ON menu_carousels (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_menu_pins_deleted_at ON menu_pins (deleted_at);
+
+-- A menu has at most one live configuration of each kind
+ALTER TABLE menu_floor_plans DROP CONSTRAINT IF EXISTS uni_menu_floor_plans_menu_id;
+ALTER TABLE menu_carousels DROP CONSTRAINT IF EXISTS uni_menu_carousels_menu_id;
+ALTER TABLE menu_pins DROP CONSTRAINT IF EXISTS uni_menu_pins_menu_id;
+CREATE UNIQUE INDEX uni_menu_floor_plans_menu_id ON menu_floor_plans (menu_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX uni_menu_carousels_menu_id ON menu_carousels (menu_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX uni_menu_pins_menu_id ON menu_pins (menu_id) WHERE deleted_at IS NULL;
+
+-- Clear dangling optional references left behind while foreign keys were disabled
+UPDATE files SET uploaded_by = NULL WHERE uploaded_by IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = files.uploaded_by);
+UPDATE enterprises SET logo_file_id = NULL WHERE logo_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = enterprises.logo_file_id);
+UPDATE users SET avatar_file_id = NULL WHERE avatar_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = users.avatar_file_id);
+UPDATE menus SET parent_menu_id = NULL WHERE parent_menu_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM menus parent WHERE parent.id = menus.parent_menu_id);
+UPDATE floors SET banner_file_id = NULL WHERE banner_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = floors.banner_file_id);
+UPDATE floors SET floor_plan_file_id = NULL WHERE floor_plan_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = floors.floor_plan_file_id);
+UPDATE suites SET floor_plan_file_id = NULL WHERE floor_plan_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = suites.floor_plan_file_id);
+UPDATE menu_carousels SET promotional_video_id = NULL WHERE promotional_video_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = menu_carousels.promotional_video_id);
+UPDATE carousel_items SET background_file_id = NULL WHERE background_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = carousel_items.background_file_id);
+UPDATE menu_pins SET background_file_id = NULL WHERE background_file_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = menu_pins.background_file_id);
+UPDATE menu_pins SET promotional_video_id = NULL WHERE promotional_video_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM files WHERE files.id = menu_pins.promotional_video_id);
+UPDATE audit_logs SET user_id = NULL WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = audit_logs.user_id);
+UPDATE audit_logs SET enterprise_id = NULL WHERE enterprise_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM enterprises WHERE enterprises.id = audit_logs.enterprise_id);
+UPDATE property_views SET user_id = NULL WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = property_views.user_id);
+
+-- Rows whose required parent no longer exists are not guessed at: reattach or delete them
+-- before migrating
+DO $$
+DECLARE
+    ref record;
+    orphaned bigint;
+    orphans text[] := '{}';
+BEGIN
+    FOR ref IN SELECT * FROM (VALUES
+        ('menu_floor_plans', 'menu_id', 'menus'),
+        ('menu_carousels', 'menu_id', 'menus'),
+        ('menu_pins', 'menu_id', 'menus'),
+        ('towers', 'menu_floor_plan_id', 'menu_floor_plans'),
+        ('floors', 'tower_id', 'towers'),
+        ('suites', 'floor_id', 'floors'),
+        ('property_views', 'suite_id', 'suites'),
+        ('carousel_items', 'menu_carousel_id', 'menu_carousels'),
+        ('carousel_text_overlays', 'carousel_item_id', 'carousel_items'),
+        ('pin_markers', 'menu_pin_id', 'menu_pins'),
+        ('pin_marker_images', 'pin_marker_id', 'pin_markers'),
+        ('pin_marker_images', 'file_id', 'files'),
+        ('file_variants', 'original_file_id', 'files')
+    ) AS refs (child, child_column, parent) LOOP
+        EXECUTE format('SELECT COUNT(*) FROM %1$I WHERE NOT EXISTS (SELECT 1 FROM %3$I WHERE %3$I.id = %1$I.%2$I)',
+            ref.child, ref.child_column, ref.parent) INTO orphaned;
+        IF orphaned > 0 THEN
+            orphans := orphans || format('%s %s.%s', orphaned, ref.child, ref.child_column);
+        END IF;
+    END LOOP;
+    IF cardinality(orphans) > 0 THEN
+        RAISE EXCEPTION 'rows reference missing parents (%), reattach or delete them before migrating', array_to_string(orphans, ', ');
+    END IF;
+END $$;
+
+-- Ownership: an enterprise cannot be removed while it still has users or menus
+ALTER TABLE users ADD CONSTRAINT fk_users_enterprise_id FOREIGN KEY (enterprise_id) REFERENCES enterprises(id) ON DELETE RESTRICT;
+ALTER TABLE menus ADD CONSTRAINT fk_menus_enterprise_id FOREIGN KEY (enterprise_id) REFERENCES enterprises(id) ON DELETE RESTRICT;
+ALTER TABLE menus ADD CONSTRAINT fk_menus_parent_menu_id FOREIGN KEY (parent_menu_id) REFERENCES menus(id) ON DELETE RESTRICT;
+
+-- Hierarchies: children go away with their parent
+ALTER TABLE menu_floor_plans ADD CONSTRAINT fk_menu_floor_plans_menu_id FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE;
+ALTER TABLE menu_carousels ADD CONSTRAINT fk_menu_carousels_menu_id FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE;
+ALTER TABLE menu_pins ADD CONSTRAINT fk_menu_pins_menu_id FOREIGN KEY (menu_id) REFERENCES menus(id) ON DELETE CASCADE;
+ALTER TABLE towers ADD CONSTRAINT fk_towers_menu_floor_plan_id FOREIGN KEY (menu_floor_plan_id) REFERENCES menu_floor_plans(id) ON DELETE CASCADE;
+ALTER TABLE floors ADD CONSTRAINT fk_floors_tower_id FOREIGN KEY (tower_id) REFERENCES towers(id) ON DELETE CASCADE;
+ALTER TABLE suites ADD CONSTRAINT fk_suites_floor_id FOREIGN KEY (floor_id) REFERENCES floors(id) ON DELETE CASCADE;
+ALTER TABLE carousel_items ADD CONSTRAINT fk_carousel_items_menu_carousel_id FOREIGN KEY (menu_carousel_id) REFERENCES menu_carousels(id) ON DELETE CASCADE;
+ALTER TABLE carousel_text_overlays ADD CONSTRAINT fk_carousel_text_overlays_carousel_item_id FOREIGN KEY (carousel_item_id) REFERENCES carousel_items(id) ON DELETE CASCADE;
+ALTER TABLE pin_markers ADD CONSTRAINT fk_pin_markers_menu_pin_id FOREIGN KEY (menu_pin_id) REFERENCES menu_pins(id) ON DELETE CASCADE;
+ALTER TABLE pin_marker_images ADD CONSTRAINT fk_pin_marker_images_pin_marker_id FOREIGN KEY (pin_marker_id) REFERENCES pin_markers(id) ON DELETE CASCADE;
+ALTER TABLE file_variants ADD CONSTRAINT fk_file_variants_original_file_id FOREIGN KEY (original_file_id) REFERENCES files(id) ON DELETE CASCADE;
+ALTER TABLE property_views ADD CONSTRAINT fk_property_views_suite_id FOREIGN KEY (suite_id) REFERENCES suites(id) ON DELETE CASCADE;
+
+-- File references are optional and cleared when the file goes away, except gallery
+-- images which must be removed explicitly first
+ALTER TABLE files ADD CONSTRAINT fk_files_uploaded_by FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE enterprises ADD CONSTRAINT fk_enterprises_logo_file_id FOREIGN KEY (logo_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE users ADD CONSTRAINT fk_users_avatar_file_id FOREIGN KEY (avatar_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE floors ADD CONSTRAINT fk_floors_banner_file_id FOREIGN KEY (banner_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE floors ADD CONSTRAINT fk_floors_floor_plan_file_id FOREIGN KEY (floor_plan_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE suites ADD CONSTRAINT fk_suites_floor_plan_file_id FOREIGN KEY (floor_plan_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE menu_carousels ADD CONSTRAINT fk_menu_carousels_promotional_video_id FOREIGN KEY (promotional_video_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE carousel_items ADD CONSTRAINT fk_carousel_items_background_file_id FOREIGN KEY (background_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE menu_pins ADD CONSTRAINT fk_menu_pins_background_file_id FOREIGN KEY (background_file_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE menu_pins ADD CONSTRAINT fk_menu_pins_promotional_video_id FOREIGN KEY (promotional_video_id) REFERENCES files(id) ON DELETE SET NULL;
+ALTER TABLE pin_marker_images ADD CONSTRAINT fk_pin_marker_images_file_id FOREIGN KEY (file_id) REFERENCES files(id) ON DELETE RESTRICT;
+
+-- Audit and analytics rows outlive the users and enterprises they mention
+ALTER TABLE audit_logs ADD CONSTRAINT fk_audit_logs_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE audit_logs ADD CONSTRAINT fk_audit_logs_enterprise_id FOREIGN KEY (enterprise_id) REFERENCES enterprises(id) ON DELETE SET NULL;
+ALTER TABLE property_views ADD CONSTRAINT fk_property_views_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Foreign key columns used by the cascades and lookups
+CREATE INDEX IF NOT EXISTS idx_users_enterprise_id ON users (enterprise_id);
+CREATE INDEX IF NOT EXISTS idx_menus_enterprise_id ON menus (enterprise_id);
+CREATE INDEX IF NOT EXISTS idx_menus_parent_menu_id ON menus (parent_menu_id);
+CREATE INDEX IF NOT EXISTS idx_towers_menu_floor_plan_id ON towers (menu_floor_plan_id);
+CREATE INDEX IF NOT EXISTS idx_floors_tower_id ON floors (tower_id);
+CREATE INDEX IF NOT EXISTS idx_suites_floor_id ON suites (floor_id);
+CREATE INDEX IF NOT EXISTS idx_carousel_items_menu_carousel_id ON carousel_items (menu_carousel_id);
+CREATE INDEX IF NOT EXISTS idx_carousel_text_overlays_carousel_item_id ON carousel_text_overlays (carousel_item_id);
+CREATE INDEX IF NOT EXISTS idx_pin_markers_menu_pin_id ON pin_markers (menu_pin_id);
+CREATE INDEX IF NOT EXISTS idx_pin_marker_images_pin_marker_id ON pin_marker_images (pin_marker_id);
+CREATE INDEX IF NOT EXISTS idx_pin_marker_images_file_id ON pin_marker_images (file_id);
+CREATE INDEX IF NOT EXISTS idx_file_variants_original_file_id ON file_variants (original_file_id);
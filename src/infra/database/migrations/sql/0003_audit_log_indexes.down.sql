@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_audit_logs_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+DROP INDEX IF EXISTS idx_audit_logs_entity;
+DROP INDEX IF EXISTS idx_audit_logs_enterprise_id;
+DROP INDEX IF EXISTS idx_audit_logs_user_id;
@@ -0,0 +1,16 @@
+-- Refresh token families, one per login. Each refresh rotates refresh_token_id;
+-- a token older than the current one revokes the session.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    refresh_token_id varchar(64) NOT NULL,
+    user_agent text,
+    ip_address inet,
+    created_at timestamptz NOT NULL,
+    last_used_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_sessions_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions (user_id);
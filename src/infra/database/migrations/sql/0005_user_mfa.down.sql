@@ -0,0 +1,3 @@
+ALTER TABLE enterprises DROP COLUMN IF EXISTS mfa_required_roles;
+DROP TABLE IF EXISTS user_mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,26 @@
+-- TOTP enrollments, pending until enabled_at is set, and their recovery codes
+CREATE TABLE IF NOT EXISTS user_mfa (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    secret varchar(64) NOT NULL,
+    enabled_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_user_mfa_user_id UNIQUE (user_id),
+    CONSTRAINT fk_user_mfa_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_mfa_recovery_codes (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    code_hash varchar(64) NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_mfa_recovery_codes_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_user_mfa_recovery_codes_user_id ON user_mfa_recovery_codes (user_id);
+
+-- Roles whose users must log in with a second factor, per enterprise
+ALTER TABLE enterprises ADD COLUMN IF NOT EXISTS mfa_required_roles jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,23 @@
+-- Read-only API keys of kiosks and display devices, one enterprise each. Only the
+-- SHA-256 hash of a key is stored; prefix identifies it in listings.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid DEFAULT gen_random_uuid(),
+    enterprise_id uuid NOT NULL,
+    name varchar(255) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    scopes jsonb NOT NULL DEFAULT '[]',
+    rate_limit integer NOT NULL DEFAULT 0,
+    created_by uuid,
+    last_used_at timestamptz,
+    last_used_ip inet,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_api_keys_key_hash UNIQUE (key_hash),
+    CONSTRAINT fk_api_keys_enterprise_id FOREIGN KEY (enterprise_id) REFERENCES enterprises(id) ON DELETE CASCADE,
+    CONSTRAINT fk_api_keys_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_enterprise_id ON api_keys (enterprise_id);
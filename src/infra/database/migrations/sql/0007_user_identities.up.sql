@@ -0,0 +1,14 @@
+-- Accounts of users at OpenID Connect providers, by the subject the provider sends
+CREATE TABLE IF NOT EXISTS user_identities (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    issuer varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    email varchar(255),
+    last_login_at timestamptz,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_identities_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_issuer_subject ON user_identities (issuer, subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_files_enterprise_id;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS fk_files_enterprise_id;
+ALTER TABLE files DROP COLUMN IF EXISTS enterprise_id;
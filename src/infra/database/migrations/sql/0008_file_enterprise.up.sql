@@ -0,0 +1,55 @@
+-- Files belong to an enterprise of their own instead of the enterprise of their uploader,
+-- which is nullable and may move or be purged.
+ALTER TABLE files ADD COLUMN IF NOT EXISTS enterprise_id uuid;
+
+-- Backfill from the uploader, then from the rows using the file, deleted ones included
+UPDATE files SET enterprise_id = users.enterprise_id FROM users WHERE files.enterprise_id IS NULL AND users.id = files.uploaded_by;
+UPDATE files SET enterprise_id = enterprises.id FROM enterprises WHERE files.enterprise_id IS NULL AND enterprises.logo_file_id = files.id;
+UPDATE files SET enterprise_id = users.enterprise_id FROM users WHERE files.enterprise_id IS NULL AND users.avatar_file_id = files.id;
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM floors
+    JOIN towers ON towers.id = floors.tower_id
+    JOIN menu_floor_plans ON menu_floor_plans.id = towers.menu_floor_plan_id
+    JOIN menus ON menus.id = menu_floor_plans.menu_id
+    WHERE files.enterprise_id IS NULL AND files.id IN (floors.banner_file_id, floors.floor_plan_file_id);
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM suites
+    JOIN floors ON floors.id = suites.floor_id
+    JOIN towers ON towers.id = floors.tower_id
+    JOIN menu_floor_plans ON menu_floor_plans.id = towers.menu_floor_plan_id
+    JOIN menus ON menus.id = menu_floor_plans.menu_id
+    WHERE files.enterprise_id IS NULL AND suites.floor_plan_file_id = files.id;
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM menu_carousels
+    JOIN menus ON menus.id = menu_carousels.menu_id
+    WHERE files.enterprise_id IS NULL AND menu_carousels.promotional_video_id = files.id;
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM carousel_items
+    JOIN menu_carousels ON menu_carousels.id = carousel_items.menu_carousel_id
+    JOIN menus ON menus.id = menu_carousels.menu_id
+    WHERE files.enterprise_id IS NULL AND carousel_items.background_file_id = files.id;
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM menu_pins
+    JOIN menus ON menus.id = menu_pins.menu_id
+    WHERE files.enterprise_id IS NULL AND files.id IN (menu_pins.background_file_id, menu_pins.promotional_video_id);
+UPDATE files SET enterprise_id = menus.enterprise_id
+    FROM pin_marker_images
+    JOIN pin_markers ON pin_markers.id = pin_marker_images.pin_marker_id
+    JOIN menu_pins ON menu_pins.id = pin_markers.menu_pin_id
+    JOIN menus ON menus.id = menu_pins.menu_id
+    WHERE files.enterprise_id IS NULL AND pin_marker_images.file_id = files.id;
+
+-- Files without an uploader or any use cannot be attributed: assign or delete them first
+DO $$
+DECLARE
+    unattributed bigint;
+BEGIN
+    SELECT COUNT(*) INTO unattributed FROM files WHERE enterprise_id IS NULL;
+    IF unattributed > 0 THEN
+        RAISE EXCEPTION '% files belong to no enterprise, set files.enterprise_id before migrating', unattributed;
+    END IF;
+END $$;
+
+ALTER TABLE files ALTER COLUMN enterprise_id SET NOT NULL;
+ALTER TABLE files ADD CONSTRAINT fk_files_enterprise_id FOREIGN KEY (enterprise_id) REFERENCES enterprises(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_files_enterprise_id ON files (enterprise_id);
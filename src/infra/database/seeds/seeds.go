@@ -22,11 +22,6 @@ func NewSeeder(db *gorm.DB) *Seeder {
 func (s *Seeder) SeedAll() error {
 	log.Println("🌱 Starting database seeding...")
 
-	// Auto-migrate tables first
-	if err := s.AutoMigrate(); err != nil {
-		return fmt.Errorf("failed to auto-migrate tables: %w", err)
-	}
-
 	if err := s.SeedEnterprises(); err != nil {
 		return fmt.Errorf("failed to seed enterprises: %w", err)
 	}
@@ -39,55 +34,6 @@ func (s *Seeder) SeedAll() error {
 	return nil
 }
 
-func (s *Seeder) AutoMigrate() error {
-	log.Println("🔄 Running auto-migrations...")
-
-	// First, migrate tables without foreign key references to break circular dependencies
-	baseEntities := []any{
-		&entities.File{},
-		&entities.Enterprise{},
-		&entities.User{},
-	}
-
-	log.Println("🔧 Migrating base entities...")
-	for _, entity := range baseEntities {
-		if err := s.db.AutoMigrate(entity); err != nil {
-			log.Printf("⚠️  Warning: Failed to migrate %T individually: %v", entity, err)
-			// Continue with batch migration as fallback
-			break
-		}
-	}
-
-	// Then migrate all remaining entities
-	allEntities := []any{
-		&entities.File{},
-		&entities.FileVariant{},
-		&entities.Enterprise{},
-		&entities.User{},
-		&entities.Menu{},
-		&entities.MenuFloorPlan{},
-		&entities.Tower{},
-		&entities.Floor{},
-		&entities.Suite{},
-		&entities.MenuCarousel{},
-		&entities.CarouselItem{},
-		&entities.CarouselTextOverlay{},
-		&entities.MenuPins{},
-		&entities.PinMarker{},
-		&entities.PinMarkerImage{},
-		&entities.AuditLog{},
-		&entities.PropertyView{},
-	}
-
-	log.Println("🔧 Running full auto-migration...")
-	if err := s.db.AutoMigrate(allEntities...); err != nil {
-		return fmt.Errorf("auto-migration failed: %w", err)
-	}
-
-	log.Println("✅ Auto-migrations completed successfully!")
-	return nil
-}
-
 func (s *Seeder) SeedEnterprises() error {
 	log.Println("🏢 Seeding enterprises...")
 
@@ -198,7 +144,7 @@ func (s *Seeder) SeedUsers() error {
 			Name:            "Super Admin",
 			Email:           "admin@terra.com",
 			PasswordHash:    hashPassword("senha123"),
-			Role:            entities.UserRoleAdmin,
+			Role:            entities.UserRoleSuperAdmin,
 			Phone:           stringPtr("+55 48 99999-0001"),
 			IsActive:        true,
 			EmailVerifiedAt: timePtr(time.Now()),
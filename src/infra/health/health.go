@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of checks and reports
+const (
+	StatusOK          = "ok"
+	StatusError       = "error"
+	StatusUnavailable = "unavailable"
+)
+
+// Phases of the API, only ready between startup and shutdown
+const (
+	PhaseStarting = "starting"
+	PhaseReady    = "ready"
+	PhaseDraining = "draining"
+)
+
+// Check reports whether a dependency of the API is usable, returning an error when it
+// is not
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check
+type Result struct {
+	Status     string    `json:"status" example:"ok"`
+	Error      string    `json:"error,omitempty" example:"dial tcp 127.0.0.1:6379: connect: connection refused"`
+	DurationMs float64   `json:"duration_ms" example:"1.2"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report is the readiness of the API and the results of its checks
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Phase  string            `json:"phase" example:"ready"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker is the registry of the health checks of the API. Each check runs with its own
+// timeout and its result is cached, so frequent probes do not load the dependencies;
+// concurrent probes share a single run of each check.
+type Checker struct {
+	cacheTTL time.Duration
+	phase    atomic.Value
+	mu       sync.RWMutex
+	checks   []*check
+}
+
+type check struct {
+	name    string
+	timeout time.Duration
+	run     Check
+	mu      sync.Mutex
+	result  Result
+	expires time.Time
+}
+
+// NewChecker creates a registry caching results for cacheTTL. The API starts in the
+// starting phase, not ready until MarkReady.
+func NewChecker(cacheTTL time.Duration) *Checker {
+	c := &Checker{cacheTTL: cacheTTL}
+	c.phase.Store(PhaseStarting)
+	return c
+}
+
+// Register adds a check named name, failed when it takes longer than timeout
+func (c *Checker) Register(name string, timeout time.Duration, run Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, &check{name: name, timeout: timeout, run: run})
+}
+
+// MarkReady reports the API ready once it started serving
+func (c *Checker) MarkReady() {
+	c.phase.Store(PhaseReady)
+}
+
+// MarkDraining reports the API not ready once it started shutting down
+func (c *Checker) MarkDraining() {
+	c.phase.Store(PhaseDraining)
+}
+
+// Phase returns the phase of the API
+func (c *Checker) Phase() string {
+	return c.phase.Load().(string)
+}
+
+// Run runs the checks concurrently, reusing the cached results, and reports the API
+// ready when it is in the ready phase and every check passed
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = chk.cached(ctx, c.cacheTTL)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Phase: c.Phase(), Checks: make(map[string]Result, len(checks))}
+	if report.Phase != PhaseReady {
+		report.Status = StatusUnavailable
+	}
+	for i, chk := range checks {
+		report.Checks[chk.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+// cached returns the last result of the check when younger than ttl, running it again
+// otherwise
+func (chk *check) cached(ctx context.Context, ttl time.Duration) Result {
+	chk.mu.Lock()
+	defer chk.mu.Unlock()
+	if time.Now().Before(chk.expires) {
+		return chk.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, chk.timeout)
+	defer cancel()
+	start := time.Now()
+	// Give up on checks ignoring their context once the timeout passed
+	done := make(chan error, 1)
+	go func() {
+		done <- chk.run(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	chk.result = Result{
+		Status:     StatusOK,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt:  start.UTC(),
+	}
+	if err != nil {
+		chk.result.Status = StatusError
+		chk.result.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			chk.result.Error = "timed out after " + chk.timeout.String()
+		}
+	}
+	chk.expires = start.Add(ttl)
+	return chk.result
+}
@@ -0,0 +1,131 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Manager runs the server and the background workers of the API and shuts them down
+// when the process receives SIGINT or SIGTERM.
+//
+// Shutting down:
+//  1. The server stops accepting connections and drains in-flight requests.
+//  2. The context of the workers is cancelled and the workers are awaited.
+//  3. The closers run in the reverse order of registration, clients registered after
+//     the resources they use are closed before them.
+//
+// Steps 1 and 2 share the shutdown timeout, closers run even when it has passed.
+type Manager struct {
+	timeout time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+	mu      sync.Mutex
+	closers []closer
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// New creates a manager giving the server and the workers timeout to stop
+func New(timeout time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{timeout: timeout, ctx: ctx, cancel: cancel}
+}
+
+// Context returns the context cancelled when the workers must stop
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Go runs worker in the background until its context is cancelled. Shutdown waits for
+// worker to return.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		worker(m.ctx)
+		slog.Info("Stopped background worker", "worker", name)
+	}()
+}
+
+// OnClose registers close to run at shutdown, once the server and the workers stopped
+func (m *Manager) OnClose(name string, close func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run calls serve and blocks until it fails or a shutdown signal arrives, then shuts
+// everything down, stopping the server with shutdown. The returned error joins the
+// failure of serve with the errors of the shutdown.
+func (m *Manager) Run(serve func() error, shutdown func(ctx context.Context) error) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	var serveErr error
+	select {
+	case sig := <-signals:
+		slog.Info("Shutting down", "signal", sig.String(), "timeout", m.timeout.String())
+	case serveErr = <-served:
+		if serveErr == nil {
+			serveErr = errors.New("server stopped unexpectedly")
+		}
+		slog.Error("Server failed, shutting down", "error", serveErr)
+	}
+
+	errs := []error{serveErr}
+	errs = append(errs, m.shutdown(shutdown)...)
+	return errors.Join(errs...)
+}
+
+func (m *Manager) shutdown(shutdown func(ctx context.Context) error) []error {
+	var errs []error
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	if err := shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain requests: %w", err))
+	} else {
+		slog.Info("In-flight requests drained")
+	}
+
+	m.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("background workers did not stop in time"))
+	}
+
+	m.mu.Lock()
+	closers := m.closers
+	m.mu.Unlock()
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", closers[i].name, err))
+			continue
+		}
+		slog.Info("Closed client", "client", closers[i].name)
+	}
+	return errs
+}
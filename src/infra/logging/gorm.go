@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration from which queries are logged as slow
+const slowQueryThreshold = 200 * time.Millisecond
+
+// GormLogger logs the queries of GORM to a structured logger: failed queries as errors,
+// slow ones as warnings and, at the info log mode, every query at debug level
+type GormLogger struct {
+	logger *slog.Logger
+	level  gormlogger.LogLevel
+}
+
+// NewGormLogger creates a GORM logger writing to logger
+func NewGormLogger(logger *slog.Logger, level gormlogger.LogLevel) *GormLogger {
+	return &GormLogger{logger: logger, level: level}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &GormLogger{logger: l.logger, level: level}
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	attrs := func() []any {
+		sql, rows := fc()
+		return []any{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		l.logger.ErrorContext(ctx, "Query failed", append(attrs(), "error", err)...)
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		l.logger.WarnContext(ctx, "Slow query", attrs()...)
+	case l.level >= gormlogger.Info:
+		l.logger.DebugContext(ctx, "Query", attrs()...)
+	}
+}
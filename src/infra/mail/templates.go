@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"terra-allwert/domain/interfaces"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Templates renders the emails sent by the API. Each email has a subject and text
+// template, and optionally an HTML one: templates/<name>.subject.tmpl, <name>.txt.tmpl
+// and <name>.html.tmpl.
+type Templates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// LoadTemplates parses the embedded email templates
+func LoadTemplates() (*Templates, error) {
+	text, err := texttemplate.ParseFS(templateFiles, "templates/*.subject.tmpl", "templates/*.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text mail templates: %w", err)
+	}
+	html, err := htmltemplate.ParseFS(templateFiles, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML mail templates: %w", err)
+	}
+	return &Templates{text: text, html: html}, nil
+}
+
+// Render renders the email name addressed to to
+func (t *Templates) Render(name, to string, data interface{}) (interfaces.MailMessage, error) {
+	message := interfaces.MailMessage{To: to}
+
+	subject, err := t.executeText(name+".subject.tmpl", data)
+	if err != nil {
+		return message, err
+	}
+	message.Subject = strings.TrimSpace(subject)
+
+	if message.Text, err = t.executeText(name+".txt.tmpl", data); err != nil {
+		return message, err
+	}
+
+	if t.html.Lookup(name+".html.tmpl") != nil {
+		var buf bytes.Buffer
+		if err := t.html.ExecuteTemplate(&buf, name+".html.tmpl", data); err != nil {
+			return message, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		message.HTML = buf.String()
+	}
+	return message, nil
+}
+
+func (t *Templates) executeText(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.text.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
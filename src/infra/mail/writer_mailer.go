@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"terra-allwert/domain/interfaces"
+)
+
+// WriterMailer writes every message, in RFC 5322 format, to a writer instead of sending
+// it. It lets development setups read reset links from stdout or a local file.
+type WriterMailer struct {
+	mu   sync.Mutex
+	from string
+	w    io.Writer
+}
+
+// NewWriterMailer creates a mailer writing messages to w
+func NewWriterMailer(from string, w io.Writer) *WriterMailer {
+	return &WriterMailer{from: from, w: w}
+}
+
+// NewFileMailer creates a mailer appending messages to the file at path
+func NewFileMailer(from, path string) (*WriterMailer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mail file: %w", err)
+	}
+	return NewWriterMailer(from, file), nil
+}
+
+// Send writes the message
+func (m *WriterMailer) Send(ctx context.Context, message interfaces.MailMessage) error {
+	body, err := compose(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := fmt.Fprintf(m.w, "%s\r\n\r\n", body); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
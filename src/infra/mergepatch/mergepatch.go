@@ -0,0 +1,81 @@
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ContentType is the media type of JSON merge patches
+const ContentType = "application/merge-patch+json"
+
+// Apply applies the JSON merge patch (RFC 7396) patch to the JSON document doc: members
+// of the patch replace those of the document, objects are merged recursively and null
+// removes a member.
+func Apply(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+func merge(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = make(map[string]any, len(changes))
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = merge(object[name], value)
+	}
+	return object
+}
+
+// Changed returns the names of the members that differ between the JSON objects before
+// and after, sorted
+func Changed(before, after []byte) ([]string, error) {
+	var old, current map[string]json.RawMessage
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &current); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, value := range current {
+		if previous, ok := old[name]; !ok || !bytes.Equal(previous, value) {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// decode parses a JSON document, keeping numbers as written
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
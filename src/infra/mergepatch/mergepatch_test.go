@@ -0,0 +1,98 @@
+package mergepatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replaces member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"adds member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"null removes missing member", `{"a":"b"}`, `{"c":null}`, `{"a":"b"}`},
+		{"merges nested objects", `{"a":{"b":"c","d":"e"}}`, `{"a":{"d":"f","g":"h"}}`, `{"a":{"b":"c","d":"f","g":"h"}}`},
+		{"null removes nested member", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":null}}`, `{"a":{"d":"e"}}`},
+		{"object replaces scalar", `{"a":"b"}`, `{"a":{"c":null,"d":"e"}}`, `{"a":{"d":"e"}}`},
+		{"replaces arrays", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"array patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"scalar patch replaces document", `{"a":"b"}`, `"c"`, `"c"`},
+		{"null patch replaces document", `{"a":"b"}`, `null`, `null`},
+		{"object patch replaces scalar document", `"a"`, `{"b":"c"}`, `{"b":"c"}`},
+		{"empty patch changes nothing", `{"a":"b"}`, `{}`, `{"a":"b"}`},
+		{"keeps numbers as written", `{"price":1234567890123456789}`, `{"name":"x"}`, `{"name":"x","price":1234567890123456789}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply(%s, %s) failed: %v", tt.doc, tt.patch, err)
+			}
+			if !jsonEqual(t, got, []byte(tt.want)) {
+				t.Fatalf("Apply(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejectsInvalidJSON(t *testing.T) {
+	if _, err := Apply([]byte(`{"a":`), []byte(`{}`)); err == nil {
+		t.Fatal("Apply accepted an invalid document")
+	}
+	if _, err := Apply([]byte(`{}`), []byte(`{"a"}`)); err == nil {
+		t.Fatal("Apply accepted an invalid patch")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{"nothing", `{"a":"b","c":1}`, `{"a":"b","c":1}`, nil},
+		{"changed value", `{"a":"b","c":1}`, `{"a":"x","c":1}`, []string{"a"}},
+		{"added member", `{"a":"b"}`, `{"a":"b","c":null}`, []string{"c"}},
+		{"removed member", `{"a":"b","c":1}`, `{"a":"b"}`, []string{"c"}},
+		{"set to null", `{"a":"b"}`, `{"a":null}`, []string{"a"}},
+		{"nested change", `{"a":{"b":1},"c":2}`, `{"a":{"b":2},"c":2}`, []string{"a"}},
+		{"sorted", `{"z":1,"a":1,"m":1}`, `{"z":2,"a":2,"m":2}`, []string{"a", "m", "z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Changed([]byte(tt.before), []byte(tt.after))
+			if err != nil {
+				t.Fatalf("Changed(%s, %s) failed: %v", tt.before, tt.after, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Changed(%s, %s) = %v, want %v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedRejectsNonObjects(t *testing.T) {
+	if _, err := Changed([]byte(`[]`), []byte(`{}`)); err == nil {
+		t.Fatal("Changed accepted an array")
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	x, err := decode(a)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	y, err := decode(b)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RouteUnmatched is the route label of requests matching no route, keeping paths
+// scanned by clients out of the label values
+const RouteUnmatched = "unmatched"
+
+var (
+	durationBuckets = prometheus.DefBuckets
+	// 100B to 1GB
+	sizeBuckets = prometheus.ExponentialBuckets(100, 10, 8)
+)
+
+// Metrics are the metrics of the API: requests, uploads, the storage backend and the
+// protections in front of it, plus the Go runtime and the process
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration     *prometheus.HistogramVec
+	httpRequestSize  *prometheus.HistogramVec
+	httpResponseSize *prometheus.HistogramVec
+
+	uploads            *prometheus.CounterVec
+	uploadBytes        *prometheus.CounterVec
+	uploadRejections   *prometheus.CounterVec
+	circuitTransitions *prometheus.CounterVec
+
+	storageDuration *prometheus.HistogramVec
+}
+
+// New creates the metrics of the API in a new registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of the HTTP requests handled, by route.",
+			Buckets: durationBuckets,
+		}, []string{"method", "route", "status"}),
+		httpRequestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_size_bytes",
+			Help:    "Size of the bodies of the HTTP requests handled, by route.",
+			Buckets: sizeBuckets,
+		}, []string{"method", "route", "status"}),
+		httpResponseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of the bodies of the HTTP responses sent, by route.",
+			Buckets: sizeBuckets,
+		}, []string{"method", "route", "status"}),
+		uploads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "uploads_total",
+			Help: "Uploads initiated, by file size category and upload method.",
+		}, []string{"category", "method"}),
+		uploadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "upload_bytes_total",
+			Help: "Declared size of the uploads initiated, by file size category and upload method.",
+		}, []string{"category", "method"}),
+		uploadRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "upload_rate_limit_rejections_total",
+			Help: "Uploads rejected by the upload rate limiter, by file size category.",
+		}, []string{"category"}),
+		circuitTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "upload_circuit_breaker_transitions_total",
+			Help: "State transitions of the circuit breaker protecting the storage backend.",
+		}, []string{"from", "to"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "storage_request_duration_seconds",
+			Help:    "Duration of the requests sent to the storage backend, until the response headers arrived.",
+			Buckets: durationBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration, m.httpRequestSize, m.httpResponseSize,
+		m.uploads, m.uploadBytes, m.uploadRejections, m.circuitTransitions,
+		m.storageDuration,
+	)
+	return m
+}
+
+// ObserveRequest records a handled HTTP request, route being its route pattern or
+// RouteUnmatched
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration, requestSize, responseSize int) {
+	code := strconv.Itoa(status)
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+	m.httpRequestSize.WithLabelValues(method, route, code).Observe(float64(requestSize))
+	m.httpResponseSize.WithLabelValues(method, route, code).Observe(float64(responseSize))
+}
+
+// ObserveUpload records an upload of size bytes initiated with method, one of direct,
+// presigned or multipart
+func (m *Metrics) ObserveUpload(category, method string, size int64) {
+	m.uploads.WithLabelValues(category, method).Inc()
+	m.uploadBytes.WithLabelValues(category, method).Add(float64(size))
+}
+
+// UploadRejected records an upload rejected by the rate limiter
+func (m *Metrics) UploadRejected(category string) {
+	m.uploadRejections.WithLabelValues(category).Inc()
+}
+
+// CircuitStateChanged records a state transition of the upload circuit breaker
+func (m *Metrics) CircuitStateChanged(from, to string) {
+	m.circuitTransitions.WithLabelValues(from, to).Inc()
+}
+
+// RegisterDB registers the statistics of the connection pool of a database, labelled
+// with its name
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterGaugeFunc registers a gauge whose value is read from value on every scrape
+func (m *Metrics) RegisterGaugeFunc(name, help string, value func() float64) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, value))
+}
+
+// transport records the duration of every request sent to the storage backend
+type transport struct {
+	metrics *Metrics
+	base    http.RoundTripper
+}
+
+// NewStorageTransport wraps base to record the duration of every request sent through
+// it to the storage backend
+func NewStorageTransport(m *Metrics, base http.RoundTripper) http.RoundTripper {
+	return &transport{metrics: m, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.storageDuration.WithLabelValues(req.Method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// Serve serves the metrics at /metrics on listener until ctx is cancelled
+func Serve(ctx context.Context, listener net.Listener, m *Metrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		slog.Error("Metrics server failed", "error", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to stop metrics server", "error", err)
+		}
+	}
+}
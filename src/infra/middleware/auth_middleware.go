@@ -1,17 +1,36 @@
 package middleware
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/google/uuid"
 	"terra-allwert/domain/entities"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/audit"
 	"terra-allwert/infra/auth"
+	"terra-allwert/infra/problem"
+	"terra-allwert/infra/tenant"
+)
+
+// Super admins send TenantScopeHeader with TenantScopeAll to see every enterprise
+const (
+	TenantScopeHeader = "X-Tenant-Scope"
+	TenantScopeAll    = "all"
 )
 
+// APIKeyHeader carries the API key of devices, which send it instead of a bearer token
+const APIKeyHeader = "X-API-Key"
+
 // AuthMiddleware handles JWT authentication
 type AuthMiddleware struct {
-	jwtService *auth.JWTService
-	userRepo   interfaces.UserRepository
+	jwtService         *auth.JWTService
+	userRepo           interfaces.UserRepository
+	verificationPolicy auth.VerificationPolicy
+	apiKeys            *auth.APIKeys
 }
 
 // NewAuthMiddleware creates a new auth middleware
@@ -22,57 +41,124 @@ func NewAuthMiddleware(jwtService *auth.JWTService, userRepo interfaces.UserRepo
 	}
 }
 
+// SetVerificationPolicy sets what users with an unverified email may do. With
+// auth.VerificationWrite, Authorize rejects their writes.
+func (am *AuthMiddleware) SetVerificationPolicy(policy auth.VerificationPolicy) {
+	am.verificationPolicy = policy
+}
+
+// SetAPIKeys lets requests authenticate with an API key in APIKeyHeader. Authorize
+// checks the scopes and rate limit of the key.
+func (am *AuthMiddleware) SetAPIKeys(apiKeys *auth.APIKeys) {
+	am.apiKeys = apiKeys
+}
+
 // RequireAuth middleware that requires valid authentication
 func (am *AuthMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authorization header is required",
-			})
+		if err := am.authenticate(c); err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
 		}
-
-		// Extract token from header
-		tokenString, err := auth.ExtractTokenFromAuthHeader(authHeader)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid authorization header format",
-			})
+		if err := scopeTenant(c); err != nil {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
 		}
 
-		// Validate token
-		claims, err := am.jwtService.ValidateAccessToken(tokenString)
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired token",
-			})
+		return c.Next()
+	}
+}
+
+// authenticate validates the bearer token and stores the user information in the
+// context for use in handlers
+func (am *AuthMiddleware) authenticate(c *fiber.Ctx) error {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		if apiKey := c.Get(APIKeyHeader); apiKey != "" && am.apiKeys != nil {
+			return am.authenticateAPIKey(c, apiKey)
 		}
+		return errors.New("Authorization header is required")
+	}
 
-		// Store user information in context for use in handlers
-		c.Locals("user_id", claims.UserID.String())
-		c.Locals("user_uuid", claims.UserID)
-		c.Locals("user_email", claims.Email)
-		c.Locals("user_role", claims.Role)
-		c.Locals("enterprise_id", claims.EnterpriseID.String())
-		c.Locals("enterprise_uuid", claims.EnterpriseID)
+	// Extract token from header
+	tokenString, err := auth.ExtractTokenFromAuthHeader(authHeader)
+	if err != nil {
+		return errors.New("Invalid authorization header format")
+	}
 
-		return c.Next()
+	// Validate token
+	claims, err := am.jwtService.ValidateAccessToken(tokenString)
+	if err != nil {
+		return errors.New("Invalid or expired token")
+	}
+
+	// Fail closed when revocation cannot be checked
+	revoked, err := am.jwtService.IsTokenRevoked(c.Context(), claims)
+	if err != nil {
+		return errors.New("Unable to verify token")
+	}
+	if revoked {
+		return errors.New("Token has been revoked")
+	}
+
+	c.Locals("user_id", claims.UserID.String())
+	c.Locals("user_uuid", claims.UserID)
+	c.Locals("user_email", claims.Email)
+	c.Locals("user_role", claims.Role)
+	c.Locals("enterprise_id", claims.EnterpriseID.String())
+	c.Locals("enterprise_uuid", claims.EnterpriseID)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("mfa_setup_required", claims.MFASetupRequired)
+	return nil
+}
+
+// authenticateAPIKey validates an API key and stores its enterprise and scopes in the
+// context. There is no user: handlers needing one reject the request.
+func (am *AuthMiddleware) authenticateAPIKey(c *fiber.Ctx, secret string) error {
+	key, err := am.apiKeys.Authenticate(c.Context(), secret, c.IP())
+	if errors.Is(err, auth.ErrInvalidAPIKey) {
+		return errors.New("Invalid or revoked API key")
+	}
+	if err != nil {
+		return errors.New("Unable to verify API key")
 	}
+
+	c.Locals("api_key", key)
+	c.Locals("enterprise_id", key.EnterpriseID.String())
+	c.Locals("enterprise_uuid", key.EnterpriseID)
+	return nil
+}
+
+// scopeTenant lifts tenant scoping for a super admin asking for every enterprise.
+// Everyone else stays restricted to their own enterprise.
+func scopeTenant(c *fiber.Ctx) error {
+	if c.Get(TenantScopeHeader) != TenantScopeAll {
+		return nil
+	}
+	if role, _ := c.Locals("user_role").(entities.UserRole); role != entities.UserRoleSuperAdmin {
+		return errors.New("Only super admins can access every enterprise")
+	}
+	c.Locals(tenant.AllEnterprisesKey, true)
+	return nil
 }
 
 // RequireRole middleware that requires specific user role
 func (am *AuthMiddleware) RequireRole(requiredRoles ...entities.UserRole) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// First run auth middleware
-		if err := am.RequireAuth()(c); err != nil {
-			return err
+		// First authenticate the request
+		if err := am.authenticate(c); err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		if err := scopeTenant(c); err != nil {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
 		}
 
 		userRole, ok := c.Locals("user_role").(entities.UserRole)
 		if !ok {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Unable to determine user role",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Unable to determine user role")
+		}
+
+		// Super admins pass every role check
+		if userRole == entities.UserRoleSuperAdmin {
+			return c.Next()
 		}
 
 		// Check if user has required role
@@ -82,27 +168,99 @@ func (am *AuthMiddleware) RequireRole(requiredRoles ...entities.UserRole) fiber.
 			}
 		}
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-			"required_roles": requiredRoles,
-			"user_role": userRole,
-		})
+		return problem.New(fiber.StatusForbidden, "Insufficient permissions").
+			With("required_roles", requiredRoles).
+			With("user_role", userRole)
 	}
 }
 
+// Authorize middleware that checks the permission matrix for the route. It runs after
+// RequireAuth, which stores the role of the user.
+func (am *AuthMiddleware) Authorize(resource auth.Resource, action auth.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key, ok := c.Locals("api_key").(*entities.APIKey); ok {
+			return am.authorizeAPIKey(c, key, resource, action)
+		}
+
+		userRole, ok := c.Locals("user_role").(entities.UserRole)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Authentication required")
+		}
+
+		// Tokens of users who still have to set up a second factor only reach /auth
+		if setup, _ := c.Locals("mfa_setup_required").(bool); setup {
+			return fiber.NewError(fiber.StatusForbidden, "Two-factor authentication setup required")
+		}
+
+		if !auth.Can(userRole, resource, action) {
+			return problem.New(fiber.StatusForbidden, "Insufficient permissions").
+				With("resource", resource).
+				With("action", action).
+				With("user_role", userRole)
+		}
+
+		if action != auth.ActionRead && am.verificationPolicy == auth.VerificationWrite {
+			verified, err := am.emailVerified(c)
+			if err != nil {
+				return problem.Internal(err, "Unable to verify email address status")
+			}
+			if !verified {
+				return fiber.NewError(fiber.StatusForbidden, "Email address must be verified")
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// authorizeAPIKey checks the route against the scopes of the key and counts the request
+// against its rate limit
+func (am *AuthMiddleware) authorizeAPIKey(c *fiber.Ctx, key *entities.APIKey, resource auth.Resource, action auth.Action) error {
+	if !auth.APIKeyAllows(key.Scopes, resource, action) {
+		return problem.New(fiber.StatusForbidden, "API key scope does not allow this request").
+			With("required_scope", auth.Scope(resource, action))
+	}
+
+	allowed, wait, err := am.apiKeys.Allow(c.Context(), key)
+	if err != nil {
+		return problem.Internal(err, "Unable to check API key rate limit")
+	}
+	if !allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		return fiber.NewError(fiber.StatusTooManyRequests, "API key rate limit exceeded")
+	}
+
+	return c.Next()
+}
+
+// emailVerified reports whether the authenticated user verified their email address.
+// It is read from the user rather than the token, so a verification applies at once.
+func (am *AuthMiddleware) emailVerified(c *fiber.Ctx) (bool, error) {
+	userID, err := GetUserFromContext(c)
+	if err != nil {
+		return false, err
+	}
+	user, err := am.userRepo.GetByID(c.Context(), userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerifiedAt != nil, nil
+}
+
 // RequireEnterprise middleware that requires user to belong to an enterprise
 func (am *AuthMiddleware) RequireEnterprise() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// First run auth middleware
-		if err := am.RequireAuth()(c); err != nil {
-			return err
+		// First authenticate the request
+		if err := am.authenticate(c); err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		if err := scopeTenant(c); err != nil {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
 		}
 
 		enterpriseID := c.Locals("enterprise_id")
 		if enterpriseID == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Enterprise access required",
-			})
+			return fiber.NewError(fiber.StatusForbidden, "Enterprise access required")
 		}
 
 		return c.Next()
@@ -112,30 +270,17 @@ func (am *AuthMiddleware) RequireEnterprise() fiber.Handler {
 // OptionalAuth middleware that extracts user info if token is present but doesn't require it
 func (am *AuthMiddleware) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
+		if c.Get("Authorization") == "" {
 			return c.Next()
 		}
 
-		// Extract token from header
-		tokenString, err := auth.ExtractTokenFromAuthHeader(authHeader)
-		if err != nil {
-			return c.Next() // Continue without auth info
-		}
-
-		// Validate token
-		claims, err := am.jwtService.ValidateAccessToken(tokenString)
-		if err != nil {
-			return c.Next() // Continue without auth info
+		// Invalid and revoked tokens continue without auth info
+		if err := am.authenticate(c); err != nil {
+			return c.Next()
 		}
 
-		// Store user information in context
-		c.Locals("user_id", claims.UserID.String())
-		c.Locals("user_uuid", claims.UserID)
-		c.Locals("user_email", claims.Email)
-		c.Locals("user_role", claims.Role)
-		c.Locals("enterprise_id", claims.EnterpriseID.String())
-		c.Locals("enterprise_uuid", claims.EnterpriseID)
+		// A forbidden opt-out just keeps the request scoped
+		scopeTenant(c)
 
 		return c.Next()
 	}
@@ -194,7 +339,7 @@ func CORSWithAuth() fiber.Handler {
 		// Set CORS headers
 		c.Set("Access-Control-Allow-Origin", origin)
 		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Requested-With")
 		c.Set("Access-Control-Allow-Credentials", "true")
 		c.Set("Access-Control-Max-Age", "86400")
 
@@ -229,7 +374,7 @@ func (am *AuthMiddleware) RateLimitByUser() fiber.Handler {
 		// Apply different limits based on role
 		var limit int
 		switch userRole {
-		case entities.UserRoleAdmin:
+		case entities.UserRoleSuperAdmin, entities.UserRoleAdmin:
 			limit = 1000 // High limit for admins
 		case entities.UserRoleManager:
 			limit = 500  // Medium limit for managers
@@ -246,33 +391,15 @@ func (am *AuthMiddleware) RateLimitByUser() fiber.Handler {
 	}
 }
 
-// LogUserActivity logs user actions for audit purposes
+// LogUserActivity stores the client IP and user agent in the request context so the
+// audit log can attribute the mutations made while handling the request
 func (am *AuthMiddleware) LogUserActivity() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Continue processing
-		err := c.Next()
-
-		// Log after request processing
-		userID := c.Locals("user_id")
-		if userID != nil && c.Method() != "GET" {
-			// Log non-GET requests for audit
-			go func() {
-				// Here you could log to database or external service
-				// For now, we'll just structure the log data
-				logData := map[string]interface{}{
-					"user_id":    userID,
-					"method":     c.Method(),
-					"path":       c.Path(),
-					"ip":         c.IP(),
-					"user_agent": c.Get("User-Agent"),
-					"timestamp":  c.Context().Time(),
-					"status":     c.Response().StatusCode(),
-				}
-				_ = logData // Placeholder for actual logging implementation
-			}()
-		}
+		// Copy the values, fiber strings point into buffers reused after the request
+		c.Locals(audit.IPKey, utils.CopyString(c.IP()))
+		c.Locals(audit.UserAgentKey, utils.CopyString(c.Get("User-Agent")))
 
-		return err
+		return c.Next()
 	}
 }
 
@@ -288,17 +415,13 @@ func (am *AuthMiddleware) ValidateResourceOwnership(getResourceUserID func(*fibe
 		// Get resource owner
 		resourceUserID, err := getResourceUserID(c)
 		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Resource not found",
-			})
+			return fiber.NewError(fiber.StatusNotFound, "Resource not found")
 		}
 
 		// Check if user owns the resource (or is admin)
 		userRole, _ := GetUserRoleFromContext(c)
-		if userRole != entities.UserRoleAdmin && userID != resourceUserID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "Access denied: You can only access your own resources",
-			})
+		if userRole != entities.UserRoleAdmin && userRole != entities.UserRoleSuperAdmin && userID != resourceUserID {
+			return fiber.NewError(fiber.StatusForbidden, "Access denied: You can only access your own resources")
 		}
 
 		return c.Next()
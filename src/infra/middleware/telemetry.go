@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"terra-allwert/infra/logging"
+	"terra-allwert/infra/metrics"
+	"terra-allwert/infra/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID of a request, from the client or a proxy in front of
+// the API, and back in the response
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID stores the ID of the request in the request context, reusing the one in the
+// X-Request-ID header when it is well formed, and returns it in the response
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if requestIDPattern.MatchString(id) {
+			id = utils.CopyString(id)
+		} else {
+			id = uuid.NewString()
+		}
+		c.Locals(logging.RequestIDKey, id)
+		c.Set(RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// Telemetry records a server span for every request, continuing the trace propagated
+// in its headers, observes its duration and sizes in the request metrics and logs it
+// once handled
+func Telemetry(tracerProvider trace.TracerProvider, requestMetrics *metrics.Metrics, logger *slog.Logger) fiber.Handler {
+	tracer := tracerProvider.Tracer("terra-allwert/infra/middleware")
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		method := utils.CopyString(c.Method())
+		parent := otel.GetTextMapPropagator().Extract(c.Context(), requestHeaders{c})
+		_, span := tracer.Start(parent, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(utils.CopyString(c.Path())),
+				semconv.ClientAddress(utils.CopyString(c.IP())),
+				semconv.UserAgentOriginal(utils.CopyString(c.Get("User-Agent"))),
+			),
+		)
+		defer span.End()
+		c.Locals(tracing.SpanKey, span)
+
+		// Let the error handler write the response of a failed handler now, to record
+		// its status
+		err := c.Next()
+		if err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// The router fails requests matching no route with a "Cannot GET /path" error
+		var fiberErr *fiber.Error
+		unmatched := errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound && strings.HasPrefix(fiberErr.Message, "Cannot ")
+		route := ""
+		if !unmatched {
+			route = c.Route().Path
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		duration := time.Since(start)
+
+		metricsRoute := route
+		if unmatched {
+			metricsRoute = metrics.RouteUnmatched
+		}
+		requestMetrics.ObserveRequest(method, metricsRoute, status, duration,
+			len(c.Request().Body()), responseSize(c.Response()))
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("path", c.Path()),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		}
+		if userID, ok := c.Locals("user_id").(string); ok {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		logger.LogAttrs(c.Context(), level, "Request handled", attrs...)
+		return nil
+	}
+}
+
+// responseSize returns the size of the response body, from its Content-Length when the
+// body is streamed
+func responseSize(resp *fasthttp.Response) int {
+	if resp.IsBodyStream() {
+		return max(resp.Header.ContentLength(), 0)
+	}
+	return len(resp.Body())
+}
+
+// requestHeaders reads the propagated trace context from the headers of a request
+type requestHeaders struct {
+	c *fiber.Ctx
+}
+
+func (h requestHeaders) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h requestHeaders) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h requestHeaders) Keys() []string {
+	keys := make([]string, 0, h.c.Request().Header.Len())
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
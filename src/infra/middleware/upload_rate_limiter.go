@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/time/rate"
+	"terra-allwert/infra/problem"
 )
 
 // FileSizeCategory represents different file size categories with different limits
@@ -23,6 +25,7 @@ type UploadRateLimiter struct {
 	limiters map[string]*rate.Limiter
 	mu       sync.RWMutex
 	config   RateLimitConfig
+	onReject func(category FileSizeCategory)
 }
 
 type RateLimitConfig struct {
@@ -44,17 +47,24 @@ func DefaultRateLimitConfig() RateLimitConfig {
 	}
 }
 
-// NewUploadRateLimiter creates a new file size-based rate limiter
+// PerHour returns the rate limit allowing n uploads per hour
+func PerHour(n int) rate.Limit {
+	return rate.Every(time.Hour / time.Duration(n))
+}
+
+// NewUploadRateLimiter creates a new file size-based rate limiter. Run removes the
+// limiters of inactive users.
 func NewUploadRateLimiter(config RateLimitConfig) *UploadRateLimiter {
-	limiter := &UploadRateLimiter{
+	return &UploadRateLimiter{
 		limiters: make(map[string]*rate.Limiter),
 		config:   config,
 	}
+}
 
-	// Start cleanup goroutine to remove inactive limiters
-	go limiter.cleanup()
-
-	return limiter
+// SetRejectionHook sets a function called with the size category of every rejected
+// upload. It is called with the limiter locked and must not use it.
+func (url *UploadRateLimiter) SetRejectionHook(onReject func(category FileSizeCategory)) {
+	url.onReject = onReject
 }
 
 // GetFileSizeCategory determines the category based on file size
@@ -93,7 +103,13 @@ func (url *UploadRateLimiter) Allow(userID string, fileSize int64) bool {
 		url.limiters[key] = limiter
 	}
 
-	return limiter.Allow()
+	if !limiter.Allow() {
+		if url.onReject != nil {
+			url.onReject(category)
+		}
+		return false
+	}
+	return true
 }
 
 // ReserveN reserves n tokens for future use
@@ -137,12 +153,18 @@ func (url *UploadRateLimiter) GetRateLimit(userID string, fileSize int64) rate.L
 	return url.config.LargeFileRate // Default to most restrictive
 }
 
-// cleanup removes inactive rate limiters periodically
-func (url *UploadRateLimiter) cleanup() {
+// Run removes inactive rate limiters periodically, until ctx is cancelled
+func (url *UploadRateLimiter) Run(ctx context.Context) {
 	ticker := time.NewTicker(url.config.CleanupTicker)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
 		url.mu.Lock()
 		// Remove limiters that haven't been used recently
 		// This prevents memory leaks from inactive users
@@ -162,16 +184,12 @@ func UploadRateLimitMiddleware(rateLimiter *UploadRateLimiter) fiber.Handler {
 		// Extract user ID (assuming it's set by auth middleware)
 		userID := c.Locals("user_id")
 		if userID == nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authentication required",
-			})
+			return fiber.NewError(fiber.StatusUnauthorized, "Authentication required")
 		}
 
 		userIDStr, ok := userID.(string)
 		if !ok {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Invalid user ID format",
-			})
+			return fiber.NewError(fiber.StatusInternalServerError, "Invalid user ID format")
 		}
 
 		// Get file size from request (assuming it's passed as header or form data)
@@ -203,13 +221,11 @@ func UploadRateLimitMiddleware(rateLimiter *UploadRateLimiter) fiber.Handler {
 			category := GetFileSizeCategory(fileSize)
 			rateLimit := rateLimiter.GetRateLimit(userIDStr, fileSize)
 			
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":           "Upload rate limit exceeded",
-				"file_size":       fileSize,
-				"size_category":   string(category),
-				"rate_limit":      fmt.Sprintf("%.2f requests per minute", float64(rateLimit)*60),
-				"retry_after":     "60", // seconds
-			})
+			return problem.New(fiber.StatusTooManyRequests, "Upload rate limit exceeded").
+				With("file_size", fileSize).
+				With("size_category", string(category)).
+				With("rate_limit", fmt.Sprintf("%.2f requests per minute", float64(rateLimit)*60)).
+				With("retry_after", "60") // seconds
 		}
 
 		return c.Next()
@@ -233,11 +249,12 @@ type CircuitBreakerConfig struct {
 
 // CircuitBreaker implements the circuit breaker pattern for upload failures
 type CircuitBreaker struct {
-	config       CircuitBreakerConfig
-	failures     int
-	lastFailTime time.Time
-	state        CircuitState
-	mu           sync.RWMutex
+	config        CircuitBreakerConfig
+	failures      int
+	lastFailTime  time.Time
+	state         CircuitState
+	mu            sync.RWMutex
+	onStateChange func(from, to CircuitState)
 }
 
 type CircuitState int
@@ -248,6 +265,19 @@ const (
 	CircuitHalfOpen
 )
 
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 	return &CircuitBreaker{
@@ -256,6 +286,31 @@ func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 	}
 }
 
+// SetStateChangeHook sets a function called on every state transition. It is called
+// with the circuit breaker locked and must not use it.
+func (cb *CircuitBreaker) SetStateChangeHook(onStateChange func(from, to CircuitState)) {
+	cb.onStateChange = onStateChange
+}
+
+// State returns the current state of the circuit
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state
+}
+
+// setState moves the circuit to state, reporting the transition
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	if cb.onStateChange != nil {
+		cb.onStateChange(from, state)
+	}
+}
+
 // IsAllowed checks if requests are allowed through the circuit breaker
 func (cb *CircuitBreaker) IsAllowed() bool {
 	cb.mu.Lock()
@@ -266,7 +321,7 @@ func (cb *CircuitBreaker) IsAllowed() bool {
 		return true
 	case CircuitOpen:
 		if time.Since(cb.lastFailTime) > cb.config.ResetTimeout {
-			cb.state = CircuitHalfOpen
+			cb.setState(CircuitHalfOpen)
 			return true
 		}
 		return false
@@ -282,7 +337,7 @@ func (cb *CircuitBreaker) RecordSuccess() {
 	defer cb.mu.Unlock()
 	
 	cb.failures = 0
-	cb.state = CircuitClosed
+	cb.setState(CircuitClosed)
 }
 
 // RecordFailure records a failed operation
@@ -294,6 +349,6 @@ func (cb *CircuitBreaker) RecordFailure() {
 	cb.lastFailTime = time.Now()
 	
 	if cb.failures >= cb.config.MaxFailures {
-		cb.state = CircuitOpen
+		cb.setState(CircuitOpen)
 	}
 }
\ No newline at end of file
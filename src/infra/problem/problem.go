@@ -0,0 +1,176 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/logging"
+	"terra-allwert/infra/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response. Extensions are added next to the
+// standard members, such as the validation errors of the request or the seconds to wait
+// before retrying.
+type Problem struct {
+	Type       string         `json:"type" example:"about:blank"`
+	Title      string         `json:"title" example:"Not Found"`
+	Status     int            `json:"status" example:"404"`
+	Detail     string         `json:"detail,omitempty" example:"Enterprise not found"`
+	Instance   string         `json:"instance,omitempty" example:"/api/v1/enterprises/0b7c3f4e-5d2a-4f8e-9a61-2c1d7e8f9a0b"`
+	RequestID  string         `json:"request_id,omitempty" example:"5f0c2a9e-8d1b-4c7a-9e3f-1a2b3c4d5e6f"`
+	Extensions map[string]any `json:"-"`
+}
+
+// New creates the problem of a response with status, detail explaining this occurrence
+func New(status int, detail string) *Problem {
+	return &Problem{Type: "about:blank", Title: utils.StatusMessage(status), Status: status, Detail: detail}
+}
+
+// With adds the extension member key to the problem
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Detail
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type members Problem
+	body, err := json.Marshal((*members)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+	extensions, err := json.Marshal(p.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	// Splice the extension members into the standard ones
+	return append(append(body[:len(body)-1], ','), extensions[1:]...), nil
+}
+
+// internalError is a failure of the server, answered with its detail while the cause is
+// only logged
+type internalError struct {
+	detail string
+	err    error
+}
+
+// Internal reports err as a server error answered with detail, such as "Failed to
+// create enterprise". Domain errors in the chain of err take precedence: a duplicate
+// value still answers 409.
+func Internal(err error, detail string) error {
+	return &internalError{detail: detail, err: err}
+}
+
+func (e *internalError) Error() string {
+	if e.err == nil {
+		return e.detail
+	}
+	return e.detail + ": " + e.err.Error()
+}
+
+func (e *internalError) Unwrap() error {
+	return e.err
+}
+
+// ErrorHandler is the error handler of the app, rendering every error returned by
+// handlers and middleware as problem details carrying the request ID. Server errors are
+// logged with their cause.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := FromError(err)
+	p.Instance = utils.CopyString(c.OriginalURL())
+	p.RequestID = logging.RequestIDFromContext(c.Context())
+	if p.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.Context(), "Request failed", "error", err)
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ContentType)
+	return c.Status(p.Status).Send(body)
+}
+
+// FromError returns the problem describing err, mapping domain and store errors to their
+// status
+func FromError(err error) *Problem {
+	var (
+		problem       *Problem
+		fiberErr      *fiber.Error
+		fieldErrs     validator.ValidationErrors
+		validationErr *interfaces.ValidationError
+		notFoundErr   *interfaces.NotFoundError
+		conflictErr   *interfaces.ConflictError
+		restrictErr   *interfaces.RestrictError
+		forbiddenErr  *interfaces.ForbiddenError
+		internalErr   *internalError
+	)
+	switch {
+	case errors.As(err, &problem):
+		return problem
+	case errors.As(err, &validationErr):
+		p := New(fiber.StatusBadRequest, validationErr.Message)
+		if errors.As(err, &fieldErrs) {
+			p.With("errors", validation.FormatValidationErrors(fieldErrs))
+		}
+		return p
+	case errors.As(err, &fieldErrs):
+		return New(fiber.StatusBadRequest, "Validation failed").With("errors", validation.FormatValidationErrors(fieldErrs))
+	case errors.As(err, &notFoundErr):
+		return New(fiber.StatusNotFound, capitalize(notFoundErr.Error()))
+	case errors.Is(err, interfaces.ErrOtherTenant):
+		return New(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return New(fiber.StatusNotFound, "Record not found")
+	case errors.As(err, &restrictErr):
+		return New(fiber.StatusConflict, restrictErr.Error()).With("blockers", restrictErr.Blockers)
+	case errors.As(err, &conflictErr):
+		p := New(fiber.StatusConflict, capitalize(conflictErr.Error()))
+		if conflictErr.Field != "" {
+			p.With("field", conflictErr.Field)
+		}
+		return p
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return New(fiber.StatusConflict, "Record already exists")
+	case errors.Is(err, interfaces.ErrParentDeleted):
+		return New(fiber.StatusConflict, err.Error())
+	case errors.Is(err, interfaces.ErrUnknownEntityType):
+		return New(fiber.StatusBadRequest, err.Error())
+	case errors.As(err, &forbiddenErr):
+		return New(fiber.StatusForbidden, forbiddenErr.Message)
+	case errors.As(err, &fiberErr):
+		return New(fiberErr.Code, fiberErr.Message)
+	case errors.As(err, &internalErr):
+		return New(fiber.StatusInternalServerError, internalErr.detail)
+	default:
+		return New(fiber.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// capitalize upper-cases the first letter of a message built from lowercase names
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,146 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{"problem", New(fiber.StatusTeapot, "Short and stout"), fiber.StatusTeapot, "Short and stout"},
+		{"fiber error", fiber.NewError(fiber.StatusUnauthorized, "Invalid token"), fiber.StatusUnauthorized, "Invalid token"},
+		{"not found", &interfaces.NotFoundError{Entity: "enterprise"}, fiber.StatusNotFound, "Enterprise not found"},
+		{"other tenant", fmt.Errorf("update menu: %w", interfaces.ErrOtherTenant), fiber.StatusNotFound, "update menu: record not found"},
+		{"record not found", gorm.ErrRecordNotFound, fiber.StatusNotFound, "Record not found"},
+		{"conflict", interfaces.NewConflictError("enterprise", "slug", gorm.ErrDuplicatedKey), fiber.StatusConflict, "Enterprise with this slug already exists"},
+		{"duplicated key", gorm.ErrDuplicatedKey, fiber.StatusConflict, "Record already exists"},
+		{"parent deleted", interfaces.ErrParentDeleted, fiber.StatusConflict, "parent is deleted, restore it first"},
+		{"unknown entity type", interfaces.ErrUnknownEntityType, fiber.StatusBadRequest, "unknown entity type"},
+		{"forbidden", &interfaces.ForbiddenError{Message: "Only admins can grant roles"}, fiber.StatusForbidden, "Only admins can grant roles"},
+		{"validation", &interfaces.ValidationError{Message: "Invalid slug"}, fiber.StatusBadRequest, "Invalid slug"},
+		{"internal", Internal(errors.New("connection refused"), "Failed to create enterprise"), fiber.StatusInternalServerError, "Failed to create enterprise"},
+		{"unknown", errors.New("connection refused"), fiber.StatusInternalServerError, "Internal server error"},
+		// Domain errors behind a server error keep their status
+		{"internal conflict", Internal(interfaces.NewConflictError("user", "email", nil), "Failed to create user"), fiber.StatusConflict, "User with this email already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := FromError(tt.err)
+			if p.Status != tt.status || p.Detail != tt.detail {
+				t.Fatalf("FromError(%v) = %d %q, want %d %q", tt.err, p.Status, p.Detail, tt.status, tt.detail)
+			}
+			if p.Title != http.StatusText(tt.status) {
+				t.Fatalf("FromError(%v) has title %q, want %q", tt.err, p.Title, http.StatusText(tt.status))
+			}
+		})
+	}
+}
+
+func TestFromErrorListsBlockers(t *testing.T) {
+	blockers := []interfaces.Blocker{{Entity: "users", Count: 2}, {Entity: "menus", Count: 1}}
+	err := interfaces.NewRestrictError("enterprise", uuid.New(), append(blockers, interfaces.Blocker{Entity: "files"})...)
+
+	p := FromError(fmt.Errorf("delete enterprise: %w", err))
+	if p.Status != fiber.StatusConflict {
+		t.Fatalf("got status %d, want 409", p.Status)
+	}
+	body := marshal(t, p)
+	var got struct {
+		Blockers []interfaces.Blocker `json:"blockers"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("invalid problem %s: %v", body, err)
+	}
+	if fmt.Sprint(got.Blockers) != fmt.Sprint(blockers) {
+		t.Fatalf("problem lists blockers %v, want %v", got.Blockers, blockers)
+	}
+}
+
+func TestFromErrorListsInvalidFields(t *testing.T) {
+	var req struct {
+		Title string `json:"title" validate:"required"`
+		Slug  string `json:"slug" validate:"slug"`
+	}
+	req.Slug = "Not A Slug"
+	err := &interfaces.ValidationError{Message: "Validation failed", Err: validation.ValidateStruct(&req)}
+
+	p := FromError(err)
+	if p.Status != fiber.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", p.Status)
+	}
+	body := marshal(t, p)
+	var got struct {
+		Errors []validation.ValidationError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("invalid problem %s: %v", body, err)
+	}
+	if len(got.Errors) != 2 || got.Errors[0].Field != "title" || got.Errors[1].Field != "slug" {
+		t.Fatalf("problem lists fields %+v, want title and slug", got.Errors)
+	}
+}
+
+func TestMarshalJSONSplicesExtensions(t *testing.T) {
+	body := marshal(t, New(fiber.StatusTooManyRequests, "Slow down").With("retry_after", 30))
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("invalid problem %s: %v", body, err)
+	}
+	if got["type"] != "about:blank" || got["title"] != "Too Many Requests" || got["status"] != float64(429) || got["retry_after"] != float64(30) {
+		t.Fatalf("problem is %s", body)
+	}
+	if _, ok := got["Extensions"]; ok {
+		t.Fatalf("problem nests its extensions: %s", body)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/enterprises/:id", func(c *fiber.Ctx) error {
+		return &interfaces.NotFoundError{Entity: "enterprise"}
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/enterprises/42?expand=menus", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound || resp.Header.Get(fiber.HeaderContentType) != ContentType {
+		t.Fatalf("got status %d as %s, want 404 as %s", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), ContentType)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var got Problem
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("invalid problem %s: %v", body, err)
+	}
+	if got.Detail != "Enterprise not found" || got.Instance != "/enterprises/42?expand=menus" {
+		t.Fatalf("problem is %s", body)
+	}
+}
+
+func marshal(t *testing.T, p *Problem) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("failed to marshal problem: %v", err)
+	}
+	return body
+}
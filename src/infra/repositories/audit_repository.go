@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditLogRepository implements the audit log repository interface
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) interfaces.AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create records an audit log entry
+func (r *AuditLogRepository) Create(ctx context.Context, log *entities.AuditLog) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(log).Error
+}
+
+// GetByID gets an audit log entry by ID
+func (r *AuditLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error) {
+	var log entities.AuditLog
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&log).Error
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// Search searches audit log entries using the given filters, newest first
+func (r *AuditLogRepository) Search(ctx context.Context, filters interfaces.AuditLogSearchFilters, limit, offset int) ([]*entities.AuditLog, error) {
+	var logs []*entities.AuditLog
+	query := r.db.WithContext(ctx).Model(&entities.AuditLog{})
+
+	if filters.EntityType != nil {
+		query = query.Where("entity_type = ?", *filters.EntityType)
+	}
+	if filters.EntityID != nil {
+		query = query.Where("entity_id = ?", *filters.EntityID)
+	}
+	if filters.UserID != nil {
+		query = query.Where("user_id = ?", *filters.UserID)
+	}
+	if filters.EnterpriseID != nil {
+		query = query.Where("enterprise_id = ?", *filters.EnterpriseID)
+	}
+	if filters.Action != nil {
+		query = query.Where("action = ?", *filters.Action)
+	}
+	if filters.From != nil {
+		query = query.Where("created_at >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		query = query.Where("created_at <= ?", *filters.To)
+	}
+
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MenuCarouselRepository implements the menu carousel repository interface
+type MenuCarouselRepository struct {
+	db *gorm.DB
+}
+
+// NewMenuCarouselRepository creates a new menu carousel repository
+func NewMenuCarouselRepository(db *gorm.DB) interfaces.MenuCarouselRepository {
+	return &MenuCarouselRepository{db: db}
+}
+
+// Create creates a new menu carousel
+func (r *MenuCarouselRepository) Create(ctx context.Context, carousel *entities.MenuCarousel) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(carousel).Error
+}
+
+// GetByID gets a menu carousel by ID
+func (r *MenuCarouselRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.MenuCarousel, error) {
+	var carousel entities.MenuCarousel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&carousel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &carousel, nil
+}
+
+// GetByMenuID gets the carousel of a menu
+func (r *MenuCarouselRepository) GetByMenuID(ctx context.Context, menuID uuid.UUID) (*entities.MenuCarousel, error) {
+	var carousel entities.MenuCarousel
+	err := r.db.WithContext(ctx).Where("menu_id = ?", menuID).First(&carousel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &carousel, nil
+}
+
+// GetAll gets all menu carousels with pagination
+func (r *MenuCarouselRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.MenuCarousel, error) {
+	var carousels []*entities.MenuCarousel
+	err := r.db.WithContext(ctx).Order("created_at ASC").Limit(limit).Offset(offset).Find(&carousels).Error
+	return carousels, err
+}
+
+// Update updates a menu carousel
+func (r *MenuCarouselRepository) Update(ctx context.Context, carousel *entities.MenuCarousel) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(carousel).Error
+}
+
+// Delete deletes a menu carousel with its items
+func (r *MenuCarouselRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteCarousels(tx, []uuid.UUID{id})
+	})
+}
+
+// CarouselItemRepository implements the carousel item repository interface
+type CarouselItemRepository struct {
+	db *gorm.DB
+}
+
+// NewCarouselItemRepository creates a new carousel item repository
+func NewCarouselItemRepository(db *gorm.DB) interfaces.CarouselItemRepository {
+	return &CarouselItemRepository{db: db}
+}
+
+// Create creates a new carousel item
+func (r *CarouselItemRepository) Create(ctx context.Context, item *entities.CarouselItem) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(item).Error
+}
+
+// GetByID gets a carousel item by ID
+func (r *CarouselItemRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.CarouselItem, error) {
+	var item entities.CarouselItem
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByMenuCarouselID gets carousel items by menu carousel ID
+func (r *CarouselItemRepository) GetByMenuCarouselID(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error) {
+	var items []*entities.CarouselItem
+	err := r.db.WithContext(ctx).Where("menu_carousel_id = ?", menuCarouselID).Order("position ASC").Limit(limit).Offset(offset).Find(&items).Error
+	return items, err
+}
+
+// GetAll gets all carousel items with pagination
+func (r *CarouselItemRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselItem, error) {
+	var items []*entities.CarouselItem
+	err := r.db.WithContext(ctx).Order("position ASC").Limit(limit).Offset(offset).Find(&items).Error
+	return items, err
+}
+
+// Update updates a carousel item
+func (r *CarouselItemRepository) Update(ctx context.Context, item *entities.CarouselItem) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(item).Error
+}
+
+// UpdateFields updates the given columns of a carousel item, leaving the others untouched
+func (r *CarouselItemRepository) UpdateFields(ctx context.Context, item *entities.CarouselItem, fields []string) error {
+	return r.db.WithContext(ctx).Model(item).Select(fields).Updates(item).Error
+}
+
+// Delete deletes a carousel item
+func (r *CarouselItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.CarouselItem{}, id).Error
+}
+
+// UpdatePosition updates carousel item position
+func (r *CarouselItemRepository) UpdatePosition(ctx context.Context, itemID uuid.UUID, position int) error {
+	return r.db.WithContext(ctx).Model(&entities.CarouselItem{}).Where("id = ?", itemID).Update("position", position).Error
+}
+
+// GetActiveItems gets active carousel items within their validity window
+func (r *CarouselItemRepository) GetActiveItems(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error) {
+	var items []*entities.CarouselItem
+	err := r.db.WithContext(ctx).
+		Where("menu_carousel_id = ? AND is_active = ?", menuCarouselID, true).
+		Where("valid_from IS NULL OR valid_from <= NOW()").
+		Where("valid_until IS NULL OR valid_until >= NOW()").
+		Order("position ASC").
+		Limit(limit).Offset(offset).
+		Find(&items).Error
+	return items, err
+}
+
+// GetByItemType gets carousel items of a carousel by item type
+func (r *CarouselItemRepository) GetByItemType(ctx context.Context, menuCarouselID uuid.UUID, itemType entities.CarouselItemType, limit, offset int) ([]*entities.CarouselItem, error) {
+	var items []*entities.CarouselItem
+	err := r.db.WithContext(ctx).Where("menu_carousel_id = ? AND item_type = ?", menuCarouselID, itemType).Order("position ASC").Limit(limit).Offset(offset).Find(&items).Error
+	return items, err
+}
+
+// CarouselTextOverlayRepository implements the carousel text overlay repository interface
+type CarouselTextOverlayRepository struct {
+	db *gorm.DB
+}
+
+// NewCarouselTextOverlayRepository creates a new carousel text overlay repository
+func NewCarouselTextOverlayRepository(db *gorm.DB) interfaces.CarouselTextOverlayRepository {
+	return &CarouselTextOverlayRepository{db: db}
+}
+
+// Create creates a new text overlay
+func (r *CarouselTextOverlayRepository) Create(ctx context.Context, overlay *entities.CarouselTextOverlay) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(overlay).Error
+}
+
+// GetByID gets a text overlay by ID
+func (r *CarouselTextOverlayRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.CarouselTextOverlay, error) {
+	var overlay entities.CarouselTextOverlay
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&overlay).Error
+	if err != nil {
+		return nil, err
+	}
+	return &overlay, nil
+}
+
+// GetByCarouselItemID gets text overlays by carousel item ID
+func (r *CarouselTextOverlayRepository) GetByCarouselItemID(ctx context.Context, carouselItemID uuid.UUID, limit, offset int) ([]*entities.CarouselTextOverlay, error) {
+	var overlays []*entities.CarouselTextOverlay
+	err := r.db.WithContext(ctx).Where("carousel_item_id = ?", carouselItemID).Order("created_at ASC").Limit(limit).Offset(offset).Find(&overlays).Error
+	return overlays, err
+}
+
+// GetAll gets all text overlays with pagination
+func (r *CarouselTextOverlayRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselTextOverlay, error) {
+	var overlays []*entities.CarouselTextOverlay
+	err := r.db.WithContext(ctx).Order("created_at ASC").Limit(limit).Offset(offset).Find(&overlays).Error
+	return overlays, err
+}
+
+// Update updates a text overlay
+func (r *CarouselTextOverlayRepository) Update(ctx context.Context, overlay *entities.CarouselTextOverlay) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(overlay).Error
+}
+
+// UpdateFields updates the given columns of a text overlay, leaving the others untouched
+func (r *CarouselTextOverlayRepository) UpdateFields(ctx context.Context, overlay *entities.CarouselTextOverlay, fields []string) error {
+	return r.db.WithContext(ctx).Model(overlay).Select(fields).Updates(overlay).Error
+}
+
+// Delete deletes a text overlay
+func (r *CarouselTextOverlayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.CarouselTextOverlay{}, id).Error
+}
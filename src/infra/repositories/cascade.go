@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The foreign keys created by the migrations only fire on hard deletes. Repositories
+// soft delete, so the same policy is applied here inside a transaction:
+//
+//	Menu -> MenuFloorPlan/MenuCarousel/MenuPins   cascade
+//	MenuFloorPlan -> Tower -> Floor -> Suite      cascade
+//	MenuCarousel -> CarouselItem                  cascade
+//	MenuPins -> PinMarker                         cascade
+//	File -> FileVariant                           cascade, when purged
+//	File references (logo, banner, ...)           set null, when purged
+//	Enterprise <- User/Menu/File, Menu <- sub-menus,
+//	File <- PinMarkerImage                        restrict
+//
+// Parents are deleted before their children, so a cascaded child is never stamped with
+// an older deleted_at than its parent. The trash bin relies on this to restore exactly
+// the children that went away with the parent.
+
+// childIDs returns the IDs of the live rows of model whose column points at one of parentIDs
+func childIDs(tx *gorm.DB, model interface{}, column string, parentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(parentIDs) == 0 {
+		return ids, nil
+	}
+	err := tx.Model(model).Where(column+" IN ?", parentIDs).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// countLive counts the live rows of model matching the condition
+func countLive(tx *gorm.DB, model interface{}, query string, args ...interface{}) (int64, error) {
+	var count int64
+	err := tx.Model(model).Where(query, args...).Count(&count).Error
+	return count, err
+}
+
+func softDelete(tx *gorm.DB, model interface{}, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Where("id IN ?", ids).Delete(model).Error
+}
+
+func deleteMenus(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.Menu{}, ids); err != nil {
+		return err
+	}
+
+	floorPlanIDs, err := childIDs(tx, &entities.MenuFloorPlan{}, "menu_id", ids)
+	if err != nil {
+		return err
+	}
+	if err := deleteFloorPlans(tx, floorPlanIDs); err != nil {
+		return err
+	}
+
+	carouselIDs, err := childIDs(tx, &entities.MenuCarousel{}, "menu_id", ids)
+	if err != nil {
+		return err
+	}
+	if err := deleteCarousels(tx, carouselIDs); err != nil {
+		return err
+	}
+
+	pinsIDs, err := childIDs(tx, &entities.MenuPins{}, "menu_id", ids)
+	if err != nil {
+		return err
+	}
+	return deleteMenuPins(tx, pinsIDs)
+}
+
+func deleteFloorPlans(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.MenuFloorPlan{}, ids); err != nil {
+		return err
+	}
+	towerIDs, err := childIDs(tx, &entities.Tower{}, "menu_floor_plan_id", ids)
+	if err != nil {
+		return err
+	}
+	return deleteTowers(tx, towerIDs)
+}
+
+func deleteTowers(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.Tower{}, ids); err != nil {
+		return err
+	}
+	floorIDs, err := childIDs(tx, &entities.Floor{}, "tower_id", ids)
+	if err != nil {
+		return err
+	}
+	return deleteFloors(tx, floorIDs)
+}
+
+func deleteFloors(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.Floor{}, ids); err != nil {
+		return err
+	}
+	suiteIDs, err := childIDs(tx, &entities.Suite{}, "floor_id", ids)
+	if err != nil {
+		return err
+	}
+	return softDelete(tx, &entities.Suite{}, suiteIDs)
+}
+
+func deleteCarousels(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.MenuCarousel{}, ids); err != nil {
+		return err
+	}
+	itemIDs, err := childIDs(tx, &entities.CarouselItem{}, "menu_carousel_id", ids)
+	if err != nil {
+		return err
+	}
+	return softDelete(tx, &entities.CarouselItem{}, itemIDs)
+}
+
+func deleteMenuPins(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := softDelete(tx, &entities.MenuPins{}, ids); err != nil {
+		return err
+	}
+	markerIDs, err := childIDs(tx, &entities.PinMarker{}, "menu_pin_id", ids)
+	if err != nil {
+		return err
+	}
+	return softDelete(tx, &entities.PinMarker{}, markerIDs)
+}
+
+// enterpriseBlockers reports the live users, menus and files that prevent deleting an
+// enterprise
+func enterpriseBlockers(tx *gorm.DB, id uuid.UUID) error {
+	users, err := countLive(tx, &entities.User{}, "enterprise_id = ?", id)
+	if err != nil {
+		return err
+	}
+	menus, err := countLive(tx, &entities.Menu{}, "enterprise_id = ?", id)
+	if err != nil {
+		return err
+	}
+	files, err := countLive(tx, &entities.File{}, "enterprise_id = ?", id)
+	if err != nil {
+		return err
+	}
+	return interfaces.NewRestrictError("enterprise", id,
+		interfaces.Blocker{Entity: "users", Count: users},
+		interfaces.Blocker{Entity: "menus", Count: menus},
+		interfaces.Blocker{Entity: "files", Count: files},
+	)
+}
+
+// menuBlockers reports the live sub-menus that prevent deleting a menu
+func menuBlockers(tx *gorm.DB, id uuid.UUID) error {
+	children, err := countLive(tx, &entities.Menu{}, "parent_menu_id = ?", id)
+	if err != nil {
+		return err
+	}
+	return interfaces.NewRestrictError("menu", id, interfaces.Blocker{Entity: "sub_menus", Count: children})
+}
+
+// fileBlockers reports the images of live pin markers that prevent deleting a file
+func fileBlockers(tx *gorm.DB, id uuid.UUID) error {
+	images, err := countLive(tx, &entities.PinMarkerImage{},
+		"file_id = ? AND EXISTS (SELECT 1 FROM pin_markers WHERE pin_markers.id = pin_marker_images.pin_marker_id AND pin_markers.deleted_at IS NULL)", id)
+	if err != nil {
+		return err
+	}
+	return interfaces.NewRestrictError("file", id, interfaces.Blocker{Entity: "pin_marker_images", Count: images})
+}
+
+// clearFileReferences sets every nullable reference to the files to NULL, soft deleted
+// rows included
+func clearFileReferences(tx *gorm.DB, ids []uuid.UUID) error {
+	for _, ref := range fileReferences {
+		if !ref.nullable {
+			continue
+		}
+		if err := tx.Table(ref.table).Where(ref.column+" IN ?", ids).Update(ref.column, nil).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnterpriseRepository implements the enterprise repository interface
+type EnterpriseRepository struct {
+	db *gorm.DB
+}
+
+// NewEnterpriseRepository creates a new enterprise repository
+func NewEnterpriseRepository(db *gorm.DB) interfaces.EnterpriseRepository {
+	return &EnterpriseRepository{db: db}
+}
+
+// Create creates a new enterprise
+func (r *EnterpriseRepository) Create(ctx context.Context, enterprise *entities.Enterprise) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(enterprise).Error
+}
+
+// GetByID gets an enterprise by ID
+func (r *EnterpriseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Enterprise, error) {
+	var enterprise entities.Enterprise
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&enterprise).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enterprise, nil
+}
+
+// GetBySlug gets an enterprise by slug
+func (r *EnterpriseRepository) GetBySlug(ctx context.Context, slug string) (*entities.Enterprise, error) {
+	var enterprise entities.Enterprise
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&enterprise).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enterprise, nil
+}
+
+// GetAll gets all enterprises with pagination
+func (r *EnterpriseRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Enterprise, error) {
+	var enterprises []*entities.Enterprise
+	err := r.db.WithContext(ctx).Order("title ASC").Limit(limit).Offset(offset).Find(&enterprises).Error
+	return enterprises, err
+}
+
+// Update updates an enterprise
+func (r *EnterpriseRepository) Update(ctx context.Context, enterprise *entities.Enterprise) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(enterprise).Error
+}
+
+// UpdateFields updates the given columns of an enterprise, leaving the others untouched
+func (r *EnterpriseRepository) UpdateFields(ctx context.Context, enterprise *entities.Enterprise, fields []string) error {
+	return r.db.WithContext(ctx).Model(enterprise).Select(fields).Updates(enterprise).Error
+}
+
+// Delete deletes an enterprise, returning a RestrictError while it still has users, menus or files
+func (r *EnterpriseRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := enterpriseBlockers(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Enterprise{}, id).Error
+	})
+}
+
+// GetByCity gets enterprises by city
+func (r *EnterpriseRepository) GetByCity(ctx context.Context, city string, limit, offset int) ([]*entities.Enterprise, error) {
+	var enterprises []*entities.Enterprise
+	err := r.db.WithContext(ctx).Where("LOWER(address_city) = LOWER(?)", city).Order("title ASC").Limit(limit).Offset(offset).Find(&enterprises).Error
+	return enterprises, err
+}
+
+// GetByStatus gets enterprises by status
+func (r *EnterpriseRepository) GetByStatus(ctx context.Context, status entities.EnterpriseStatus, limit, offset int) ([]*entities.Enterprise, error) {
+	var enterprises []*entities.Enterprise
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("title ASC").Limit(limit).Offset(offset).Find(&enterprises).Error
+	return enterprises, err
+}
+
+// Search searches enterprises by title, slug, description or city
+func (r *EnterpriseRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entities.Enterprise, error) {
+	var enterprises []*entities.Enterprise
+	pattern := "%" + query + "%"
+	err := r.db.WithContext(ctx).
+		Where("title ILIKE ? OR slug ILIKE ? OR description ILIKE ? OR address_city ILIKE ?", pattern, pattern, pattern, pattern).
+		Order("title ASC").
+		Limit(limit).Offset(offset).
+		Find(&enterprises).Error
+	return enterprises, err
+}
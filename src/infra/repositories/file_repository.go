@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fileReferences lists every column that points at a file, used to detect orphaned files
+// and to clear references when a file is deleted
+var fileReferences = []struct {
+	table    string
+	column   string
+	nullable bool
+}{
+	{"enterprises", "logo_file_id", true},
+	{"users", "avatar_file_id", true},
+	{"floors", "banner_file_id", true},
+	{"floors", "floor_plan_file_id", true},
+	{"suites", "floor_plan_file_id", true},
+	{"menu_carousels", "promotional_video_id", true},
+	{"carousel_items", "background_file_id", true},
+	{"menu_pins", "background_file_id", true},
+	{"menu_pins", "promotional_video_id", true},
+	{"pin_marker_images", "file_id", false},
+}
+
+// FileRepository implements the file repository interface
+type FileRepository struct {
+	db *gorm.DB
+}
+
+// NewFileRepository creates a new file repository
+func NewFileRepository(db *gorm.DB) interfaces.FileRepository {
+	return &FileRepository{db: db}
+}
+
+// Create creates a new file
+func (r *FileRepository) Create(ctx context.Context, file *entities.File) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(file).Error
+}
+
+// GetByID gets a file by ID
+func (r *FileRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.File, error) {
+	var file entities.File
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetByHash gets a file by content hash
+func (r *FileRepository) GetByHash(ctx context.Context, hash string) (*entities.File, error) {
+	var file entities.File
+	err := r.db.WithContext(ctx).Where("file_hash = ?", hash).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetAll gets all files with pagination
+func (r *FileRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	err := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// Update updates a file
+func (r *FileRepository) Update(ctx context.Context, file *entities.File) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(file).Error
+}
+
+// UpdateFields updates the given columns of a file, leaving the others untouched
+func (r *FileRepository) UpdateFields(ctx context.Context, file *entities.File, fields []string) error {
+	return r.db.WithContext(ctx).Model(file).Select(fields).Updates(file).Error
+}
+
+// Delete moves a file to the trash. References to it, its variants and storage objects are
+// kept so restoring the file relinks it, and are only dropped when the file is purged. It
+// returns a RestrictError while pin marker images still use the file.
+func (r *FileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fileBlockers(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&entities.File{}, id).Error
+	})
+}
+
+// GetByUploader gets files uploaded by a user
+func (r *FileRepository) GetByUploader(ctx context.Context, uploaderID uuid.UUID, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	err := r.db.WithContext(ctx).Where("uploaded_by = ?", uploaderID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// GetByType gets files by file type
+func (r *FileRepository) GetByType(ctx context.Context, fileType entities.FileType, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	err := r.db.WithContext(ctx).Where("file_type = ?", fileType).Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// GetByMimeType gets files by mime type
+func (r *FileRepository) GetByMimeType(ctx context.Context, mimeType string, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	err := r.db.WithContext(ctx).Where("mime_type = ?", mimeType).Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// Search searches files using the given filters
+func (r *FileRepository) Search(ctx context.Context, filters interfaces.FileSearchFilters, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	query := r.db.WithContext(ctx).Model(&entities.File{})
+
+	if filters.FileType != nil {
+		query = query.Where("file_type = ?", *filters.FileType)
+	}
+	if filters.MimeType != nil {
+		query = query.Where("mime_type = ?", *filters.MimeType)
+	}
+	if filters.Extension != nil {
+		query = query.Where("LOWER(extension) = LOWER(?)", *filters.Extension)
+	}
+	if filters.UploadedBy != nil {
+		query = query.Where("uploaded_by = ?", *filters.UploadedBy)
+	}
+	if filters.MinSize != nil {
+		query = query.Where("file_size_bytes >= ?", *filters.MinSize)
+	}
+	if filters.MaxSize != nil {
+		query = query.Where("file_size_bytes <= ?", *filters.MaxSize)
+	}
+	if filters.WidthMin != nil {
+		query = query.Where("width >= ?", *filters.WidthMin)
+	}
+	if filters.WidthMax != nil {
+		query = query.Where("width <= ?", *filters.WidthMax)
+	}
+	if filters.HeightMin != nil {
+		query = query.Where("height >= ?", *filters.HeightMin)
+	}
+	if filters.HeightMax != nil {
+		query = query.Where("height <= ?", *filters.HeightMax)
+	}
+	if filters.DurationMin != nil {
+		query = query.Where("duration_seconds >= ?", *filters.DurationMin)
+	}
+	if filters.DurationMax != nil {
+		query = query.Where("duration_seconds <= ?", *filters.DurationMax)
+	}
+
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// GetByStoragePath gets a file by storage path
+func (r *FileRepository) GetByStoragePath(ctx context.Context, storagePath string) (*entities.File, error) {
+	var file entities.File
+	err := r.db.WithContext(ctx).Where("storage_path = ?", storagePath).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetOrphaned gets files that are not referenced by any other entity
+func (r *FileRepository) GetOrphaned(ctx context.Context, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	query := r.db.WithContext(ctx).Model(&entities.File{})
+	for _, ref := range fileReferences {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM " + ref.table + " WHERE " + ref.table + "." + ref.column + " = files.id)")
+	}
+	err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, err
+}
+
+// GetImagesByDimensions gets images within the given dimension range
+func (r *FileRepository) GetImagesByDimensions(ctx context.Context, minWidth, maxWidth, minHeight, maxHeight int, limit, offset int) ([]*entities.File, error) {
+	var files []*entities.File
+	err := r.db.WithContext(ctx).
+		Where("file_type = ?", entities.FileTypeImage).
+		Where("width BETWEEN ? AND ?", minWidth, maxWidth).
+		Where("height BETWEEN ? AND ?", minHeight, maxHeight).
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&files).Error
+	return files, err
+}
+
+// FileVariantRepository implements the file variant repository interface
+type FileVariantRepository struct {
+	db *gorm.DB
+}
+
+// NewFileVariantRepository creates a new file variant repository
+func NewFileVariantRepository(db *gorm.DB) interfaces.FileVariantRepository {
+	return &FileVariantRepository{db: db}
+}
+
+// Create creates a new file variant
+func (r *FileVariantRepository) Create(ctx context.Context, variant *entities.FileVariant) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(variant).Error
+}
+
+// GetByID gets a file variant by ID
+func (r *FileVariantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.FileVariant, error) {
+	var variant entities.FileVariant
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&variant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetByOriginalFileID gets variants of a file
+func (r *FileVariantRepository) GetByOriginalFileID(ctx context.Context, originalFileID uuid.UUID, limit, offset int) ([]*entities.FileVariant, error) {
+	var variants []*entities.FileVariant
+	err := r.db.WithContext(ctx).Where("original_file_id = ?", originalFileID).Order("width ASC").Limit(limit).Offset(offset).Find(&variants).Error
+	return variants, err
+}
+
+// GetByVariantName gets a variant of a file by name
+func (r *FileVariantRepository) GetByVariantName(ctx context.Context, originalFileID uuid.UUID, variantName string) (*entities.FileVariant, error) {
+	var variant entities.FileVariant
+	err := r.db.WithContext(ctx).Where("original_file_id = ? AND variant_name = ?", originalFileID, variantName).First(&variant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetAll gets all file variants with pagination
+func (r *FileVariantRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.FileVariant, error) {
+	var variants []*entities.FileVariant
+	err := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&variants).Error
+	return variants, err
+}
+
+// Update updates a file variant
+func (r *FileVariantRepository) Update(ctx context.Context, variant *entities.FileVariant) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(variant).Error
+}
+
+// Delete deletes a file variant
+func (r *FileVariantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.FileVariant{}, id).Error
+}
+
+// DeleteByOriginalFile deletes every variant of a file
+func (r *FileVariantRepository) DeleteByOriginalFile(ctx context.Context, originalFileID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("original_file_id = ?", originalFileID).Delete(&entities.FileVariant{}).Error
+}
+
+// GetByDimensions gets variants with the given dimensions
+func (r *FileVariantRepository) GetByDimensions(ctx context.Context, width, height int, limit, offset int) ([]*entities.FileVariant, error) {
+	var variants []*entities.FileVariant
+	err := r.db.WithContext(ctx).Where("width = ? AND height = ?", width, height).Order("created_at DESC").Limit(limit).Offset(offset).Find(&variants).Error
+	return variants, err
+}
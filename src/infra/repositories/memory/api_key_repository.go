@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository implements the API key repository interface in memory
+type APIKeyRepository struct {
+	store *Store
+}
+
+// NewAPIKeyRepository creates a new in-memory API key repository
+func NewAPIKeyRepository(store *Store) interfaces.APIKeyRepository {
+	return &APIKeyRepository{store: store}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.apiKeys, key)
+}
+
+// GetByID gets an API key by ID
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.apiKeys, id)
+}
+
+// GetByHash gets an API key by the hash of its secret
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.apiKeys, func(k *entities.APIKey) bool { return k.KeyHash == keyHash })
+}
+
+// GetAll gets the API keys of the caller's enterprise, newest first
+func (r *APIKeyRepository) GetAll(ctx context.Context) ([]*entities.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := filter(ctx, r.store, r.store.apiKeys, func(*entities.APIKey) bool { return true })
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Revoke revokes an API key
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := find(ctx, r.store, r.store.apiKeys, id); err != nil {
+		return err
+	}
+	update(ctx, r.store, r.store.apiKeys, id, func(k *entities.APIKey) {
+		if k.RevokedAt == nil {
+			now := time.Now()
+			k.RevokedAt = &now
+		}
+	})
+	return nil
+}
+
+// TouchLastUsed records the last use of an API key
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, ip string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	update(ctx, r.store, r.store.apiKeys, id, func(k *entities.APIKey) {
+		k.LastUsedAt = &usedAt
+		k.LastUsedIP = &ip
+	})
+	return nil
+}
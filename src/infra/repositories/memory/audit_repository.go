@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogRepository implements the audit log repository interface in memory
+type AuditLogRepository struct {
+	store *Store
+}
+
+// NewAuditLogRepository creates a new in-memory audit log repository
+func NewAuditLogRepository(store *Store) interfaces.AuditLogRepository {
+	return &AuditLogRepository{store: store}
+}
+
+// Create records an audit log entry
+func (r *AuditLogRepository) Create(ctx context.Context, log *entities.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.auditLogs, log)
+}
+
+// GetByID gets an audit log entry by ID
+func (r *AuditLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.auditLogs, id)
+}
+
+// Search searches audit log entries using the given filters, newest first
+func (r *AuditLogRepository) Search(ctx context.Context, filters interfaces.AuditLogSearchFilters, limit, offset int) ([]*entities.AuditLog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	logs := filter(ctx, r.store, r.store.auditLogs, func(l *entities.AuditLog) bool {
+		switch {
+		case filters.EntityType != nil && l.EntityType != *filters.EntityType,
+			filters.EntityID != nil && l.EntityID != *filters.EntityID,
+			filters.UserID != nil && (l.UserID == nil || *l.UserID != *filters.UserID),
+			filters.EnterpriseID != nil && (l.EnterpriseID == nil || *l.EnterpriseID != *filters.EnterpriseID),
+			filters.Action != nil && l.Action != *filters.Action,
+			filters.From != nil && l.CreatedAt.Before(*filters.From),
+			filters.To != nil && l.CreatedAt.After(*filters.To):
+			return false
+		}
+		return true
+	})
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].CreatedAt.After(logs[j].CreatedAt) })
+	return paginate(logs, limit, offset), nil
+}
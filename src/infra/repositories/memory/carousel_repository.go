@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MenuCarouselRepository implements the menu carousel repository interface in memory
+type MenuCarouselRepository struct {
+	store *Store
+}
+
+// NewMenuCarouselRepository creates a new in-memory menu carousel repository
+func NewMenuCarouselRepository(store *Store) interfaces.MenuCarouselRepository {
+	return &MenuCarouselRepository{store: store}
+}
+
+// Create creates a new menu carousel
+func (r *MenuCarouselRepository) Create(ctx context.Context, carousel *entities.MenuCarousel) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Unique constraints hold across enterprises
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.menuCarousels, func(c *entities.MenuCarousel) bool { return c.MenuID == carousel.MenuID }); err == nil {
+		return interfaces.NewConflictError("menu carousel", "menu_id", gorm.ErrDuplicatedKey)
+	}
+	return insert(ctx, r.store, r.store.menuCarousels, carousel)
+}
+
+// GetByID gets a menu carousel by ID
+func (r *MenuCarouselRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.MenuCarousel, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.menuCarousels, id)
+}
+
+// GetByMenuID gets the carousel of a menu
+func (r *MenuCarouselRepository) GetByMenuID(ctx context.Context, menuID uuid.UUID) (*entities.MenuCarousel, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.menuCarousels, func(c *entities.MenuCarousel) bool { return c.MenuID == menuID })
+}
+
+// GetAll gets all menu carousels with pagination
+func (r *MenuCarouselRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.MenuCarousel, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginate(filter(ctx, r.store, r.store.menuCarousels, nil), limit, offset), nil
+}
+
+// Update updates a menu carousel
+func (r *MenuCarouselRepository) Update(ctx context.Context, carousel *entities.MenuCarousel) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.menuCarousels, carousel)
+}
+
+// Delete deletes a menu carousel with its items
+func (r *MenuCarouselRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.deleteCarousel(ctx, id)
+	return nil
+}
+
+// CarouselItemRepository implements the carousel item repository interface in memory
+type CarouselItemRepository struct {
+	store *Store
+}
+
+// NewCarouselItemRepository creates a new in-memory carousel item repository
+func NewCarouselItemRepository(store *Store) interfaces.CarouselItemRepository {
+	return &CarouselItemRepository{store: store}
+}
+
+// Create creates a new carousel item
+func (r *CarouselItemRepository) Create(ctx context.Context, item *entities.CarouselItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.carouselItems, item)
+}
+
+// GetByID gets a carousel item by ID
+func (r *CarouselItemRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.CarouselItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.carouselItems, id)
+}
+
+// GetByMenuCarouselID gets carousel items by menu carousel ID
+func (r *CarouselItemRepository) GetByMenuCarouselID(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error) {
+	items := r.list(ctx, func(i *entities.CarouselItem) bool { return i.MenuCarouselID == menuCarouselID })
+	return paginate(items, limit, offset), nil
+}
+
+// GetAll gets all carousel items with pagination
+func (r *CarouselItemRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselItem, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a carousel item
+func (r *CarouselItemRepository) Update(ctx context.Context, item *entities.CarouselItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.carouselItems, item)
+}
+
+// UpdateFields updates a carousel item, the whole row being replaced in memory
+func (r *CarouselItemRepository) UpdateFields(ctx context.Context, item *entities.CarouselItem, fields []string) error {
+	return r.Update(ctx, item)
+}
+
+// Delete deletes a carousel item
+func (r *CarouselItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.carouselItems, id)
+	return nil
+}
+
+// UpdatePosition updates carousel item position
+func (r *CarouselItemRepository) UpdatePosition(ctx context.Context, itemID uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.carouselItems, itemID, func(i *entities.CarouselItem) { i.Position = position })
+	return nil
+}
+
+// GetActiveItems gets active carousel items within their validity window
+func (r *CarouselItemRepository) GetActiveItems(ctx context.Context, menuCarouselID uuid.UUID, limit, offset int) ([]*entities.CarouselItem, error) {
+	now := time.Now()
+	items := r.list(ctx, func(i *entities.CarouselItem) bool {
+		return i.MenuCarouselID == menuCarouselID && i.IsActive &&
+			(i.ValidFrom == nil || !i.ValidFrom.After(now)) &&
+			(i.ValidUntil == nil || !i.ValidUntil.Before(now))
+	})
+	return paginate(items, limit, offset), nil
+}
+
+// GetByItemType gets carousel items of a carousel by item type
+func (r *CarouselItemRepository) GetByItemType(ctx context.Context, menuCarouselID uuid.UUID, itemType entities.CarouselItemType, limit, offset int) ([]*entities.CarouselItem, error) {
+	items := r.list(ctx, func(i *entities.CarouselItem) bool {
+		return i.MenuCarouselID == menuCarouselID && i.ItemType == itemType
+	})
+	return paginate(items, limit, offset), nil
+}
+
+func (r *CarouselItemRepository) list(ctx context.Context, match func(*entities.CarouselItem) bool) []*entities.CarouselItem {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	items := filter(ctx, r.store, r.store.carouselItems, match)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	return items
+}
+
+// CarouselTextOverlayRepository implements the carousel text overlay repository interface in memory
+type CarouselTextOverlayRepository struct {
+	store *Store
+}
+
+// NewCarouselTextOverlayRepository creates a new in-memory carousel text overlay repository
+func NewCarouselTextOverlayRepository(store *Store) interfaces.CarouselTextOverlayRepository {
+	return &CarouselTextOverlayRepository{store: store}
+}
+
+// Create creates a new text overlay
+func (r *CarouselTextOverlayRepository) Create(ctx context.Context, overlay *entities.CarouselTextOverlay) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.carouselTextOverlays, overlay)
+}
+
+// GetByID gets a text overlay by ID
+func (r *CarouselTextOverlayRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.CarouselTextOverlay, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.carouselTextOverlays, id)
+}
+
+// GetByCarouselItemID gets text overlays by carousel item ID
+func (r *CarouselTextOverlayRepository) GetByCarouselItemID(ctx context.Context, carouselItemID uuid.UUID, limit, offset int) ([]*entities.CarouselTextOverlay, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	overlays := filter(ctx, r.store, r.store.carouselTextOverlays, func(o *entities.CarouselTextOverlay) bool { return o.CarouselItemID == carouselItemID })
+	return paginate(overlays, limit, offset), nil
+}
+
+// GetAll gets all text overlays with pagination
+func (r *CarouselTextOverlayRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.CarouselTextOverlay, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginate(filter(ctx, r.store, r.store.carouselTextOverlays, nil), limit, offset), nil
+}
+
+// Update updates a text overlay
+func (r *CarouselTextOverlayRepository) Update(ctx context.Context, overlay *entities.CarouselTextOverlay) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.carouselTextOverlays, overlay)
+}
+
+// UpdateFields updates a text overlay, the whole row being replaced in memory
+func (r *CarouselTextOverlayRepository) UpdateFields(ctx context.Context, overlay *entities.CarouselTextOverlay, fields []string) error {
+	return r.Update(ctx, overlay)
+}
+
+// Delete deletes a text overlay
+func (r *CarouselTextOverlayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.carouselTextOverlays, id)
+	return nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// The helpers below mirror the deletion policy of the GORM repositories (see
+// repositories/cascade.go), deleting parents before their children. Callers must hold
+// the store write lock.
+
+func (s *Store) deleteMenu(ctx context.Context, id uuid.UUID) {
+	remove(ctx, s, s.menus, id)
+	for carouselID, carousel := range s.menuCarousels {
+		if carousel.MenuID == id && !isDeleted(carousel) {
+			s.deleteCarousel(ctx, carouselID)
+		}
+	}
+	for pinsID, pins := range s.menuPins {
+		if pins.MenuID == id && !isDeleted(pins) {
+			s.deleteMenuPins(ctx, pinsID)
+		}
+	}
+}
+
+func (s *Store) deleteTower(ctx context.Context, id uuid.UUID) {
+	remove(ctx, s, s.towers, id)
+	for floorID, floor := range s.floors {
+		if floor.TowerID == id && !isDeleted(floor) {
+			s.deleteFloor(ctx, floorID)
+		}
+	}
+}
+
+func (s *Store) deleteFloor(ctx context.Context, id uuid.UUID) {
+	remove(ctx, s, s.floors, id)
+	for suiteID, suite := range s.suites {
+		if suite.FloorID == id && !isDeleted(suite) {
+			remove(ctx, s, s.suites, suiteID)
+		}
+	}
+}
+
+func (s *Store) deleteCarousel(ctx context.Context, id uuid.UUID) {
+	remove(ctx, s, s.menuCarousels, id)
+	for itemID, item := range s.carouselItems {
+		if item.MenuCarouselID == id && !isDeleted(item) {
+			remove(ctx, s, s.carouselItems, itemID)
+		}
+	}
+}
+
+func (s *Store) deleteMenuPins(ctx context.Context, id uuid.UUID) {
+	remove(ctx, s, s.menuPins, id)
+	for markerID, marker := range s.pinMarkers {
+		if marker.MenuPinID == id && !isDeleted(marker) {
+			remove(ctx, s, s.pinMarkers, markerID)
+		}
+	}
+}
+
+// clearFileReferences sets every nullable reference to the file to nil, when it is purged
+func (s *Store) clearFileReferences(id uuid.UUID) {
+	unset := func(ref **uuid.UUID) {
+		if *ref != nil && **ref == id {
+			*ref = nil
+		}
+	}
+	for _, e := range s.enterprises {
+		unset(&e.LogoFileID)
+	}
+	for _, u := range s.users {
+		unset(&u.AvatarFileID)
+	}
+	for _, f := range s.floors {
+		unset(&f.BannerFileID)
+		unset(&f.FloorPlanFileID)
+	}
+	for _, su := range s.suites {
+		unset(&su.FloorPlanFileID)
+	}
+	for _, c := range s.menuCarousels {
+		unset(&c.PromotionalVideoID)
+	}
+	for _, i := range s.carouselItems {
+		unset(&i.BackgroundFileID)
+	}
+	for _, p := range s.menuPins {
+		unset(&p.BackgroundFileID)
+		unset(&p.PromotionalVideoID)
+	}
+}
+
+func (s *Store) enterpriseBlockers(id uuid.UUID) error {
+	var users, menus, files int64
+	for _, u := range s.users {
+		if u.EnterpriseID == id && !isDeleted(u) {
+			users++
+		}
+	}
+	for _, m := range s.menus {
+		if m.EnterpriseID == id && !isDeleted(m) {
+			menus++
+		}
+	}
+	for _, f := range s.files {
+		if f.EnterpriseID == id && !isDeleted(f) {
+			files++
+		}
+	}
+	return interfaces.NewRestrictError("enterprise", id,
+		interfaces.Blocker{Entity: "users", Count: users},
+		interfaces.Blocker{Entity: "menus", Count: menus},
+		interfaces.Blocker{Entity: "files", Count: files},
+	)
+}
+
+func (s *Store) menuBlockers(id uuid.UUID) error {
+	var children int64
+	for _, m := range s.menus {
+		if m.ParentMenuID != nil && *m.ParentMenuID == id && !isDeleted(m) {
+			children++
+		}
+	}
+	return interfaces.NewRestrictError("menu", id, interfaces.Blocker{Entity: "sub_menus", Count: children})
+}
+
+func (s *Store) fileBlockers(id uuid.UUID) error {
+	var images int64
+	for _, image := range s.pinMarkerImages {
+		if image.FileID != id {
+			continue
+		}
+		if marker, ok := s.pinMarkers[image.PinMarkerID]; ok && !isDeleted(marker) {
+			images++
+		}
+	}
+	return interfaces.NewRestrictError("file", id, interfaces.Blocker{Entity: "pin_marker_images", Count: images})
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EnterpriseRepository implements the enterprise repository interface in memory
+type EnterpriseRepository struct {
+	store *Store
+}
+
+// NewEnterpriseRepository creates a new in-memory enterprise repository
+func NewEnterpriseRepository(store *Store) interfaces.EnterpriseRepository {
+	return &EnterpriseRepository{store: store}
+}
+
+// Create creates a new enterprise
+func (r *EnterpriseRepository) Create(ctx context.Context, enterprise *entities.Enterprise) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Unique constraints hold across enterprises
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.enterprises, func(e *entities.Enterprise) bool { return e.Slug == enterprise.Slug }); err == nil {
+		return interfaces.NewConflictError("enterprise", "slug", gorm.ErrDuplicatedKey)
+	}
+	return insert(ctx, r.store, r.store.enterprises, enterprise)
+}
+
+// GetByID gets an enterprise by ID
+func (r *EnterpriseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Enterprise, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.enterprises, id)
+}
+
+// GetBySlug gets an enterprise by slug
+func (r *EnterpriseRepository) GetBySlug(ctx context.Context, slug string) (*entities.Enterprise, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.enterprises, func(e *entities.Enterprise) bool { return e.Slug == slug })
+}
+
+// GetAll gets all enterprises with pagination
+func (r *EnterpriseRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Enterprise, error) {
+	return r.list(ctx, nil, limit, offset), nil
+}
+
+// Update updates an enterprise
+func (r *EnterpriseRepository) Update(ctx context.Context, enterprise *entities.Enterprise) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.enterprises, func(e *entities.Enterprise) bool { return e.Slug == enterprise.Slug && e.ID != enterprise.ID }); err == nil {
+		return interfaces.NewConflictError("enterprise", "slug", gorm.ErrDuplicatedKey)
+	}
+	return save(ctx, r.store, r.store.enterprises, enterprise)
+}
+
+// UpdateFields updates an enterprise, the whole row being replaced in memory
+func (r *EnterpriseRepository) UpdateFields(ctx context.Context, enterprise *entities.Enterprise, fields []string) error {
+	return r.Update(ctx, enterprise)
+}
+
+// Delete deletes an enterprise, returning a RestrictError while it still has users, menus or files
+func (r *EnterpriseRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.enterpriseBlockers(id); err != nil {
+		return err
+	}
+	remove(ctx, r.store, r.store.enterprises, id)
+	return nil
+}
+
+// GetByCity gets enterprises by city
+func (r *EnterpriseRepository) GetByCity(ctx context.Context, city string, limit, offset int) ([]*entities.Enterprise, error) {
+	return r.list(ctx, func(e *entities.Enterprise) bool { return strings.EqualFold(e.AddressCity, city) }, limit, offset), nil
+}
+
+// GetByStatus gets enterprises by status
+func (r *EnterpriseRepository) GetByStatus(ctx context.Context, status entities.EnterpriseStatus, limit, offset int) ([]*entities.Enterprise, error) {
+	return r.list(ctx, func(e *entities.Enterprise) bool { return e.Status == status }, limit, offset), nil
+}
+
+// Search searches enterprises by title, slug, description or city
+func (r *EnterpriseRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entities.Enterprise, error) {
+	query = strings.ToLower(query)
+	return r.list(ctx, func(e *entities.Enterprise) bool {
+		return containsFold(e.Title, query) || containsFold(e.Slug, query) ||
+			(e.Description != nil && containsFold(*e.Description, query)) || containsFold(e.AddressCity, query)
+	}, limit, offset), nil
+}
+
+func (r *EnterpriseRepository) list(ctx context.Context, match func(*entities.Enterprise) bool, limit, offset int) []*entities.Enterprise {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	enterprises := filter(ctx, r.store, r.store.enterprises, match)
+	sort.SliceStable(enterprises, func(i, j int) bool { return enterprises[i].Title < enterprises[j].Title })
+	return paginate(enterprises, limit, offset)
+}
+
+// containsFold reports whether s contains the lower-cased substr, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), substr)
+}
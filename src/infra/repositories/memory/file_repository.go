@@ -0,0 +1,291 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FileRepository implements the file repository interface in memory
+type FileRepository struct {
+	store *Store
+}
+
+// NewFileRepository creates a new in-memory file repository
+func NewFileRepository(store *Store) interfaces.FileRepository {
+	return &FileRepository{store: store}
+}
+
+// Create creates a new file
+func (r *FileRepository) Create(ctx context.Context, file *entities.File) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if file.FileHash != nil {
+		// Unique constraints hold across enterprises
+		if _, err := first(tenant.Unscoped(ctx), r.store, r.store.files, func(f *entities.File) bool { return f.FileHash != nil && *f.FileHash == *file.FileHash }); err == nil {
+			return interfaces.NewConflictError("file", "file_hash", gorm.ErrDuplicatedKey)
+		}
+	}
+	return insert(ctx, r.store, r.store.files, file)
+}
+
+// GetByID gets a file by ID
+func (r *FileRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.File, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.files, id)
+}
+
+// GetByHash gets a file by content hash
+func (r *FileRepository) GetByHash(ctx context.Context, hash string) (*entities.File, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.files, func(f *entities.File) bool { return f.FileHash != nil && *f.FileHash == hash })
+}
+
+// GetAll gets all files with pagination
+func (r *FileRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.File, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a file
+func (r *FileRepository) Update(ctx context.Context, file *entities.File) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.files, file)
+}
+
+// UpdateFields updates a file, the whole row being replaced in memory
+func (r *FileRepository) UpdateFields(ctx context.Context, file *entities.File, fields []string) error {
+	return r.Update(ctx, file)
+}
+
+// Delete moves a file to the trash. References to it, its variants and storage objects are
+// kept so restoring the file relinks it, and are only dropped when the file is purged. It
+// returns a RestrictError while pin marker images still use the file.
+func (r *FileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fileBlockers(id); err != nil {
+		return err
+	}
+	remove(ctx, r.store, r.store.files, id)
+	return nil
+}
+
+// GetByUploader gets files uploaded by a user
+func (r *FileRepository) GetByUploader(ctx context.Context, uploaderID uuid.UUID, limit, offset int) ([]*entities.File, error) {
+	files := r.list(ctx, func(f *entities.File) bool { return f.UploadedBy != nil && *f.UploadedBy == uploaderID })
+	return paginate(files, limit, offset), nil
+}
+
+// GetByType gets files by file type
+func (r *FileRepository) GetByType(ctx context.Context, fileType entities.FileType, limit, offset int) ([]*entities.File, error) {
+	return paginate(r.list(ctx, func(f *entities.File) bool { return f.FileType == fileType }), limit, offset), nil
+}
+
+// GetByMimeType gets files by mime type
+func (r *FileRepository) GetByMimeType(ctx context.Context, mimeType string, limit, offset int) ([]*entities.File, error) {
+	return paginate(r.list(ctx, func(f *entities.File) bool { return f.MimeType == mimeType }), limit, offset), nil
+}
+
+// Search searches files using the given filters
+func (r *FileRepository) Search(ctx context.Context, filters interfaces.FileSearchFilters, limit, offset int) ([]*entities.File, error) {
+	files := r.list(ctx, func(f *entities.File) bool {
+		switch {
+		case filters.FileType != nil && f.FileType != *filters.FileType,
+			filters.MimeType != nil && f.MimeType != *filters.MimeType,
+			filters.Extension != nil && !strings.EqualFold(f.Extension, *filters.Extension),
+			filters.UploadedBy != nil && (f.UploadedBy == nil || *f.UploadedBy != *filters.UploadedBy),
+			filters.MinSize != nil && f.FileSizeBytes < *filters.MinSize,
+			filters.MaxSize != nil && f.FileSizeBytes > *filters.MaxSize,
+			!intInRange(f.Width, filters.WidthMin, filters.WidthMax),
+			!intInRange(f.Height, filters.HeightMin, filters.HeightMax),
+			!intInRange(f.DurationSeconds, filters.DurationMin, filters.DurationMax):
+			return false
+		}
+		return true
+	})
+	return paginate(files, limit, offset), nil
+}
+
+// GetByStoragePath gets a file by storage path
+func (r *FileRepository) GetByStoragePath(ctx context.Context, storagePath string) (*entities.File, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.files, func(f *entities.File) bool { return f.StoragePath == storagePath })
+}
+
+// GetOrphaned gets files that are not referenced by any other entity
+func (r *FileRepository) GetOrphaned(ctx context.Context, limit, offset int) ([]*entities.File, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	referenced := r.store.referencedFiles()
+	files := filter(ctx, r.store, r.store.files, func(f *entities.File) bool { return !referenced[f.ID] })
+	return paginate(files, limit, offset), nil
+}
+
+// GetImagesByDimensions gets images within the given dimension range
+func (r *FileRepository) GetImagesByDimensions(ctx context.Context, minWidth, maxWidth, minHeight, maxHeight int, limit, offset int) ([]*entities.File, error) {
+	files := r.list(ctx, func(f *entities.File) bool {
+		return f.FileType == entities.FileTypeImage &&
+			intInRange(f.Width, &minWidth, &maxWidth) &&
+			intInRange(f.Height, &minHeight, &maxHeight)
+	})
+	return paginate(files, limit, offset), nil
+}
+
+// list returns live files, newest first
+func (r *FileRepository) list(ctx context.Context, match func(*entities.File) bool) []*entities.File {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	files := filter(ctx, r.store, r.store.files, match)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].CreatedAt.After(files[j].CreatedAt) })
+	return files
+}
+
+// referencedFiles collects the IDs of every file referenced by another row, mirroring
+// the rows the GORM repository checks (soft deleted rows still hold their reference)
+func (s *Store) referencedFiles() map[uuid.UUID]bool {
+	referenced := make(map[uuid.UUID]bool)
+	mark := func(id *uuid.UUID) {
+		if id != nil {
+			referenced[*id] = true
+		}
+	}
+	for _, e := range s.enterprises {
+		mark(e.LogoFileID)
+	}
+	for _, u := range s.users {
+		mark(u.AvatarFileID)
+	}
+	for _, f := range s.floors {
+		mark(f.BannerFileID)
+		mark(f.FloorPlanFileID)
+	}
+	for _, su := range s.suites {
+		mark(su.FloorPlanFileID)
+	}
+	for _, c := range s.menuCarousels {
+		mark(c.PromotionalVideoID)
+	}
+	for _, i := range s.carouselItems {
+		mark(i.BackgroundFileID)
+	}
+	for _, p := range s.menuPins {
+		mark(p.BackgroundFileID)
+		mark(p.PromotionalVideoID)
+	}
+	for _, i := range s.pinMarkerImages {
+		mark(&i.FileID)
+	}
+	return referenced
+}
+
+// intInRange reports whether value lies within the optional bounds; nil values only match when unbounded
+func intInRange(value *int, min, max *int) bool {
+	if min == nil && max == nil {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	return (min == nil || *value >= *min) && (max == nil || *value <= *max)
+}
+
+// FileVariantRepository implements the file variant repository interface in memory
+type FileVariantRepository struct {
+	store *Store
+}
+
+// NewFileVariantRepository creates a new in-memory file variant repository
+func NewFileVariantRepository(store *Store) interfaces.FileVariantRepository {
+	return &FileVariantRepository{store: store}
+}
+
+// Create creates a new file variant
+func (r *FileVariantRepository) Create(ctx context.Context, variant *entities.FileVariant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.fileVariants, variant)
+}
+
+// GetByID gets a file variant by ID
+func (r *FileVariantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.FileVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.fileVariants, id)
+}
+
+// GetByOriginalFileID gets variants of a file
+func (r *FileVariantRepository) GetByOriginalFileID(ctx context.Context, originalFileID uuid.UUID, limit, offset int) ([]*entities.FileVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	variants := filter(ctx, r.store, r.store.fileVariants, func(v *entities.FileVariant) bool { return v.OriginalFileID == originalFileID })
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Width < variants[j].Width })
+	return paginate(variants, limit, offset), nil
+}
+
+// GetByVariantName gets a variant of a file by name
+func (r *FileVariantRepository) GetByVariantName(ctx context.Context, originalFileID uuid.UUID, variantName string) (*entities.FileVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.fileVariants, func(v *entities.FileVariant) bool {
+		return v.OriginalFileID == originalFileID && v.VariantName == variantName
+	})
+}
+
+// GetAll gets all file variants with pagination
+func (r *FileVariantRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.FileVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginate(filter(ctx, r.store, r.store.fileVariants, nil), limit, offset), nil
+}
+
+// Update updates a file variant
+func (r *FileVariantRepository) Update(ctx context.Context, variant *entities.FileVariant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.fileVariants, variant)
+}
+
+// Delete deletes a file variant
+func (r *FileVariantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.fileVariants, id)
+	return nil
+}
+
+// DeleteByOriginalFile deletes every variant of a file
+func (r *FileVariantRepository) DeleteByOriginalFile(ctx context.Context, originalFileID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, variant := range r.store.fileVariants {
+		if variant.OriginalFileID == originalFileID {
+			remove(ctx, r.store, r.store.fileVariants, id)
+		}
+	}
+	return nil
+}
+
+// GetByDimensions gets variants with the given dimensions
+func (r *FileVariantRepository) GetByDimensions(ctx context.Context, width, height int, limit, offset int) ([]*entities.FileVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	variants := filter(ctx, r.store, r.store.fileVariants, func(v *entities.FileVariant) bool { return v.Width == width && v.Height == height })
+	return paginate(variants, limit, offset), nil
+}
@@ -0,0 +1,291 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// MenuRepository implements the menu repository interface in memory
+type MenuRepository struct {
+	store *Store
+}
+
+// NewMenuRepository creates a new in-memory menu repository
+func NewMenuRepository(store *Store) interfaces.MenuRepository {
+	return &MenuRepository{store: store}
+}
+
+// Create creates a new menu
+func (r *MenuRepository) Create(ctx context.Context, menu *entities.Menu) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.menus, menu)
+}
+
+// GetByID gets a menu by ID
+func (r *MenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Menu, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.menus, id)
+}
+
+// GetByEnterpriseID gets menus by enterprise ID
+func (r *MenuRepository) GetByEnterpriseID(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	return paginate(r.list(ctx, func(m *entities.Menu) bool { return m.EnterpriseID == enterpriseID }), limit, offset), nil
+}
+
+// GetBySlug gets a menu by enterprise and slug
+func (r *MenuRepository) GetBySlug(ctx context.Context, enterpriseID uuid.UUID, slug string) (*entities.Menu, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.menus, func(m *entities.Menu) bool { return m.EnterpriseID == enterpriseID && m.Slug == slug })
+}
+
+// GetAll gets all menus with pagination
+func (r *MenuRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Menu, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a menu
+func (r *MenuRepository) Update(ctx context.Context, menu *entities.Menu) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.menus, menu)
+}
+
+// UpdateFields updates a menu, the whole row being replaced in memory
+func (r *MenuRepository) UpdateFields(ctx context.Context, menu *entities.Menu, fields []string) error {
+	return r.Update(ctx, menu)
+}
+
+// Delete deletes a menu with its carousel and pins, returning a RestrictError while it
+// still has sub-menus
+func (r *MenuRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.menuBlockers(id); err != nil {
+		return err
+	}
+	r.store.deleteMenu(ctx, id)
+	return nil
+}
+
+// GetChildren gets the direct children of a menu
+func (r *MenuRepository) GetChildren(ctx context.Context, parentID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	menus := r.list(ctx, func(m *entities.Menu) bool { return m.ParentMenuID != nil && *m.ParentMenuID == parentID })
+	return paginate(menus, limit, offset), nil
+}
+
+// GetRootMenus gets the top level menus of an enterprise
+func (r *MenuRepository) GetRootMenus(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	menus := r.list(ctx, func(m *entities.Menu) bool { return m.EnterpriseID == enterpriseID && m.ParentMenuID == nil })
+	return paginate(menus, limit, offset), nil
+}
+
+// GetByScreenType gets menus of an enterprise by screen type
+func (r *MenuRepository) GetByScreenType(ctx context.Context, enterpriseID uuid.UUID, screenType entities.ScreenType, limit, offset int) ([]*entities.Menu, error) {
+	menus := r.list(ctx, func(m *entities.Menu) bool { return m.EnterpriseID == enterpriseID && m.ScreenType == screenType })
+	return paginate(menus, limit, offset), nil
+}
+
+// UpdatePosition updates menu position
+func (r *MenuRepository) UpdatePosition(ctx context.Context, menuID uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.menus, menuID, func(m *entities.Menu) { m.Position = position })
+	return nil
+}
+
+// GetMenuHierarchy gets the menu tree of an enterprise, returning root menus with nested sub menus
+func (r *MenuRepository) GetMenuHierarchy(ctx context.Context, enterpriseID uuid.UUID) ([]*entities.Menu, error) {
+	menus := r.list(ctx, func(m *entities.Menu) bool { return m.EnterpriseID == enterpriseID })
+
+	children := make(map[uuid.UUID][]*entities.Menu)
+	known := make(map[uuid.UUID]bool, len(menus))
+	for _, menu := range menus {
+		known[menu.ID] = true
+	}
+	var roots []*entities.Menu
+	for _, menu := range menus {
+		if menu.ParentMenuID != nil && known[*menu.ParentMenuID] {
+			children[*menu.ParentMenuID] = append(children[*menu.ParentMenuID], menu)
+			continue
+		}
+		roots = append(roots, menu)
+	}
+
+	var attach func(menu *entities.Menu)
+	attach = func(menu *entities.Menu) {
+		for _, child := range children[menu.ID] {
+			attach(child)
+			menu.SubMenus = append(menu.SubMenus, *child)
+		}
+	}
+	for _, root := range roots {
+		attach(root)
+	}
+
+	return roots, nil
+}
+
+// list returns live menus ordered by depth and position
+func (r *MenuRepository) list(ctx context.Context, match func(*entities.Menu) bool) []*entities.Menu {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	menus := filter(ctx, r.store, r.store.menus, match)
+	sort.SliceStable(menus, func(i, j int) bool {
+		if menus[i].DepthLevel != menus[j].DepthLevel {
+			return menus[i].DepthLevel < menus[j].DepthLevel
+		}
+		return menus[i].Position < menus[j].Position
+	})
+	return menus
+}
+
+// TowerRepository implements the tower repository interface in memory
+type TowerRepository struct {
+	store *Store
+}
+
+// NewTowerRepository creates a new in-memory tower repository
+func NewTowerRepository(store *Store) interfaces.TowerRepository {
+	return &TowerRepository{store: store}
+}
+
+// Create creates a new tower
+func (r *TowerRepository) Create(ctx context.Context, tower *entities.Tower) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.towers, tower)
+}
+
+// GetByID gets a tower by ID
+func (r *TowerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Tower, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.towers, id)
+}
+
+// GetByMenuFloorPlanID gets towers by menu floor plan ID
+func (r *TowerRepository) GetByMenuFloorPlanID(ctx context.Context, menuFloorPlanID uuid.UUID, limit, offset int) ([]*entities.Tower, error) {
+	towers := r.list(ctx, func(t *entities.Tower) bool { return t.MenuFloorPlanID == menuFloorPlanID })
+	return paginate(towers, limit, offset), nil
+}
+
+// GetAll gets all towers with pagination
+func (r *TowerRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Tower, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a tower
+func (r *TowerRepository) Update(ctx context.Context, tower *entities.Tower) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.towers, tower)
+}
+
+// UpdateFields updates a tower, the whole row being replaced in memory
+func (r *TowerRepository) UpdateFields(ctx context.Context, tower *entities.Tower, fields []string) error {
+	return r.Update(ctx, tower)
+}
+
+// Delete deletes a tower with its floors and suites
+func (r *TowerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.deleteTower(ctx, id)
+	return nil
+}
+
+// UpdatePosition updates tower position
+func (r *TowerRepository) UpdatePosition(ctx context.Context, towerID uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.towers, towerID, func(t *entities.Tower) { t.Position = position })
+	return nil
+}
+
+func (r *TowerRepository) list(ctx context.Context, match func(*entities.Tower) bool) []*entities.Tower {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	towers := filter(ctx, r.store, r.store.towers, match)
+	sort.SliceStable(towers, func(i, j int) bool { return towers[i].Position < towers[j].Position })
+	return towers
+}
+
+// FloorRepository implements the floor repository interface in memory
+type FloorRepository struct {
+	store *Store
+}
+
+// NewFloorRepository creates a new in-memory floor repository
+func NewFloorRepository(store *Store) interfaces.FloorRepository {
+	return &FloorRepository{store: store}
+}
+
+// Create creates a new floor
+func (r *FloorRepository) Create(ctx context.Context, floor *entities.Floor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.floors, floor)
+}
+
+// GetByID gets a floor by ID
+func (r *FloorRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Floor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.floors, id)
+}
+
+// GetByTowerID gets floors by tower ID
+func (r *FloorRepository) GetByTowerID(ctx context.Context, towerID uuid.UUID, limit, offset int) ([]*entities.Floor, error) {
+	return paginate(r.list(ctx, func(f *entities.Floor) bool { return f.TowerID == towerID }), limit, offset), nil
+}
+
+// GetAll gets all floors with pagination
+func (r *FloorRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Floor, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a floor
+func (r *FloorRepository) Update(ctx context.Context, floor *entities.Floor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.floors, floor)
+}
+
+// UpdateFields updates a floor, the whole row being replaced in memory
+func (r *FloorRepository) UpdateFields(ctx context.Context, floor *entities.Floor, fields []string) error {
+	return r.Update(ctx, floor)
+}
+
+// Delete deletes a floor with its suites
+func (r *FloorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.deleteFloor(ctx, id)
+	return nil
+}
+
+// GetByFloorNumber gets a floor of a tower by its number
+func (r *FloorRepository) GetByFloorNumber(ctx context.Context, towerID uuid.UUID, floorNumber int) (*entities.Floor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.floors, func(f *entities.Floor) bool { return f.TowerID == towerID && f.FloorNumber == floorNumber })
+}
+
+func (r *FloorRepository) list(ctx context.Context, match func(*entities.Floor) bool) []*entities.Floor {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	floors := filter(ctx, r.store, r.store.floors, match)
+	sort.SliceStable(floors, func(i, j int) bool { return floors[i].FloorNumber < floors[j].FloorNumber })
+	return floors
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// MFARepository implements the MFA repository interface in memory
+type MFARepository struct {
+	store *Store
+}
+
+// NewMFARepository creates a new in-memory MFA repository
+func NewMFARepository(store *Store) interfaces.MFARepository {
+	return &MFARepository{store: store}
+}
+
+// GetByUserID gets the enrollment of a user
+func (r *MFARepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.UserMFA, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.userMFA, func(m *entities.UserMFA) bool { return m.UserID == userID })
+}
+
+// Save creates or updates an enrollment
+func (r *MFARepository) Save(ctx context.Context, mfa *entities.UserMFA) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.userMFA, mfa)
+}
+
+// Delete removes the enrollment of a user and its recovery codes
+func (r *MFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, mfa := range filter(ctx, r.store, r.store.userMFA, func(m *entities.UserMFA) bool { return m.UserID == userID }) {
+		remove(ctx, r.store, r.store.userMFA, mfa.ID)
+	}
+	r.deleteRecoveryCodes(ctx, userID)
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps the recovery codes of a user
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.deleteRecoveryCodes(ctx, userID)
+	for _, hash := range codeHashes {
+		if err := insert(ctx, r.store, r.store.mfaRecoveryCodes, &entities.MFARecoveryCode{UserID: userID, CodeHash: hash}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	code, err := first(ctx, r.store, r.store.mfaRecoveryCodes, func(c *entities.MFARecoveryCode) bool {
+		return c.UserID == userID && c.CodeHash == codeHash && c.UsedAt == nil
+	})
+	if err != nil {
+		return false, nil
+	}
+	update(ctx, r.store, r.store.mfaRecoveryCodes, code.ID, func(c *entities.MFARecoveryCode) {
+		now := time.Now()
+		c.UsedAt = &now
+	})
+	return true, nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	codes := filter(ctx, r.store, r.store.mfaRecoveryCodes, func(c *entities.MFARecoveryCode) bool {
+		return c.UserID == userID && c.UsedAt == nil
+	})
+	return len(codes), nil
+}
+
+func (r *MFARepository) deleteRecoveryCodes(ctx context.Context, userID uuid.UUID) {
+	for _, code := range filter(ctx, r.store, r.store.mfaRecoveryCodes, func(c *entities.MFARecoveryCode) bool { return c.UserID == userID }) {
+		remove(ctx, r.store, r.store.mfaRecoveryCodes, code.ID)
+	}
+}
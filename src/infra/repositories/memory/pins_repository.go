@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MenuPinsRepository implements the menu pins repository interface in memory
+type MenuPinsRepository struct {
+	store *Store
+}
+
+// NewMenuPinsRepository creates a new in-memory menu pins repository
+func NewMenuPinsRepository(store *Store) interfaces.MenuPinsRepository {
+	return &MenuPinsRepository{store: store}
+}
+
+// Create creates a new menu pins
+func (r *MenuPinsRepository) Create(ctx context.Context, pins *entities.MenuPins) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Unique constraints hold across enterprises
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.menuPins, func(p *entities.MenuPins) bool { return p.MenuID == pins.MenuID }); err == nil {
+		return interfaces.NewConflictError("menu pins", "menu_id", gorm.ErrDuplicatedKey)
+	}
+	return insert(ctx, r.store, r.store.menuPins, pins)
+}
+
+// GetByID gets a menu pins by ID
+func (r *MenuPinsRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.MenuPins, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.menuPins, id)
+}
+
+// GetByMenuID gets the pins screen of a menu
+func (r *MenuPinsRepository) GetByMenuID(ctx context.Context, menuID uuid.UUID) (*entities.MenuPins, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.menuPins, func(p *entities.MenuPins) bool { return p.MenuID == menuID })
+}
+
+// GetAll gets all menu pins with pagination
+func (r *MenuPinsRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.MenuPins, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginate(filter(ctx, r.store, r.store.menuPins, nil), limit, offset), nil
+}
+
+// Update updates a menu pins
+func (r *MenuPinsRepository) Update(ctx context.Context, pins *entities.MenuPins) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.menuPins, pins)
+}
+
+// Delete deletes a menu pins with its markers
+func (r *MenuPinsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.deleteMenuPins(ctx, id)
+	return nil
+}
+
+// PinMarkerRepository implements the pin marker repository interface in memory
+type PinMarkerRepository struct {
+	store *Store
+}
+
+// NewPinMarkerRepository creates a new in-memory pin marker repository
+func NewPinMarkerRepository(store *Store) interfaces.PinMarkerRepository {
+	return &PinMarkerRepository{store: store}
+}
+
+// Create creates a new pin marker
+func (r *PinMarkerRepository) Create(ctx context.Context, marker *entities.PinMarker) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.pinMarkers, marker)
+}
+
+// GetByID gets a pin marker by ID
+func (r *PinMarkerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.PinMarker, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.pinMarkers, id)
+}
+
+// GetByMenuPinID gets pin markers by menu pins ID
+func (r *PinMarkerRepository) GetByMenuPinID(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error) {
+	return paginate(r.list(ctx, func(m *entities.PinMarker) bool { return m.MenuPinID == menuPinID }), limit, offset), nil
+}
+
+// GetAll gets all pin markers with pagination
+func (r *PinMarkerRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.PinMarker, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a pin marker
+func (r *PinMarkerRepository) Update(ctx context.Context, marker *entities.PinMarker) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.pinMarkers, marker)
+}
+
+// UpdateFields updates a pin marker, the whole row being replaced in memory
+func (r *PinMarkerRepository) UpdateFields(ctx context.Context, marker *entities.PinMarker, fields []string) error {
+	return r.Update(ctx, marker)
+}
+
+// Delete deletes a pin marker
+func (r *PinMarkerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.pinMarkers, id)
+	return nil
+}
+
+// GetVisibleMarkers gets visible pin markers of a menu pins
+func (r *PinMarkerRepository) GetVisibleMarkers(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error) {
+	markers := r.list(ctx, func(m *entities.PinMarker) bool { return m.MenuPinID == menuPinID && m.IsVisible })
+	return paginate(markers, limit, offset), nil
+}
+
+// GetByActionType gets pin markers of a menu pins by action type
+func (r *PinMarkerRepository) GetByActionType(ctx context.Context, menuPinID uuid.UUID, actionType entities.PinAction, limit, offset int) ([]*entities.PinMarker, error) {
+	markers := r.list(ctx, func(m *entities.PinMarker) bool { return m.MenuPinID == menuPinID && m.ActionType == actionType })
+	return paginate(markers, limit, offset), nil
+}
+
+// GetByPosition gets pin markers of a menu pins inside the given bounding box
+func (r *PinMarkerRepository) GetByPosition(ctx context.Context, menuPinID uuid.UUID, minX, maxX, minY, maxY float64, limit, offset int) ([]*entities.PinMarker, error) {
+	markers := r.list(ctx, func(m *entities.PinMarker) bool {
+		return m.MenuPinID == menuPinID &&
+			m.PositionX >= minX && m.PositionX <= maxX &&
+			m.PositionY >= minY && m.PositionY <= maxY
+	})
+	sort.SliceStable(markers, func(i, j int) bool {
+		if markers[i].PositionY != markers[j].PositionY {
+			return markers[i].PositionY < markers[j].PositionY
+		}
+		return markers[i].PositionX < markers[j].PositionX
+	})
+	return paginate(markers, limit, offset), nil
+}
+
+func (r *PinMarkerRepository) list(ctx context.Context, match func(*entities.PinMarker) bool) []*entities.PinMarker {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return filter(ctx, r.store, r.store.pinMarkers, match)
+}
+
+// PinMarkerImageRepository implements the pin marker image repository interface in memory
+type PinMarkerImageRepository struct {
+	store *Store
+}
+
+// NewPinMarkerImageRepository creates a new in-memory pin marker image repository
+func NewPinMarkerImageRepository(store *Store) interfaces.PinMarkerImageRepository {
+	return &PinMarkerImageRepository{store: store}
+}
+
+// Create creates a new pin marker image
+func (r *PinMarkerImageRepository) Create(ctx context.Context, image *entities.PinMarkerImage) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.pinMarkerImages, image)
+}
+
+// GetByID gets a pin marker image by ID
+func (r *PinMarkerImageRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.PinMarkerImage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.pinMarkerImages, id)
+}
+
+// GetByPinMarkerID gets images by pin marker ID
+func (r *PinMarkerImageRepository) GetByPinMarkerID(ctx context.Context, pinMarkerID uuid.UUID, limit, offset int) ([]*entities.PinMarkerImage, error) {
+	return paginate(r.list(ctx, func(i *entities.PinMarkerImage) bool { return i.PinMarkerID == pinMarkerID }), limit, offset), nil
+}
+
+// GetAll gets all pin marker images with pagination
+func (r *PinMarkerImageRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.PinMarkerImage, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a pin marker image
+func (r *PinMarkerImageRepository) Update(ctx context.Context, image *entities.PinMarkerImage) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.pinMarkerImages, image)
+}
+
+// Delete deletes a pin marker image
+func (r *PinMarkerImageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.pinMarkerImages, id)
+	return nil
+}
+
+// UpdatePosition updates pin marker image position
+func (r *PinMarkerImageRepository) UpdatePosition(ctx context.Context, imageID uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.pinMarkerImages, imageID, func(i *entities.PinMarkerImage) { i.Position = position })
+	return nil
+}
+
+func (r *PinMarkerImageRepository) list(ctx context.Context, match func(*entities.PinMarkerImage) bool) []*entities.PinMarkerImage {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	images := filter(ctx, r.store, r.store.pinMarkerImages, match)
+	sort.SliceStable(images, func(i, j int) bool { return images[i].Position < images[j].Position })
+	return images
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"terra-allwert/domain/entities"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Seed fills an empty store with the same default enterprise and users the
+// database seeder creates, so the memory mode can be logged into right away
+func (s *Store) Seed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.enterprises) > 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	description := "Empreendimento"
+	enterprise := &entities.Enterprise{
+		Title:        "Terra Allwert",
+		Description:  &description,
+		Slug:         "allwert",
+		AddressCity:  "Pelotas",
+		AddressState: "SC",
+		Status:       entities.EnterpriseStatusConstruction,
+	}
+	if err := insert(ctx, s, s.enterprises, enterprise); err != nil {
+		return fmt.Errorf("failed to seed enterprise: %w", err)
+	}
+
+	now := time.Now()
+	users := []*entities.User{
+		{Name: "Admin " + enterprise.Title, Email: "admin@" + enterprise.Slug, Role: entities.UserRoleAdmin, EmailVerifiedAt: &now},
+		{Name: "Manager " + enterprise.Title, Email: "manager@" + enterprise.Slug, Role: entities.UserRoleManager, EmailVerifiedAt: &now},
+		{Name: "Visitor " + enterprise.Title, Email: "visitor@" + enterprise.Slug, Role: entities.UserRoleVisitor},
+		{Name: "Super Admin", Email: "admin@terra.com", Role: entities.UserRoleSuperAdmin, EmailVerifiedAt: &now},
+	}
+	for _, user := range users {
+		user.EnterpriseID = enterprise.ID
+		user.PasswordHash = string(passwordHash)
+		user.IsActive = true
+		if err := insert(ctx, s, s.users, user); err != nil {
+			return fmt.Errorf("failed to seed user '%s': %w", user.Email, err)
+		}
+	}
+
+	slog.Info("Seeded in-memory store", "enterprise", enterprise.Title, "users", len(users))
+	return nil
+}
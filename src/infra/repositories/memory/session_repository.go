@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// SessionRepository implements the session repository interface in memory
+type SessionRepository struct {
+	store *Store
+}
+
+// NewSessionRepository creates a new in-memory session repository
+func NewSessionRepository(store *Store) interfaces.SessionRepository {
+	return &SessionRepository{store: store}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, session *entities.UserSession) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return insert(ctx, r.store, r.store.userSessions, session)
+}
+
+// GetByID gets a session by ID
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.userSessions, id)
+}
+
+// GetActiveByUserID gets the live sessions of a user, most recently used first
+func (r *SessionRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.UserSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	sessions := filter(ctx, r.store, r.store.userSessions, func(s *entities.UserSession) bool {
+		return s.UserID == userID && s.Active(now)
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// Rotate swaps the refresh token of a session, guarded by the token being replaced
+func (r *SessionRepository) Rotate(ctx context.Context, id uuid.UUID, refreshTokenID string, rotation interfaces.SessionRotation) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.userSessions[id]
+	if !ok || session.RefreshTokenID != refreshTokenID || !session.Active(rotation.UsedAt) {
+		return false, nil
+	}
+	update(ctx, r.store, r.store.userSessions, id, func(s *entities.UserSession) {
+		s.RefreshTokenID = rotation.RefreshTokenID
+		s.ExpiresAt = rotation.ExpiresAt
+		s.LastUsedAt = rotation.UsedAt
+		s.IPAddress = rotation.IPAddress
+		s.UserAgent = rotation.UserAgent
+	})
+	return true, nil
+}
+
+// Revoke revokes a session
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	revokeSession(ctx, r.store, id)
+	return nil
+}
+
+// RevokeAllForUser revokes every session of a user
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, session := range r.store.userSessions {
+		if session.UserID == userID {
+			revokeSession(ctx, r.store, id)
+		}
+	}
+	return nil
+}
+
+func revokeSession(ctx context.Context, s *Store, id uuid.UUID) {
+	update(ctx, s, s.userSessions, id, func(session *entities.UserSession) {
+		if session.RevokedAt == nil {
+			now := time.Now()
+			session.RevokedAt = &now
+		}
+	})
+}
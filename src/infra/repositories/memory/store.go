@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/audit"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Store holds every in-memory table. It is shared by the memory repositories so
+// cross-entity queries (menu hierarchies, suites by tower, orphaned files) see
+// one consistent view, the same way the GORM repositories share a database.
+type Store struct {
+	mu sync.RWMutex
+
+	users                map[uuid.UUID]*entities.User
+	enterprises          map[uuid.UUID]*entities.Enterprise
+	menus                map[uuid.UUID]*entities.Menu
+	towers               map[uuid.UUID]*entities.Tower
+	floors               map[uuid.UUID]*entities.Floor
+	suites               map[uuid.UUID]*entities.Suite
+	menuCarousels        map[uuid.UUID]*entities.MenuCarousel
+	carouselItems        map[uuid.UUID]*entities.CarouselItem
+	carouselTextOverlays map[uuid.UUID]*entities.CarouselTextOverlay
+	menuPins             map[uuid.UUID]*entities.MenuPins
+	pinMarkers           map[uuid.UUID]*entities.PinMarker
+	pinMarkerImages      map[uuid.UUID]*entities.PinMarkerImage
+	files                map[uuid.UUID]*entities.File
+	fileVariants         map[uuid.UUID]*entities.FileVariant
+	auditLogs            map[uuid.UUID]*entities.AuditLog
+	userSessions         map[uuid.UUID]*entities.UserSession
+	userMFA              map[uuid.UUID]*entities.UserMFA
+	mfaRecoveryCodes     map[uuid.UUID]*entities.MFARecoveryCode
+	apiKeys              map[uuid.UUID]*entities.APIKey
+	userIdentities       map[uuid.UUID]*entities.UserIdentity
+}
+
+// NewStore creates an empty in-memory store
+func NewStore() *Store {
+	return &Store{
+		users:                make(map[uuid.UUID]*entities.User),
+		enterprises:          make(map[uuid.UUID]*entities.Enterprise),
+		menus:                make(map[uuid.UUID]*entities.Menu),
+		towers:               make(map[uuid.UUID]*entities.Tower),
+		floors:               make(map[uuid.UUID]*entities.Floor),
+		suites:               make(map[uuid.UUID]*entities.Suite),
+		menuCarousels:        make(map[uuid.UUID]*entities.MenuCarousel),
+		carouselItems:        make(map[uuid.UUID]*entities.CarouselItem),
+		carouselTextOverlays: make(map[uuid.UUID]*entities.CarouselTextOverlay),
+		menuPins:             make(map[uuid.UUID]*entities.MenuPins),
+		pinMarkers:           make(map[uuid.UUID]*entities.PinMarker),
+		pinMarkerImages:      make(map[uuid.UUID]*entities.PinMarkerImage),
+		files:                make(map[uuid.UUID]*entities.File),
+		fileVariants:         make(map[uuid.UUID]*entities.FileVariant),
+		auditLogs:            make(map[uuid.UUID]*entities.AuditLog),
+		userSessions:         make(map[uuid.UUID]*entities.UserSession),
+		userMFA:              make(map[uuid.UUID]*entities.UserMFA),
+		mfaRecoveryCodes:     make(map[uuid.UUID]*entities.MFARecoveryCode),
+		apiKeys:              make(map[uuid.UUID]*entities.APIKey),
+		userIdentities:       make(map[uuid.UUID]*entities.UserIdentity),
+	}
+}
+
+// insert stores a copy of row, assigning its ID and timestamps like the GORM hooks do
+func insert[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, row *T) error {
+	value := reflect.ValueOf(row).Elem()
+	id := value.FieldByName("ID")
+	if id.Interface().(uuid.UUID) == uuid.Nil {
+		id.Set(reflect.ValueOf(uuid.New()))
+	}
+	key := id.Interface().(uuid.UUID)
+	if _, exists := rows[key]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	if err := s.checkOwner(ctx, row); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if createdAt := value.FieldByName("CreatedAt"); createdAt.IsValid() && createdAt.Interface().(time.Time).IsZero() {
+		createdAt.Set(reflect.ValueOf(now))
+	}
+	if updatedAt := value.FieldByName("UpdatedAt"); updatedAt.IsValid() && updatedAt.IsNil() {
+		updatedAt.Set(reflect.ValueOf(&now))
+	}
+
+	rows[key] = clone(row)
+	s.audit(ctx, entities.AuditActionCreate, row, key, nil, snapshot(row))
+	return nil
+}
+
+// save upserts a copy of row, keeping the original creation time
+func save[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, row *T) error {
+	key := idOf(row)
+	existing, exists := rows[key]
+	if !exists {
+		return insert(ctx, s, rows, row)
+	}
+	if !s.visible(ctx, existing) {
+		return fmt.Errorf("%s: %w", audit.TableName(row), interfaces.ErrOtherTenant)
+	}
+	if err := s.checkOwner(ctx, row); err != nil {
+		return err
+	}
+	before := snapshot(existing)
+
+	value := reflect.ValueOf(row).Elem()
+	if createdAt := value.FieldByName("CreatedAt"); createdAt.IsValid() && createdAt.Interface().(time.Time).IsZero() {
+		createdAt.Set(reflect.ValueOf(existing).Elem().FieldByName("CreatedAt"))
+	}
+	if updatedAt := value.FieldByName("UpdatedAt"); updatedAt.IsValid() {
+		now := time.Now()
+		updatedAt.Set(reflect.ValueOf(&now))
+	}
+
+	rows[key] = clone(row)
+	s.auditUpdate(ctx, row, key, before, snapshot(row))
+	return nil
+}
+
+// find gets a copy of a live row of the caller's enterprise by ID
+func find[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, id uuid.UUID) (*T, error) {
+	row, ok := rows[id]
+	if !ok || isDeleted(row) || !s.visible(ctx, row) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return clone(row), nil
+}
+
+// first gets a copy of the first live row of the caller's enterprise matching the predicate
+func first[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, match func(*T) bool) (*T, error) {
+	matches := filter(ctx, s, rows, match)
+	if len(matches) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return matches[0], nil
+}
+
+// remove soft deletes rows with a DeletedAt column and hard deletes the others
+func remove[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, id uuid.UUID) {
+	row, ok := rows[id]
+	if !ok || isDeleted(row) || !s.visible(ctx, row) {
+		return
+	}
+	s.audit(ctx, entities.AuditActionDelete, row, id, snapshot(row), nil)
+	deletedAt := reflect.ValueOf(row).Elem().FieldByName("DeletedAt")
+	if !deletedAt.IsValid() {
+		delete(rows, id)
+		return
+	}
+	deletedAt.Set(reflect.ValueOf(gorm.DeletedAt{Time: time.Now(), Valid: true}))
+}
+
+// restore undeletes a soft deleted row
+func restore[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, id uuid.UUID) {
+	row, ok := rows[id]
+	if !ok || !isDeleted(row) {
+		return
+	}
+	before := snapshot(row)
+	value := reflect.ValueOf(row).Elem()
+	value.FieldByName("DeletedAt").Set(reflect.ValueOf(gorm.DeletedAt{}))
+	if updatedAt := value.FieldByName("UpdatedAt"); updatedAt.IsValid() {
+		now := time.Now()
+		updatedAt.Set(reflect.ValueOf(&now))
+	}
+	s.auditUpdate(ctx, row, id, before, snapshot(row))
+}
+
+// purge hard deletes a row, soft deleted or not
+func purge[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, id uuid.UUID) bool {
+	row, ok := rows[id]
+	if !ok {
+		return false
+	}
+	s.audit(ctx, entities.AuditActionDelete, row, id, snapshot(row), nil)
+	delete(rows, id)
+	return true
+}
+
+// update applies fn to a live row of the caller's enterprise in place
+func update[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, id uuid.UUID, fn func(*T)) {
+	row, ok := rows[id]
+	if !ok || isDeleted(row) || !s.visible(ctx, row) {
+		return
+	}
+	before := snapshot(row)
+	fn(row)
+	if updatedAt := reflect.ValueOf(row).Elem().FieldByName("UpdatedAt"); updatedAt.IsValid() {
+		now := time.Now()
+		updatedAt.Set(reflect.ValueOf(&now))
+	}
+	s.auditUpdate(ctx, row, id, before, snapshot(row))
+}
+
+// audit records a mutation of row in the audit log, like the GORM audit callbacks do
+func (s *Store) audit(ctx context.Context, action entities.AuditAction, row interface{}, id uuid.UUID, oldValues, newValues entities.JSONValues) {
+	table := audit.TableName(row)
+	if audit.Ignored(table) {
+		return
+	}
+	entry := audit.NewEntry(ctx, action, table, id, oldValues, newValues)
+	entry.CreatedAt = time.Now()
+	s.auditLogs[entry.ID] = entry
+}
+
+// auditUpdate records the changed columns of an update, if any
+func (s *Store) auditUpdate(ctx context.Context, row interface{}, id uuid.UUID, before, after entities.JSONValues) {
+	oldValues, newValues := audit.Diff(before, after)
+	if len(newValues) == 0 {
+		return
+	}
+	s.audit(ctx, audit.UpdateAction(before, after), row, id, oldValues, newValues)
+}
+
+// filter returns copies of the live rows of the caller's enterprise matching the
+// predicate, oldest first
+func filter[T any](ctx context.Context, s *Store, rows map[uuid.UUID]*T, match func(*T) bool) []*T {
+	var result []*T
+	for _, row := range rows {
+		if isDeleted(row) || !s.visible(ctx, row) || (match != nil && !match(row)) {
+			continue
+		}
+		result = append(result, clone(row))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ci, cj := createdAtOf(result[i]), createdAtOf(result[j])
+		if !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return idOf(result[i]).String() < idOf(result[j]).String()
+	})
+	return result
+}
+
+// paginate applies limit and offset the way SQL does
+func paginate[T any](rows []*T, limit, offset int) []*T {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return []*T{}
+		}
+		rows = rows[offset:]
+	}
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func snapshot(row interface{}) entities.JSONValues {
+	values, _ := audit.Snapshot(row)
+	return values
+}
+
+func clone[T any](row *T) *T {
+	copied := *row
+	return &copied
+}
+
+func idOf[T any](row *T) uuid.UUID {
+	return reflect.ValueOf(row).Elem().FieldByName("ID").Interface().(uuid.UUID)
+}
+
+func createdAtOf[T any](row *T) time.Time {
+	createdAt := reflect.ValueOf(row).Elem().FieldByName("CreatedAt")
+	if !createdAt.IsValid() {
+		return time.Time{}
+	}
+	return createdAt.Interface().(time.Time)
+}
+
+// deletedAtOf returns when a row was soft deleted, or nil while it is live
+func deletedAtOf[T any](row *T) *time.Time {
+	deletedAt := reflect.ValueOf(row).Elem().FieldByName("DeletedAt")
+	if !deletedAt.IsValid() || !deletedAt.Interface().(gorm.DeletedAt).Valid {
+		return nil
+	}
+	t := deletedAt.Interface().(gorm.DeletedAt).Time
+	return &t
+}
+
+func isDeleted[T any](row *T) bool {
+	deletedAt := reflect.ValueOf(row).Elem().FieldByName("DeletedAt")
+	return deletedAt.IsValid() && deletedAt.Interface().(gorm.DeletedAt).Valid
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// SuiteRepository implements the suite repository interface in memory
+type SuiteRepository struct {
+	store *Store
+}
+
+// NewSuiteRepository creates a new in-memory suite repository
+func NewSuiteRepository(store *Store) interfaces.SuiteRepository {
+	return &SuiteRepository{store: store}
+}
+
+// Create creates a new suite
+func (r *SuiteRepository) Create(ctx context.Context, suite *entities.Suite) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if suite.Status == "" {
+		suite.Status = entities.SuiteStatusAvailable
+	}
+	return insert(ctx, r.store, r.store.suites, suite)
+}
+
+// GetByID gets a suite by ID
+func (r *SuiteRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Suite, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.suites, id)
+}
+
+// GetByFloorID gets suites by floor ID
+func (r *SuiteRepository) GetByFloorID(ctx context.Context, floorID uuid.UUID, limit, offset int) ([]*entities.Suite, error) {
+	return paginate(r.list(ctx, func(s *entities.Suite) bool { return s.FloorID == floorID }), limit, offset), nil
+}
+
+// GetAll gets all suites with pagination
+func (r *SuiteRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Suite, error) {
+	return paginate(r.list(ctx, nil), limit, offset), nil
+}
+
+// Update updates a suite
+func (r *SuiteRepository) Update(ctx context.Context, suite *entities.Suite) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return save(ctx, r.store, r.store.suites, suite)
+}
+
+// UpdateFields updates a suite, the whole row being replaced in memory
+func (r *SuiteRepository) UpdateFields(ctx context.Context, suite *entities.Suite, fields []string) error {
+	return r.Update(ctx, suite)
+}
+
+// Delete deletes a suite
+func (r *SuiteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.suites, id)
+	return nil
+}
+
+// UpdateStatus updates suite status
+func (r *SuiteRepository) UpdateStatus(ctx context.Context, suiteID uuid.UUID, status entities.SuiteStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.suites, suiteID, func(s *entities.Suite) { s.Status = status })
+	return nil
+}
+
+// GetByStatus gets suites by status
+func (r *SuiteRepository) GetByStatus(ctx context.Context, status entities.SuiteStatus, limit, offset int) ([]*entities.Suite, error) {
+	return paginate(r.list(ctx, func(s *entities.Suite) bool { return s.Status == status }), limit, offset), nil
+}
+
+// Search searches suites using the given filters
+func (r *SuiteRepository) Search(ctx context.Context, filters interfaces.SuiteSearchFilters, limit, offset int) ([]*entities.Suite, error) {
+	var towerFloors map[uuid.UUID]bool
+	if filters.TowerID != nil {
+		towerFloors = r.floorsOfTower(ctx, *filters.TowerID)
+	}
+
+	suites := r.list(ctx, func(s *entities.Suite) bool {
+		switch {
+		case filters.MinBedrooms != nil && s.Bedrooms < *filters.MinBedrooms,
+			filters.MaxBedrooms != nil && s.Bedrooms > *filters.MaxBedrooms,
+			filters.MinArea != nil && s.AreaSqm < *filters.MinArea,
+			filters.MaxArea != nil && s.AreaSqm > *filters.MaxArea,
+			filters.MinPrice != nil && (s.Price == nil || *s.Price < *filters.MinPrice),
+			filters.MaxPrice != nil && (s.Price == nil || *s.Price > *filters.MaxPrice),
+			filters.Status != nil && s.Status != *filters.Status,
+			filters.SunPosition != nil && (s.SunPosition == nil || *s.SunPosition != *filters.SunPosition),
+			filters.FloorID != nil && s.FloorID != *filters.FloorID,
+			towerFloors != nil && !towerFloors[s.FloorID],
+			filters.MinSuites != nil && s.SuitesCount < *filters.MinSuites,
+			filters.MaxSuites != nil && s.SuitesCount > *filters.MaxSuites,
+			filters.MinBathrooms != nil && s.Bathrooms < *filters.MinBathrooms,
+			filters.MaxBathrooms != nil && s.Bathrooms > *filters.MaxBathrooms,
+			filters.ParkingSpaces != nil && (s.ParkingSpaces == nil || *s.ParkingSpaces < *filters.ParkingSpaces):
+			return false
+		}
+		return true
+	})
+	return paginate(suites, limit, offset), nil
+}
+
+// GetByTowerID gets suites of every floor of a tower
+func (r *SuiteRepository) GetByTowerID(ctx context.Context, towerID uuid.UUID, limit, offset int) ([]*entities.Suite, error) {
+	floors := r.floorsOfTower(ctx, towerID)
+	return paginate(r.list(ctx, func(s *entities.Suite) bool { return floors[s.FloorID] }), limit, offset), nil
+}
+
+// GetAvailableSuites gets suites with available status
+func (r *SuiteRepository) GetAvailableSuites(ctx context.Context, limit, offset int) ([]*entities.Suite, error) {
+	return r.GetByStatus(ctx, entities.SuiteStatusAvailable, limit, offset)
+}
+
+// GetSuitesByPriceRange gets suites priced within the given range
+func (r *SuiteRepository) GetSuitesByPriceRange(ctx context.Context, minPrice, maxPrice float64, limit, offset int) ([]*entities.Suite, error) {
+	suites := r.list(ctx, func(s *entities.Suite) bool {
+		return s.Price != nil && *s.Price >= minPrice && *s.Price <= maxPrice
+	})
+	sort.SliceStable(suites, func(i, j int) bool { return *suites[i].Price < *suites[j].Price })
+	return paginate(suites, limit, offset), nil
+}
+
+func (r *SuiteRepository) list(ctx context.Context, match func(*entities.Suite) bool) []*entities.Suite {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	suites := filter(ctx, r.store, r.store.suites, match)
+	sort.SliceStable(suites, func(i, j int) bool { return suites[i].UnitNumber < suites[j].UnitNumber })
+	return suites
+}
+
+// floorsOfTower collects the IDs of the live floors of a tower
+func (r *SuiteRepository) floorsOfTower(ctx context.Context, towerID uuid.UUID) map[uuid.UUID]bool {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	floors := make(map[uuid.UUID]bool)
+	for _, floor := range filter(ctx, r.store, r.store.floors, func(f *entities.Floor) bool { return f.TowerID == towerID }) {
+		floors[floor.ID] = true
+	}
+	return floors
+}
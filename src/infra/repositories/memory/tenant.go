@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/audit"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+)
+
+// enterpriseOf follows the owner links of a row up to its enterprise, like the tenant
+// conditions of the GORM callbacks. The store holds no menu floor plans, so towers and
+// the rows below them cannot be traced and are only visible outside tenant scoping.
+func (s *Store) enterpriseOf(row interface{}) (uuid.UUID, bool) {
+	switch r := row.(type) {
+	case *entities.Enterprise:
+		return r.ID, true
+	case *entities.User:
+		return r.EnterpriseID, true
+	case *entities.Menu:
+		return r.EnterpriseID, true
+	case *entities.APIKey:
+		return r.EnterpriseID, true
+	case *entities.AuditLog:
+		if r.EnterpriseID != nil {
+			return *r.EnterpriseID, true
+		}
+	case *entities.Tower:
+		return uuid.Nil, false
+	case *entities.Floor:
+		return ownerEnterprise(s, s.towers, r.TowerID)
+	case *entities.Suite:
+		return ownerEnterprise(s, s.floors, r.FloorID)
+	case *entities.MenuCarousel:
+		return ownerEnterprise(s, s.menus, r.MenuID)
+	case *entities.CarouselItem:
+		return ownerEnterprise(s, s.menuCarousels, r.MenuCarouselID)
+	case *entities.CarouselTextOverlay:
+		return ownerEnterprise(s, s.carouselItems, r.CarouselItemID)
+	case *entities.MenuPins:
+		return ownerEnterprise(s, s.menus, r.MenuID)
+	case *entities.PinMarker:
+		return ownerEnterprise(s, s.menuPins, r.MenuPinID)
+	case *entities.PinMarkerImage:
+		return ownerEnterprise(s, s.pinMarkers, r.PinMarkerID)
+	case *entities.File:
+		return r.EnterpriseID, true
+	case *entities.FileVariant:
+		return ownerEnterprise(s, s.files, r.OriginalFileID)
+	}
+	return uuid.Nil, false
+}
+
+func ownerEnterprise[T any](s *Store, rows map[uuid.UUID]*T, id uuid.UUID) (uuid.UUID, bool) {
+	row, ok := rows[id]
+	if !ok {
+		return uuid.Nil, false
+	}
+	return s.enterpriseOf(row)
+}
+
+// visible reports whether the caller's enterprise owns the row. Rows of tables outside
+// the tenant links are visible to everyone.
+func (s *Store) visible(ctx context.Context, row interface{}) bool {
+	enterpriseID, scoped := tenant.FromContext(ctx)
+	if !scoped || !tenant.Scoped(audit.TableName(row)) {
+		return true
+	}
+	owner, ok := s.enterpriseOf(row)
+	return ok && owner == enterpriseID
+}
+
+// checkOwner rejects writes attaching a row to another enterprise, with the error the
+// GORM tenant callbacks return
+func (s *Store) checkOwner(ctx context.Context, row interface{}) error {
+	table := audit.TableName(row)
+	if s.visible(ctx, row) || table == "audit_logs" {
+		return nil
+	}
+	column, _ := tenant.Owner(table)
+	return fmt.Errorf("%s: %w", column, interfaces.ErrOtherTenant)
+}
@@ -0,0 +1,403 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// enterpriseParent marks the tables owned directly by an enterprise
+const enterpriseParent = "enterprises"
+
+// trashTable adapts one soft deleted map of the store to the trash bin, with the same
+// entities and parent links as the GORM trash repository. The store holds no menu floor
+// plans, so towers, floors and suites cannot be traced to an enterprise and stay out of it.
+type trashTable struct {
+	entityType string
+	parent     string
+	cascade    bool
+	records    func(s *Store) []trashRecord
+	restore    func(ctx context.Context, s *Store, id uuid.UUID)
+	purge      func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool
+}
+
+type trashRecord struct {
+	id        uuid.UUID
+	parentID  uuid.UUID
+	name      string
+	deletedAt *time.Time
+}
+
+// trashTables is ordered for purging like the GORM repository, parents before their
+// children
+var trashTables = []trashTable{
+	{
+		entityType: "files", parent: enterpriseParent,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.files, func(f *entities.File) (uuid.UUID, string) { return f.EnterpriseID, f.OriginalName })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.files, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			file, ok := s.files[id]
+			if !ok {
+				return false
+			}
+			result.StoragePaths = append(result.StoragePaths, file.StoragePath)
+			for variantID, variant := range s.fileVariants {
+				if variant.OriginalFileID == id {
+					result.StoragePaths = append(result.StoragePaths, variant.StoragePath)
+					purge(ctx, s, s.fileVariants, variantID)
+				}
+			}
+			for imageID, image := range s.pinMarkerImages {
+				if image.FileID == id {
+					purge(ctx, s, s.pinMarkerImages, imageID)
+				}
+			}
+			s.clearFileReferences(id)
+			return purge(ctx, s, s.files, id)
+		},
+	},
+	{
+		entityType: "menus", parent: enterpriseParent,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.menus, func(m *entities.Menu) (uuid.UUID, string) { return m.EnterpriseID, m.Title })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.menus, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			// Sub-menus are restricted, detach the ones left behind in the trash
+			for _, m := range s.menus {
+				if m.ParentMenuID != nil && *m.ParentMenuID == id && isDeleted(m) {
+					m.ParentMenuID = nil
+				}
+			}
+			return purge(ctx, s, s.menus, id)
+		},
+	},
+	{
+		entityType: "towers", parent: "menu_floor_plans", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.towers, func(t *entities.Tower) (uuid.UUID, string) { return t.MenuFloorPlanID, t.Title })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.towers, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			return purge(ctx, s, s.towers, id)
+		},
+	},
+	{
+		entityType: "floors", parent: "towers", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.floors, func(f *entities.Floor) (uuid.UUID, string) {
+				if f.FloorName != nil {
+					return f.TowerID, *f.FloorName
+				}
+				return f.TowerID, strconv.Itoa(f.FloorNumber)
+			})
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.floors, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			return purge(ctx, s, s.floors, id)
+		},
+	},
+	{
+		entityType: "suites", parent: "floors", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.suites, func(su *entities.Suite) (uuid.UUID, string) { return su.FloorID, su.Title })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.suites, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			return purge(ctx, s, s.suites, id)
+		},
+	},
+	{
+		entityType: "menu_carousels", parent: "menus", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.menuCarousels, func(c *entities.MenuCarousel) (uuid.UUID, string) { return c.MenuID, s.menuTitle(c.MenuID) })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.menuCarousels, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			return purge(ctx, s, s.menuCarousels, id)
+		},
+	},
+	{
+		entityType: "carousel_items", parent: "menu_carousels", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.carouselItems, func(i *entities.CarouselItem) (uuid.UUID, string) {
+				if i.Title != nil {
+					return i.MenuCarouselID, *i.Title
+				}
+				return i.MenuCarouselID, string(i.ItemType)
+			})
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.carouselItems, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			for overlayID, overlay := range s.carouselTextOverlays {
+				if overlay.CarouselItemID == id {
+					purge(ctx, s, s.carouselTextOverlays, overlayID)
+				}
+			}
+			return purge(ctx, s, s.carouselItems, id)
+		},
+	},
+	{
+		entityType: "menu_pins", parent: "menus", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.menuPins, func(p *entities.MenuPins) (uuid.UUID, string) { return p.MenuID, s.menuTitle(p.MenuID) })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.menuPins, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			return purge(ctx, s, s.menuPins, id)
+		},
+	},
+	{
+		entityType: "pin_markers", parent: "menu_pins", cascade: true,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.pinMarkers, func(m *entities.PinMarker) (uuid.UUID, string) { return m.MenuPinID, m.Title })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.pinMarkers, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			for imageID, image := range s.pinMarkerImages {
+				if image.PinMarkerID == id {
+					purge(ctx, s, s.pinMarkerImages, imageID)
+				}
+			}
+			return purge(ctx, s, s.pinMarkers, id)
+		},
+	},
+	{
+		entityType: "users", parent: enterpriseParent,
+		records: func(s *Store) []trashRecord {
+			return trashRecords(s.users, func(u *entities.User) (uuid.UUID, string) { return u.EnterpriseID, u.Name })
+		},
+		restore: func(ctx context.Context, s *Store, id uuid.UUID) { restore(ctx, s, s.users, id) },
+		purge: func(ctx context.Context, s *Store, id uuid.UUID, result *interfaces.PurgeResult) bool {
+			// Mirror the SET NULL foreign keys on the user
+			for _, f := range s.files {
+				if f.UploadedBy != nil && *f.UploadedBy == id {
+					f.UploadedBy = nil
+				}
+			}
+			for _, l := range s.auditLogs {
+				if l.UserID != nil && *l.UserID == id {
+					l.UserID = nil
+				}
+			}
+			return purge(ctx, s, s.users, id)
+		},
+	},
+}
+
+func trashRecords[T any](rows map[uuid.UUID]*T, describe func(*T) (uuid.UUID, string)) []trashRecord {
+	records := make([]trashRecord, 0, len(rows))
+	for id, row := range rows {
+		parentID, name := describe(row)
+		records = append(records, trashRecord{id: id, parentID: parentID, name: name, deletedAt: deletedAtOf(row)})
+	}
+	return records
+}
+
+func trashTableByType(entityType string) (trashTable, bool) {
+	for _, t := range trashTables {
+		if t.entityType == entityType {
+			return t, true
+		}
+	}
+	return trashTable{}, false
+}
+
+func (s *Store) menuTitle(id uuid.UUID) string {
+	if menu, ok := s.menus[id]; ok {
+		return menu.Title
+	}
+	return ""
+}
+
+// trashIndex holds the records of every trash table, keyed by entity type and ID
+type trashIndex map[string]map[uuid.UUID]trashRecord
+
+func (s *Store) trashIndex() trashIndex {
+	index := make(trashIndex, len(trashTables))
+	for _, t := range trashTables {
+		records := make(map[uuid.UUID]trashRecord)
+		for _, record := range t.records(s) {
+			records[record.id] = record
+		}
+		index[t.entityType] = records
+	}
+	return index
+}
+
+// parentOf returns the record owning a row, if the parent is tracked by the store
+func (index trashIndex) parentOf(t trashTable, record trashRecord) (trashRecord, bool) {
+	parent, ok := index[t.parent][record.parentID]
+	return parent, ok
+}
+
+// enterpriseOf follows the parent links of a row up to its enterprise
+func (index trashIndex) enterpriseOf(t trashTable, record trashRecord) (uuid.UUID, bool) {
+	for t.parent != enterpriseParent {
+		parentTable, ok := trashTableByType(t.parent)
+		if !ok {
+			return uuid.Nil, false
+		}
+		parent, ok := index.parentOf(t, record)
+		if !ok {
+			return uuid.Nil, false
+		}
+		t, record = parentTable, parent
+	}
+	return record.parentID, true
+}
+
+// TrashRepository implements the trash repository interface in memory
+type TrashRepository struct {
+	store *Store
+}
+
+// NewTrashRepository creates a new in-memory trash repository
+func NewTrashRepository(store *Store) interfaces.TrashRepository {
+	return &TrashRepository{store: store}
+}
+
+// List lists the rows deleted on their own in the enterprise, most recently deleted first
+func (r *TrashRepository) List(ctx context.Context, enterpriseID uuid.UUID, filters interfaces.TrashListFilters, limit, offset int) ([]*interfaces.TrashItem, error) {
+	if _, ok := trashTableByType(filters.EntityType); filters.EntityType != "" && !ok {
+		return nil, interfaces.ErrUnknownEntityType
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	index := r.store.trashIndex()
+	items := []*interfaces.TrashItem{}
+	for _, t := range trashTables {
+		if filters.EntityType != "" && t.entityType != filters.EntityType || slices.Contains(filters.ExcludedTypes, t.entityType) {
+			continue
+		}
+		for _, record := range index[t.entityType] {
+			if record.deletedAt == nil {
+				continue
+			}
+			if owner, ok := index.enterpriseOf(t, record); !ok || owner != enterpriseID {
+				continue
+			}
+			if parent, ok := index.parentOf(t, record); t.cascade && ok && parent.deletedAt != nil && !record.deletedAt.Before(*parent.deletedAt) {
+				continue
+			}
+			items = append(items, &interfaces.TrashItem{EntityType: t.entityType, ID: record.id, Name: record.name, DeletedAt: *record.deletedAt})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return paginate(items, limit, offset), nil
+}
+
+// Restore undeletes a row of the enterprise together with the children deleted with it.
+// It returns ErrParentDeleted while the row's parent is still in the trash.
+func (r *TrashRepository) Restore(ctx context.Context, enterpriseID uuid.UUID, entityType string, id uuid.UUID) error {
+	t, ok := trashTableByType(entityType)
+	if !ok {
+		return interfaces.ErrUnknownEntityType
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	index := r.store.trashIndex()
+	record, ok := index[t.entityType][id]
+	if !ok || record.deletedAt == nil {
+		return gorm.ErrRecordNotFound
+	}
+	if owner, ok := index.enterpriseOf(t, record); !ok || owner != enterpriseID {
+		return gorm.ErrRecordNotFound
+	}
+	if parent, ok := index.parentOf(t, record); t.cascade && ok && parent.deletedAt != nil {
+		return fmt.Errorf("cannot restore %s %s: %w", t.entityType, id, interfaces.ErrParentDeleted)
+	}
+
+	// Sub-menus are not cascaded but still need a live parent menu
+	if menu, ok := r.store.menus[id]; ok && t.entityType == "menus" && menu.ParentMenuID != nil {
+		if parent, ok := r.store.menus[*menu.ParentMenuID]; ok && isDeleted(parent) {
+			return fmt.Errorf("cannot restore %s %s: %w", t.entityType, id, interfaces.ErrParentDeleted)
+		}
+	}
+
+	r.store.restoreTree(ctx, index, t, record)
+	return nil
+}
+
+// Purge permanently removes the enterprise rows deleted before deletedBefore, with their
+// cascaded children and dependents
+func (r *TrashRepository) Purge(ctx context.Context, enterpriseID uuid.UUID, deletedBefore time.Time) (*interfaces.PurgeResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	result := &interfaces.PurgeResult{Purged: make(map[string]int64)}
+	index := r.store.trashIndex()
+	for _, t := range trashTables {
+		var ids []uuid.UUID
+		for _, record := range index[t.entityType] {
+			if record.deletedAt == nil || !record.deletedAt.Before(deletedBefore) {
+				continue
+			}
+			if owner, ok := index.enterpriseOf(t, record); ok && owner == enterpriseID {
+				ids = append(ids, record.id)
+			}
+		}
+		r.store.purgeTree(ctx, index, t, ids, result)
+	}
+	return result, nil
+}
+
+// restoreTree undeletes a row and, recursively, the children deleted no earlier than it
+func (s *Store) restoreTree(ctx context.Context, index trashIndex, t trashTable, record trashRecord) {
+	t.restore(ctx, s, record.id)
+	for _, child := range trashTables {
+		if !child.cascade || child.parent != t.entityType {
+			continue
+		}
+		for _, childRecord := range index[child.entityType] {
+			if childRecord.parentID == record.id && childRecord.deletedAt != nil && !childRecord.deletedAt.Before(*record.deletedAt) {
+				s.restoreTree(ctx, index, child, childRecord)
+			}
+		}
+	}
+}
+
+// purgeTree hard deletes rows with their cascaded children and dependents
+func (s *Store) purgeTree(ctx context.Context, index trashIndex, t trashTable, ids []uuid.UUID, result *interfaces.PurgeResult) {
+	if len(ids) == 0 {
+		return
+	}
+	purged := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		purged[id] = true
+	}
+
+	for _, child := range trashTables {
+		if !child.cascade || child.parent != t.entityType {
+			continue
+		}
+		var childIDs []uuid.UUID
+		for _, childRecord := range index[child.entityType] {
+			if purged[childRecord.parentID] {
+				childIDs = append(childIDs, childRecord.id)
+			}
+		}
+		s.purgeTree(ctx, index, child, childIDs, result)
+	}
+
+	for _, id := range ids {
+		if t.purge(ctx, s, id, result) {
+			result.Purged[t.entityType]++
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository implements the user identity repository interface in memory
+type UserIdentityRepository struct {
+	store *Store
+}
+
+// NewUserIdentityRepository creates a new in-memory user identity repository
+func NewUserIdentityRepository(store *Store) interfaces.UserIdentityRepository {
+	return &UserIdentityRepository{store: store}
+}
+
+// Create links an identity to a user
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := first(ctx, r.store, r.store.userIdentities, func(i *entities.UserIdentity) bool {
+		return i.Issuer == identity.Issuer && i.Subject == identity.Subject
+	}); err == nil {
+		return interfaces.NewConflictError("user identity", "issuer, subject", gorm.ErrDuplicatedKey)
+	}
+	return insert(ctx, r.store, r.store.userIdentities, identity)
+}
+
+// GetByIssuerSubject gets the identity a provider knows by subject
+func (r *UserIdentityRepository) GetByIssuerSubject(ctx context.Context, issuer, subject string) (*entities.UserIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.userIdentities, func(i *entities.UserIdentity) bool {
+		return i.Issuer == issuer && i.Subject == subject
+	})
+}
+
+// TouchLastLogin records a login with an identity
+func (r *UserIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, email string, loginAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	update(ctx, r.store, r.store.userIdentities, id, func(i *entities.UserIdentity) {
+		i.Email = email
+		i.LastLoginAt = &loginAt
+	})
+	return nil
+}
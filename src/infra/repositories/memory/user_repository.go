@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserRepository implements the user repository interface in memory
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository(store *Store) interfaces.UserRepository {
+	return &UserRepository{store: store}
+}
+
+// Create creates a new user
+func (r *UserRepository) Create(ctx context.Context, user *entities.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Unique constraints hold across enterprises
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.users, func(u *entities.User) bool { return strings.EqualFold(u.Email, user.Email) }); err == nil {
+		return interfaces.NewConflictError("user", "email", gorm.ErrDuplicatedKey)
+	}
+	return insert(ctx, r.store, r.store.users, user)
+}
+
+// GetByID gets a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return find(ctx, r.store, r.store.users, id)
+}
+
+// GetByEmail gets a user by email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return first(ctx, r.store, r.store.users, func(u *entities.User) bool { return u.Email == email })
+}
+
+// GetByEnterpriseID gets users by enterprise ID
+func (r *UserRepository) GetByEnterpriseID(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	users := filter(ctx, r.store, r.store.users, func(u *entities.User) bool { return u.EnterpriseID == enterpriseID })
+	return paginate(users, limit, offset), nil
+}
+
+// GetAll gets all users with pagination
+func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return paginate(filter(ctx, r.store, r.store.users, nil), limit, offset), nil
+}
+
+// Update updates a user
+func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := first(tenant.Unscoped(ctx), r.store, r.store.users, func(u *entities.User) bool { return strings.EqualFold(u.Email, user.Email) && u.ID != user.ID }); err == nil {
+		return interfaces.NewConflictError("user", "email", gorm.ErrDuplicatedKey)
+	}
+	return save(ctx, r.store, r.store.users, user)
+}
+
+// Delete deletes a user
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	remove(ctx, r.store, r.store.users, id)
+	return nil
+}
+
+// UpdateLastLogin updates user's last login time
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.users, userID, func(u *entities.User) {
+		now := time.Now()
+		u.LastLoginAt = &now
+	})
+	return nil
+}
+
+// MarkEmailVerified records that the user verified their email address. Users verified
+// before keep their original verification time.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.users, userID, func(u *entities.User) {
+		if u.EmailVerifiedAt == nil {
+			now := time.Now()
+			u.EmailVerifiedAt = &now
+		}
+	})
+	return nil
+}
+
+// GetByRole gets users by role
+func (r *UserRepository) GetByRole(ctx context.Context, role entities.UserRole, limit, offset int) ([]*entities.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	users := filter(ctx, r.store, r.store.users, func(u *entities.User) bool { return u.Role == role })
+	return paginate(users, limit, offset), nil
+}
+
+// GetActiveUsers gets active users (not deleted)
+func (r *UserRepository) GetActiveUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return r.GetAll(ctx, limit, offset)
+}
+
+// UpdatePassword updates user password
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	update(ctx, r.store, r.store.users, userID, func(u *entities.User) { u.PasswordHash = passwordHash })
+	return nil
+}
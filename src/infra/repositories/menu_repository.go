@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MenuRepository implements the menu repository interface
+type MenuRepository struct {
+	db *gorm.DB
+}
+
+// NewMenuRepository creates a new menu repository
+func NewMenuRepository(db *gorm.DB) interfaces.MenuRepository {
+	return &MenuRepository{db: db}
+}
+
+// Create creates a new menu
+func (r *MenuRepository) Create(ctx context.Context, menu *entities.Menu) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(menu).Error
+}
+
+// GetByID gets a menu by ID
+func (r *MenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Menu, error) {
+	var menu entities.Menu
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&menu).Error
+	if err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// GetByEnterpriseID gets menus by enterprise ID
+func (r *MenuRepository) GetByEnterpriseID(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Where("enterprise_id = ?", enterpriseID).Order("depth_level ASC, position ASC").Limit(limit).Offset(offset).Find(&menus).Error
+	return menus, err
+}
+
+// GetBySlug gets a menu by enterprise and slug
+func (r *MenuRepository) GetBySlug(ctx context.Context, enterpriseID uuid.UUID, slug string) (*entities.Menu, error) {
+	var menu entities.Menu
+	err := r.db.WithContext(ctx).Where("enterprise_id = ? AND slug = ?", enterpriseID, slug).First(&menu).Error
+	if err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// GetAll gets all menus with pagination
+func (r *MenuRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Order("depth_level ASC, position ASC").Limit(limit).Offset(offset).Find(&menus).Error
+	return menus, err
+}
+
+// Update updates a menu
+func (r *MenuRepository) Update(ctx context.Context, menu *entities.Menu) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(menu).Error
+}
+
+// UpdateFields updates the given columns of a menu, leaving the others untouched
+func (r *MenuRepository) UpdateFields(ctx context.Context, menu *entities.Menu, fields []string) error {
+	return r.db.WithContext(ctx).Model(menu).Select(fields).Updates(menu).Error
+}
+
+// Delete deletes a menu with its floor plan, carousel and pins, returning a RestrictError
+// while it still has sub-menus
+func (r *MenuRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := menuBlockers(tx, id); err != nil {
+			return err
+		}
+		return deleteMenus(tx, []uuid.UUID{id})
+	})
+}
+
+// GetChildren gets the direct children of a menu
+func (r *MenuRepository) GetChildren(ctx context.Context, parentID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Where("parent_menu_id = ?", parentID).Order("position ASC").Limit(limit).Offset(offset).Find(&menus).Error
+	return menus, err
+}
+
+// GetRootMenus gets the top level menus of an enterprise
+func (r *MenuRepository) GetRootMenus(ctx context.Context, enterpriseID uuid.UUID, limit, offset int) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Where("enterprise_id = ? AND parent_menu_id IS NULL", enterpriseID).Order("position ASC").Limit(limit).Offset(offset).Find(&menus).Error
+	return menus, err
+}
+
+// GetByScreenType gets menus of an enterprise by screen type
+func (r *MenuRepository) GetByScreenType(ctx context.Context, enterpriseID uuid.UUID, screenType entities.ScreenType, limit, offset int) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Where("enterprise_id = ? AND screen_type = ?", enterpriseID, screenType).Order("position ASC").Limit(limit).Offset(offset).Find(&menus).Error
+	return menus, err
+}
+
+// UpdatePosition updates menu position
+func (r *MenuRepository) UpdatePosition(ctx context.Context, menuID uuid.UUID, position int) error {
+	return r.db.WithContext(ctx).Model(&entities.Menu{}).Where("id = ?", menuID).Update("position", position).Error
+}
+
+// GetMenuHierarchy gets the menu tree of an enterprise, returning root menus with nested sub menus
+func (r *MenuRepository) GetMenuHierarchy(ctx context.Context, enterpriseID uuid.UUID) ([]*entities.Menu, error) {
+	var menus []*entities.Menu
+	err := r.db.WithContext(ctx).Where("enterprise_id = ?", enterpriseID).Order("depth_level ASC, position ASC").Find(&menus).Error
+	if err != nil {
+		return nil, err
+	}
+	return buildMenuTree(menus), nil
+}
+
+// buildMenuTree nests a flat menu list into a tree, keeping sibling order
+func buildMenuTree(menus []*entities.Menu) []*entities.Menu {
+	children := make(map[uuid.UUID][]*entities.Menu)
+	known := make(map[uuid.UUID]bool, len(menus))
+	for _, menu := range menus {
+		known[menu.ID] = true
+	}
+
+	var roots []*entities.Menu
+	for _, menu := range menus {
+		if menu.ParentMenuID != nil && known[*menu.ParentMenuID] {
+			children[*menu.ParentMenuID] = append(children[*menu.ParentMenuID], menu)
+			continue
+		}
+		roots = append(roots, menu)
+	}
+
+	var attach func(menu *entities.Menu)
+	attach = func(menu *entities.Menu) {
+		for _, child := range children[menu.ID] {
+			attach(child)
+			menu.SubMenus = append(menu.SubMenus, *child)
+		}
+	}
+	for _, root := range roots {
+		attach(root)
+	}
+
+	return roots
+}
+
+// TowerRepository implements the tower repository interface
+type TowerRepository struct {
+	db *gorm.DB
+}
+
+// NewTowerRepository creates a new tower repository
+func NewTowerRepository(db *gorm.DB) interfaces.TowerRepository {
+	return &TowerRepository{db: db}
+}
+
+// Create creates a new tower
+func (r *TowerRepository) Create(ctx context.Context, tower *entities.Tower) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(tower).Error
+}
+
+// GetByID gets a tower by ID
+func (r *TowerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Tower, error) {
+	var tower entities.Tower
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&tower).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tower, nil
+}
+
+// GetByMenuFloorPlanID gets towers by menu floor plan ID
+func (r *TowerRepository) GetByMenuFloorPlanID(ctx context.Context, menuFloorPlanID uuid.UUID, limit, offset int) ([]*entities.Tower, error) {
+	var towers []*entities.Tower
+	err := r.db.WithContext(ctx).Where("menu_floor_plan_id = ?", menuFloorPlanID).Order("position ASC").Limit(limit).Offset(offset).Find(&towers).Error
+	return towers, err
+}
+
+// GetAll gets all towers with pagination
+func (r *TowerRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Tower, error) {
+	var towers []*entities.Tower
+	err := r.db.WithContext(ctx).Order("position ASC").Limit(limit).Offset(offset).Find(&towers).Error
+	return towers, err
+}
+
+// Update updates a tower
+func (r *TowerRepository) Update(ctx context.Context, tower *entities.Tower) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(tower).Error
+}
+
+// UpdateFields updates the given columns of a tower, leaving the others untouched
+func (r *TowerRepository) UpdateFields(ctx context.Context, tower *entities.Tower, fields []string) error {
+	return r.db.WithContext(ctx).Model(tower).Select(fields).Updates(tower).Error
+}
+
+// Delete deletes a tower with its floors and suites
+func (r *TowerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteTowers(tx, []uuid.UUID{id})
+	})
+}
+
+// UpdatePosition updates tower position
+func (r *TowerRepository) UpdatePosition(ctx context.Context, towerID uuid.UUID, position int) error {
+	return r.db.WithContext(ctx).Model(&entities.Tower{}).Where("id = ?", towerID).Update("position", position).Error
+}
+
+// FloorRepository implements the floor repository interface
+type FloorRepository struct {
+	db *gorm.DB
+}
+
+// NewFloorRepository creates a new floor repository
+func NewFloorRepository(db *gorm.DB) interfaces.FloorRepository {
+	return &FloorRepository{db: db}
+}
+
+// Create creates a new floor
+func (r *FloorRepository) Create(ctx context.Context, floor *entities.Floor) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(floor).Error
+}
+
+// GetByID gets a floor by ID
+func (r *FloorRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Floor, error) {
+	var floor entities.Floor
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&floor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &floor, nil
+}
+
+// GetByTowerID gets floors by tower ID
+func (r *FloorRepository) GetByTowerID(ctx context.Context, towerID uuid.UUID, limit, offset int) ([]*entities.Floor, error) {
+	var floors []*entities.Floor
+	err := r.db.WithContext(ctx).Where("tower_id = ?", towerID).Order("floor_number ASC").Limit(limit).Offset(offset).Find(&floors).Error
+	return floors, err
+}
+
+// GetAll gets all floors with pagination
+func (r *FloorRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Floor, error) {
+	var floors []*entities.Floor
+	err := r.db.WithContext(ctx).Order("floor_number ASC").Limit(limit).Offset(offset).Find(&floors).Error
+	return floors, err
+}
+
+// Update updates a floor
+func (r *FloorRepository) Update(ctx context.Context, floor *entities.Floor) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(floor).Error
+}
+
+// UpdateFields updates the given columns of a floor, leaving the others untouched
+func (r *FloorRepository) UpdateFields(ctx context.Context, floor *entities.Floor, fields []string) error {
+	return r.db.WithContext(ctx).Model(floor).Select(fields).Updates(floor).Error
+}
+
+// Delete deletes a floor with its suites
+func (r *FloorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteFloors(tx, []uuid.UUID{id})
+	})
+}
+
+// GetByFloorNumber gets a floor of a tower by its number
+func (r *FloorRepository) GetByFloorNumber(ctx context.Context, towerID uuid.UUID, floorNumber int) (*entities.Floor, error) {
+	var floor entities.Floor
+	err := r.db.WithContext(ctx).Where("tower_id = ? AND floor_number = ?", towerID, floorNumber).First(&floor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &floor, nil
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MenuPinsRepository implements the menu pins repository interface
+type MenuPinsRepository struct {
+	db *gorm.DB
+}
+
+// NewMenuPinsRepository creates a new menu pins repository
+func NewMenuPinsRepository(db *gorm.DB) interfaces.MenuPinsRepository {
+	return &MenuPinsRepository{db: db}
+}
+
+// Create creates a new menu pins
+func (r *MenuPinsRepository) Create(ctx context.Context, pins *entities.MenuPins) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(pins).Error
+}
+
+// GetByID gets a menu pins by ID
+func (r *MenuPinsRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.MenuPins, error) {
+	var pins entities.MenuPins
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&pins).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pins, nil
+}
+
+// GetByMenuID gets the pins screen of a menu
+func (r *MenuPinsRepository) GetByMenuID(ctx context.Context, menuID uuid.UUID) (*entities.MenuPins, error) {
+	var pins entities.MenuPins
+	err := r.db.WithContext(ctx).Where("menu_id = ?", menuID).First(&pins).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pins, nil
+}
+
+// GetAll gets all menu pins with pagination
+func (r *MenuPinsRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.MenuPins, error) {
+	var pins []*entities.MenuPins
+	err := r.db.WithContext(ctx).Order("created_at ASC").Limit(limit).Offset(offset).Find(&pins).Error
+	return pins, err
+}
+
+// Update updates a menu pins
+func (r *MenuPinsRepository) Update(ctx context.Context, pins *entities.MenuPins) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(pins).Error
+}
+
+// Delete deletes a menu pins with its markers
+func (r *MenuPinsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteMenuPins(tx, []uuid.UUID{id})
+	})
+}
+
+// PinMarkerRepository implements the pin marker repository interface
+type PinMarkerRepository struct {
+	db *gorm.DB
+}
+
+// NewPinMarkerRepository creates a new pin marker repository
+func NewPinMarkerRepository(db *gorm.DB) interfaces.PinMarkerRepository {
+	return &PinMarkerRepository{db: db}
+}
+
+// Create creates a new pin marker
+func (r *PinMarkerRepository) Create(ctx context.Context, marker *entities.PinMarker) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(marker).Error
+}
+
+// GetByID gets a pin marker by ID
+func (r *PinMarkerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.PinMarker, error) {
+	var marker entities.PinMarker
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&marker).Error
+	if err != nil {
+		return nil, err
+	}
+	return &marker, nil
+}
+
+// GetByMenuPinID gets pin markers by menu pins ID
+func (r *PinMarkerRepository) GetByMenuPinID(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error) {
+	var markers []*entities.PinMarker
+	err := r.db.WithContext(ctx).Where("menu_pin_id = ?", menuPinID).Order("created_at ASC").Limit(limit).Offset(offset).Find(&markers).Error
+	return markers, err
+}
+
+// GetAll gets all pin markers with pagination
+func (r *PinMarkerRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.PinMarker, error) {
+	var markers []*entities.PinMarker
+	err := r.db.WithContext(ctx).Order("created_at ASC").Limit(limit).Offset(offset).Find(&markers).Error
+	return markers, err
+}
+
+// Update updates a pin marker
+func (r *PinMarkerRepository) Update(ctx context.Context, marker *entities.PinMarker) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(marker).Error
+}
+
+// UpdateFields updates the given columns of a pin marker, leaving the others untouched
+func (r *PinMarkerRepository) UpdateFields(ctx context.Context, marker *entities.PinMarker, fields []string) error {
+	return r.db.WithContext(ctx).Model(marker).Select(fields).Updates(marker).Error
+}
+
+// Delete deletes a pin marker
+func (r *PinMarkerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.PinMarker{}, id).Error
+}
+
+// GetVisibleMarkers gets visible pin markers of a menu pins
+func (r *PinMarkerRepository) GetVisibleMarkers(ctx context.Context, menuPinID uuid.UUID, limit, offset int) ([]*entities.PinMarker, error) {
+	var markers []*entities.PinMarker
+	err := r.db.WithContext(ctx).Where("menu_pin_id = ? AND is_visible = ?", menuPinID, true).Order("created_at ASC").Limit(limit).Offset(offset).Find(&markers).Error
+	return markers, err
+}
+
+// GetByActionType gets pin markers of a menu pins by action type
+func (r *PinMarkerRepository) GetByActionType(ctx context.Context, menuPinID uuid.UUID, actionType entities.PinAction, limit, offset int) ([]*entities.PinMarker, error) {
+	var markers []*entities.PinMarker
+	err := r.db.WithContext(ctx).Where("menu_pin_id = ? AND action_type = ?", menuPinID, actionType).Order("created_at ASC").Limit(limit).Offset(offset).Find(&markers).Error
+	return markers, err
+}
+
+// GetByPosition gets pin markers of a menu pins inside the given bounding box
+func (r *PinMarkerRepository) GetByPosition(ctx context.Context, menuPinID uuid.UUID, minX, maxX, minY, maxY float64, limit, offset int) ([]*entities.PinMarker, error) {
+	var markers []*entities.PinMarker
+	err := r.db.WithContext(ctx).
+		Where("menu_pin_id = ?", menuPinID).
+		Where("position_x BETWEEN ? AND ?", minX, maxX).
+		Where("position_y BETWEEN ? AND ?", minY, maxY).
+		Order("position_y ASC, position_x ASC").
+		Limit(limit).Offset(offset).
+		Find(&markers).Error
+	return markers, err
+}
+
+// PinMarkerImageRepository implements the pin marker image repository interface
+type PinMarkerImageRepository struct {
+	db *gorm.DB
+}
+
+// NewPinMarkerImageRepository creates a new pin marker image repository
+func NewPinMarkerImageRepository(db *gorm.DB) interfaces.PinMarkerImageRepository {
+	return &PinMarkerImageRepository{db: db}
+}
+
+// Create creates a new pin marker image
+func (r *PinMarkerImageRepository) Create(ctx context.Context, image *entities.PinMarkerImage) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(image).Error
+}
+
+// GetByID gets a pin marker image by ID
+func (r *PinMarkerImageRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.PinMarkerImage, error) {
+	var image entities.PinMarkerImage
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&image).Error
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// GetByPinMarkerID gets images by pin marker ID
+func (r *PinMarkerImageRepository) GetByPinMarkerID(ctx context.Context, pinMarkerID uuid.UUID, limit, offset int) ([]*entities.PinMarkerImage, error) {
+	var images []*entities.PinMarkerImage
+	err := r.db.WithContext(ctx).Where("pin_marker_id = ?", pinMarkerID).Order("position ASC").Limit(limit).Offset(offset).Find(&images).Error
+	return images, err
+}
+
+// GetAll gets all pin marker images with pagination
+func (r *PinMarkerImageRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.PinMarkerImage, error) {
+	var images []*entities.PinMarkerImage
+	err := r.db.WithContext(ctx).Order("position ASC").Limit(limit).Offset(offset).Find(&images).Error
+	return images, err
+}
+
+// Update updates a pin marker image
+func (r *PinMarkerImageRepository) Update(ctx context.Context, image *entities.PinMarkerImage) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(image).Error
+}
+
+// Delete deletes a pin marker image
+func (r *PinMarkerImageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.PinMarkerImage{}, id).Error
+}
+
+// UpdatePosition updates pin marker image position
+func (r *PinMarkerImageRepository) UpdatePosition(ctx context.Context, imageID uuid.UUID, position int) error {
+	return r.db.WithContext(ctx).Model(&entities.PinMarkerImage{}).Where("id = ?", imageID).Update("position", position).Error
+}
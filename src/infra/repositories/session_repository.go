@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SessionRepository implements the session repository interface
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *gorm.DB) interfaces.SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, session *entities.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetByID gets a session by ID
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserSession, error) {
+	var session entities.UserSession
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveByUserID gets the live sessions of a user, most recently used first
+func (r *SessionRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.UserSession, error) {
+	var sessions []*entities.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Rotate swaps the refresh token of a session, guarded by the token being replaced
+func (r *SessionRepository) Rotate(ctx context.Context, id uuid.UUID, refreshTokenID string, rotation interfaces.SessionRotation) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.UserSession{}).
+		Where("id = ? AND refresh_token_id = ? AND revoked_at IS NULL AND expires_at > ?", id, refreshTokenID, rotation.UsedAt).
+		Updates(map[string]interface{}{
+			"refresh_token_id": rotation.RefreshTokenID,
+			"expires_at":       rotation.ExpiresAt,
+			"last_used_at":     rotation.UsedAt,
+			"ip_address":       rotation.IPAddress,
+			"user_agent":       rotation.UserAgent,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Revoke revokes a session
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entities.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser revokes every session of a user
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entities.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SuiteRepository implements the suite repository interface
+type SuiteRepository struct {
+	db *gorm.DB
+}
+
+// NewSuiteRepository creates a new suite repository
+func NewSuiteRepository(db *gorm.DB) interfaces.SuiteRepository {
+	return &SuiteRepository{db: db}
+}
+
+// Create creates a new suite
+func (r *SuiteRepository) Create(ctx context.Context, suite *entities.Suite) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(suite).Error
+}
+
+// GetByID gets a suite by ID
+func (r *SuiteRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Suite, error) {
+	var suite entities.Suite
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&suite).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// GetByFloorID gets suites by floor ID
+func (r *SuiteRepository) GetByFloorID(ctx context.Context, floorID uuid.UUID, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	err := r.db.WithContext(ctx).Where("floor_id = ?", floorID).Order("unit_number ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// GetAll gets all suites with pagination
+func (r *SuiteRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	err := r.db.WithContext(ctx).Order("unit_number ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// Update updates a suite
+func (r *SuiteRepository) Update(ctx context.Context, suite *entities.Suite) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(suite).Error
+}
+
+// UpdateFields updates the given columns of a suite, leaving the others untouched
+func (r *SuiteRepository) UpdateFields(ctx context.Context, suite *entities.Suite, fields []string) error {
+	return r.db.WithContext(ctx).Model(suite).Select(fields).Updates(suite).Error
+}
+
+// Delete deletes a suite
+func (r *SuiteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.Suite{}, id).Error
+}
+
+// UpdateStatus updates suite status
+func (r *SuiteRepository) UpdateStatus(ctx context.Context, suiteID uuid.UUID, status entities.SuiteStatus) error {
+	return r.db.WithContext(ctx).Model(&entities.Suite{}).Where("id = ?", suiteID).Update("status", status).Error
+}
+
+// GetByStatus gets suites by status
+func (r *SuiteRepository) GetByStatus(ctx context.Context, status entities.SuiteStatus, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("unit_number ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// Search searches suites using the given filters
+func (r *SuiteRepository) Search(ctx context.Context, filters interfaces.SuiteSearchFilters, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	query := r.db.WithContext(ctx).Model(&entities.Suite{})
+
+	if filters.MinBedrooms != nil {
+		query = query.Where("bedrooms >= ?", *filters.MinBedrooms)
+	}
+	if filters.MaxBedrooms != nil {
+		query = query.Where("bedrooms <= ?", *filters.MaxBedrooms)
+	}
+	if filters.MinArea != nil {
+		query = query.Where("area_sqm >= ?", *filters.MinArea)
+	}
+	if filters.MaxArea != nil {
+		query = query.Where("area_sqm <= ?", *filters.MaxArea)
+	}
+	if filters.MinPrice != nil {
+		query = query.Where("price >= ?", *filters.MinPrice)
+	}
+	if filters.MaxPrice != nil {
+		query = query.Where("price <= ?", *filters.MaxPrice)
+	}
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.SunPosition != nil {
+		query = query.Where("sun_position = ?", *filters.SunPosition)
+	}
+	if filters.FloorID != nil {
+		query = query.Where("floor_id = ?", *filters.FloorID)
+	}
+	if filters.TowerID != nil {
+		query = query.Where("floor_id IN (?)", r.floorsOfTower(ctx, *filters.TowerID))
+	}
+	if filters.MinSuites != nil {
+		query = query.Where("suites_count >= ?", *filters.MinSuites)
+	}
+	if filters.MaxSuites != nil {
+		query = query.Where("suites_count <= ?", *filters.MaxSuites)
+	}
+	if filters.MinBathrooms != nil {
+		query = query.Where("bathrooms >= ?", *filters.MinBathrooms)
+	}
+	if filters.MaxBathrooms != nil {
+		query = query.Where("bathrooms <= ?", *filters.MaxBathrooms)
+	}
+	if filters.ParkingSpaces != nil {
+		query = query.Where("parking_spaces >= ?", *filters.ParkingSpaces)
+	}
+
+	err := query.Order("unit_number ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// GetByTowerID gets suites of every floor of a tower
+func (r *SuiteRepository) GetByTowerID(ctx context.Context, towerID uuid.UUID, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	err := r.db.WithContext(ctx).Where("floor_id IN (?)", r.floorsOfTower(ctx, towerID)).Order("unit_number ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// GetAvailableSuites gets suites with available status
+func (r *SuiteRepository) GetAvailableSuites(ctx context.Context, limit, offset int) ([]*entities.Suite, error) {
+	return r.GetByStatus(ctx, entities.SuiteStatusAvailable, limit, offset)
+}
+
+// GetSuitesByPriceRange gets suites priced within the given range
+func (r *SuiteRepository) GetSuitesByPriceRange(ctx context.Context, minPrice, maxPrice float64, limit, offset int) ([]*entities.Suite, error) {
+	var suites []*entities.Suite
+	err := r.db.WithContext(ctx).Where("price BETWEEN ? AND ?", minPrice, maxPrice).Order("price ASC").Limit(limit).Offset(offset).Find(&suites).Error
+	return suites, err
+}
+
+// floorsOfTower builds a subquery selecting the floor IDs of a tower
+func (r *SuiteRepository) floorsOfTower(ctx context.Context, towerID uuid.UUID) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entities.Floor{}).Select("id").Where("tower_id = ?", towerID)
+}
@@ -0,0 +1,322 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// trashEntity describes a soft deleted table of the trash bin. Every entity reaches an
+// enterprise through its parents, which scopes the trash to the caller's enterprise.
+type trashEntity struct {
+	table string
+	model func() interface{}
+	// label is the SQL expression naming a row, it may use the tables joined for scoping
+	label string
+	// parent and column link the row to its owner, enterpriseColumn ends the chain
+	parent           string
+	column           string
+	enterpriseColumn string
+	// cascade entities are deleted, restored and purged together with their parent
+	cascade bool
+	// dependents are hard deleted rows removed when the entity is purged
+	dependents []trashDependent
+}
+
+type trashDependent struct {
+	model  func() interface{}
+	column string
+}
+
+// trashEntities is ordered for purging, parents before their children. Files carry their
+// enterprise, so files without an uploader are listed and purged too.
+var trashEntities = []trashEntity{
+	{
+		table: "files", model: func() interface{} { return &entities.File{} }, label: "files.original_name",
+		enterpriseColumn: "enterprise_id",
+		dependents: []trashDependent{
+			{func() interface{} { return &entities.FileVariant{} }, "original_file_id"},
+			{func() interface{} { return &entities.PinMarkerImage{} }, "file_id"},
+		},
+	},
+	{
+		table: "menus", model: func() interface{} { return &entities.Menu{} }, label: "menus.title",
+		enterpriseColumn: "enterprise_id",
+	},
+	{
+		table: "menu_floor_plans", model: func() interface{} { return &entities.MenuFloorPlan{} }, label: "menus.title",
+		parent: "menus", column: "menu_id", cascade: true,
+	},
+	{
+		table: "towers", model: func() interface{} { return &entities.Tower{} }, label: "towers.title",
+		parent: "menu_floor_plans", column: "menu_floor_plan_id", cascade: true,
+	},
+	{
+		table: "floors", model: func() interface{} { return &entities.Floor{} }, label: "COALESCE(floors.floor_name, CAST(floors.floor_number AS TEXT))",
+		parent: "towers", column: "tower_id", cascade: true,
+	},
+	{
+		table: "suites", model: func() interface{} { return &entities.Suite{} }, label: "suites.title",
+		parent: "floors", column: "floor_id", cascade: true,
+	},
+	{
+		table: "menu_carousels", model: func() interface{} { return &entities.MenuCarousel{} }, label: "menus.title",
+		parent: "menus", column: "menu_id", cascade: true,
+	},
+	{
+		table: "carousel_items", model: func() interface{} { return &entities.CarouselItem{} }, label: "COALESCE(carousel_items.title, carousel_items.item_type)",
+		parent: "menu_carousels", column: "menu_carousel_id", cascade: true,
+		dependents: []trashDependent{
+			{func() interface{} { return &entities.CarouselTextOverlay{} }, "carousel_item_id"},
+		},
+	},
+	{
+		table: "menu_pins", model: func() interface{} { return &entities.MenuPins{} }, label: "menus.title",
+		parent: "menus", column: "menu_id", cascade: true,
+	},
+	{
+		table: "pin_markers", model: func() interface{} { return &entities.PinMarker{} }, label: "pin_markers.title",
+		parent: "menu_pins", column: "menu_pin_id", cascade: true,
+		dependents: []trashDependent{
+			{func() interface{} { return &entities.PinMarkerImage{} }, "pin_marker_id"},
+		},
+	},
+	{
+		table: "users", model: func() interface{} { return &entities.User{} }, label: "users.name",
+		enterpriseColumn: "enterprise_id",
+	},
+}
+
+func trashEntityByTable(table string) (trashEntity, bool) {
+	for _, e := range trashEntities {
+		if e.table == table {
+			return e, true
+		}
+	}
+	return trashEntity{}, false
+}
+
+// cascadeChildren returns the entities deleted together with rows of table
+func cascadeChildren(table string) []trashEntity {
+	var children []trashEntity
+	for _, e := range trashEntities {
+		if e.cascade && e.parent == table {
+			children = append(children, e)
+		}
+	}
+	return children
+}
+
+// TrashRepository implements the trash repository interface
+type TrashRepository struct {
+	db *gorm.DB
+}
+
+// NewTrashRepository creates a new trash repository
+func NewTrashRepository(db *gorm.DB) interfaces.TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// List lists the rows deleted on their own in the enterprise, most recently deleted first
+func (r *TrashRepository) List(ctx context.Context, enterpriseID uuid.UUID, filters interfaces.TrashListFilters, limit, offset int) ([]*interfaces.TrashItem, error) {
+	if _, ok := trashEntityByTable(filters.EntityType); filters.EntityType != "" && !ok {
+		return nil, interfaces.ErrUnknownEntityType
+	}
+
+	var queries []string
+	var args []interface{}
+	for _, e := range trashEntities {
+		if filters.EntityType != "" && e.table != filters.EntityType || slices.Contains(filters.ExcludedTypes, e.table) {
+			continue
+		}
+		from, where, scopeArgs := trashScope(e, enterpriseID)
+		if e.cascade {
+			where += fmt.Sprintf(" AND (%[1]s.deleted_at IS NULL OR %[2]s.deleted_at < %[1]s.deleted_at)", e.parent, e.table)
+		}
+		queries = append(queries, fmt.Sprintf("SELECT '%s' AS entity_type, %s.id AS id, %s AS name, %s.deleted_at AS deleted_at FROM %s WHERE %s",
+			e.table, e.table, e.label, e.table, from, where))
+		args = append(args, scopeArgs...)
+	}
+
+	items := []*interfaces.TrashItem{}
+	if len(queries) == 0 {
+		return items, nil
+	}
+
+	query := "SELECT * FROM (" + strings.Join(queries, " UNION ALL ") + ") AS trash ORDER BY deleted_at DESC LIMIT ? OFFSET ?"
+	err := r.db.WithContext(ctx).Raw(query, append(args, limit, offset)...).Scan(&items).Error
+	return items, err
+}
+
+// Restore undeletes a row of the enterprise together with the children deleted with it.
+// It returns ErrParentDeleted while the row's parent is still in the trash.
+func (r *TrashRepository) Restore(ctx context.Context, enterpriseID uuid.UUID, entityType string, id uuid.UUID) error {
+	e, ok := trashEntityByTable(entityType)
+	if !ok {
+		return interfaces.ErrUnknownEntityType
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		from, where, args := trashScope(e, enterpriseID)
+		parentDeletedAt := "NULL"
+		if e.cascade {
+			parentDeletedAt = e.parent + ".deleted_at"
+		}
+
+		var row struct {
+			DeletedAt       time.Time
+			ParentDeletedAt *time.Time
+		}
+		query := fmt.Sprintf("SELECT %s.deleted_at AS deleted_at, %s AS parent_deleted_at FROM %s WHERE %s AND %s.id = ?",
+			e.table, parentDeletedAt, from, where, e.table)
+		result := tx.Raw(query, append(args, id)...).Scan(&row)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if row.ParentDeletedAt != nil {
+			return fmt.Errorf("cannot restore %s %s: %w", e.table, id, interfaces.ErrParentDeleted)
+		}
+
+		// Sub-menus are not cascaded but still need a live parent menu
+		if e.table == "menus" {
+			var deletedParents int64
+			err := tx.Unscoped().Model(&entities.Menu{}).
+				Where("deleted_at IS NOT NULL AND id = (SELECT parent_menu_id FROM menus WHERE id = ?)", id).
+				Count(&deletedParents).Error
+			if err != nil {
+				return err
+			}
+			if deletedParents > 0 {
+				return fmt.Errorf("cannot restore %s %s: %w", e.table, id, interfaces.ErrParentDeleted)
+			}
+		}
+
+		return restoreTree(tx, e, id, row.DeletedAt)
+	})
+}
+
+// Purge permanently removes the enterprise rows deleted before deletedBefore, with their
+// cascaded children and dependents
+func (r *TrashRepository) Purge(ctx context.Context, enterpriseID uuid.UUID, deletedBefore time.Time) (*interfaces.PurgeResult, error) {
+	result := &interfaces.PurgeResult{Purged: make(map[string]int64)}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, e := range trashEntities {
+			from, where, args := trashScope(e, enterpriseID)
+			var ids []uuid.UUID
+			query := fmt.Sprintf("SELECT %s.id FROM %s WHERE %s AND %s.deleted_at < ?", e.table, from, where, e.table)
+			if err := tx.Raw(query, append(args, deletedBefore)...).Scan(&ids).Error; err != nil {
+				return err
+			}
+			if err := purgeTree(tx, e, ids, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// trashScope builds the FROM and WHERE clauses selecting the deleted rows of e that belong
+// to the enterprise. Parents are joined regardless of their own deleted_at.
+func trashScope(e trashEntity, enterpriseID uuid.UUID) (string, string, []interface{}) {
+	from := e.table
+	owner := e
+	for owner.enterpriseColumn == "" {
+		parent, _ := trashEntityByTable(owner.parent)
+		from += fmt.Sprintf(" JOIN %s ON %s.id = %s.%s", parent.table, parent.table, owner.table, owner.column)
+		owner = parent
+	}
+	where := fmt.Sprintf("%s.deleted_at IS NOT NULL AND %s.%s = ?", e.table, owner.table, owner.enterpriseColumn)
+	return from, where, []interface{}{enterpriseID}
+}
+
+// restoreTree undeletes a row and, recursively, the children deleted no earlier than it
+func restoreTree(tx *gorm.DB, e trashEntity, id uuid.UUID, deletedAt time.Time) error {
+	if err := tx.Unscoped().Model(e.model()).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	for _, child := range cascadeChildren(e.table) {
+		var rows []struct {
+			ID        uuid.UUID
+			DeletedAt time.Time
+		}
+		err := tx.Unscoped().Model(child.model()).Select("id", "deleted_at").
+			Where(child.column+" = ? AND deleted_at >= ?", id, deletedAt).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := restoreTree(tx, child, row.ID, row.DeletedAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// purgeTree hard deletes rows of e with their cascaded children and dependents, collecting
+// the storage objects of purged files
+func purgeTree(tx *gorm.DB, e trashEntity, ids []uuid.UUID, result *interfaces.PurgeResult) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, child := range cascadeChildren(e.table) {
+		var childIDs []uuid.UUID
+		if err := tx.Unscoped().Model(child.model()).Where(child.column+" IN ?", ids).Pluck("id", &childIDs).Error; err != nil {
+			return err
+		}
+		if err := purgeTree(tx, child, childIDs, result); err != nil {
+			return err
+		}
+	}
+
+	switch e.table {
+	case "files":
+		var filePaths, variantPaths []string
+		if err := tx.Unscoped().Model(&entities.File{}).Where("id IN ?", ids).Pluck("storage_path", &filePaths).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.FileVariant{}).Where("original_file_id IN ?", ids).Pluck("storage_path", &variantPaths).Error; err != nil {
+			return err
+		}
+		result.StoragePaths = append(append(result.StoragePaths, filePaths...), variantPaths...)
+		if err := clearFileReferences(tx, ids); err != nil {
+			return err
+		}
+	case "menus":
+		// Sub-menus are restricted, detach the ones left behind in the trash
+		err := tx.Unscoped().Model(&entities.Menu{}).Where("parent_menu_id IN ? AND deleted_at IS NOT NULL", ids).Update("parent_menu_id", nil).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dependent := range e.dependents {
+		if err := tx.Where(dependent.column+" IN ?", ids).Delete(dependent.model()).Error; err != nil {
+			return err
+		}
+	}
+
+	deleted := tx.Unscoped().Where("id IN ?", ids).Delete(e.model())
+	if deleted.Error != nil {
+		return deleted.Error
+	}
+	result.Purged[e.table] += deleted.RowsAffected
+	return nil
+}
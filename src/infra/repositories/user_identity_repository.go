@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository implements the user identity repository interface
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new user identity repository
+func NewUserIdentityRepository(db *gorm.DB) interfaces.UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+// Create links an identity to a user
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// GetByIssuerSubject gets the identity a provider knows by subject
+func (r *UserIdentityRepository) GetByIssuerSubject(ctx context.Context, issuer, subject string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	err := r.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// TouchLastLogin records a login with an identity
+func (r *UserIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, email string, loginAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.UserIdentity{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"email":         email,
+			"last_login_at": loginAt,
+		}).Error
+}
@@ -74,6 +74,12 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID)
 	return r.db.WithContext(ctx).Model(&entities.User{}).Where("id = ?", userID).Update("last_login_at", "NOW()").Error
 }
 
+// MarkEmailVerified records that the user verified their email address. Users verified
+// before keep their original verification time.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entities.User{}).Where("id = ? AND email_verified_at IS NULL", userID).Update("email_verified_at", "NOW()").Error
+}
+
 // GetByRole gets users by role
 func (r *UserRepository) GetByRole(ctx context.Context, role entities.UserRole, limit, offset int) ([]*entities.User, error) {
 	var users []*entities.User
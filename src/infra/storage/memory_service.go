@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"terra-allwert/domain/interfaces"
+)
+
+var (
+	// ErrObjectNotFound is returned when an object does not exist in the memory storage
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrUploadNotFound is returned when a multipart upload does not exist
+	ErrUploadNotFound = errors.New("multipart upload not found")
+	// ErrInvalidSignature is returned when a presigned URL is tampered or expired
+	ErrInvalidSignature = errors.New("invalid or expired presigned URL")
+)
+
+type memoryObject struct {
+	data         []byte
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+type memoryMultipartUpload struct {
+	objectKey   string
+	contentType string
+	parts       map[int]*memoryObject
+}
+
+// MemoryStorageService implements StorageService in process memory. Presigned URLs
+// point at baseURL and are signed with an HMAC so they can be served by the API itself.
+type MemoryStorageService struct {
+	mu         sync.RWMutex
+	baseURL    string
+	bucketName string
+	signingKey []byte
+	buckets    map[string]bool
+	objects    map[string]*memoryObject
+	uploads    map[string]*memoryMultipartUpload
+}
+
+// NewMemoryStorageService creates a new in-memory storage service
+func NewMemoryStorageService(baseURL, bucketName string) (*MemoryStorageService, error) {
+	signingKey := make([]byte, 32)
+	if _, err := rand.Read(signingKey); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return &MemoryStorageService{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		bucketName: bucketName,
+		signingKey: signingKey,
+		buckets:    map[string]bool{bucketName: true},
+		objects:    make(map[string]*memoryObject),
+		uploads:    make(map[string]*memoryMultipartUpload),
+	}, nil
+}
+
+func (s *MemoryStorageService) GeneratePresignedUploadURL(ctx context.Context, objectKey string, expiration time.Duration, contentType string) (*url.URL, error) {
+	params := make(url.Values)
+	if contentType != "" {
+		params.Set("Content-Type", contentType)
+	}
+	return s.presign("PUT", objectKey, expiration, params)
+}
+
+func (s *MemoryStorageService) GeneratePresignedDownloadURL(ctx context.Context, objectKey string, expiration time.Duration) (*url.URL, error) {
+	return s.presign("GET", objectKey, expiration, make(url.Values))
+}
+
+func (s *MemoryStorageService) UploadFile(ctx context.Context, objectKey string, reader io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return fmt.Errorf("failed to upload file: expected %d bytes, got %d", size, len(data))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[objectKey] = newMemoryObject(data, contentType)
+	return nil
+}
+
+func (s *MemoryStorageService) DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[objectKey]
+	if !ok {
+		return nil, fmt.Errorf("failed to download file: %w", ErrObjectNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(object.data)), nil
+}
+
+func (s *MemoryStorageService) DeleteFile(ctx context.Context, objectKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, objectKey)
+	return nil
+}
+
+func (s *MemoryStorageService) CopyFile(ctx context.Context, sourceKey, destKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[sourceKey]
+	if !ok {
+		return fmt.Errorf("failed to copy file: %w", ErrObjectNotFound)
+	}
+	copied := *object
+	copied.lastModified = time.Now()
+	s.objects[destKey] = &copied
+	return nil
+}
+
+func (s *MemoryStorageService) MoveFile(ctx context.Context, sourceKey, destKey string) error {
+	if err := s.CopyFile(ctx, sourceKey, destKey); err != nil {
+		return err
+	}
+
+	if err := s.DeleteFile(ctx, sourceKey); err != nil {
+		return fmt.Errorf("failed to delete source file after move: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MemoryStorageService) FileExists(ctx context.Context, objectKey string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.objects[objectKey]
+	return ok, nil
+}
+
+func (s *MemoryStorageService) GetFileInfo(ctx context.Context, objectKey string) (*interfaces.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[objectKey]
+	if !ok {
+		return nil, fmt.Errorf("failed to get file info: %w", ErrObjectNotFound)
+	}
+	info := object.info(objectKey)
+	return &info, nil
+}
+
+func (s *MemoryStorageService) GetFileURL(ctx context.Context, objectKey string) string {
+	return fmt.Sprintf("%s/%s/%s", s.baseURL, s.bucketName, objectKey)
+}
+
+func (s *MemoryStorageService) EnsureBucket(ctx context.Context, bucketName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buckets[bucketName] = true
+	return nil
+}
+
+func (s *MemoryStorageService) ListFiles(ctx context.Context, prefix string, limit int) ([]interfaces.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var files []interfaces.FileInfo
+	for _, key := range keys {
+		if limit > 0 && len(files) >= limit {
+			break
+		}
+		files = append(files, s.objects[key].info(key))
+	}
+
+	return files, nil
+}
+
+func (s *MemoryStorageService) InitiateMultipartUpload(ctx context.Context, objectKey string, contentType string) (string, error) {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	uploadID := hex.EncodeToString(idBytes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads[uploadID] = &memoryMultipartUpload{
+		objectKey:   objectKey,
+		contentType: contentType,
+		parts:       make(map[int]*memoryObject),
+	}
+	return uploadID, nil
+}
+
+func (s *MemoryStorageService) GeneratePresignedPartURL(ctx context.Context, objectKey, uploadID string, partNumber int, expiration time.Duration) (*url.URL, error) {
+	s.mu.RLock()
+	upload, ok := s.uploads[uploadID]
+	s.mu.RUnlock()
+	if !ok || upload.objectKey != objectKey {
+		return nil, fmt.Errorf("failed to generate presigned part URL: %w", ErrUploadNotFound)
+	}
+	if partNumber < 1 || partNumber > 10000 {
+		return nil, fmt.Errorf("failed to generate presigned part URL: invalid part number %d", partNumber)
+	}
+
+	params := make(url.Values)
+	params.Set("uploadId", uploadID)
+	params.Set("partNumber", strconv.Itoa(partNumber))
+	return s.presign("PUT", objectKey, expiration, params)
+}
+
+// UploadPart stores a single part of a multipart upload and returns its ETag
+func (s *MemoryStorageService) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int, data []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.objectKey != objectKey {
+		return "", ErrUploadNotFound
+	}
+	part := newMemoryObject(data, upload.contentType)
+	upload.parts[partNumber] = part
+	return part.etag, nil
+}
+
+func (s *MemoryStorageService) CompleteMultipartUpload(ctx context.Context, objectKey, uploadID string, parts []interfaces.CompletePart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.objectKey != objectKey {
+		return fmt.Errorf("failed to complete multipart upload: %w", ErrUploadNotFound)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("failed to complete multipart upload: no parts given")
+	}
+
+	var data bytes.Buffer
+	previous := 0
+	for _, part := range parts {
+		if part.PartNumber <= previous {
+			return fmt.Errorf("failed to complete multipart upload: parts must be in ascending order")
+		}
+		previous = part.PartNumber
+
+		uploaded, ok := upload.parts[part.PartNumber]
+		if !ok {
+			return fmt.Errorf("failed to complete multipart upload: part %d was not uploaded", part.PartNumber)
+		}
+		if strings.Trim(part.ETag, `"`) != uploaded.etag {
+			return fmt.Errorf("failed to complete multipart upload: etag mismatch for part %d", part.PartNumber)
+		}
+		data.Write(uploaded.data)
+	}
+
+	s.objects[objectKey] = newMemoryObject(data.Bytes(), upload.contentType)
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+func (s *MemoryStorageService) AbortMultipartUpload(ctx context.Context, objectKey, uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+// VerifyPresignedURL checks the signature and expiration of a presigned request
+func (s *MemoryStorageService) VerifyPresignedURL(method, objectKey string, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get("X-Expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+
+	signed := make(url.Values)
+	for key, values := range query {
+		if key != "X-Signature" {
+			signed[key] = values
+		}
+	}
+	expected := s.sign(method, objectKey, signed)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("X-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *MemoryStorageService) presign(method, objectKey string, expiration time.Duration, params url.Values) (*url.URL, error) {
+	params.Set("X-Expires", strconv.FormatInt(time.Now().Add(expiration).Unix(), 10))
+	params.Set("X-Signature", s.sign(method, objectKey, params))
+
+	presignedURL, err := url.Parse(s.GetFileURL(context.Background(), objectKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+	presignedURL.RawQuery = params.Encode()
+	return presignedURL, nil
+}
+
+func (s *MemoryStorageService) sign(method, objectKey string, params url.Values) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(method + "\n" + objectKey + "\n" + params.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newMemoryObject(data []byte, contentType string) *memoryObject {
+	sum := md5.Sum(data)
+	return &memoryObject{
+		data:         append([]byte(nil), data...),
+		contentType:  contentType,
+		etag:         hex.EncodeToString(sum[:]),
+		lastModified: time.Now(),
+	}
+}
+
+func (o *memoryObject) info(objectKey string) interfaces.FileInfo {
+	return interfaces.FileInfo{
+		Key:          objectKey,
+		Size:         int64(len(o.data)),
+		LastModified: o.lastModified,
+		ContentType:  o.contentType,
+		ETag:         o.etag,
+	}
+}
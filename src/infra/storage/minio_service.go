@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -11,7 +12,10 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/metrics"
 )
 
 // Buffer pool for efficient memory management during large file uploads
@@ -23,25 +27,47 @@ var bufferPool = sync.Pool{
 
 type MinIOService struct {
 	client     *minio.Client
+	transport  *http.Transport
 	bucketName string
 	endpoint   string
 	useSSL     bool
 }
 
 type MinIOConfig struct {
-	Endpoint        string
-	AccessKey       string
-	SecretKey       string
-	BucketName      string
-	UseSSL          bool
-	Region          string
+	Endpoint   string
+	AccessKey  string
+	SecretKey  string
+	BucketName string
+	UseSSL     bool
+	Region     string
+	// TracerProvider records a span for every request to MinIO, when set
+	TracerProvider trace.TracerProvider
+	// Metrics observe the duration of every request to MinIO, when set
+	Metrics *metrics.Metrics
 }
 
 func NewMinIOService(config MinIOConfig) (*MinIOService, error) {
+	transport, err := minio.DefaultTransport(config.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO transport: %w", err)
+	}
+	var roundTripper http.RoundTripper = transport
+	if config.Metrics != nil {
+		roundTripper = metrics.NewStorageTransport(config.Metrics, roundTripper)
+	}
+	if config.TracerProvider != nil {
+		roundTripper = otelhttp.NewTransport(roundTripper,
+			otelhttp.WithTracerProvider(config.TracerProvider),
+			otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+				return "minio " + req.Method
+			}),
+		)
+	}
 	client, err := minio.New(config.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
-		Secure: config.UseSSL,
-		Region: config.Region,
+		Creds:     credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure:    config.UseSSL,
+		Region:    config.Region,
+		Transport: roundTripper,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
@@ -49,6 +75,7 @@ func NewMinIOService(config MinIOConfig) (*MinIOService, error) {
 
 	service := &MinIOService{
 		client:     client,
+		transport:  transport,
 		bucketName: config.BucketName,
 		endpoint:   config.Endpoint,
 		useSSL:     config.UseSSL,
@@ -62,6 +89,12 @@ func NewMinIOService(config MinIOConfig) (*MinIOService, error) {
 	return service, nil
 }
 
+// Close closes the idle connections to MinIO, requests still running keep theirs
+func (s *MinIOService) Close() error {
+	s.transport.CloseIdleConnections()
+	return nil
+}
+
 func (s *MinIOService) GeneratePresignedUploadURL(ctx context.Context, objectKey string, expiration time.Duration, contentType string) (*url.URL, error) {
 	reqParams := make(url.Values)
 	if contentType != "" {
@@ -256,6 +289,18 @@ func (s *MinIOService) GetFileURL(ctx context.Context, objectKey string) string
 	return fmt.Sprintf("%s://%s/%s/%s", scheme, s.endpoint, s.bucketName, objectKey)
 }
 
+// Ping checks MinIO answers and the bucket of the API exists
+func (s *MinIOService) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucketName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucketName)
+	}
+	return nil
+}
+
 func (s *MinIOService) EnsureBucket(ctx context.Context, bucketName string) error {
 	exists, err := s.client.BucketExists(ctx, bucketName)
 	if err != nil {
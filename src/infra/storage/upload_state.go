@@ -3,10 +3,13 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/cache"
 )
 
 // UploadState represents the state of a resumable upload
@@ -33,18 +36,24 @@ const (
 	UploadStatusAborted    UploadStatus = "aborted"
 )
 
-// UploadStateManager manages resumable upload states using Redis
+// UploadStateManager manages resumable upload states using Redis or an in-memory store
 type UploadStateManager struct {
-	redisClient *redis.Client
-	keyPrefix   string
-	ttl         time.Duration
+	store     cache.Store
+	keyPrefix string
+	ttl       time.Duration
 }
 
+// NewUploadStateManager creates an upload state manager backed by Redis
 func NewUploadStateManager(redisClient *redis.Client) *UploadStateManager {
+	return NewUploadStateManagerWithStore(cache.NewRedisStore(redisClient))
+}
+
+// NewUploadStateManagerWithStore creates an upload state manager backed by the given store
+func NewUploadStateManagerWithStore(store cache.Store) *UploadStateManager {
 	return &UploadStateManager{
-		redisClient: redisClient,
-		keyPrefix:   "upload_state:",
-		ttl:         24 * time.Hour, // Upload states expire after 24 hours
+		store:     store,
+		keyPrefix: "upload_state:",
+		ttl:       24 * time.Hour, // Upload states expire after 24 hours
 	}
 }
 
@@ -58,7 +67,7 @@ func (usm *UploadStateManager) SaveUploadState(ctx context.Context, state *Uploa
 	}
 
 	key := usm.keyPrefix + state.UploadID
-	err = usm.redisClient.Set(ctx, key, data, usm.ttl).Err()
+	err = usm.store.Set(ctx, key, data, usm.ttl)
 	if err != nil {
 		return fmt.Errorf("failed to save upload state: %w", err)
 	}
@@ -69,16 +78,16 @@ func (usm *UploadStateManager) SaveUploadState(ctx context.Context, state *Uploa
 // GetUploadState retrieves an upload state by upload ID
 func (usm *UploadStateManager) GetUploadState(ctx context.Context, uploadID string) (*UploadState, error) {
 	key := usm.keyPrefix + uploadID
-	data, err := usm.redisClient.Get(ctx, key).Result()
+	data, err := usm.store.Get(ctx, key)
 	if err != nil {
-		if err == redis.Nil {
+		if errors.Is(err, cache.ErrNotFound) {
 			return nil, fmt.Errorf("upload state not found: %s", uploadID)
 		}
 		return nil, fmt.Errorf("failed to get upload state: %w", err)
 	}
 
 	var state UploadState
-	err = json.Unmarshal([]byte(data), &state)
+	err = json.Unmarshal(data, &state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal upload state: %w", err)
 	}
@@ -89,7 +98,7 @@ func (usm *UploadStateManager) GetUploadState(ctx context.Context, uploadID stri
 // DeleteUploadState removes an upload state
 func (usm *UploadStateManager) DeleteUploadState(ctx context.Context, uploadID string) error {
 	key := usm.keyPrefix + uploadID
-	err := usm.redisClient.Del(ctx, key).Err()
+	err := usm.store.Delete(ctx, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete upload state: %w", err)
 	}
@@ -116,21 +125,20 @@ func (usm *UploadStateManager) UpdatePartProgress(ctx context.Context, uploadID
 
 // GetUserUploads returns all active uploads for a user
 func (usm *UploadStateManager) GetUserUploads(ctx context.Context, userID string) ([]*UploadState, error) {
-	pattern := usm.keyPrefix + "*"
-	keys, err := usm.redisClient.Keys(ctx, pattern).Result()
+	keys, err := usm.store.Keys(ctx, usm.keyPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get upload keys: %w", err)
 	}
 
 	var userUploads []*UploadState
 	for _, key := range keys {
-		data, err := usm.redisClient.Get(ctx, key).Result()
+		data, err := usm.store.Get(ctx, key)
 		if err != nil {
 			continue // Skip invalid entries
 		}
 
 		var state UploadState
-		if err := json.Unmarshal([]byte(data), &state); err != nil {
+		if err := json.Unmarshal(data, &state); err != nil {
 			continue
 		}
 
@@ -143,32 +151,31 @@ func (usm *UploadStateManager) GetUserUploads(ctx context.Context, userID string
 }
 
 // CleanupExpiredUploads removes expired upload states (called by background job)
-func (usm *UploadStateManager) CleanupExpiredUploads(ctx context.Context, minioService *MinIOService) error {
-	pattern := usm.keyPrefix + "*"
-	keys, err := usm.redisClient.Keys(ctx, pattern).Result()
+func (usm *UploadStateManager) CleanupExpiredUploads(ctx context.Context, storageService interfaces.StorageService) error {
+	keys, err := usm.store.Keys(ctx, usm.keyPrefix)
 	if err != nil {
 		return fmt.Errorf("failed to get upload keys for cleanup: %w", err)
 	}
 
 	now := time.Now()
 	for _, key := range keys {
-		data, err := usm.redisClient.Get(ctx, key).Result()
+		data, err := usm.store.Get(ctx, key)
 		if err != nil {
 			continue
 		}
 
 		var state UploadState
-		if err := json.Unmarshal([]byte(data), &state); err != nil {
+		if err := json.Unmarshal(data, &state); err != nil {
 			continue
 		}
 
 		// Clean up uploads older than 24 hours that are not completed
 		if state.Status != UploadStatusCompleted && now.Sub(state.UpdatedAt) > usm.ttl {
-			// Abort the multipart upload in MinIO
-			minioService.AbortMultipartUpload(ctx, state.ObjectKey, state.UploadID)
+			// Abort the multipart upload in the storage backend
+			storageService.AbortMultipartUpload(ctx, state.ObjectKey, state.UploadID)
 			
-			// Remove from Redis
-			usm.redisClient.Del(ctx, key)
+			// Remove from the state store
+			usm.store.Delete(ctx, key)
 		}
 	}
 
@@ -0,0 +1,228 @@
+package tenant
+
+import (
+	"fmt"
+	"reflect"
+
+	"terra-allwert/domain/interfaces"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// unguarded tables accept rows for any enterprise: audit entries are written on behalf of
+// whichever mutation triggered them
+var unguarded = map[string]bool{
+	"audit_logs": true,
+}
+
+// RegisterCallbacks scopes every query, update and delete issued through db to the
+// enterprise found in the statement context, and rejects creates and updates that would
+// attach rows to another enterprise. Raw SQL is left alone.
+func RegisterCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeQuery); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeQuery); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", scopeUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeDelete); err != nil {
+		return err
+	}
+	return db.Callback().Create().Before("gorm:create").Register("tenant:create", guardCreate)
+}
+
+func scopeQuery(db *gorm.DB) {
+	if enterpriseID, ok := scopeOf(db); ok {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{Condition(db.Statement.Table, enterpriseID)}})
+	}
+}
+
+func scopeUpdate(db *gorm.DB) {
+	enterpriseID, ok := scopeOf(db)
+	if !ok || !conditioned(db) {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{Condition(db.Statement.Table, enterpriseID)}})
+	guardAssignment(db, enterpriseID)
+}
+
+func scopeDelete(db *gorm.DB) {
+	if enterpriseID, ok := scopeOf(db); ok && conditioned(db) {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{Condition(db.Statement.Table, enterpriseID)}})
+	}
+}
+
+// guardCreate checks that new rows belong to the caller's enterprise, and that an upsert
+// does not overwrite rows of another enterprise
+func guardCreate(db *gorm.DB) {
+	enterpriseID, ok := scopeOf(db)
+	if !ok || unguarded[db.Statement.Table] || db.Statement.Schema == nil {
+		return
+	}
+	stmt := db.Statement
+	column, _ := Owner(stmt.Table)
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return
+	}
+
+	var owners []uuid.UUID
+	var ids []interface{}
+	eachRow(stmt.ReflectValue, func(row reflect.Value) {
+		value, _ := field.ValueOf(stmt.Context, row)
+		owner, _ := toUUID(value)
+		owners = append(owners, owner)
+		if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil {
+			if id, zero := pk.ValueOf(stmt.Context, row); !zero {
+				ids = append(ids, id)
+			}
+		}
+	})
+	if err := checkOwners(db, enterpriseID, owners); err != nil {
+		db.AddError(err)
+		return
+	}
+
+	if _, upsert := stmt.Clauses["ON CONFLICT"]; upsert && len(ids) > 0 {
+		count := func(tx *gorm.DB) int64 {
+			var n int64
+			tx.Table(stmt.Table).Where("id IN ?", ids).Count(&n)
+			return n
+		}
+		all := count(db.Session(&gorm.Session{NewDB: true, Context: Unscoped(stmt.Context)}))
+		visible := count(db.Session(&gorm.Session{NewDB: true}))
+		if all != visible {
+			db.AddError(fmt.Errorf("%s: %w", stmt.Table, interfaces.ErrOtherTenant))
+		}
+	}
+}
+
+// guardAssignment checks the owner an update moves rows to, when it sets one
+func guardAssignment(db *gorm.DB, enterpriseID uuid.UUID) {
+	stmt := db.Statement
+	if stmt.Schema == nil {
+		return
+	}
+	column, _ := Owner(stmt.Table)
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return
+	}
+
+	var value interface{}
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		var set bool
+		if value, set = dest[field.DBName]; !set {
+			value, set = dest[field.Name]
+		}
+		if !set {
+			return
+		}
+	default:
+		if !stmt.ReflectValue.IsValid() || stmt.ReflectValue.Kind() != reflect.Struct {
+			return
+		}
+		var zero bool
+		if value, zero = field.ValueOf(stmt.Context, stmt.ReflectValue); zero {
+			return
+		}
+	}
+
+	owner, _ := toUUID(value)
+	if err := checkOwners(db, enterpriseID, []uuid.UUID{owner}); err != nil {
+		db.AddError(err)
+	}
+}
+
+// checkOwners verifies that every owner reference of the statement's table resolves to the
+// enterprise. A missing reference cannot be traced and is rejected.
+func checkOwners(db *gorm.DB, enterpriseID uuid.UUID, owners []uuid.UUID) error {
+	column, parent := Owner(db.Statement.Table)
+	notFound := fmt.Errorf("%s: %w", column, interfaces.ErrOtherTenant)
+
+	unique := make(map[uuid.UUID]bool, len(owners))
+	for _, owner := range owners {
+		if owner == uuid.Nil || (parent == "" && owner != enterpriseID) {
+			return notFound
+		}
+		unique[owner] = true
+	}
+	if parent == "" || len(unique) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(unique))
+	for id := range unique {
+		ids = append(ids, id)
+	}
+	// The query callbacks scope the parent lookup to the enterprise
+	var count int64
+	if err := db.Session(&gorm.Session{NewDB: true}).Table(parent).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return notFound
+	}
+	return nil
+}
+
+// scopeOf returns the enterprise a statement must be restricted to, if any
+func scopeOf(db *gorm.DB) (uuid.UUID, bool) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.SQL.Len() > 0 || !Scoped(stmt.Table) {
+		return uuid.Nil, false
+	}
+	return FromContext(stmt.Context)
+}
+
+// conditioned reports whether an update or delete already targets specific rows, so the
+// tenant condition does not lift GORM's guard against global updates
+func conditioned(db *gorm.DB) bool {
+	stmt := db.Statement
+	if stmt.AllowGlobalUpdate {
+		return true
+	}
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		return true
+	}
+	if stmt.Schema == nil || !stmt.ReflectValue.IsValid() {
+		return false
+	}
+	_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	return len(values) > 0
+}
+
+func eachRow(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			fn(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+func toUUID(value interface{}) (uuid.UUID, bool) {
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, true
+	case *uuid.UUID:
+		if v != nil {
+			return *v, true
+		}
+	case string:
+		if id, err := uuid.Parse(v); err == nil {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
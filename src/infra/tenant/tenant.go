@@ -0,0 +1,105 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Context keys read by FromContext. EnterpriseKey is set by AuthMiddleware for every
+// authenticated request; AllEnterprisesKey is set when a super admin opts out of scoping.
+// Handlers pass the fiber request context to repositories, so these Locals are visible there.
+const (
+	EnterpriseKey     = "enterprise_uuid"
+	AllEnterprisesKey = "tenant_all_enterprises"
+)
+
+type unscopedKey struct{}
+
+// link ties a table to its owner: either the enterprise column of the row itself, or the
+// column referencing the parent table it is scoped through
+type link struct {
+	parent string
+	column string
+}
+
+// links maps every tenant owned table to its owner, walking up to the enterprise.
+// Tables missing here (schema_migrations) are not scoped.
+var links = map[string]link{
+	"enterprises":            {column: "id"},
+	"users":                  {column: "enterprise_id"},
+	"menus":                  {column: "enterprise_id"},
+	"audit_logs":             {column: "enterprise_id"},
+	"api_keys":               {column: "enterprise_id"},
+	"menu_floor_plans":       {parent: "menus", column: "menu_id"},
+	"towers":                 {parent: "menu_floor_plans", column: "menu_floor_plan_id"},
+	"floors":                 {parent: "towers", column: "tower_id"},
+	"suites":                 {parent: "floors", column: "floor_id"},
+	"property_views":         {parent: "suites", column: "suite_id"},
+	"menu_carousels":         {parent: "menus", column: "menu_id"},
+	"carousel_items":         {parent: "menu_carousels", column: "menu_carousel_id"},
+	"carousel_text_overlays": {parent: "carousel_items", column: "carousel_item_id"},
+	"menu_pins":              {parent: "menus", column: "menu_id"},
+	"pin_markers":            {parent: "menu_pins", column: "menu_pin_id"},
+	"pin_marker_images":      {parent: "pin_markers", column: "pin_marker_id"},
+	"files":                  {column: "enterprise_id"},
+	"file_variants":          {parent: "files", column: "original_file_id"},
+}
+
+// FromContext returns the enterprise the caller is restricted to. It reports false for
+// unauthenticated and internal calls, and for super admins who opted out.
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	if unscoped, _ := ctx.Value(unscopedKey{}).(bool); unscoped {
+		return uuid.Nil, false
+	}
+	if all, _ := ctx.Value(AllEnterprisesKey).(bool); all {
+		return uuid.Nil, false
+	}
+	enterpriseID, ok := ctx.Value(EnterpriseKey).(uuid.UUID)
+	return enterpriseID, ok
+}
+
+// Unscoped returns a context that sees every enterprise, for checks that must hold across
+// tenants such as unique constraints
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// Scoped reports whether rows of the table belong to an enterprise
+func Scoped(table string) bool {
+	_, ok := links[table]
+	return ok
+}
+
+// Owner returns the column linking rows of the table to their owner and the parent table
+// it references, empty when the column holds the enterprise ID itself
+func Owner(table string) (column, parent string) {
+	l := links[table]
+	return l.column, l.parent
+}
+
+// Condition restricts rows of the table, under its current name in the statement, to
+// those owned by the enterprise
+func Condition(table string, enterpriseID uuid.UUID) clause.Expression {
+	l := links[table]
+	column := clause.Column{Table: clause.CurrentTable, Name: l.column}
+	if l.parent == "" {
+		return clause.Expr{SQL: "? = ?", Vars: []interface{}{column, enterpriseID}}
+	}
+	return clause.Expr{SQL: "? IN (" + subquery(l.parent) + ")", Vars: []interface{}{column, enterpriseID}}
+}
+
+// subquery selects the IDs of the table's rows owned by the enterprise bound to its
+// single placeholder
+func subquery(table string) string {
+	l := links[table]
+	if l.parent == "" {
+		return fmt.Sprintf("SELECT id FROM %s WHERE %s = ?", table, l.column)
+	}
+	return fmt.Sprintf("SELECT id FROM %s WHERE %s IN (%s)", table, l.column, subquery(l.parent))
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"terra-allwert/infra/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanKey is the key of the current span in a context, as the OpenTelemetry API stores
+// it. Handlers pass the fiber request context, which looks values up among the request
+// locals, to repositories, Redis and the storage service, so the telemetry middleware
+// stores the span of the request there under this key to parent their spans.
+var SpanKey = spanKey()
+
+// NewProvider creates the tracer provider exporting spans as OTEL_TRACES_EXPORTER
+// selects and installs it, with the W3C trace context propagator, as the global one.
+// Without an exporter spans are not recorded, their IDs still correlate logs. Shutdown
+// exports the spans still queued.
+func NewProvider(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.OTelServiceName))),
+	}
+
+	switch cfg.OTelTracesExporter {
+	case "otlp":
+		headers, err := parseHeaders(cfg.OTelEndpointHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+		}
+		exporter, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.OTelEndpoint, "/")+"/v1/traces"),
+			otlptracehttp.WithHeaders(headers),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+		slog.Info("Exporting traces over OTLP", "endpoint", cfg.OTelEndpoint)
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider, nil
+}
+
+// parseHeaders parses headers in the OTEL_EXPORTER_OTLP_HEADERS format, "key=value"
+// pairs separated by commas
+func parseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q, use key=value", pair)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// spanKey finds the unexported key the OpenTelemetry API reads the current span with
+func spanKey() any {
+	probe := &keyProbe{Context: context.Background()}
+	trace.SpanFromContext(probe)
+	return probe.key
+}
+
+// keyProbe is a context recording the key of the last value looked up in it
+type keyProbe struct {
+	context.Context
+	key any
+}
+
+func (p *keyProbe) Value(key any) any {
+	p.key = key
+	return nil
+}
@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -11,6 +12,18 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+
+	// Report fields by their JSON names, as clients send them
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
 	
 	// Register custom validators
 	validate.RegisterValidation("slug", validateSlug)
@@ -95,14 +108,20 @@ func FormatValidationErrors(err error) []ValidationError {
 
 func getErrorMessage(ve validator.FieldError) string {
 	switch ve.Tag() {
-	case "required":
+	case "required", "required_without":
 		return ve.Field() + " is required"
 	case "email":
 		return ve.Field() + " must be a valid email address"
 	case "min":
-		return ve.Field() + " must be at least " + ve.Param() + " characters"
+		return ve.Field() + " must be at least " + ve.Param() + unit(ve)
 	case "max":
-		return ve.Field() + " must be at most " + ve.Param() + " characters"
+		return ve.Field() + " must be at most " + ve.Param() + unit(ve)
+	case "oneof":
+		return ve.Field() + " must be one of: " + strings.ReplaceAll(ve.Param(), " ", ", ")
+	case "url":
+		return ve.Field() + " must be a valid URL"
+	case "hexcolor":
+		return ve.Field() + " must be a hexadecimal color, such as #FF0000"
 	case "len":
 		return ve.Field() + " must be exactly " + ve.Param() + " characters"
 	case "slug":
@@ -116,4 +135,16 @@ func getErrorMessage(ve validator.FieldError) string {
 	default:
 		return ve.Field() + " is invalid"
 	}
-}
\ No newline at end of file
+}
+
+// unit names what the bound of a min or max rule counts, nothing for numbers
+func unit(ve validator.FieldError) string {
+	switch ve.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
@@ -1,9 +1,10 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
@@ -29,6 +30,7 @@ type ProgressHub struct {
 	broadcast chan ProgressUpdate
 	register  chan *ClientConnection
 	unregister chan *ClientConnection
+	pings     chan chan struct{}
 	mu        sync.RWMutex
 }
 
@@ -47,13 +49,18 @@ func NewProgressHub() *ProgressHub {
 		broadcast:  make(chan ProgressUpdate, 256),
 		register:   make(chan *ClientConnection, 16),
 		unregister: make(chan *ClientConnection, 16),
+		pings:      make(chan chan struct{}),
 	}
 }
 
-// Run starts the progress hub
-func (h *ProgressHub) Run() {
+// Run starts the progress hub, until ctx is cancelled
+func (h *ProgressHub) Run(ctx context.Context) {
 	for {
 		select {
+		case <-ctx.Done():
+			h.closeAll()
+			return
+
 		case client := <-h.register:
 			h.registerClient(client)
 
@@ -62,6 +69,9 @@ func (h *ProgressHub) Run() {
 
 		case update := <-h.broadcast:
 			h.broadcastUpdate(update)
+
+		case pong := <-h.pings:
+			close(pong)
 		}
 	}
 }
@@ -76,7 +86,7 @@ func (h *ProgressHub) registerClient(client *ClientConnection) {
 	}
 	
 	h.clients[client.UserID][client.ConnectionID] = client.Conn
-	log.Printf("Client registered: userID=%s, connectionID=%s", client.UserID, client.ConnectionID)
+	slog.Debug("WebSocket client registered", "user_id", client.UserID, "connection_id", client.ConnectionID)
 
 	// Send initial connection confirmation
 	initialUpdate := ProgressUpdate{
@@ -107,8 +117,24 @@ func (h *ProgressHub) unregisterClient(client *ClientConnection) {
 				delete(h.clients, client.UserID)
 			}
 			
-			log.Printf("Client unregistered: userID=%s, connectionID=%s", client.UserID, client.ConnectionID)
+			slog.Debug("WebSocket client unregistered", "user_id", client.UserID, "connection_id", client.ConnectionID)
+		}
+	}
+}
+
+// closeAll tells every client the server is going away and closes its connection,
+// clients reconnect to another instance
+func (h *ProgressHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	message := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "Server shutting down")
+	for userID, userConnections := range h.clients {
+		for _, conn := range userConnections {
+			conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+			conn.Close()
 		}
+		delete(h.clients, userID)
 	}
 }
 
@@ -136,12 +162,25 @@ func (h *ProgressHub) broadcastUpdate(update ProgressUpdate) {
 func (h *ProgressHub) sendToConnection(conn *websocket.Conn, update ProgressUpdate) bool {
 	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 	if err := conn.WriteJSON(update); err != nil {
-		log.Printf("Error writing to WebSocket: %v", err)
+		slog.Warn("Failed to write to WebSocket", "error", err)
 		return false
 	}
 	return true
 }
 
+// Ping checks the hub is running and not stuck delivering updates, failing when its loop
+// does not answer before ctx is done
+func (h *ProgressHub) Ping(ctx context.Context) error {
+	pong := make(chan struct{})
+	select {
+	case h.pings <- pong:
+	case <-ctx.Done():
+		return fmt.Errorf("progress hub is not running: %w", ctx.Err())
+	}
+	<-pong
+	return nil
+}
+
 // BroadcastProgress sends a progress update to all connections for a user
 func (h *ProgressHub) BroadcastProgress(userID, taskID, taskType string, progress float64, status, message string, metadata map[string]interface{}) {
 	update := ProgressUpdate{
@@ -158,7 +197,7 @@ func (h *ProgressHub) BroadcastProgress(userID, taskID, taskType string, progres
 	select {
 	case h.broadcast <- update:
 	default:
-		log.Printf("Progress broadcast buffer full, dropping update for user %s", userID)
+		slog.Warn("Progress broadcast buffer full, dropping update", "user_id", userID)
 	}
 }
 
@@ -199,7 +238,7 @@ var upgrader = websocket.Upgrader{
 func (h *ProgressHub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		slog.WarnContext(r.Context(), "WebSocket upgrade failed", "error", err)
 		return
 	}
 
@@ -251,7 +290,7 @@ func (h *ProgressHub) readPump(client *ClientConnection) {
 		messageType, message, err := client.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				slog.Warn("WebSocket closed unexpectedly", "user_id", client.UserID, "error", err)
 			}
 			break
 		}
@@ -309,12 +348,12 @@ func (h *ProgressHub) handleControlMessage(client *ClientConnection, message map
 	case "subscribe":
 		// Handle task subscription if needed
 		if taskID, ok := message["task_id"].(string); ok {
-			log.Printf("Client %s subscribed to task %s", client.UserID, taskID)
+			slog.Debug("WebSocket client subscribed", "user_id", client.UserID, "task_id", taskID)
 		}
 	case "unsubscribe":
 		// Handle task unsubscription if needed
 		if taskID, ok := message["task_id"].(string); ok {
-			log.Printf("Client %s unsubscribed from task %s", client.UserID, taskID)
+			slog.Debug("WebSocket client unsubscribed", "user_id", client.UserID, "task_id", taskID)
 		}
 	}
 }
@@ -2,26 +2,44 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
-	"strconv"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
 	"time"
 
+	"terra-allwert/api/handlers"
 	"terra-allwert/api/routes"
 	_ "terra-allwert/docs"
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
 	"terra-allwert/infra/auth"
+	"terra-allwert/infra/cache"
 	"terra-allwert/infra/config"
 	"terra-allwert/infra/database"
+	"terra-allwert/infra/health"
+	"terra-allwert/infra/lifecycle"
+	"terra-allwert/infra/logging"
+	"terra-allwert/infra/mail"
+	"terra-allwert/infra/metrics"
 	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
 	"terra-allwert/infra/repositories"
+	"terra-allwert/infra/repositories/memory"
+	"terra-allwert/infra/storage"
+	"terra-allwert/infra/tracing"
 	"terra-allwert/infra/websocket"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/swagger"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // @title Terra Allwert API
@@ -34,193 +52,469 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 // @example Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description Read-only API key of a kiosk or display device, accepted by content read routes within its scopes.
 func main() {
+	configFile := flag.String("config", "", "YAML configuration file, overridden by the environment")
+	printConfig := flag.Bool("print-config", false, "print the configuration with secrets redacted and exit")
+	flag.Parse()
+
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.LoadFile(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal("Failed to print configuration:", err)
+		}
+		return
+	}
+
+	// Log JSON records carrying the request and trace IDs, the standard logger included
+	logger := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+
+	// Stop the server and background workers gracefully on SIGINT and SIGTERM
+	lc := lifecycle.New(cfg.ShutdownTimeout)
 
-	// Initialize database
-	db, err := database.New(cfg)
+	// Trace requests and the queries and calls made handling them
+	tracerProvider, err := tracing.NewProvider(lc.Context(), cfg)
 	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		fatal("Invalid tracing configuration", err)
 	}
-	defer db.Close()
+	lc.OnClose("tracer", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return tracerProvider.Shutdown(ctx)
+	})
+
+	// Measure requests, uploads and the clients of the API for Prometheus
+	appMetrics := metrics.New()
 
-	// Initialize repositories
-	userRepo := repositories.NewUserRepository(db.GetDB())
+	// Check the shared clients for /readyz, ready once the server listens
+	healthChecker := health.NewChecker(cfg.HealthCacheTTL)
+
+	// Initialize persistence: Postgres + Redis, or the in-memory store for offline development
+	var repos *repositorySet
+	var uploadStateStore, tokenStore cache.Store
+	if cfg.DBDriver == config.DriverMemory {
+		store := memory.NewStore()
+		if err := store.Seed(); err != nil {
+			fatal("Failed to seed in-memory store", err)
+		}
+		repos = newMemoryRepositories(store)
+		uploadStateStore = cache.NewMemoryStore()
+		tokenStore = cache.NewMemoryStore()
+		slog.Warn("Running with in-memory repositories, data is lost on restart")
+	} else {
+		db, err := database.New(cfg, tracerProvider)
+		if err != nil {
+			fatal("Failed to initialize database", err)
+		}
+		lc.OnClose("database", db.Close)
+		sqlDB, err := db.GetDB().DB()
+		if err != nil {
+			fatal("Failed to access the database connection pool", err)
+		}
+		appMetrics.RegisterDB(sqlDB, "postgres")
+		healthChecker.Register("postgres", cfg.HealthCheckTimeout, db.Ping)
+		repos = newGormRepositories(db.GetDB())
+
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:         cfg.RedisHost + ":" + cfg.RedisPort,
+			Password:     cfg.RedisPassword,
+			DB:           cfg.RedisDB,
+			PoolSize:     cfg.RedisPoolSize,
+			MinIdleConns: cfg.RedisMinIdleConns,
+			MaxRetries:   cfg.RedisMaxRetries,
+		})
+		// Trace commands by name, their arguments carry tokens
+		if err := redisotel.InstrumentTracing(redisClient,
+			redisotel.WithTracerProvider(tracerProvider),
+			redisotel.WithDBStatement(false),
+		); err != nil {
+			fatal("Failed to instrument Redis", err)
+		}
+		lc.OnClose("Redis", redisClient.Close)
+		healthChecker.Register("redis", cfg.HealthCheckTimeout, func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+		uploadStateStore = cache.NewRedisStore(redisClient)
+		tokenStore = uploadStateStore
+	}
+	uploadStateManager := storage.NewUploadStateManagerWithStore(uploadStateStore)
+
+	// Initialize storage service
+	var storageService interfaces.StorageService
+	var memoryStorage *storage.MemoryStorageService
+	if cfg.StorageDriver == config.DriverMemory {
+		memoryStorage, err = storage.NewMemoryStorageService("http://localhost:"+cfg.Port+"/storage", cfg.MinIOBucket)
+		if err != nil {
+			fatal("Failed to initialize storage", err)
+		}
+		storageService = memoryStorage
+	} else {
+		minioService, err := storage.NewMinIOService(storage.MinIOConfig{
+			Endpoint:       cfg.MinIOEndpoint,
+			AccessKey:      cfg.MinIOAccessKey,
+			SecretKey:      cfg.MinIOSecretKey,
+			BucketName:     cfg.MinIOBucket,
+			UseSSL:         cfg.MinIOUseSSL,
+			TracerProvider: tracerProvider,
+			Metrics:        appMetrics,
+		})
+		if err != nil {
+			fatal("Failed to initialize storage", err)
+		}
+		storageService = minioService
+		lc.OnClose("MinIO", minioService.Close)
+		healthChecker.Register("minio", cfg.HealthCheckTimeout, minioService.Ping)
+	}
 
 	// Initialize JWT service
-	accessTokenHours, _ := strconv.Atoi("24")  // Default 24 hours
-	refreshTokenHours, _ := strconv.Atoi("168") // Default 7 days (168 hours)
-	jwtService := auth.NewJWTService(cfg.JWTSecret, accessTokenHours, refreshTokenHours)
+	jwtKeys, err := newJWTKeySet(cfg)
+	if err != nil {
+		fatal("Failed to load JWT keys", err)
+	}
+	jwtService := auth.NewJWTService(jwtKeys, cfg.JWTAccessTokenTTL, cfg.JWTRefreshTokenTTL)
+	jwtService.SetBlacklist(auth.NewTokenBlacklist(tokenStore))
 
 	// Initialize progress hub for WebSocket connections
 	progressHub := websocket.NewProgressHub()
-	go progressHub.Run() // Start in background
+	lc.Go("progress hub", progressHub.Run)
+	healthChecker.Register("websocket_hub", cfg.HealthCheckTimeout, progressHub.Ping)
+	appMetrics.RegisterGaugeFunc("websocket_connections", "Number of open WebSocket connections following upload progress.", func() float64 {
+		return float64(progressHub.GetConnectionCount())
+	})
 
-	// Initialize rate limiter with production-ready config
-	rateLimiter := middleware.NewUploadRateLimiter(middleware.DefaultRateLimitConfig())
+	// Initialize rate limiter with the configured upload rates
+	rateLimitConfig := middleware.DefaultRateLimitConfig()
+	rateLimitConfig.SmallFileRate = middleware.PerHour(cfg.UploadRateSmallPerHour)
+	rateLimitConfig.MediumFileRate = middleware.PerHour(cfg.UploadRateMediumPerHour)
+	rateLimitConfig.LargeFileRate = middleware.PerHour(cfg.UploadRateLargePerHour)
+	rateLimitConfig.BurstSize = cfg.UploadRateBurst
+	rateLimiter := middleware.NewUploadRateLimiter(rateLimitConfig)
+	rateLimiter.SetRejectionHook(func(category middleware.FileSizeCategory) {
+		appMetrics.UploadRejected(string(category))
+	})
+	lc.Go("upload rate limiter", rateLimiter.Run)
+
+	// Initialize circuit breaker protecting the storage backend during uploads
+	circuitBreaker := middleware.NewCircuitBreaker(middleware.CircuitBreakerConfig{
+		MaxFailures:   5,
+		ResetTimeout:  30 * time.Second,
+		CheckInterval: 10 * time.Second,
+	})
+	circuitBreaker.SetStateChangeHook(func(from, to middleware.CircuitState) {
+		appMetrics.CircuitStateChanged(from.String(), to.String())
+	})
+	appMetrics.RegisterGaugeFunc("upload_circuit_breaker_state", "State of the circuit breaker protecting the storage backend: 0 closed, 1 open, 2 half open.", func() float64 {
+		return float64(circuitBreaker.State())
+	})
 
 	app := fiber.New(fiber.Config{
-		AppName: "Terra Allwert API v1.0",
+		AppName:               "Terra Allwert API v1.0",
+		DisableStartupMessage: true,
+		ErrorHandler:          problem.ErrorHandler,
 	})
 
 	// Middleware
-	app.Use(logger.New())
+	app.Use(middleware.RequestID())
+	app.Use(middleware.Telemetry(tracerProvider, appMetrics, logger))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "*",
+		AllowOrigins:     strings.Join(cfg.CORSAllowedOrigins, ","),
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
 		AllowHeaders:     "*",
-		AllowCredentials: false,
+		AllowCredentials: cfg.CORSAllowCredentials,
 	}))
 
-	// Upload rate limiting middleware for file uploads
-	app.Use("/api/v1/files", middleware.UploadRateLimitMiddleware(rateLimiter))
-
 	// Swagger
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
 	// WebSocket endpoint for progress tracking
 	app.Get("/ws/progress", func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
-			"error": "WebSocket upgrade required",
-			"note":  "Use WebSocket client to connect to this endpoint",
-		})
+		return problem.New(fiber.StatusUpgradeRequired, "WebSocket upgrade required").
+			With("note", "Use WebSocket client to connect to this endpoint")
 	})
 
 	// API Routes
 	api := app.Group("/api/v1")
 
-	// Health endpoint
-	api.Get("/health", func(c *fiber.Ctx) error {
-		return healthCheck(c, cfg)
-	})
+	// Liveness and readiness probes, and the health report
+	routes.SetupHealthRoutes(app, handlers.NewHealthHandler(healthChecker, "1.0.0"))
 
-	// Setup optimized upload routes (commented until FileRepository is available)
-	// routes.SetupOptimizedUploadRoutes(app, fileRepo, storageService, redisClient, progressHub, rateLimiter, circuitBreaker)
+	// Public keys for services verifying our tokens
+	routes.SetupWellKnownRoutes(app, handlers.NewJWKSHandler(jwtService))
 
 	// Initialize auth middleware with actual services
-	authMiddleware := middleware.NewAuthMiddleware(jwtService, userRepo)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, repos.users)
+
+	// Make the client IP and user agent available to the audit log
+	app.Use(authMiddleware.LogUserActivity())
 
 	// Seed routes (for development)
-	if cfg.Environment == "development" {
+	if cfg.Environment == "development" && cfg.DBDriver != config.DriverMemory {
 		routes.SetupSeedRoutes(app, cfg, authMiddleware)
 	}
 
 	// Setup auth routes
-	routes.SetupAuthRoutes(api, userRepo, jwtService, authMiddleware)
+	verificationPolicy, err := auth.ParseVerificationPolicy(cfg.EmailVerificationPolicy)
+	if err != nil {
+		fatal("Invalid EMAIL_VERIFICATION_POLICY", err)
+	}
+	authMiddleware.SetVerificationPolicy(verificationPolicy)
+	apiKeys := auth.NewAPIKeys(repos.apiKeys, tokenStore, cfg.APIKeyRateLimit)
+	authMiddleware.SetAPIKeys(apiKeys)
+
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		fatal("Failed to load mail templates", err)
+	}
+	authEmails := &handlers.AuthEmails{
+		Mailer:              newMailer(cfg),
+		Templates:           mailTemplates,
+		PasswordResets:      auth.NewOneTimeTokens(tokenStore, "password_reset", 30*time.Minute),
+		PasswordResetURL:    cfg.PasswordResetURL,
+		Verifications:       auth.NewOneTimeTokens(tokenStore, "email_verification", 24*time.Hour),
+		VerificationURL:     cfg.EmailVerificationURL,
+		VerificationResends: auth.NewThrottle(tokenStore, "email_verification", time.Duration(cfg.EmailVerificationResendSeconds)*time.Second),
+		VerificationPolicy:  verificationPolicy,
+	}
+	authMFA := &handlers.AuthMFA{
+		Service:     auth.NewMFAService(tokenStore, "Terra Allwert", 5*time.Minute),
+		Enrollments: repos.mfa,
+		Enterprises: repos.enterprises,
+	}
+	loginLockout := auth.NewLoginLockout(tokenStore, auth.LockoutPolicy{
+		MaxAttempts:   cfg.LoginMaxAttempts,
+		MaxIPAttempts: cfg.LoginIPMaxAttempts,
+		Backoff:       time.Duration(cfg.LoginBackoffSeconds) * time.Second,
+		Duration:      time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+	})
+	authOIDC, err := newAuthOIDC(cfg, repos.userIdentities, repos.enterprises, tokenStore)
+	if err != nil {
+		fatal("Invalid OIDC configuration", err)
+	}
+	routes.SetupAuthRoutes(api, repos.users, repos.auditLogs, repos.sessions, jwtService, authEmails, authMFA, loginLockout, authOIDC, authMiddleware)
 
 	// Setup main API routes
-	// TODO: Initialize handlers and setup main routes when repositories are available
-	// handlers := &routes.Handlers{...}
-	// routes.SetupAllRoutes(app, handlers, authMiddleware)
+	apiHandlers := &routes.Handlers{
+		EnterpriseHandler:  handlers.NewEnterpriseHandler(repos.enterprises),
+		MenuHandler:        handlers.NewMenuHandler(repos.menus),
+		TowerHandler:       handlers.NewTowerHandler(repos.towers),
+		FloorHandler:       handlers.NewFloorHandler(repos.floors),
+		SuiteHandler:       handlers.NewSuiteHandler(repos.suites),
+		CarouselHandler:    handlers.NewCarouselHandler(repos.menuCarousels, repos.carouselItems, repos.carouselOverlays),
+		PinsHandler:        handlers.NewPinsHandler(repos.menuPins, repos.pinMarkers, repos.pinMarkerImages),
+		FileHandler:        handlers.NewFileHandler(repos.files, repos.fileVariants, storageService, uploadStateManager, progressHub, rateLimiter, circuitBreaker, appMetrics),
+		FileVariantHandler: handlers.NewFileVariantHandler(repos.files, repos.fileVariants, storageService),
+		AuditHandler:       handlers.NewAuditHandler(repos.auditLogs),
+		TrashHandler:       handlers.NewTrashHandler(repos.trash, storageService, cfg.TrashRetentionDays),
+		UserHandler:        handlers.NewUserHandler(repos.users, repos.auditLogs, loginLockout),
+		APIKeyHandler:      handlers.NewAPIKeyHandler(repos.apiKeys, apiKeys),
+	}
+	routes.SetupAllRoutes(app, apiHandlers, authMiddleware, rateLimiter)
 
-	// Start server
-	log.Printf("🚀 Server starting on port %s", cfg.Port)
-	log.Printf("📈 Progress tracking available at ws://localhost:%s/ws/progress", cfg.Port)
-	log.Printf("📚 API documentation at http://localhost:%s/swagger/", cfg.Port)
-	log.Fatal(app.Listen(":" + cfg.Port))
-}
+	// Setup optimized upload routes
+	uploadThresholds := handlers.UploadThresholds{
+		DirectMaxSize:    int64(cfg.UploadDirectMaxSize),
+		PresignedMaxSize: int64(cfg.UploadPresignedMaxSize),
+	}
+	routes.SetupOptimizedUploadRoutes(app, repos.files, storageService, uploadStateManager, progressHub, rateLimiter, circuitBreaker, uploadThresholds, appMetrics, authMiddleware)
 
-// ServiceHealth represents the status of a service
-type ServiceHealth struct {
-	Status  string `json:"status" example:"ok"`
-	Message string `json:"message,omitempty" example:"Connected"`
-	Error   string `json:"error,omitempty" example:"Connection failed"`
-}
+	// Serve presigned URLs of the in-memory storage driver
+	if memoryStorage != nil {
+		routes.SetupMemoryStorageRoutes(app, handlers.NewMemoryStorageHandler(memoryStorage))
+	}
 
-// HealthResponse represents the health check response
-type HealthResponse struct {
-	Status   string                   `json:"status" example:"ok"`
-	Message  string                   `json:"message" example:"API is running"`
-	Version  string                   `json:"version" example:"1.0.0"`
-	Services map[string]ServiceHealth `json:"services"`
-}
+	// Serve the metrics on their own port, failing at boot when it is taken
+	if cfg.PrometheusEnabled {
+		metricsListener, err := net.Listen("tcp", ":"+cfg.PrometheusPort)
+		if err != nil {
+			fatal("Failed to listen for metrics scrapes", err)
+		}
+		lc.Go("metrics server", func(ctx context.Context) {
+			metrics.Serve(ctx, metricsListener, appMetrics)
+		})
+		slog.Info("Serving metrics", "metrics_url", "http://localhost:"+cfg.PrometheusPort+"/metrics")
+	}
 
-// healthCheck godoc
-// @Summary Health Check
-// @Description Verifica se a API está funcionando e testa conexões com MinIO e Redis
-// @Tags health
-// @Accept json
-// @Produce json
-// @Success 200 {object} HealthResponse
-// @Router /health [get]
-func healthCheck(c *fiber.Ctx, cfg *config.Config) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	services := make(map[string]ServiceHealth)
-	overallStatus := "ok"
-
-	// Test Redis connection
-	redisHealth := checkRedis(ctx, cfg)
-	services["redis"] = redisHealth
-	if redisHealth.Status != "ok" {
-		overallStatus = "degraded"
-	}
-
-	// Test MinIO connection
-	minioHealth := checkMinIO(ctx, cfg)
-	services["minio"] = minioHealth
-	if minioHealth.Status != "ok" {
-		overallStatus = "degraded"
-	}
-
-	return c.JSON(HealthResponse{
-		Status:   overallStatus,
-		Message:  "API is running",
-		Version:  "1.0.0",
-		Services: services,
+	// Start server
+	slog.Info("Server starting",
+		"port", cfg.Port,
+		"environment", cfg.Environment,
+		"progress_url", "ws://localhost:"+cfg.Port+"/ws/progress",
+		"docs_url", "http://localhost:"+cfg.Port+"/swagger/",
+	)
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		healthChecker.MarkReady()
+		return nil
 	})
+	serve := func() error { return app.Listen(":" + cfg.Port) }
+	shutdown := func(ctx context.Context) error {
+		// Fail readiness first and keep serving while load balancers notice, so they stop
+		// routing to the draining instance before it refuses connections
+		healthChecker.MarkDraining()
+		slog.Info("Failing readiness before draining requests", "delay", cfg.ShutdownDrainDelay.String())
+		select {
+		case <-time.After(cfg.ShutdownDrainDelay):
+		case <-ctx.Done():
+		}
+		return app.ShutdownWithContext(ctx)
+	}
+	if err := lc.Run(serve, shutdown); err != nil {
+		fatal("Server stopped with errors", err)
+	}
+	slog.Info("Server stopped")
 }
 
-func checkRedis(ctx context.Context, cfg *config.Config) ServiceHealth {
-	// Create Redis client
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.RedisHost + ":" + cfg.RedisPort,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisDB,
-	})
-	defer rdb.Close()
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	// Test connection with ping
-	_, err := rdb.Ping(ctx).Result()
-	if err != nil {
-		return ServiceHealth{
-			Status: "error",
-			Error:  "Connection failed: " + err.Error(),
+// newJWTKeySet loads the asymmetric keys in JWT_KEYS_DIR, falling back to signing with
+// JWT_SECRET
+func newJWTKeySet(cfg *config.Config) (*auth.JWTKeySet, error) {
+	if cfg.JWTKeysDir != "" {
+		keys, err := auth.LoadJWTKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+		if err != nil {
+			return nil, err
 		}
+		slog.Info("Signing tokens with asymmetric keys", "algorithm", keys.Algorithm(), "signing_key", keys.SigningKeyID(), "keys", keys.KeyIDs())
+		return keys, nil
 	}
+	slog.Warn("Signing tokens with JWT_SECRET (HS256), set JWT_KEYS_DIR to sign with asymmetric keys")
+	return auth.NewHMACKeySet(cfg.JWTSecret), nil
+}
 
-	return ServiceHealth{
-		Status:  "ok",
-		Message: "Connected to " + cfg.RedisHost + ":" + cfg.RedisPort,
+// newAuthOIDC configures single sign-on with the OpenID Connect provider at
+// OIDC_ISSUER_URL, nil when none is set
+func newAuthOIDC(cfg *config.Config, identities interfaces.UserIdentityRepository, enterprises interfaces.EnterpriseRepository, store cache.Store) (*handlers.AuthOIDC, error) {
+	if cfg.OIDCIssuerURL == "" {
+		return nil, nil
+	}
+	authOIDC := &handlers.AuthOIDC{
+		Provider: auth.NewOIDCProvider(auth.OIDCConfig{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       strings.Fields(cfg.OIDCScopes),
+		}, store),
+		Identities:    identities,
+		ProvisionRole: entities.UserRole(cfg.OIDCProvisionRole),
+	}
+	if cfg.OIDCProvisionEnterpriseID != "" {
+		enterpriseID, err := uuid.Parse(cfg.OIDCProvisionEnterpriseID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDC_PROVISION_ENTERPRISE_ID: %w", err)
+		}
+		if _, err := enterprises.GetByID(context.Background(), enterpriseID); err != nil {
+			return nil, fmt.Errorf("OIDC_PROVISION_ENTERPRISE_ID: %w", err)
+		}
+		authOIDC.ProvisionEnterpriseID = enterpriseID
 	}
+	slog.Info("Single sign-on enabled", "issuer", cfg.OIDCIssuerURL)
+	return authOIDC, nil
 }
 
-func checkMinIO(ctx context.Context, cfg *config.Config) ServiceHealth {
-	// Create MinIO client
-	minioClient, err := minio.New(cfg.MinIOEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.MinIOAccessKey, cfg.MinIOSecretKey, ""),
-		Secure: cfg.MinIOUseSSL,
-	})
-	if err != nil {
-		return ServiceHealth{
-			Status: "error",
-			Error:  "Failed to create client: " + err.Error(),
+// repositorySet groups the repositories of one persistence driver
+type repositorySet struct {
+	users            interfaces.UserRepository
+	enterprises      interfaces.EnterpriseRepository
+	menus            interfaces.MenuRepository
+	towers           interfaces.TowerRepository
+	floors           interfaces.FloorRepository
+	suites           interfaces.SuiteRepository
+	menuCarousels    interfaces.MenuCarouselRepository
+	carouselItems    interfaces.CarouselItemRepository
+	carouselOverlays interfaces.CarouselTextOverlayRepository
+	menuPins         interfaces.MenuPinsRepository
+	pinMarkers       interfaces.PinMarkerRepository
+	pinMarkerImages  interfaces.PinMarkerImageRepository
+	files            interfaces.FileRepository
+	fileVariants     interfaces.FileVariantRepository
+	auditLogs        interfaces.AuditLogRepository
+	trash            interfaces.TrashRepository
+	sessions         interfaces.SessionRepository
+	mfa              interfaces.MFARepository
+	userIdentities   interfaces.UserIdentityRepository
+	apiKeys          interfaces.APIKeyRepository
+}
+
+// newMailer creates the mailer selected by MAIL_DRIVER
+func newMailer(cfg *config.Config) interfaces.Mailer {
+	switch cfg.MailDriver {
+	case mail.DriverSMTP:
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	case mail.DriverFile:
+		mailer, err := mail.NewFileMailer(cfg.SMTPFrom, cfg.MailFilePath)
+		if err != nil {
+			fatal("Failed to open mail file", err)
 		}
+		return mailer
+	default:
+		return mail.NewWriterMailer(cfg.SMTPFrom, os.Stdout)
 	}
+}
 
-	// Test connection by listing buckets
-	_, err = minioClient.ListBuckets(ctx)
-	if err != nil {
-		return ServiceHealth{
-			Status: "error",
-			Error:  "Connection failed: " + err.Error(),
-		}
+func newGormRepositories(db *gorm.DB) *repositorySet {
+	return &repositorySet{
+		users:            repositories.NewUserRepository(db),
+		enterprises:      repositories.NewEnterpriseRepository(db),
+		menus:            repositories.NewMenuRepository(db),
+		towers:           repositories.NewTowerRepository(db),
+		floors:           repositories.NewFloorRepository(db),
+		suites:           repositories.NewSuiteRepository(db),
+		menuCarousels:    repositories.NewMenuCarouselRepository(db),
+		carouselItems:    repositories.NewCarouselItemRepository(db),
+		carouselOverlays: repositories.NewCarouselTextOverlayRepository(db),
+		menuPins:         repositories.NewMenuPinsRepository(db),
+		pinMarkers:       repositories.NewPinMarkerRepository(db),
+		pinMarkerImages:  repositories.NewPinMarkerImageRepository(db),
+		files:            repositories.NewFileRepository(db),
+		fileVariants:     repositories.NewFileVariantRepository(db),
+		auditLogs:        repositories.NewAuditLogRepository(db),
+		trash:            repositories.NewTrashRepository(db),
+		sessions:         repositories.NewSessionRepository(db),
+		mfa:              repositories.NewMFARepository(db),
+		userIdentities:   repositories.NewUserIdentityRepository(db),
+		apiKeys:          repositories.NewAPIKeyRepository(db),
 	}
+}
 
-	return ServiceHealth{
-		Status:  "ok",
-		Message: "Connected to " + cfg.MinIOEndpoint,
+func newMemoryRepositories(store *memory.Store) *repositorySet {
+	return &repositorySet{
+		users:            memory.NewUserRepository(store),
+		enterprises:      memory.NewEnterpriseRepository(store),
+		menus:            memory.NewMenuRepository(store),
+		towers:           memory.NewTowerRepository(store),
+		floors:           memory.NewFloorRepository(store),
+		suites:           memory.NewSuiteRepository(store),
+		menuCarousels:    memory.NewMenuCarouselRepository(store),
+		carouselItems:    memory.NewCarouselItemRepository(store),
+		carouselOverlays: memory.NewCarouselTextOverlayRepository(store),
+		menuPins:         memory.NewMenuPinsRepository(store),
+		pinMarkers:       memory.NewPinMarkerRepository(store),
+		pinMarkerImages:  memory.NewPinMarkerImageRepository(store),
+		files:            memory.NewFileRepository(store),
+		fileVariants:     memory.NewFileVariantRepository(store),
+		auditLogs:        memory.NewAuditLogRepository(store),
+		trash:            memory.NewTrashRepository(store),
+		sessions:         memory.NewSessionRepository(store),
+		mfa:              memory.NewMFARepository(store),
+		userIdentities:   memory.NewUserIdentityRepository(store),
+		apiKeys:          memory.NewAPIKeyRepository(store),
 	}
 }
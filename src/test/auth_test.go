@@ -0,0 +1,138 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"terra-allwert/api/handlers"
+	"terra-allwert/infra/auth"
+)
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	var body handlers.AuthResponse
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    "admin@allwert",
+		"password": seedPassword,
+	}), http.StatusOK, &body)
+	if body.User.Email != "admin@allwert" || body.TokenPair == nil || body.TokenPair.RefreshToken == "" {
+		t.Fatalf("logged in as %s with tokens %+v", body.User.Email, body.TokenPair)
+	}
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/auth/profile", body.TokenPair.AccessToken, nil), http.StatusOK)
+
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    "admin@allwert",
+		"password": "wrong-password",
+	}), http.StatusUnauthorized)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    "nobody@allwert",
+		"password": seedPassword,
+	}), http.StatusUnauthorized)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"email": "admin@allwert"}), http.StatusBadRequest)
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	s := newTestServer(t, nil)
+	tokens := s.login(t, "admin@allwert")
+
+	var rotated auth.TokenPair
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": tokens.RefreshToken}), http.StatusOK, &rotated)
+	if rotated.RefreshToken == "" || rotated.RefreshToken == tokens.RefreshToken {
+		t.Fatal("refresh did not rotate the refresh token")
+	}
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/auth/profile", rotated.AccessToken, nil), http.StatusOK)
+
+	// Reusing a rotated token revokes the session, its newer tokens too
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": tokens.RefreshToken}), http.StatusUnauthorized)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": rotated.RefreshToken}), http.StatusUnauthorized)
+
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": "not-a-token"}), http.StatusUnauthorized)
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	s := newTestServer(t, nil)
+	tokens := s.login(t, "admin@allwert")
+
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/logout", tokens.AccessToken, map[string]string{"refresh_token": tokens.RefreshToken}), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/auth/profile", tokens.AccessToken, nil), http.StatusUnauthorized)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": tokens.RefreshToken}), http.StatusUnauthorized)
+
+	// Other sessions stay logged in
+	other := s.login(t, "admin@allwert")
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/auth/profile", other.AccessToken, nil), http.StatusOK)
+}
+
+func TestLoginWithTOTP(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@allwert").AccessToken
+
+	var setup handlers.TOTPSetupResponse
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/mfa/totp/setup", token, nil), http.StatusOK, &setup)
+	step := time.Now().Unix() / 30
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/mfa/totp/enable", token, map[string]string{"code": "000000"}), http.StatusUnauthorized)
+	var recovery handlers.RecoveryCodesResponse
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/mfa/totp/enable", token, map[string]string{"code": totp(t, setup.Secret, step)}), http.StatusOK, &recovery)
+	if len(recovery.RecoveryCodes) == 0 {
+		t.Fatal("enabling TOTP returned no recovery codes")
+	}
+
+	// The password alone only starts a challenge
+	challenge := startMFALogin(t, s)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "code": "000000"}), http.StatusUnauthorized)
+	// The code enabling TOTP is used up
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "code": totp(t, setup.Secret, step)}), http.StatusUnauthorized)
+	var body handlers.AuthResponse
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "code": totp(t, setup.Secret, step+1)}), http.StatusOK, &body)
+	if body.TokenPair == nil || body.TokenPair.AccessToken == "" {
+		t.Fatal("login returned no tokens")
+	}
+	// Challenges are answered once
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "code": totp(t, setup.Secret, step-1)}), http.StatusUnauthorized)
+
+	// Recovery codes log in once each
+	challenge = startMFALogin(t, s)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "recovery_code": recovery.RecoveryCodes[0]}), http.StatusOK)
+	challenge = startMFALogin(t, s)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/auth/login/mfa", "", map[string]string{"mfa_token": challenge, "recovery_code": recovery.RecoveryCodes[0]}), http.StatusUnauthorized)
+}
+
+// startMFALogin logs admin@allwert in with their password, returning the token of the
+// second factor challenge
+func startMFALogin(t *testing.T, s *testServer) string {
+	t.Helper()
+
+	var challenge handlers.MFAChallengeResponse
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email":    "admin@allwert",
+		"password": seedPassword,
+	}), http.StatusAccepted, &challenge)
+	if !challenge.MFARequired || challenge.MFAToken == "" {
+		t.Fatalf("login returned challenge %+v", challenge)
+	}
+	return challenge.MFAToken
+}
+
+// totp computes the code of secret for a 30 second time step (RFC 6238)
+func totp(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid TOTP secret %q: %v", secret, err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:])&0x7fffffff)%1000000)
+}
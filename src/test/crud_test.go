@@ -0,0 +1,187 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"terra-allwert/domain/entities"
+)
+
+func TestEnterpriseCRUD(t *testing.T) {
+	s := newTestServer(t, nil)
+	// A super admin manages the enterprises beyond their own
+	token := s.login(t, "admin@terra.com").AccessToken
+
+	var created entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodPost, "/api/v1/enterprises", token, map[string]any{
+		"title":         "Jardim Allwert",
+		"slug":          "jardim-allwert",
+		"address_city":  "Pelotas",
+		"address_state": "rs",
+	}), http.StatusCreated, &created)
+	if created.Status != entities.EnterpriseStatusActive || created.AddressState != "RS" {
+		t.Fatalf("created enterprise with status %s in %s, want active in RS", created.Status, created.AddressState)
+	}
+	path := "/api/v1/enterprises/" + created.ID.String()
+
+	var fetched entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodGet, path, token, nil), http.StatusOK, &fetched)
+	if fetched.Slug != "jardim-allwert" {
+		t.Fatalf("fetched enterprise %s, want jardim-allwert", fetched.Slug)
+	}
+	expectJSON(t, s.doAll(t, http.MethodGet, "/api/v1/enterprises/slug/jardim-allwert", token, nil), http.StatusOK, &fetched)
+
+	var updated entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodPut, path, token, map[string]any{
+		"title":         "Jardim Allwert II",
+		"slug":          "jardim-allwert",
+		"address_city":  "Rio Grande",
+		"address_state": "RS",
+		"status":        "construction",
+	}), http.StatusOK, &updated)
+	if updated.Title != "Jardim Allwert II" || updated.AddressCity != "Rio Grande" || updated.Status != entities.EnterpriseStatusConstruction {
+		t.Fatalf("updated enterprise to %q in %s, %s", updated.Title, updated.AddressCity, updated.Status)
+	}
+
+	// A merge patch changes only the members it names
+	var patched entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodPatch, path, token, map[string]any{"status": "completed"}), http.StatusOK, &patched)
+	if patched.Status != entities.EnterpriseStatusCompleted || patched.Title != "Jardim Allwert II" {
+		t.Fatalf("patched enterprise to %q, %s", patched.Title, patched.Status)
+	}
+
+	var listed []entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodGet, "/api/v1/enterprises?limit=10", token, nil), http.StatusOK, &listed)
+	if len(listed) != 2 {
+		t.Fatalf("listed %d enterprises, want the seeded one and the created one", len(listed))
+	}
+
+	expectStatus(t, s.doAll(t, http.MethodDelete, path, token, nil), http.StatusNoContent)
+	expectStatus(t, s.doAll(t, http.MethodGet, path, token, nil), http.StatusNotFound)
+}
+
+func TestEnterpriseValidation(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@terra.com").AccessToken
+
+	expectStatus(t, s.doAll(t, http.MethodPost, "/api/v1/enterprises", token, map[string]any{
+		"title": "No",
+		"slug":  "Not A Slug",
+	}), http.StatusBadRequest)
+
+	// Slugs are unique
+	expectStatus(t, s.doAll(t, http.MethodPost, "/api/v1/enterprises", token, map[string]any{
+		"title":         "Terra Allwert",
+		"slug":          "allwert",
+		"address_city":  "Pelotas",
+		"address_state": "RS",
+	}), http.StatusConflict)
+
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/enterprises/not-an-id", token, nil), http.StatusBadRequest)
+}
+
+func TestMenuCRUD(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@allwert").AccessToken
+
+	var created entities.Menu
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/menus", token, map[string]any{
+		"enterprise_id": s.enterpriseID,
+		"title":         "Plantas",
+		"slug":          "plantas",
+		"screen_type":   "floor_plan",
+	}), http.StatusCreated, &created)
+	if created.EnterpriseID != s.enterpriseID || !created.IsVisible {
+		t.Fatalf("created menu in enterprise %s visible %t", created.EnterpriseID, created.IsVisible)
+	}
+	path := "/api/v1/menus/" + created.ID.String()
+
+	var child entities.Menu
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/menus", token, map[string]any{
+		"enterprise_id":  s.enterpriseID,
+		"parent_menu_id": created.ID,
+		"title":          "Torre A",
+		"slug":           "torre-a",
+		"screen_type":    "pins",
+		"menu_type":      "submenu",
+	}), http.StatusCreated, &child)
+
+	var children []entities.Menu
+	expectJSON(t, s.do(t, http.MethodGet, path+"/children", token, nil), http.StatusOK, &children)
+	if len(children) != 1 || children[0].ID != child.ID {
+		t.Fatalf("listed %d children, want the created submenu", len(children))
+	}
+
+	var patched entities.Menu
+	expectJSON(t, s.do(t, http.MethodPatch, path, token, map[string]any{"title": "Plantas baixas", "is_visible": false}), http.StatusOK, &patched)
+	if patched.Title != "Plantas baixas" || patched.IsVisible || patched.Slug != "plantas" {
+		t.Fatalf("patched menu to %q (%s) visible %t", patched.Title, patched.Slug, patched.IsVisible)
+	}
+
+	// the submenu restricts the deletion of its parent
+	expectStatus(t, s.do(t, http.MethodDelete, path, token, nil), http.StatusConflict)
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/menus/"+child.ID.String(), token, nil), http.StatusNoContent)
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/menus/"+child.ID.String(), token, nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodGet, path, token, nil), http.StatusOK)
+}
+
+func TestCRUDRequiresAuthorization(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/menus", "", nil), http.StatusUnauthorized)
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/menus", "not-a-token", nil), http.StatusUnauthorized)
+
+	// Visitors read, they do not write
+	token := s.login(t, "visitor@allwert").AccessToken
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/menus", token, nil), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/menus", token, map[string]any{
+		"enterprise_id": s.enterpriseID,
+		"title":         "Plantas",
+		"slug":          "plantas",
+		"screen_type":   "floor_plan",
+	}), http.StatusForbidden)
+
+	// Only super admins create enterprises
+	token = s.login(t, "admin@allwert").AccessToken
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/enterprises", token, map[string]any{
+		"title":         "Jardim Allwert",
+		"slug":          "jardim-allwert",
+		"address_city":  "Pelotas",
+		"address_state": "RS",
+	}), http.StatusForbidden)
+}
+
+func TestCRUDIsScopedToEnterprise(t *testing.T) {
+	s := newTestServer(t, nil)
+	superAdmin := s.login(t, "admin@terra.com").AccessToken
+
+	var other entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodPost, "/api/v1/enterprises", superAdmin, map[string]any{
+		"title":         "Outro Empreendimento",
+		"slug":          "outro",
+		"address_city":  "Pelotas",
+		"address_state": "RS",
+	}), http.StatusCreated, &other)
+	var menu entities.Menu
+	expectJSON(t, s.doAll(t, http.MethodPost, "/api/v1/menus", superAdmin, map[string]any{
+		"enterprise_id": other.ID,
+		"title":         "Plantas",
+		"slug":          "plantas",
+		"screen_type":   "floor_plan",
+	}), http.StatusCreated, &menu)
+
+	// The admin of the seeded enterprise neither sees nor changes the menus of another
+	token := s.login(t, "admin@allwert").AccessToken
+	path := "/api/v1/menus/" + menu.ID.String()
+	expectStatus(t, s.do(t, http.MethodGet, path, token, nil), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodPatch, path, token, map[string]any{"title": "Tomado"}), http.StatusNotFound)
+	expectStatus(t, s.do(t, http.MethodDelete, path, token, nil), http.StatusNotFound)
+
+	var menus []entities.Menu
+	expectJSON(t, s.do(t, http.MethodGet, "/api/v1/menus", token, nil), http.StatusOK, &menus)
+	for _, m := range menus {
+		if m.EnterpriseID != s.enterpriseID {
+			t.Fatalf("listed menu %s of enterprise %s", m.ID, m.EnterpriseID)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"terra-allwert/api/handlers"
+	"terra-allwert/domain/entities"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/repositories/memory"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcClientID    = "terra-allwert"
+	oidcRedirectURL = "http://localhost/auth/oidc/callback"
+	oidcKeyID       = "test-key"
+)
+
+// oidcProvider is an OpenID Connect provider serving discovery, its keys and the token
+// endpoint. Users are logged in by authorize, which issues the code of a login.
+type oidcProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]oidcAuthorization
+}
+
+// oidcAuthorization is a login the provider issued a code for
+type oidcAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newOIDCProvider(t *testing.T) *oidcProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate provider key: %v", err)
+	}
+	p := &oidcProvider{key: key, codes: make(map[string]oidcAuthorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, auth.JWKS{Keys: []auth.JWK{{
+			KeyType:   "RSA",
+			KeyID:     oidcKeyID,
+			Use:       "sig",
+			Algorithm: "RS256",
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize logs a user in at the authorization URL the API redirected to, returning
+// the state to send back and the code. The ID token carries the standard claims of the
+// login, overridden by claims.
+func (p *oidcProvider) authorize(t *testing.T, location string, claims jwt.MapClaims) (state, code string) {
+	t.Helper()
+
+	authorizationURL, err := url.Parse(location)
+	if err != nil {
+		t.Fatalf("invalid authorization URL %q: %v", location, err)
+	}
+	query := authorizationURL.Query()
+	if query.Get("client_id") != oidcClientID || query.Get("redirect_uri") != oidcRedirectURL {
+		t.Fatalf("authorization URL names client %q and redirect %q", query.Get("client_id"), query.Get("redirect_uri"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL carries no S256 code challenge: %s", location)
+	}
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            oidcClientID,
+		"sub":            "subject-1",
+		"nonce":          query.Get("nonce"),
+		"email":          "admin@allwert",
+		"email_verified": true,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	code = base64.RawURLEncoding.EncodeToString(raw)
+	p.mu.Lock()
+	p.codes[code] = oidcAuthorization{challenge: query.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+	return query.Get("state"), code
+}
+
+// token redeems a code once, for the client presenting the verifier of its challenge
+func (p *oidcProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	p.mu.Lock()
+	authorization, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != oidcClientID ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, authorization.claims)
+	token.Header["kid"] = oidcKeyID
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newOIDCServer starts the API with single sign-on at provider, provisioning users
+// without an account as visitors of the seeded enterprise when provision is set
+func newOIDCServer(t *testing.T, provider *oidcProvider, provision bool) *testServer {
+	t.Helper()
+
+	return newTestServer(t, func(s *testServer) *handlers.AuthOIDC {
+		authOIDC := &handlers.AuthOIDC{
+			Provider: auth.NewOIDCProvider(auth.OIDCConfig{
+				IssuerURL:    provider.server.URL,
+				ClientID:     oidcClientID,
+				ClientSecret: "secret",
+				RedirectURL:  oidcRedirectURL,
+			}, s.tokens),
+			Identities:    memory.NewUserIdentityRepository(s.store),
+			ProvisionRole: entities.UserRoleVisitor,
+		}
+		if provision {
+			authOIDC.ProvisionEnterpriseID = s.enterpriseID
+		}
+		return authOIDC
+	})
+}
+
+// startOIDCLogin starts a login at the API, returning where it redirected to
+func startOIDCLogin(t *testing.T, s *testServer) string {
+	t.Helper()
+
+	resp := s.do(t, http.MethodGet, "/api/v1/auth/oidc/login", "", nil)
+	expectStatus(t, resp, http.StatusFound)
+	return resp.Header.Get("Location")
+}
+
+// completeOIDCLogin sends the state and code of a login back to the API
+func completeOIDCLogin(t *testing.T, s *testServer, state, code string) *http.Response {
+	t.Helper()
+
+	query := url.Values{"state": {state}, "code": {code}}
+	return s.do(t, http.MethodGet, "/api/v1/auth/oidc/callback?"+query.Encode(), "", nil)
+}
+
+func TestOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, false)
+
+	state, code := provider.authorize(t, startOIDCLogin(t, s), nil)
+	var body handlers.AuthResponse
+	expectJSON(t, completeOIDCLogin(t, s, state, code), http.StatusOK, &body)
+	if body.TokenPair == nil || body.TokenPair.AccessToken == "" {
+		t.Fatal("login returned no tokens")
+	}
+	if body.User.Email != "admin@allwert" {
+		t.Fatalf("logged in as %s, want admin@allwert", body.User.Email)
+	}
+
+	// The identity is linked, the subject logs in without the provider vouching for
+	// the address again
+	state, code = provider.authorize(t, startOIDCLogin(t, s), jwt.MapClaims{"email": "renamed@example.com", "email_verified": false})
+	expectJSON(t, completeOIDCLogin(t, s, state, code), http.StatusOK, &body)
+	if body.User.Email != "admin@allwert" {
+		t.Fatalf("linked identity logged in as %s, want admin@allwert", body.User.Email)
+	}
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, true)
+
+	state, code := provider.authorize(t, startOIDCLogin(t, s), jwt.MapClaims{"sub": "subject-2", "email": "New.User@Example.com", "name": "New User"})
+	var body handlers.AuthResponse
+	expectJSON(t, completeOIDCLogin(t, s, state, code), http.StatusOK, &body)
+	if body.User.Email != "new.user@example.com" || body.User.Role != entities.UserRoleVisitor {
+		t.Fatalf("provisioned %s as %s, want new.user@example.com as visitor", body.User.Email, body.User.Role)
+	}
+}
+
+func TestOIDCLoginRejectsCodeOfAnotherLogin(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, false)
+
+	// The code of one login redeemed with the state, and so the PKCE verifier, of
+	// another fails at the token endpoint
+	_, code := provider.authorize(t, startOIDCLogin(t, s), nil)
+	state, _ := provider.authorize(t, startOIDCLogin(t, s), nil)
+	expectStatus(t, completeOIDCLogin(t, s, state, code), http.StatusUnauthorized)
+}
+
+func TestOIDCLoginUsesStateOnce(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, false)
+
+	state, code := provider.authorize(t, startOIDCLogin(t, s), nil)
+	expectStatus(t, completeOIDCLogin(t, s, state, code), http.StatusOK)
+	expectStatus(t, completeOIDCLogin(t, s, state, code), http.StatusBadRequest)
+	expectStatus(t, completeOIDCLogin(t, s, "unknown", code), http.StatusBadRequest)
+}
+
+func TestOIDCLoginRejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"nonce mismatch", jwt.MapClaims{"nonce": "another-nonce"}},
+		{"no nonce", jwt.MapClaims{"nonce": nil}},
+		{"wrong audience", jwt.MapClaims{"aud": "another-client"}},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://attacker.example.com"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{"no subject", jwt.MapClaims{"sub": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newOIDCProvider(t)
+			s := newOIDCServer(t, provider, false)
+
+			state, code := provider.authorize(t, startOIDCLogin(t, s), tt.claims)
+			expectStatus(t, completeOIDCLogin(t, s, state, code), http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestOIDCLoginRejectsUnverifiedEmail(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, true)
+
+	// An address the provider did not verify claims neither an account nor a new one
+	for _, email := range []string{"admin@allwert", "unverified@example.com"} {
+		state, code := provider.authorize(t, startOIDCLogin(t, s), jwt.MapClaims{"email": email, "email_verified": "false"})
+		expectStatus(t, completeOIDCLogin(t, s, state, code), http.StatusForbidden)
+	}
+}
+
+func TestOIDCLoginReportsProviderError(t *testing.T) {
+	provider := newOIDCProvider(t)
+	s := newOIDCServer(t, provider, false)
+
+	query := url.Values{"error": {"access_denied"}, "error_description": {"User cancelled"}}
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/auth/oidc/callback?"+query.Encode(), "", nil), http.StatusUnauthorized)
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"terra-allwert/api/handlers"
+	"terra-allwert/api/routes"
+	"terra-allwert/infra/auth"
+	"terra-allwert/infra/cache"
+	"terra-allwert/infra/mail"
+	"terra-allwert/infra/metrics"
+	"terra-allwert/infra/middleware"
+	"terra-allwert/infra/problem"
+	"terra-allwert/infra/repositories/memory"
+	"terra-allwert/infra/storage"
+	"terra-allwert/infra/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// seedPassword is the password of the users of the seeded in-memory store
+const seedPassword = "senha123"
+
+// testServer is the API wired to the in-memory repositories and cache, as run with
+// DB_DRIVER=memory
+type testServer struct {
+	app    *fiber.App
+	store  *memory.Store
+	tokens cache.Store
+	// enterpriseID is the seeded enterprise, of every seeded user
+	enterpriseID uuid.UUID
+}
+
+// newTestServer starts the API on a seeded in-memory store, with single sign-on through
+// oidc when it is not nil
+func newTestServer(t *testing.T, oidc func(*testServer) *handlers.AuthOIDC) *testServer {
+	t.Helper()
+
+	store := memory.NewStore()
+	if err := store.Seed(); err != nil {
+		t.Fatalf("failed to seed store: %v", err)
+	}
+	tokens := cache.NewMemoryStore()
+	enterprise, err := memory.NewEnterpriseRepository(store).GetBySlug(context.Background(), "allwert")
+	if err != nil {
+		t.Fatalf("failed to find the seeded enterprise: %v", err)
+	}
+	s := &testServer{store: store, tokens: tokens, enterpriseID: enterprise.ID}
+
+	users := memory.NewUserRepository(store)
+	files := memory.NewFileRepository(store)
+	fileVariants := memory.NewFileVariantRepository(store)
+	apiKeys := auth.NewAPIKeys(memory.NewAPIKeyRepository(store), tokens, 120)
+	auditLogs := memory.NewAuditLogRepository(store)
+	enterprises := memory.NewEnterpriseRepository(store)
+
+	jwtService := auth.NewJWTService(auth.NewHMACKeySet("test-secret"), 15*time.Minute, time.Hour)
+	jwtService.SetBlacklist(auth.NewTokenBlacklist(tokens))
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, users)
+	authMiddleware.SetVerificationPolicy(auth.VerificationOptional)
+	authMiddleware.SetAPIKeys(apiKeys)
+
+	templates, err := mail.LoadTemplates()
+	if err != nil {
+		t.Fatalf("failed to load mail templates: %v", err)
+	}
+	emails := &handlers.AuthEmails{
+		Mailer:              mail.NewWriterMailer("noreply@test", io.Discard),
+		Templates:           templates,
+		PasswordResets:      auth.NewOneTimeTokens(tokens, "password_reset", 30*time.Minute),
+		PasswordResetURL:    "http://localhost/reset-password",
+		Verifications:       auth.NewOneTimeTokens(tokens, "email_verification", 24*time.Hour),
+		VerificationURL:     "http://localhost/verify-email",
+		VerificationResends: auth.NewThrottle(tokens, "email_verification", time.Minute),
+		VerificationPolicy:  auth.VerificationOptional,
+	}
+	mfa := &handlers.AuthMFA{
+		Service:     auth.NewMFAService(tokens, "Terra Allwert", 5*time.Minute),
+		Enrollments: memory.NewMFARepository(store),
+		Enterprises: enterprises,
+	}
+	lockout := auth.NewLoginLockout(tokens, auth.LockoutPolicy{
+		MaxAttempts:   5,
+		MaxIPAttempts: 20,
+		Duration:      15 * time.Minute,
+	})
+	var authOIDC *handlers.AuthOIDC
+	if oidc != nil {
+		authOIDC = oidc(s)
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
+	app.Use(middleware.RequestID())
+	app.Use(authMiddleware.LogUserActivity())
+	api := app.Group("/api/v1")
+	routes.SetupAuthRoutes(api, users, auditLogs, memory.NewSessionRepository(store), jwtService, emails, mfa, lockout, authOIDC, authMiddleware)
+
+	storageService, err := storage.NewMemoryStorageService("http://localhost/storage", "test")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	rateLimiter := middleware.NewUploadRateLimiter(middleware.DefaultRateLimitConfig())
+	circuitBreaker := middleware.NewCircuitBreaker(middleware.CircuitBreakerConfig{
+		MaxFailures:   5,
+		ResetTimeout:  30 * time.Second,
+		CheckInterval: 10 * time.Second,
+	})
+	routes.SetupAllRoutes(app, &routes.Handlers{
+		EnterpriseHandler:  handlers.NewEnterpriseHandler(enterprises),
+		MenuHandler:        handlers.NewMenuHandler(memory.NewMenuRepository(store)),
+		TowerHandler:       handlers.NewTowerHandler(memory.NewTowerRepository(store)),
+		FloorHandler:       handlers.NewFloorHandler(memory.NewFloorRepository(store)),
+		SuiteHandler:       handlers.NewSuiteHandler(memory.NewSuiteRepository(store)),
+		CarouselHandler:    handlers.NewCarouselHandler(memory.NewMenuCarouselRepository(store), memory.NewCarouselItemRepository(store), memory.NewCarouselTextOverlayRepository(store)),
+		PinsHandler:        handlers.NewPinsHandler(memory.NewMenuPinsRepository(store), memory.NewPinMarkerRepository(store), memory.NewPinMarkerImageRepository(store)),
+		FileHandler:        handlers.NewFileHandler(files, fileVariants, storageService, storage.NewUploadStateManagerWithStore(cache.NewMemoryStore()), websocket.NewProgressHub(), rateLimiter, circuitBreaker, metrics.New()),
+		FileVariantHandler: handlers.NewFileVariantHandler(files, fileVariants, storageService),
+		AuditHandler:       handlers.NewAuditHandler(auditLogs),
+		TrashHandler:       handlers.NewTrashHandler(memory.NewTrashRepository(store), storageService, 30),
+		UserHandler:        handlers.NewUserHandler(users, auditLogs, lockout),
+		APIKeyHandler:      handlers.NewAPIKeyHandler(memory.NewAPIKeyRepository(store), apiKeys),
+	}, authMiddleware, rateLimiter)
+
+	s.app = app
+	return s
+}
+
+// do sends a request to the API, with body encoded as JSON unless it is nil and token
+// as bearer token unless it is empty
+func (s *testServer) do(t *testing.T, method, path, token string, body any) *http.Response {
+	t.Helper()
+	return s.send(t, newRequest(t, method, path, token, body))
+}
+
+// doAll sends a request like do, for a super admin acting on every enterprise
+func (s *testServer) doAll(t *testing.T, method, path, token string, body any) *http.Response {
+	t.Helper()
+
+	req := newRequest(t, method, path, token, body)
+	req.Header.Set(middleware.TenantScopeHeader, middleware.TenantScopeAll)
+	return s.send(t, req)
+}
+
+func (s *testServer) send(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", req.Method, req.URL.Path, err)
+	}
+	return resp
+}
+
+func newRequest(t *testing.T, method, path, token string, body any) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+// login logs a seeded user in with their password, returning their tokens
+func (s *testServer) login(t *testing.T, email string) *auth.TokenPair {
+	t.Helper()
+
+	resp := s.do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"email": email, "password": seedPassword})
+	var body handlers.AuthResponse
+	expectJSON(t, resp, http.StatusOK, &body)
+	if body.TokenPair == nil || body.TokenPair.AccessToken == "" {
+		t.Fatalf("login of %s returned no tokens", email)
+	}
+	return body.TokenPair
+}
+
+// expectStatus fails the test unless resp has status
+func expectStatus(t *testing.T, resp *http.Response, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s: got status %d, want %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, status, body)
+	}
+}
+
+// expectJSON fails the test unless resp has status, then decodes its body into v
+func expectJSON(t *testing.T, resp *http.Response, status int, v any) {
+	t.Helper()
+
+	expectStatus(t, resp, status)
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s %s: failed to decode response: %v", resp.Request.Method, resp.Request.URL.Path, err)
+	}
+}
@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"terra-allwert/domain/entities"
+	"terra-allwert/domain/interfaces"
+	"terra-allwert/infra/repositories/memory"
+
+	"github.com/google/uuid"
+)
+
+// createMenu creates a menu in the seeded enterprise, under parent unless it is nil
+func createMenu(t *testing.T, s *testServer, token, slug string, parent *uuid.UUID) entities.Menu {
+	t.Helper()
+
+	var menu entities.Menu
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/menus", token, map[string]any{
+		"enterprise_id":  s.enterpriseID,
+		"parent_menu_id": parent,
+		"title":          slug,
+		"slug":           slug,
+		"screen_type":    "list",
+	}), http.StatusCreated, &menu)
+	return menu
+}
+
+// listTrash lists the trash of the enterprise of token
+func listTrash(t *testing.T, s *testServer, token, query string) []interfaces.TrashItem {
+	t.Helper()
+
+	var items []interfaces.TrashItem
+	expectJSON(t, s.do(t, http.MethodGet, "/api/v1/trash"+query, token, nil), http.StatusOK, &items)
+	return items
+}
+
+func TestTrashRestoresDeletedMenu(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@allwert").AccessToken
+
+	parent := createMenu(t, s, token, "plantas", nil)
+	child := createMenu(t, s, token, "torre-a", &parent.ID)
+
+	// Menus with submenus are not deleted
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/menus/"+parent.ID.String(), token, nil), http.StatusConflict)
+
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/menus/"+child.ID.String(), token, nil), http.StatusNoContent)
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/menus/"+child.ID.String(), token, nil), http.StatusNotFound)
+	items := listTrash(t, s, token, "?entity_type=menus")
+	if len(items) != 1 || items[0].ID != child.ID || items[0].EntityType != "menus" {
+		t.Fatalf("trash lists %+v, want the deleted menu", items)
+	}
+
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/menus/"+child.ID.String()+"/restore", token, nil), http.StatusNoContent)
+	var restored entities.Menu
+	expectJSON(t, s.do(t, http.MethodGet, "/api/v1/menus/"+child.ID.String(), token, nil), http.StatusOK, &restored)
+	if restored.ParentMenuID == nil || *restored.ParentMenuID != parent.ID {
+		t.Fatalf("restored menu under %v, want %s", restored.ParentMenuID, parent.ID)
+	}
+	if items := listTrash(t, s, token, ""); len(items) != 0 {
+		t.Fatalf("trash lists %+v after the restore, want nothing", items)
+	}
+
+	// Restored items are gone from the trash
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/menus/"+child.ID.String()+"/restore", token, nil), http.StatusNotFound)
+}
+
+func TestTrashRestoresFileReferences(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@allwert").AccessToken
+
+	var file entities.File
+	expectJSON(t, s.do(t, http.MethodPost, "/api/v1/files", token, map[string]any{
+		"file_type":       "image",
+		"mime_type":       "image/png",
+		"extension":       "png",
+		"storage_path":    "logos/allwert.png",
+		"file_size_bytes": 1024,
+		"original_name":   "allwert.png",
+	}), http.StatusCreated, &file)
+	if file.EnterpriseID != s.enterpriseID {
+		t.Fatalf("created file in enterprise %s, want %s", file.EnterpriseID, s.enterpriseID)
+	}
+	enterprisePath := "/api/v1/enterprises/" + s.enterpriseID.String()
+	expectStatus(t, s.do(t, http.MethodPatch, enterprisePath, token, map[string]any{"logo_file_id": file.ID}), http.StatusOK)
+
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/files/"+file.ID.String(), token, nil), http.StatusNoContent)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/files/"+file.ID.String()+"/restore", token, nil), http.StatusNoContent)
+
+	// The logo still points at the restored file
+	var enterprise entities.Enterprise
+	expectJSON(t, s.do(t, http.MethodGet, enterprisePath, token, nil), http.StatusOK, &enterprise)
+	if enterprise.LogoFileID == nil || *enterprise.LogoFileID != file.ID {
+		t.Fatalf("enterprise logo is %v after restoring file %s", enterprise.LogoFileID, file.ID)
+	}
+}
+
+func TestTrashIsScopedToEnterprise(t *testing.T) {
+	s := newTestServer(t, nil)
+	superAdmin := s.login(t, "admin@terra.com").AccessToken
+
+	var other entities.Enterprise
+	expectJSON(t, s.doAll(t, http.MethodPost, "/api/v1/enterprises", superAdmin, map[string]any{
+		"title":         "Outro Empreendimento",
+		"slug":          "outro",
+		"address_city":  "Pelotas",
+		"address_state": "RS",
+	}), http.StatusCreated, &other)
+	var menu entities.Menu
+	expectJSON(t, s.doAll(t, http.MethodPost, "/api/v1/menus", superAdmin, map[string]any{
+		"enterprise_id": other.ID,
+		"title":         "Plantas",
+		"slug":          "plantas",
+		"screen_type":   "list",
+	}), http.StatusCreated, &menu)
+	expectStatus(t, s.doAll(t, http.MethodDelete, "/api/v1/menus/"+menu.ID.String(), superAdmin, nil), http.StatusNoContent)
+
+	token := s.login(t, "admin@allwert").AccessToken
+	if items := listTrash(t, s, token, ""); len(items) != 0 {
+		t.Fatalf("trash lists %+v of another enterprise", items)
+	}
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/menus/"+menu.ID.String()+"/restore", token, nil), http.StatusNotFound)
+}
+
+func TestTrashValidatesRequests(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.login(t, "admin@allwert").AccessToken
+
+	for _, query := range []string{"?limit=-1", "?limit=abc", "?offset=-2", "?entity_type=passwords"} {
+		expectStatus(t, s.do(t, http.MethodGet, "/api/v1/trash"+query, token, nil), http.StatusBadRequest)
+	}
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/trash?limit=1000000", token, nil), http.StatusOK)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/passwords/"+uuid.NewString()+"/restore", token, nil), http.StatusBadRequest)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/menus/not-an-id/restore", token, nil), http.StatusBadRequest)
+
+	// Visitors have no trash
+	visitor := s.login(t, "visitor@allwert").AccessToken
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/trash", visitor, nil), http.StatusForbidden)
+}
+
+func TestTrashKeepsDeletedUsersFromEditors(t *testing.T) {
+	s := newTestServer(t, nil)
+	admin := s.login(t, "admin@allwert").AccessToken
+	manager := s.login(t, "manager@allwert").AccessToken
+
+	visitor, err := memory.NewUserRepository(s.store).GetByEmail(context.Background(), "visitor@allwert")
+	if err != nil {
+		t.Fatalf("failed to find the seeded visitor: %v", err)
+	}
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/users/"+visitor.ID.String(), admin, nil), http.StatusNoContent)
+	menu := createMenu(t, s, manager, "plantas", nil)
+	expectStatus(t, s.do(t, http.MethodDelete, "/api/v1/menus/"+menu.ID.String(), manager, nil), http.StatusNoContent)
+
+	// Managers edit the content, not the users
+	if items := listTrash(t, s, manager, ""); len(items) != 1 || items[0].ID != menu.ID {
+		t.Fatalf("trash lists %+v to a manager, want only the deleted menu", items)
+	}
+	expectStatus(t, s.do(t, http.MethodGet, "/api/v1/trash?entity_type=users", manager, nil), http.StatusForbidden)
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/users/"+visitor.ID.String()+"/restore", manager, nil), http.StatusForbidden)
+
+	if items := listTrash(t, s, admin, "?entity_type=users"); len(items) != 1 || items[0].ID != visitor.ID {
+		t.Fatalf("trash lists %+v to an admin, want the deleted user", items)
+	}
+	expectStatus(t, s.do(t, http.MethodPost, "/api/v1/trash/users/"+visitor.ID.String()+"/restore", admin, nil), http.StatusNoContent)
+}